@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -31,6 +32,10 @@ type options struct {
 	config.Options
 
 	resolver           registry.Resolver
+	refs               registry.ReferenceByName
+	chains             registry.ChainByName
+	workflows          registry.WorkflowByName
+	graph              registry.NodeByName
 	ciOPConfigAgent    agents.ConfigAgent
 	clusterProfiles    api.ClusterProfilesMap
 	clusterClaimOwners api.ClusterClaimOwnersMap
@@ -129,7 +134,12 @@ func (o *options) loadResolver(path string) error {
 	if err != nil {
 		return err
 	}
+	graph, err := registry.NewGraph(refs, chains, workflows, observers)
+	if err != nil {
+		return err
+	}
 	o.resolver = registry.NewResolver(refs, chains, workflows, observers)
+	o.refs, o.chains, o.workflows, o.graph = refs, chains, workflows, graph
 	return nil
 }
 
@@ -144,6 +154,9 @@ func (o *options) validateConfiguration(
 		} else if err := validator.IsValidResolvedConfiguration(&c); err != nil {
 			return err
 		}
+		if err := o.checkDeprecations(configuration, time.Now()); err != nil {
+			return err
+		}
 	}
 	if _, err := o.ciOPConfigAgent.GetMatchingConfig(configuration.Metadata); err != nil {
 		return err
@@ -161,6 +174,28 @@ func (o *options) validateConfiguration(
 	return nil
 }
 
+// checkDeprecations warns about tests which execute deprecated components of
+// the registry and fails for those still executing them past their removal
+func (o *options) checkDeprecations(configuration api.ReleaseBuildConfiguration, now time.Time) error {
+	var removed []string
+	for _, test := range configuration.Tests {
+		if test.MultiStageTestConfiguration == nil {
+			continue
+		}
+		for _, deprecation := range registry.DeprecationsFor(*test.MultiStageTestConfiguration, o.graph, o.refs, o.chains, o.workflows) {
+			if deprecation.Lifecycle.Removed(now) {
+				removed = append(removed, fmt.Sprintf("test %s: %s", test.As, deprecation))
+				continue
+			}
+			logrus.WithFields(logrus.Fields{"config": configuration.Metadata.RelativePath(), "test": test.As}).Warn(deprecation.String())
+		}
+	}
+	if len(removed) > 0 {
+		return fmt.Errorf("configuration uses registry components past their removal date: %s", strings.Join(removed, ", "))
+	}
+	return nil
+}
+
 func validateTags(seen tagSet) []error {
 	var dupes []error
 	for tag, infos := range seen {
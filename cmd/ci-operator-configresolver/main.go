@@ -51,6 +51,8 @@ type options struct {
 	gracePeriod            time.Duration
 	validateOnly           bool
 	flatRegistry           bool
+	registryHistory        int
+	registryRevisionFile   string
 	instrumentationOptions flagutil.InstrumentationOptions
 }
 
@@ -73,6 +75,8 @@ func gatherOptions() (options, error) {
 	_ = fs.Duration("cycle", time.Minute*2, "Legacy flag kept for compatibility. Does nothing")
 	fs.BoolVar(&o.validateOnly, "validate-only", false, "Load the config and registry, validate them and exit.")
 	fs.BoolVar(&o.flatRegistry, "flat-registry", false, "Disable directory structure based registry validation")
+	fs.IntVar(&o.registryHistory, "registry-history", 10, "Number of revisions of the registry, including the current one, that configurations can be resolved against. Only revisions loaded since the server started are held, older ones are not available after a restart")
+	fs.StringVar(&o.registryRevisionFile, "registry-revision-file", "", "Path to a file holding the revision of the release repository the registry is synced from, written by the sync before the registry is updated. Defaults to the version file of the registry")
 	o.instrumentationOptions.AddFlags(fs)
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return o, fmt.Errorf("failed to parse flags: %w", err)
@@ -124,6 +128,10 @@ func validateOptions(o *options) error {
 		o.registryPath = filepath.Join(o.releaseRepoGitSyncPath, config.RegistryPath)
 	}
 
+	if o.registryHistory < 1 {
+		return errors.New("--registry-history must be at least 1")
+	}
+
 	if o.validateOnly && o.flatRegistry {
 		return errors.New("--validate-only and --flat-registry flags cannot be set simultaneously")
 	}
@@ -292,7 +300,7 @@ func main() {
 	go func() { logrus.Fatal(<-configErrCh) }()
 
 	registryErrCh := make(chan error)
-	registryAgent, err := agents.NewRegistryAgent(o.registryPath, registryErrCh, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry), registryAgentOption)
+	registryAgent, err := agents.NewRegistryAgent(o.registryPath, registryErrCh, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry), agents.WithRegistryHistory(o.registryHistory), agents.WithRegistryRevisionFile(o.registryRevisionFile), registryAgentOption)
 	if err != nil {
 		logrus.Fatalf("Failed to get registry agent: %v", err)
 	}
@@ -320,9 +328,11 @@ func main() {
 		l("config"),
 		l("resolve"),
 		l("clusterProfile"),
+		l("usages"),
 		l("configGeneration"),
 		l("registryGeneration"),
 		l("integratedStream"),
+		l("api", l("v1", l("registry", l("reference"), l("chain"), l("workflow")))),
 	))
 
 	uisimplifier := simplifypath.NewSimplifier(l("", // shadow element mimicing the root
@@ -332,6 +342,7 @@ func main() {
 		l("reference"),
 		l("chain"),
 		l("workflow"),
+		l("usages"),
 	))
 	handler := metrics.TraceHandler(simplifier, configresolverMetrics.HTTPRequestDuration, configresolverMetrics.HTTPResponseSize)
 	uihandler := metrics.TraceHandler(uisimplifier, configresolverMetrics.HTTPRequestDuration, configresolverMetrics.HTTPResponseSize)
@@ -341,8 +352,10 @@ func main() {
 	http.HandleFunc("/mergeConfigsWithInjectedTest", handler(registryserver.ResolveAndMergeConfigsAndInjectTest(configAgent, registryAgent, configresolverMetrics)).ServeHTTP)
 	http.HandleFunc("/resolve", handler(registryserver.ResolveLiteralConfig(registryAgent, configresolverMetrics)).ServeHTTP)
 	http.HandleFunc("/clusterProfile", handler(registryserver.ResolveClusterProfile(registryAgent, configresolverMetrics)).ServeHTTP)
+	http.HandleFunc("/usages", handler(registryserver.ResolveUsages(registryAgent, configAgent, configresolverMetrics)).ServeHTTP)
 	http.HandleFunc("/configGeneration", handler(getConfigGeneration(configAgent)).ServeHTTP)
 	http.HandleFunc("/registryGeneration", handler(getRegistryGeneration(registryAgent)).ServeHTTP)
+	http.HandleFunc(webreg.APIPrefix, handler(webreg.RegistryAPIHandler(registryAgent)).ServeHTTP)
 	cache := memoryCache{Client: ocClient, CacheDuration: time.Minute}
 	http.HandleFunc("/integratedStream", handler(getIntegratedStream(context.Background(), &cache)).ServeHTTP)
 	http.HandleFunc("/readyz", func(_ http.ResponseWriter, _ *http.Request) {})
@@ -84,6 +84,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/labeledclient"
 	"github.com/openshift/ci-tools/pkg/lease"
 	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/provenance"
 	"github.com/openshift/ci-tools/pkg/registry"
 	"github.com/openshift/ci-tools/pkg/registry/server"
 	"github.com/openshift/ci-tools/pkg/results"
@@ -171,10 +172,16 @@ In CI environments the inputs to a job may be different than what a normal
 development workflow would use. The --override file will override fields
 defined in the config file, such as base images and the release tag configuration.
 
+To review the effect of a change to the configuration or to the step registry,
+--render writes the pods, builds and other objects the steps would create to a
+directory and exits without accessing a cluster.
+
 After a successful build the --promote will tag each built image (in "images")
 to the image stream(s) identified by the "promotion" config. You may add
 additional images to promote and their target names via the "additional_images"
-map.
+map. With --promotion-provenance, an in-toto statement describing how each image
+was built is attached to it in the registry, signed with the key passed to
+--promotion-provenance-signing-key if any. provenance-verifier checks it.
 `
 
 const (
@@ -360,15 +367,23 @@ type options struct {
 	targets stringSlice
 	promote bool
 
+	promotionProvenance           bool
+	promotionProvenanceSigningKey string
+	promotionProvenanceImage      string
+	provenanceOptions             *provenance.Options
+
 	verbose    bool
 	help       bool
 	printGraph bool
+	renderDir  string
 
 	writeParams string
 	artifactDir string
 
 	gitRef                 string
 	namespace              string
+	resumeFrom             string
+	recordExecutionState   bool
 	baseNamespace          string
 	extraInputHash         stringSlice
 	idleCleanupDuration    time.Duration
@@ -389,6 +404,8 @@ type options struct {
 	leaseServer                string
 	leaseServerCredentialsFile string
 	leaseAcquireTimeout        time.Duration
+	leasePriorityPolicyPath    string
+	jobLabelsPath              string
 	leaseClient                lease.Client
 	clusterProfiles            []clusterProfileForTarget
 
@@ -399,11 +416,13 @@ type options struct {
 	resolverAddress string
 	resolverClient  server.ResolverClient
 
-	registryPath string
-	org          string
-	repo         string
-	branch       string
-	variant      string
+	registryPath             string
+	registryRevision         string
+	resolvedRegistryRevision string
+	org                      string
+	repo                     string
+	branch                   string
+	variant                  string
 
 	injectTest string
 
@@ -436,6 +455,7 @@ type options struct {
 
 	restrictNetworkAccess       bool
 	enableSecretsStoreCSIDriver bool
+	enableBuildAvoidance        bool
 }
 
 func bindOptions(flag *flag.FlagSet) *options {
@@ -455,11 +475,14 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.leaseServer, "lease-server", leaseServerAddress, "Address of the server that manages leases. Required if any test is configured to acquire a lease.")
 	flag.StringVar(&opt.leaseServerCredentialsFile, "lease-server-credentials-file", "", "The path to credentials file used to access the lease server. The content is of the form <username>:<password>.")
 	flag.DurationVar(&opt.leaseAcquireTimeout, "lease-acquire-timeout", leaseAcquireTimeout, "Maximum amount of time to wait for lease acquisition")
+	flag.StringVar(&opt.leasePriorityPolicyPath, "lease-priority-policy", "", "Path to a file mapping job labels to the priority with which leases are acquired. If not specified, payload jobs and rehearsals are recognized by their default labels.")
+	flag.StringVar(&opt.jobLabelsPath, "job-labels-file", "", "Path to the labels of the job, as projected by the downward API, used to determine the priority of lease acquisition. If not specified, the priority is determined by the type and name of the job, and periodics acquire leases without giving up the place in the queue.")
 	flag.StringVar(&opt.registryPath, "registry", "", "Path to the step registry directory")
 	flag.StringVar(&opt.configSpecPath, "config", "", "The configuration file. If not specified the CONFIG_SPEC environment variable or the configresolver will be used.")
 	flag.StringVar(&opt.unresolvedConfigPath, "unresolved-config", "", "The configuration file, before resolution. If not specified the UNRESOLVED_CONFIG environment variable will be used, if set.")
 	flag.Var(&opt.targets, "target", "One or more targets in the configuration to build. Only steps that are required for this target will be run.")
 	flag.BoolVar(&opt.printGraph, "print-graph", opt.printGraph, "Print a directed graph of the build steps and exit. Intended for use with the golang digraph utility.")
+	flag.StringVar(&opt.renderDir, "render", "", "Write the objects the build steps would create to this directory and exit, without accessing a cluster. Values only known once the steps run, like image digests, are left empty.")
 
 	// add to the graph of things we run or create
 	flag.Var(&opt.templatePaths, "template", "A set of paths to optional templates to add as stages to this job. Each template is expected to contain at least one restart=Never pod. Parameters are filled from environment or from the automatic parameters generated by the operator.")
@@ -470,12 +493,17 @@ func bindOptions(flag *flag.FlagSet) *options {
 	// the target namespace and cleanup behavior
 	flag.Var(&opt.extraInputHash, "input-hash", "Add arbitrary inputs to the build input hash to make the created namespace unique.")
 	flag.StringVar(&opt.namespace, "namespace", "", "Namespace to create builds into, defaults to build_id from JOB_SPEC. If the string '{id}' is in this value it will be replaced with the build input hash.")
+	flag.StringVar(&opt.resumeFrom, "resume-from", "", "Namespace of a failed execution to resume: steps which completed in it are skipped and execution restarts from the first step which failed. Multi-stage tests are only resumed from the first failed step when it is a post step: tests which failed in a pre or test step are executed again from the start, as their post steps tore down what the pre steps set up. Cannot be used with --namespace. Implies --record-execution-state.")
+	flag.BoolVar(&opt.recordExecutionState, "record-execution-state", false, "Record the progress of the execution in the namespace so that it can be resumed with --resume-from if it fails.")
 	flag.StringVar(&opt.baseNamespace, "base-namespace", "stable", "Namespace to read builds from, defaults to stable.")
 	flag.DurationVar(&opt.idleCleanupDuration, "delete-when-idle", opt.idleCleanupDuration, "If no pod is running for longer than this interval, delete the namespace. Set to zero to retain the contents. Requires the namespace TTL controller to be deployed.")
 	flag.DurationVar(&opt.cleanupDuration, "delete-after", opt.cleanupDuration, "If namespace exists for longer than this interval, delete the namespace. Set to zero to retain the contents. Requires the namespace TTL controller to be deployed.")
 
 	// actions to add to the graph
 	flag.BoolVar(&opt.promote, "promote", false, "When all other targets complete, publish the set of images built by this job into the release configuration.")
+	flag.BoolVar(&opt.promotionProvenance, "promotion-provenance", false, "Attach an in-toto statement describing how each promoted image was built to the image in the registry.")
+	flag.StringVar(&opt.promotionProvenanceSigningKey, "promotion-provenance-signing-key", "", "Path to a PEM-encoded private key signing the provenance attached to the promoted images.")
+	flag.StringVar(&opt.promotionProvenanceImage, "promotion-provenance-image", provenance.DefaultAttachImage, "The image of oras, used to attach the provenance to the promoted images.")
 
 	// output control
 	flag.StringVar(&opt.artifactDir, "artifact-dir", "", "DEPRECATED. Does nothing, set $ARTIFACTS instead.")
@@ -487,6 +515,7 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.impersonateUser, "as", "", "Username to impersonate")
 	flag.BoolVar(&opt.restrictNetworkAccess, "restrict-network-access", false, "Restrict network access to 10.0.0.0/8 (RedHat intranet).")
 	flag.BoolVar(&opt.enableSecretsStoreCSIDriver, "enable-secrets-store-csi-driver", false, "Use Secrets Store CSI driver for accessing multi-stage credentials.")
+	flag.BoolVar(&opt.enableBuildAvoidance, "enable-build-avoidance", false, "Label images built from the repository with the digest of their content and tag promoted images with the same digest instead of building them again.")
 
 	// flags needed for the configresolver
 	flag.StringVar(&opt.resolverAddress, "resolver-address", configResolverAddress, "Address of configresolver")
@@ -494,6 +523,7 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.repo, "repo", "", "Repo of the project (used by configresolver)")
 	flag.StringVar(&opt.branch, "branch", "", "Branch of the project (used by configresolver)")
 	flag.StringVar(&opt.variant, "variant", "", "Variant of the project's ci-operator config (used by configresolver)")
+	flag.StringVar(&opt.registryRevision, "registry-revision", "", "Revision of the release repository whose step registry the configuration is resolved against instead of the current one (used by configresolver)")
 
 	flag.StringVar(&opt.injectTest, "with-test-from", "", "Inject a test from another ci-operator config, specified by ORG/REPO@BRANCH{__VARIANT}:TEST or JSON (used by configresolver)")
 
@@ -544,7 +574,10 @@ func (o *options) Complete() error {
 	o.jobSpec.Target = target
 
 	info := o.getResolverInfo(jobSpec)
-	o.resolverClient = server.NewResolverClient(o.resolverAddress)
+	o.resolverClient = server.NewResolverClientAtRevision(o.resolverAddress, o.registryRevision)
+	if o.registryRevision != "" && o.registryPath != "" {
+		return errors.New("cannot request a --registry-revision when resolving against a local --registry")
+	}
 
 	if o.unresolvedConfigPath != "" && o.configSpecPath != "" {
 		return errors.New("cannot set --config and --unresolved-config at the same time")
@@ -587,6 +620,12 @@ func (o *options) Complete() error {
 	}
 	o.configSpec = config
 	o.jobSpec.Metadata = config.Metadata
+	if o.resolvedRegistryRevision = o.resolverClient.RegistryRevision(); o.resolvedRegistryRevision == "" && o.registryPath != "" {
+		o.resolvedRegistryRevision = load.RegistryRevision(o.registryPath)
+	}
+	if o.resolvedRegistryRevision != "" {
+		logrus.Infof("Resolved the configuration against the step registry at revision %s", o.resolvedRegistryRevision)
+	}
 	mergedConfig := o.injectTest != ""
 	if err := validation.IsValidResolvedConfiguration(o.configSpec, mergedConfig); err != nil {
 		return results.ForReason("validating_config").ForError(err)
@@ -624,6 +663,14 @@ func (o *options) Complete() error {
 		}
 	}
 
+	if o.resumeFrom != "" {
+		if o.namespace != "" {
+			return errors.New("cannot set --namespace and --resume-from at the same time")
+		}
+		o.namespace = o.resumeFrom
+		o.recordExecutionState = true
+	}
+
 	if len(o.sshKeyPath) > 0 && len(o.oauthTokenPath) > 0 {
 		return errors.New("both --ssh-key-path and --oauth-token-path are specified")
 	}
@@ -684,21 +731,24 @@ func (o *options) Complete() error {
 		o.templates = append(o.templates, template)
 	}
 
-	clusterConfig, err := util.LoadClusterConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load cluster config: %w", err)
-	}
+	// rendering the objects of the steps does not access a cluster
+	if o.renderDir == "" {
+		clusterConfig, err := util.LoadClusterConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load cluster config: %w", err)
+		}
 
-	if len(o.impersonateUser) > 0 {
-		clusterConfig.Impersonate = rest.ImpersonationConfig{UserName: o.impersonateUser}
-	}
+		if len(o.impersonateUser) > 0 {
+			clusterConfig.Impersonate = rest.ImpersonationConfig{UserName: o.impersonateUser}
+		}
 
-	if o.verbose {
-		clusterConfig.ContentType = "application/json"
-		clusterConfig.AcceptContentTypes = "application/json"
-	}
+		if o.verbose {
+			clusterConfig.ContentType = "application/json"
+			clusterConfig.AcceptContentTypes = "application/json"
+		}
 
-	o.clusterConfig = clusterConfig
+		o.clusterConfig = clusterConfig
+	}
 
 	if o.pullSecretPath != "" {
 		if o.pullSecret, err = getDockerConfigSecret(api.RegistryPullCredentialsSecret, o.pullSecretPath); err != nil {
@@ -711,6 +761,18 @@ func (o *options) Complete() error {
 		}
 	}
 
+	if o.promotionProvenanceSigningKey != "" && !o.promotionProvenance {
+		return errors.New("--promotion-provenance-signing-key requires --promotion-provenance")
+	}
+	if o.promotionProvenance {
+		o.provenanceOptions = &provenance.Options{AttachImage: o.promotionProvenanceImage}
+		if o.promotionProvenanceSigningKey != "" {
+			if o.provenanceOptions.Signer, err = provenance.LoadSigner(o.promotionProvenanceSigningKey); err != nil {
+				return fmt.Errorf("could not load provenance signing key from path %s: %w", o.promotionProvenanceSigningKey, err)
+			}
+		}
+	}
+
 	if o.uploadSecretPath != "" {
 		gcsSecretName := resolveGCSCredentialsSecret(o.jobSpec)
 		if o.uploadSecret, err = getSecret(gcsSecretName, o.uploadSecretPath); err != nil {
@@ -913,6 +975,9 @@ func (o *options) Run() []error {
 		logrus.Infof("error: Process interrupted with signal %s, cancelling execution...", s)
 		cancel()
 	}
+	if o.renderDir != "" {
+		return o.render(ctx)
+	}
 	var leaseClient *lease.Client
 	if o.leaseServer != "" && o.leaseServerCredentialsFile != "" {
 		leaseClient = &o.leaseClient
@@ -935,11 +1000,18 @@ func (o *options) Run() []error {
 		return []error{fmt.Errorf("could not resolve the node architectures: %w", err)}
 	}
 
+	var leaseReporter results.LeaseReporter
+	if leaseClient != nil {
+		if leaseReporter, err = o.resultsOptions.Reporter(o.jobSpec, o.consoleHost); err != nil {
+			logrus.WithError(err).Warn("Could not load result reporting options, leases will not be reported.")
+		}
+	}
+
 	injectedTest := o.injectTest != ""
 	// load the graph from the configuration
 	buildSteps, promotionSteps, err := defaults.FromConfig(ctx, o.configSpec, &o.graphConfig, o.jobSpec, o.templates, o.writeParams, o.promote, o.clusterConfig,
-		o.podPendingTimeout, leaseClient, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.hiveKubeconfig,
-		o.nodeName, nodeArchitectures, o.targetAdditionalSuffix, o.manifestToolDockerCfg, o.localRegistryDNS, streams, injectedTest, o.enableSecretsStoreCSIDriver)
+		o.podPendingTimeout, leaseClient, leaseReporter, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.hiveKubeconfig,
+		o.nodeName, nodeArchitectures, o.targetAdditionalSuffix, o.manifestToolDockerCfg, o.localRegistryDNS, streams, injectedTest, o.enableSecretsStoreCSIDriver, o.enableBuildAvoidance, o.provenanceOptions)
 	if err != nil {
 		return []error{results.ForReason("defaulting_config").WithError(err).Errorf("failed to generate steps from config: %v", err)}
 	}
@@ -1011,8 +1083,12 @@ func (o *options) Run() []error {
 		}
 		runtimeObject := &coreapi.ObjectReference{Namespace: o.namespace}
 		eventRecorder.Event(runtimeObject, coreapi.EventTypeNormal, "CiJobStarted", eventJobDescription(o.jobSpec, o.namespace))
+		state, err := o.executionState(ctx)
+		if err != nil {
+			return []error{results.ForReason("loading_execution_state").WithError(err).Errorf("could not load execution state: %v", err)}
+		}
 		// execute the graph
-		suites, graphDetails, errs := steps.Run(ctx, nodes)
+		suites, graphDetails, errs := steps.Run(ctx, nodes, state)
 		if err := o.writeJUnit(suites, "operator"); err != nil {
 			logrus.WithError(err).Warn("Unable to write JUnit result.")
 		}
@@ -1053,6 +1129,28 @@ func (o *options) Run() []error {
 	})
 }
 
+// executionState creates the store which records the progress of the
+// execution in the namespace, loading the state of the execution being resumed
+// if requested.  No store is created unless recording was requested.  The
+// state is stored separately for each job and set of targets.
+func (o *options) executionState(ctx context.Context) (*steps.ExecutionStateStore, error) {
+	if !o.recordExecutionState {
+		return nil, nil
+	}
+	client, err := ctrlruntimeclient.New(o.clusterConfig, ctrlruntimeclient.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get client for cluster config: %w", err)
+	}
+	job := strings.Join(append([]string{o.jobSpec.Job}, o.targets.values...), "/")
+	state := steps.NewExecutionStateStore(client, o.jobSpec.Namespace, job)
+	if o.resumeFrom != "" {
+		if err := state.Load(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
 func runPromotionStep(ctx context.Context, step api.Step, detailsChan chan<- api.CIOperatorStepDetails, errChan chan<- error) {
 	details, err := runStep(ctx, step)
 	if err != nil {
@@ -1493,7 +1591,7 @@ func (o *options) initializeNamespace() error {
 	for _, cp := range o.clusterProfiles {
 		cpSecret, err := getClusterProfileSecret(cp, labeledclient.Wrap(ctrlClient, o.jobSpec), o.resolverClient, ctx)
 		if err != nil {
-			return fmt.Errorf("failed to create cluster profile secret %s: %w", cp, err)
+			return fmt.Errorf("failed to create cluster profile secret %s: %w", cp.profileName, err)
 		}
 		cpSecret.Namespace = o.namespace
 		o.secrets = append(o.secrets, cpSecret)
@@ -1617,7 +1715,9 @@ type prowResultMetadata struct {
 	JobVersion    string            `json:"job-version"`
 	Pod           string            `json:"pod"`
 	WorkNamespace string            `json:"work-namespace"`
-	Metadata      map[string]string `json:"metadata"`
+	// RegistryRevision is the revision of the step registry the configuration was resolved against
+	RegistryRevision string            `json:"registry-revision,omitempty"`
+	Metadata         map[string]string `json:"metadata"`
 }
 
 const metadataJSONfile = "metadata.json"
@@ -1713,6 +1813,7 @@ func (o *options) generateProwMetadata() (m prowResultMetadata) {
 
 	m.Pod = o.jobSpec.ProwJobID
 	m.WorkNamespace = o.namespace
+	m.RegistryRevision = o.resolvedRegistryRevision
 
 	return m
 }
@@ -1911,7 +2012,12 @@ func (o *options) initializeLeaseClient() error {
 	if err != nil {
 		return fmt.Errorf("failed to load lease credentials: %w", err)
 	}
-	if o.leaseClient, err = lease.NewClient(owner, o.leaseServer, username, passwordGetter, 60, o.leaseAcquireTimeout); err != nil {
+	priority, err := o.leasePriority()
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("Acquiring leases with %s priority.", priority)
+	if o.leaseClient, err = lease.NewClient(owner, o.leaseServer, username, passwordGetter, 60, o.leaseAcquireTimeout, priority); err != nil {
 		return fmt.Errorf("failed to create the lease client: %w", err)
 	}
 	t := time.NewTicker(30 * time.Second)
@@ -1930,6 +2036,32 @@ func (o *options) initializeLeaseClient() error {
 	return nil
 }
 
+// leasePriority determines the priority of the job when acquiring leases
+func (o *options) leasePriority() (lease.Priority, error) {
+	policy := &lease.DefaultPriorityPolicy
+	if o.leasePriorityPolicyPath != "" {
+		var err error
+		if policy, err = lease.LoadPriorityPolicy(o.leasePriorityPolicyPath); err != nil {
+			return 0, err
+		}
+	}
+	var labels map[string]string
+	if o.jobLabelsPath != "" {
+		raw, err := os.ReadFile(o.jobLabelsPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the job labels: %w", err)
+		}
+		if labels, err = lease.ParseDownwardAPILabels(raw); err != nil {
+			return 0, fmt.Errorf("failed to parse the job labels: %w", err)
+		}
+	}
+	if len(labels) == 0 && o.jobSpec.Type == prowapi.PeriodicJob && !strings.HasPrefix(o.jobSpec.Job, "rehearse-") {
+		// payload jobs are periodics which cannot be told apart without their labels
+		return lease.PriorityUnknown, nil
+	}
+	return policy.PriorityFor(o.jobSpec.Type, o.jobSpec.Job, labels), nil
+}
+
 // eventJobDescription returns a string representing the pull requests and authors description, to be used in events.
 func eventJobDescription(jobSpec *api.JobSpec, namespace string) string {
 	var pulls []string
@@ -2020,6 +2152,50 @@ func nodeNames(nodes []*api.StepNode) []string {
 	return names
 }
 
+// renderedInputHash stands in for the hash of the inputs in the namespace
+// when rendering, as the inputs are resolved against the cluster
+const renderedInputHash = "render"
+
+// render writes the objects the steps required for the targets would create to
+// the render directory, using clients backed by an empty in-memory cluster
+func (o *options) render(ctx context.Context) []error {
+	if len(o.namespace) == 0 {
+		o.namespace = "ci-op-{id}"
+	}
+	o.namespace = strings.Replace(o.namespace, "{id}", renderedInputHash, -1)
+	o.jobSpec.SetNamespace(o.namespace)
+	if o.jobSpec.DecorationConfig == nil {
+		// the pods of steps are decorated like the pod of the job, with the utilities it configures
+		return []error{results.ForReason("defaulting_config").ForError(errors.New("cannot render the objects of the steps without the decoration_config of the job in JOB_SPEC"))}
+	}
+
+	injectedTest := o.injectTest != ""
+	buildSteps, promotionSteps, err := defaults.FromConfigForRender(ctx, o.configSpec, &o.graphConfig, o.jobSpec, o.templates, o.promote, o.targets.values,
+		o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.nodeName, nil, o.targetAdditionalSuffix, map[string]*configresolver.IntegratedStream{}, injectedTest, o.enableSecretsStoreCSIDriver)
+	if err != nil {
+		return []error{results.ForReason("defaulting_config").WithError(err).Errorf("failed to generate steps from config: %v", err)}
+	}
+	nodes, err := api.BuildPartialGraph(buildSteps, o.targets.values)
+	if err != nil {
+		return []error{results.ForReason("building_graph").WithError(err).Errorf("could not build execution graph: %v", err)}
+	}
+	api.ResolveMultiArch(nodes)
+	stepList, errs := nodes.TopologicalSort()
+	if errs != nil {
+		return append([]error{results.ForReason("building_graph").ForError(errors.New("could not sort nodes"))}, errs...)
+	}
+	var toRender []api.Step
+	for _, node := range stepList {
+		toRender = append(toRender, node.Step)
+	}
+	toRender = append(toRender, promotionSteps...)
+	if err := steps.WriteRenderedObjects(ctx, o.renderDir, toRender); err != nil {
+		return []error{fmt.Errorf("could not render the objects of the steps: %w", err)}
+	}
+	logrus.Infof("Rendered the objects of %s to %s", strings.Join(nodeNames(stepList), ", "), o.renderDir)
+	return nil
+}
+
 func printDigraph(w io.Writer, steps api.OrderedStepList) error {
 	for i, step := range steps {
 		req := step.Step.Requires()
@@ -2443,11 +2619,15 @@ func getClusterProfileSecret(cp clusterProfileForTarget, client ctrlruntimeclien
 		return nil, fmt.Errorf("failed to get secret '%s' from ci namespace: %w", cpDetails.Secret, err)
 	}
 
+	name := fmt.Sprintf("%s-cluster-profile", cp.target)
+	if cp.fallback {
+		name = fmt.Sprintf("%s-cluster-profile-%s", cp.target, cp.profileName)
+	}
 	newSecret := &coreapi.Secret{
 		Data: ciSecret.Data,
 		Type: ciSecret.Type,
 		ObjectMeta: metav1.ObjectMeta{
-			Name: fmt.Sprintf("%s-cluster-profile", cp.target),
+			Name: name,
 		},
 	}
 
@@ -2457,6 +2637,8 @@ func getClusterProfileSecret(cp clusterProfileForTarget, client ctrlruntimeclien
 type clusterProfileForTarget struct {
 	target      string
 	profileName string
+	// fallback profiles are only used when their lease is acquired in place of that of the profile of the target
+	fallback bool
 }
 
 // getClusterProfileNamesFromTargets extracts the needed cluster profile name(s) from the target arg(s)
@@ -2473,6 +2655,15 @@ func (o *options) getClusterProfileNamesFromTargets() {
 					profileName: profile,
 				})
 			}
+			if literal := test.MultiStageTestConfigurationLiteral; literal != nil {
+				for _, fallback := range literal.FallbackClusterProfiles {
+					o.clusterProfiles = append(o.clusterProfiles, clusterProfileForTarget{
+						target:      test.As,
+						profileName: fallback.Name(),
+						fallback:    true,
+					})
+				}
+			}
 		}
 	}
 }
@@ -27,6 +27,7 @@ import (
 	imagev1 "github.com/openshift/api/image/v1"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/lease"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps"
@@ -1042,7 +1043,7 @@ func TestBuildPartialGraph(t *testing.T) {
 						},
 						To: api.PipelineImageStreamTagReference("oc-bin-image"),
 					},
-					&api.ReleaseBuildConfiguration{}, api.ResourceConfiguration{}, nil, nil, nil, nil,
+					&api.ReleaseBuildConfiguration{}, api.ResourceConfiguration{}, nil, nil, nil, nil, nil,
 				),
 				steps.OutputImageTagStep(api.OutputImageTagStepConfiguration{From: api.PipelineImageStreamTagReference("oc-bin-image")}, nil, nil),
 				steps.ImagesReadyStep(steps.OutputImageTagStep(api.OutputImageTagStepConfiguration{From: api.PipelineImageStreamTagReference("oc-bin-image")}, nil, nil).Creates()),
@@ -1832,3 +1833,81 @@ func TestGetClusterProfileNamesFromTargets(t *testing.T) {
 		})
 	}
 }
+
+func TestLeasePriority(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	for _, tc := range []struct {
+		name       string
+		labelsPath string
+		jobType    prowapi.ProwJobType
+		expected   lease.Priority
+	}{{
+		name:     "labels of a periodic are not known",
+		expected: lease.PriorityUnknown,
+	}, {
+		name:       "no labels were projected",
+		labelsPath: write("empty", ""),
+		expected:   lease.PriorityUnknown,
+	}, {
+		name:     "presubmit without labels",
+		jobType:  prowapi.PresubmitJob,
+		expected: lease.PriorityPresubmit,
+	}, {
+		name:       "payload job",
+		labelsPath: write("payload", "prow.k8s.io/type=\"periodic\"\nrelease.openshift.io/verify=\"true\"\n"),
+		expected:   lease.PriorityPayload,
+	}, {
+		name:       "periodic matching no rule",
+		labelsPath: write("periodic", "prow.k8s.io/type=\"periodic\"\n"),
+		expected:   lease.PriorityPeriodic,
+	}, {
+		name:       "postsubmit matching no rule",
+		labelsPath: write("postsubmit", "prow.k8s.io/type=\"postsubmit\"\n"),
+		jobType:    prowapi.PostsubmitJob,
+		expected:   lease.PriorityPostsubmit,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			jobType := prowapi.PeriodicJob
+			if tc.jobType != "" {
+				jobType = tc.jobType
+			}
+			o := &options{
+				jobLabelsPath: tc.labelsPath,
+				jobSpec:       &api.JobSpec{JobSpec: downwardapi.JobSpec{Type: jobType, Job: "periodic-ci-org-repo-master-e2e"}},
+			}
+			priority, err := o.leasePriority()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, priority); diff != "" {
+				t.Errorf("unexpected priority: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRenderRequiresDecorationConfig(t *testing.T) {
+	o := &options{
+		jobSpec:   &api.JobSpec{JobSpec: downwardapi.JobSpec{Type: prowapi.PeriodicJob, Job: "periodic-ci-org-repo-master-e2e"}},
+		renderDir: t.TempDir(),
+	}
+	errs := o.render(context.Background())
+	if diff := cmp.Diff([]string{"cannot render the objects of the steps without the decoration_config of the job in JOB_SPEC"}, errorMessages(errs)); diff != "" {
+		t.Errorf("unexpected errors: %s", diff)
+	}
+}
+
+func errorMessages(errs []error) []string {
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
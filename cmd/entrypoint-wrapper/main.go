@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"flag"
@@ -21,6 +22,7 @@ import (
 	"gopkg.in/fsnotify.v1"
 
 	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreclientset "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/util"
@@ -82,6 +85,13 @@ type options struct {
 	rwKubeconfig     bool
 	uploadKubeconfig bool
 	updateSharedDir  bool
+	mergeSharedDir   bool
+	initialSharedDir map[string][]byte
+	initialFiles     map[string]fileState
+	backendStr       string
+	backend          api.SharedDirBackend
+	sizeLimitStr     string
+	sizeLimit        *resource.Quantity
 	cmd              []string
 	client           coreclientset.SecretInterface
 }
@@ -92,6 +102,9 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.waitPath, "wait-for-file", "", "Wait for a file to appear at this path before starting the program")
 	flag.StringVar(&opt.waitTimeoutStr, "wait-timeout", "", "Used with --wait-for-file, maximum wait time before starting the program")
 	flag.StringVar(&opt.mode, "mode", manageKubeconfigMode, fmt.Sprintf("Set how kubeconfig should be managed. Allowed values are: %s, %s or %s", manageKubeconfigMode, skipKubeconfigMode, observerMode))
+	flag.BoolVar(&opt.mergeSharedDir, "merge-shared-dir", false, "Merge files created or modified by the command into the shared directory instead of replacing its content, used for steps which execute concurrently")
+	flag.StringVar(&opt.backendStr, "shared-dir-backend", string(api.SharedDirBackendSecret), fmt.Sprintf("Storage backing the shared directory. Allowed values are: %s or %s", api.SharedDirBackendSecret, api.SharedDirBackendPVC))
+	flag.StringVar(&opt.sizeLimitStr, "shared-dir-size-limit", "", "Maximum total size of the files in the shared directory (e.g. 1Gi)")
 	return opt
 }
 
@@ -126,7 +139,20 @@ func (o *options) complete() error {
 		return err
 	}
 
-	if !o.dry && o.mode != skipKubeconfigMode {
+	switch o.backend = api.SharedDirBackend(o.backendStr); o.backend {
+	case api.SharedDirBackendSecret, api.SharedDirBackendPVC:
+	default:
+		return fmt.Errorf("unrecognized shared directory backend: %s", o.backendStr)
+	}
+	if l := o.sizeLimitStr; l != "" {
+		q, err := resource.ParseQuantity(l)
+		if err != nil {
+			return fmt.Errorf("invalid shared directory size limit %q: %w", l, err)
+		}
+		o.sizeLimit = &q
+	}
+
+	if !o.dry && o.mode != skipKubeconfigMode && o.backend == api.SharedDirBackendSecret {
 		var err error
 		if o.client, err = loadClient(ns); err != nil {
 			return err
@@ -156,8 +182,8 @@ func (o *options) validateMode() error {
 }
 
 func (o *options) run() (exitCode int, err error) {
-	if err := copyDir(o.dstPath, o.srcPath); err != nil {
-		return errorCode, fmt.Errorf("failed to copy secret mount: %w", err)
+	if err := o.copySharedDir(); err != nil {
+		return errorCode, err
 	}
 	if o.waitPath != "" {
 		if err := waitForFile(o.waitPath, o.waitTimeout); err != nil {
@@ -167,7 +193,7 @@ func (o *options) run() (exitCode int, err error) {
 	var errs []error
 	ctx, cancel := context.WithCancel(context.Background())
 	if o.uploadKubeconfig {
-		go uploadKubeconfig(ctx, o.client, o.name, o.dstPath, o.dry)
+		go uploadKubeconfig(ctx, o.dstPath, o.uploadSharedDir)
 	}
 	if exitCode, err = o.execCmd(); err != nil {
 		errs = append(errs, fmt.Errorf("failed to execute wrapped command: %w", err))
@@ -177,8 +203,8 @@ func (o *options) run() (exitCode int, err error) {
 	// not to race with the post-execution one
 	cancel()
 	if o.updateSharedDir {
-		if err := createSecret(o.client, o.name, o.dstPath, o.dry); err != nil {
-			errs = append(errs, fmt.Errorf("failed to create/update secret: %w", err))
+		if err := o.uploadSharedDir(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update shared directory: %w", err))
 			return errorCode, utilerrors.NewAggregate(errs)
 		}
 	}
@@ -416,6 +442,86 @@ func manageKubeconfig(proc *exec.Cmd) error {
 	return nil
 }
 
+// copySharedDir creates the local copy of the shared directory and records
+// its initial state when the content has to be merged back.
+func (o *options) copySharedDir() error {
+	if o.backend == api.SharedDirBackendPVC {
+		if err := copyTree(o.dstPath, o.srcPath, nil); err != nil {
+			return fmt.Errorf("failed to copy shared directory volume: %w", err)
+		}
+		if o.mergeSharedDir {
+			initial, err := snapshotTree(o.dstPath)
+			if err != nil {
+				return fmt.Errorf("failed to read initial shared directory content: %w", err)
+			}
+			o.initialFiles = initial
+		}
+		return nil
+	}
+	if err := copyDir(o.dstPath, o.srcPath); err != nil {
+		return fmt.Errorf("failed to copy secret mount: %w", err)
+	}
+	if o.mergeSharedDir {
+		initial, err := util.SecretFromDir(o.dstPath)
+		if err != nil {
+			return fmt.Errorf("failed to read initial shared directory content: %w", err)
+		}
+		o.initialSharedDir = initial.Data
+	}
+	return nil
+}
+
+// uploadSharedDir propagates the content of the local copy of the shared
+// directory to its backing storage, after verifying it fits in the limit.
+func (o *options) uploadSharedDir() error {
+	if err := checkSizeLimit(o.dstPath, o.sizeLimit); err != nil {
+		return err
+	}
+	if o.backend == api.SharedDirBackendPVC {
+		return o.updateVolume()
+	}
+	return o.updateSecret()
+}
+
+// checkSizeLimit verifies that the total size of the files in the directory
+// does not exceed the limit, if one is set.
+func checkSizeLimit(dir string, limit *resource.Quantity) error {
+	if limit == nil {
+		return nil
+	}
+	size, err := treeSize(dir)
+	if err != nil {
+		return fmt.Errorf("failed to determine the size of the shared directory: %w", err)
+	}
+	if size > limit.Value() {
+		return fmt.Errorf("shared directory content size (%d bytes) exceeds the limit of %s", size, limit.String())
+	}
+	return nil
+}
+
+// updateVolume propagates the content of the local copy of the shared
+// directory to the volume.  When merging, only files created or modified
+// relative to the initial state are written and nothing is removed.
+func (o *options) updateVolume() error {
+	if o.dry {
+		logrus.Infof("Dry run, not updating shared directory volume at %s", o.srcPath)
+		return nil
+	}
+	if o.mergeSharedDir {
+		return copyTree(o.srcPath, o.dstPath, modifiedSince(o.initialFiles))
+	}
+	return syncTree(o.srcPath, o.dstPath)
+}
+
+// updateSecret propagates the content of the local copy of the shared
+// directory to the secret.
+func (o *options) updateSecret() error {
+	if o.mergeSharedDir {
+		return mergeSecret(o.client, o.name, o.dstPath, o.initialSharedDir, o.dry)
+	}
+	return createSecret(o.client, o.name, o.dstPath, o.dry)
+}
+
 func createSecret(client coreclientset.SecretInterface, name, dir string, dry bool) error {
 	if _, err := os.Stat(dir); err != nil {
 		if os.IsNotExist(err) {
@@ -443,6 +549,65 @@ func createSecret(client coreclientset.SecretInterface, name, dir string, dry bo
 	return nil
 }
 
+// mergeSecret updates the secret with the files in the directory which were
+// created or modified relative to `initial`, leaving all other keys untouched.
+// Files that were removed from the directory are not removed from the secret.
+// This allows steps which execute concurrently to share the same secret without
+// overwriting each other's changes.
+func mergeSecret(client coreclientset.SecretInterface, name, dir string, initial map[string][]byte, dry bool) error {
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat directory %q: %w", dir, err)
+	}
+	current, err := util.SecretFromDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to generate secret: %w", err)
+	}
+	changed := changedData(initial, current.Data)
+	if len(changed) == 0 {
+		return nil
+	}
+	if dry {
+		current.Name = name
+		current.Data = changed
+		if err := encoder.Encode(current, os.Stdout); err != nil {
+			return fmt.Errorf("failed to log secret: %w", err)
+		}
+		return nil
+	}
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		for k, v := range changed {
+			secret.Data[k] = v
+		}
+		_, err = client.Update(context.TODO(), secret, metav1.UpdateOptions{})
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to merge secret: %w", err)
+	}
+	return nil
+}
+
+// changedData returns the entries in `current` which do not exist or have a
+// different value in `initial`.
+func changedData(initial, current map[string][]byte) map[string][]byte {
+	ret := map[string][]byte{}
+	for k, v := range current {
+		if old, ok := initial[k]; !ok || !bytes.Equal(old, v) {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
 // uploadKubeconfig will do a best-effort attempt at uploading a kubeconfig
 // file if one does not exist at the time we start running but one does get
 // created while executing the command
@@ -451,7 +616,7 @@ func createSecret(client coreclientset.SecretInterface, name, dir string, dry bo
 // make a minimally functional kubeconfig available for tasks that need to run
 // before the final complete kubeconfig is available for general usage. An example
 // use case is for observers to start observing while install is still in progress.
-func uploadKubeconfig(ctx context.Context, client coreclientset.SecretInterface, name, dir string, dry bool) {
+func uploadKubeconfig(ctx context.Context, dir string, upload func() error) {
 	if _, err := os.Stat(path.Join(dir, "kubeconfig")); err == nil {
 		// kubeconfig already exists, no need to do anything
 		return
@@ -461,7 +626,7 @@ func uploadKubeconfig(ctx context.Context, client coreclientset.SecretInterface,
 	if err := wait.PollUntil(time.Second, func() (done bool, err error) {
 		if !minimalUploaded {
 			if _, uploadErr = os.Stat(path.Join(dir, "kubeconfig-minimal")); uploadErr == nil {
-				uploadErr = createSecret(client, name, dir, dry)
+				uploadErr = upload()
 				if uploadErr == nil {
 					minimalUploaded = true
 				}
@@ -472,7 +637,7 @@ func uploadKubeconfig(ctx context.Context, client coreclientset.SecretInterface,
 			return false, nil
 		}
 		// kubeconfig exists, we can upload it
-		uploadErr = createSecret(client, name, dir, dry)
+		uploadErr = upload()
 		return uploadErr == nil, nil // retry errors
 	}, ctx.Done()); err != nil && !errors.Is(err, wait.ErrWaitTimeout) {
 		log.Printf("Failed to upload $KUBECONFIG: %v: %v\n", err, uploadErr)
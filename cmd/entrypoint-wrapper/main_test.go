@@ -122,3 +122,38 @@ func TestManageHome(t *testing.T) {
 		})
 	}
 }
+
+func TestChangedData(t *testing.T) {
+	testCases := []struct {
+		testName string
+		initial  map[string][]byte
+		current  map[string][]byte
+		expected map[string][]byte
+	}{
+		{
+			testName: "no changes",
+			initial:  map[string][]byte{"a": []byte("a")},
+			current:  map[string][]byte{"a": []byte("a")},
+			expected: map[string][]byte{},
+		},
+		{
+			testName: "new and modified files are returned",
+			initial:  map[string][]byte{"a": []byte("a"), "b": []byte("b")},
+			current:  map[string][]byte{"a": []byte("a"), "b": []byte("modified"), "c": []byte("c")},
+			expected: map[string][]byte{"b": []byte("modified"), "c": []byte("c")},
+		},
+		{
+			testName: "removed files are ignored",
+			initial:  map[string][]byte{"a": []byte("a"), "b": []byte("b")},
+			current:  map[string][]byte{"a": []byte("a")},
+			expected: map[string][]byte{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, changedData(testCase.initial, testCase.current)); diff != "" {
+				t.Fatalf("unexpected changed data: %s", diff)
+			}
+		})
+	}
+}
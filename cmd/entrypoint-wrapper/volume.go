@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileState is the information used to detect whether a file in the local
+// copy of the shared directory was modified by the command.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshotTree records the state of all regular files under a directory,
+// indexed by their path relative to it.
+func snapshotTree(dir string) (map[string]fileState, error) {
+	ret := map[string]fileState{}
+	err := walkFiles(dir, func(rel string, info fs.FileInfo) error {
+		ret[rel] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return ret, err
+}
+
+// modifiedSince returns a filter for copyTree which only accepts files that do
+// not exist or are different in the snapshot.
+func modifiedSince(snapshot map[string]fileState) func(string, fs.FileInfo) bool {
+	return func(rel string, info fs.FileInfo) bool {
+		old, ok := snapshot[rel]
+		return !ok || old.size != info.Size() || !old.modTime.Equal(info.ModTime())
+	}
+}
+
+// treeSize returns the total size of the regular files under a directory.
+func treeSize(dir string) (int64, error) {
+	var ret int64
+	err := walkFiles(dir, func(_ string, info fs.FileInfo) error {
+		ret += info.Size()
+		return nil
+	})
+	return ret, err
+}
+
+// copyTree recursively copies the regular files under `src` to `dst`,
+// creating directories as required.  If `filter` is not nil, only files for
+// which it returns true are copied.
+func copyTree(dst, src string, filter func(string, fs.FileInfo) bool) error {
+	if err := os.MkdirAll(dst, 0770); err != nil {
+		return err
+	}
+	return walkFiles(src, func(rel string, info fs.FileInfo) error {
+		if filter != nil && !filter(rel, info) {
+			return nil
+		}
+		path := filepath.Join(dst, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0770); err != nil {
+			return err
+		}
+		return copyFile(path, filepath.Join(src, rel), info.Mode().Perm())
+	})
+}
+
+// syncTree makes the content of `dst` identical to that of `src`: all files
+// are copied and files and directories which do not exist in `src` are
+// removed.
+func syncTree(dst, src string) error {
+	if err := copyTree(dst, src, nil); err != nil {
+		return err
+	}
+	return filepath.WalkDir(dst, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dst, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if _, err := os.Lstat(filepath.Join(src, rel)); err == nil {
+			return nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// walkFiles calls `f` for every regular file under a directory, following
+// symbolic links to files.
+func walkFiles(dir string, f func(string, fs.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// broken symbolic link
+				return nil
+			}
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return f(rel, info)
+	})
+}
+
+func copyFile(dst, src string, mode fs.FileMode) error {
+	srcFD, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFD.Close()
+	dstFD, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFD, srcFD); err != nil {
+		dstFD.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return dstFD.Close()
+}
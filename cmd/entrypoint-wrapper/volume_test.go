@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0770); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	ret := map[string]string{}
+	if err := walkFiles(dir, func(rel string, _ os.FileInfo) error {
+		content, err := os.ReadFile(filepath.Join(dir, rel))
+		ret[rel] = string(content)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func TestSyncTree(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTree(t, src, map[string]string{"a": "a", "dir/b": "modified", "dir/c": "c"})
+	writeTree(t, dst, map[string]string{"dir/b": "b", "removed": "x", "removed-dir/d": "d"})
+	if err := syncTree(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"a": "a", "dir/b": "modified", "dir/c": "c"}
+	if diff := cmp.Diff(expected, readTree(t, dst)); diff != "" {
+		t.Fatalf("unexpected content: %s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "removed-dir")); !os.IsNotExist(err) {
+		t.Fatalf("expected removed directory to not exist, got: %v", err)
+	}
+}
+
+func TestCopyTreeModifiedSince(t *testing.T) {
+	local, volume := t.TempDir(), t.TempDir()
+	writeTree(t, local, map[string]string{"a": "a", "b": "b", "c": "c"})
+	snapshot, err := snapshotTree(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// another step executing concurrently modifies the volume
+	writeTree(t, volume, map[string]string{"a": "a", "b": "sibling", "c": "c", "d": "sibling"})
+	writeTree(t, local, map[string]string{"a": "modified", "e": "new"})
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(local, "a"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(local, "c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyTree(volume, local, modifiedSince(snapshot)); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"a": "modified", "b": "sibling", "c": "c", "d": "sibling", "e": "new"}
+	if diff := cmp.Diff(expected, readTree(t, volume)); diff != "" {
+		t.Fatalf("unexpected content: %s", diff)
+	}
+}
+
+func TestCheckSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a": "0123456789", "dir/b": "0123456789"})
+	for _, testCase := range []struct {
+		name        string
+		limit       *resource.Quantity
+		expectedErr string
+	}{{
+		name: "no limit",
+	}, {
+		name:  "within limit",
+		limit: resource.NewQuantity(20, resource.DecimalSI),
+	}, {
+		name:        "exceeds limit",
+		limit:       resource.NewQuantity(19, resource.DecimalSI),
+		expectedErr: "shared directory content size (20 bytes) exceeds the limit of 19",
+	}} {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual string
+			if err := checkSizeLimit(dir, testCase.limit); err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expectedErr, actual); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
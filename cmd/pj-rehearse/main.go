@@ -46,6 +46,8 @@ type options struct {
 	gcsCredentialsFile string
 	gcsBrowserPrefix   string
 
+	jobDurationsFile string
+
 	dryRun        bool
 	dryRunOptions dryRunOptions
 
@@ -76,6 +78,8 @@ func gatherOptions() (options, error) {
 	fs.IntVar(&o.moreLimit, "more-limit", 20, "Upper limit of jobs attempted to rehearse with more command (if more jobs are being touched, only this many will be rehearsed)")
 	fs.IntVar(&o.maxLimit, "max-limit", 35, "Upper limit of jobs attempted to rehearse with max command (if more jobs are being touched, only this many will be rehearsed)")
 
+	fs.StringVar(&o.jobDurationsFile, "job-durations", "", "Path to a YAML file mapping job names to their historical durations, used to prefer cheaper jobs when only a subset of the affected jobs can be rehearsed")
+
 	fs.Var(&o.stickyLabelAuthors, "sticky-label-author", "PR Author for which the 'rehearsals-ack' label will not be removed upon a new push. Can be passed multiple times.")
 	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
 
@@ -147,6 +151,7 @@ func rehearsalConfigFromOptions(o options) rehearse.RehearsalConfig {
 		GCSBucket:          o.gcsBucket,
 		GCSCredentialsFile: o.gcsCredentialsFile,
 		GCSBrowserPrefix:   o.gcsBrowserPrefix,
+		JobDurationsFile:   o.jobDurationsFile,
 	}
 }
 
@@ -173,10 +178,13 @@ func dryRun(o options, logger *logrus.Entry) error {
 		return fmt.Errorf("error determining affected jobs: %w: %s", err, "ERROR: pj-rehearse: misconfiguration")
 	}
 
-	prConfig, prRefs, presubmitsToRehearse, err := rc.SetupJobs(candidate, candidatePath, presubmits, periodics, dro.limit, logger)
+	prConfig, prRefs, presubmitsToRehearse, decisions, err := rc.SetupJobs(candidate, candidatePath, presubmits, periodics, dro.limit, logger)
 	if err != nil {
 		return fmt.Errorf("error setting up jobs: %w: %s", err, "ERROR: pj-rehearse: setup failure")
 	}
+	for _, decision := range decisions {
+		logger.WithFields(logrus.Fields{"job": decision.Job, "selected": decision.Selected}).Info(decision.Reason)
+	}
 
 	if len(presubmitsToRehearse) > 0 {
 		if err := prConfig.Prow.ValidateJobConfig(); err != nil {
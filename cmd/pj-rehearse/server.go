@@ -443,12 +443,17 @@ func (s *server) handlePotentialCommands(pullRequest *github.PullRequest, commen
 						limit = rc.MaxLimit
 					}
 
-					prConfig, prRefs, presubmitsToRehearse, err := rc.SetupJobs(candidate, candidatePath, presubmits, periodics, limit, logger)
+					prConfig, prRefs, presubmitsToRehearse, decisions, err := rc.SetupJobs(candidate, candidatePath, presubmits, periodics, limit, logger)
 					if err != nil {
 						logger.WithError(err).Error("couldn't set up jobs")
 						s.reportFailure("unable to set up jobs", err, org, repo, user, number, true, false, logger)
 						continue
 					}
+					if len(decisions) > 0 {
+						if err := s.ghc.CreateComment(org, repo, number, strings.Join(getSelectionLines(decisions, limit, user), "\n")); err != nil {
+							logger.WithError(err).Error("failed to create comment")
+						}
+					}
 
 					if err := prConfig.Prow.ValidateJobConfig(); err != nil {
 						logger.WithError(err).Error("validation of job config failed")
@@ -599,6 +604,28 @@ func (s *server) getJobsTableLines(presubmits config.Presubmits, periodics confi
 	return append(lines, ""), jobCount
 }
 
+// getSelectionLines returns a Markdown formatted explanation of which affected jobs
+// were selected to be rehearsed and why, in the form of a []string
+func getSelectionLines(decisions []rehearse.SelectionDecision, limit int, user string) []string {
+	lines := []string{
+		fmt.Sprintf("@%s: %d jobs are affected by this change, more than the limit of %d. Rehearsals were selected to cover as many distinct cluster profiles, workflows and changed registry content as possible, preferring cheaper jobs:", user, len(decisions), limit),
+		"",
+		"<details>",
+		"<summary>Selected and dropped rehearsals</summary>",
+		"",
+		"Test name | Rehearsed | Reason",
+		"--- | --- | ---",
+	}
+	for _, decision := range decisions {
+		rehearsed := "no"
+		if decision.Selected {
+			rehearsed = "yes"
+		}
+		lines = append(lines, fmt.Sprintf("%s | %s | %s", decision.Job, rehearsed, decision.Reason))
+	}
+	return append(lines, "", "</details>")
+}
+
 func getAffectedJobFormattedList(presubmits config.Presubmits, periodics config.Periodics) []string {
 	var jobs []string
 	for repoName, tests := range presubmits {
@@ -33,18 +33,19 @@ import (
 	"github.com/openshift/ci-tools/pkg/steps"
 )
 
-func admit(port, healthPort int, certDir string, client buildclientv1.BuildV1Interface, loaders map[string][]*cacheReloader, mutateResourceLimits bool, cpuCap int64, memoryCap string, cpuPriorityScheduling int64, reporter results.PodScalerReporter) {
+func admit(port, healthPort int, certDir string, client buildclientv1.BuildV1Interface, loaders map[string][]*cacheReloader, mutateResourceLimits bool, cpuCap int64, memoryCap, ephemeralStorageCap string, cpuPriorityScheduling int64, reporter results.PodScalerReporter) {
 	logger := logrus.WithField("component", "pod-scaler admission")
 	logger.Infof("Initializing admission webhook server with %d loaders.", len(loaders))
 	health := pjutil.NewHealthOnPort(healthPort)
-	resources := newResourceServer(loaders, health)
+	resources := newResourceServer(loaders, func() { health.ServeReady() })
 	decoder := admission.NewDecoder(scheme.Scheme)
 
 	server := webhook.NewServer(webhook.Options{
 		Port:    port,
 		CertDir: certDir,
 	})
-	server.Register("/pods", &webhook.Admission{Handler: &podMutator{logger: logger, client: client, decoder: decoder, resources: resources, mutateResourceLimits: mutateResourceLimits, cpuCap: cpuCap, memoryCap: memoryCap, cpuPriorityScheduling: cpuPriorityScheduling, reporter: reporter}})
+	server.Register("/pods", &webhook.Admission{Handler: &podMutator{logger: logger, client: client, decoder: decoder, resources: resources, mutateResourceLimits: mutateResourceLimits, cpuCap: cpuCap, memoryCap: memoryCap, ephemeralStorageCap: ephemeralStorageCap, cpuPriorityScheduling: cpuPriorityScheduling, reporter: reporter}})
+	server.Register("/report", &reportServer{resources: resources, mutateResourceLimits: mutateResourceLimits, cpuCap: cpuCap, memoryCap: memoryCap, ephemeralStorageCap: ephemeralStorageCap, logger: logger.WithField("handler", "report")})
 	logger.Info("Serving admission webhooks.")
 	if err := server.Start(interrupts.Context()); err != nil {
 		logrus.WithError(err).Fatal("Failed to serve webhooks.")
@@ -59,6 +60,7 @@ type podMutator struct {
 	decoder               admission.Decoder
 	cpuCap                int64
 	memoryCap             string
+	ephemeralStorageCap   string
 	cpuPriorityScheduling int64
 	reporter              results.PodScalerReporter
 }
@@ -97,7 +99,7 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 		logger.WithError(err).Error("Failed to handle rehearsal Pod.")
 		return admission.Allowed("Failed to handle rehearsal Pod, ignoring.")
 	}
-	mutatePodResources(pod, m.resources, m.mutateResourceLimits, m.cpuCap, m.memoryCap, m.reporter, logger)
+	mutatePodResources(pod, m.resources, m.mutateResourceLimits, m.cpuCap, m.memoryCap, m.ephemeralStorageCap, m.reporter, logger)
 	m.addPriorityClass(pod)
 
 	marshaledPod, err := json.Marshal(pod)
@@ -213,13 +215,8 @@ func useOursIfLarger(allOfOurs, allOfTheirs *corev1.ResourceRequirements, worklo
 		{ours: &allOfOurs.Requests, theirs: &allOfTheirs.Requests, resource: "request"},
 		{ours: &allOfOurs.Limits, theirs: &allOfTheirs.Limits, resource: "limit"},
 	} {
-		for _, field := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		for _, field := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
 			our := (*pair.ours)[field]
-			//TODO(sgoeddel): this is a temporary experiment to see what effect setting values that are 120% of what has
-			// been determined has on the rate of OOMKilled and similar termination of workloads
-			increased := our.AsApproximateFloat64() * 1.2
-			our.Set(int64(increased))
-
 			their := (*pair.theirs)[field]
 			fieldLogger := logger.WithFields(logrus.Fields{
 				"workloadName": workloadName,
@@ -245,30 +242,86 @@ func useOursIfLarger(allOfOurs, allOfTheirs *corev1.ResourceRequirements, worklo
 	}
 }
 
+// applyAdjustments increases our recommendations for workloads which were recently
+// starved of a resource, like ones that were OOMKilled or had their CPU throttled.
+// CPU throttling only happens under a CPU limit, so its adjustment is only applied
+// when limits are not mutated, in which case the CPU limit is raised along with
+// the request. The recommendation is mutated, so it must not be shared with the
+// resource server
+func applyAdjustments(recommendation corev1.ResourceRequirements, adjustments map[corev1.ResourceName]adjustment, logger *logrus.Entry) corev1.ResourceRequirements {
+	if len(adjustments) == 0 {
+		return recommendation
+	}
+	for _, list := range []corev1.ResourceList{recommendation.Requests, recommendation.Limits} {
+		for field, a := range adjustments {
+			quantity, set := list[field]
+			if !set {
+				continue
+			}
+			var increased *resource.Quantity
+			if field == corev1.ResourceCPU {
+				increased = resource.NewMilliQuantity(int64(float64(quantity.MilliValue())*a.Factor), quantity.Format)
+			} else {
+				increased = resource.NewQuantity(int64(float64(quantity.Value())*a.Factor), quantity.Format)
+			}
+			list[field] = *increased
+			logger.WithFields(logrus.Fields{
+				"field":      field,
+				"determined": quantity.String(),
+				"adjusted":   increased.String(),
+			}).Debugf("increasing determined amount: %s", a.Reason)
+		}
+	}
+	return recommendation
+}
+
+// raiseCPULimit increases the CPU limit of a workload which was recently throttled
+// by the same factor as its request, so that the increased request is not capped
+// by the limit which caused the throttling in the first place
+func raiseCPULimit(resources *corev1.ResourceRequirements, a adjustment, logger *logrus.Entry) {
+	limit, set := resources.Limits[corev1.ResourceCPU]
+	if !set || limit.IsZero() { // Never set a limit where there isn't one defined
+		return
+	}
+	increased := resource.NewMilliQuantity(int64(float64(limit.MilliValue())*a.Factor), limit.Format)
+	if request, set := resources.Requests[corev1.ResourceCPU]; set && increased.Cmp(request) < 0 {
+		increased = &request
+	}
+	resources.Limits[corev1.ResourceCPU] = *increased
+	logger.WithFields(logrus.Fields{
+		"configured": limit.String(),
+		"adjusted":   increased.String(),
+	}).Debugf("increasing CPU limit: %s", a.Reason)
+}
+
 // reconcileLimits ensures that container resource limits do not set anything for CPU (as we
 // are fairly certain this is never a useful thing to do) and that any limits that have been configured
-// are >=200% of requests (which they may not be any longer if we've changed requests)
+// are >=200% of requests (which they may not be any longer if we've changed requests), for memory
+// and ephemeral storage alike
 func reconcileLimits(resources *corev1.ResourceRequirements) {
 	if resources.Limits == nil {
 		return
 	}
 	delete(resources.Limits, corev1.ResourceCPU)
-	if !resources.Limits.Memory().IsZero() { // Never set a limit where there isn't one defined
+	for _, field := range []corev1.ResourceName{corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		currentLimit, set := resources.Limits[field]
+		if !set || currentLimit.IsZero() { // Never set a limit where there isn't one defined
+			continue
+		}
 		// Note: doing math on Quantities is not easy, since they may contain values that overflow
 		// normal integers. Doing math on inf.Dec is possible, but there does not exist any way to
 		// convert back from an inf.Dec to a resource.Quantity. So, while we would want to have a
 		// limit threshold like 120% or similar, we use 200% as that's what is trivially easy to
 		// accomplish with the math we can do on resource.Quantity.
-		minimumLimit := resources.Requests[corev1.ResourceMemory]
+		minimumLimit := resources.Requests[field]
 		minimumLimit.Add(minimumLimit)
-		currentLimit := resources.Limits[corev1.ResourceMemory]
 		if currentLimit.Cmp(minimumLimit) == -1 {
-			resources.Limits[corev1.ResourceMemory] = minimumLimit
+			resources.Limits[field] = minimumLimit
 		}
 	}
 }
 
-func preventUnschedulable(resources *corev1.ResourceRequirements, cpuCap int64, memoryCap string, logger *logrus.Entry) {
+func preventUnschedulable(resources *corev1.ResourceRequirements, cpuCap int64, memoryCap, ephemeralStorageCap string, logger *logrus.Entry) {
 	if resources.Requests == nil {
 		logger.Debug("no requests, skipping")
 		return
@@ -290,9 +343,17 @@ func preventUnschedulable(resources *corev1.ResourceRequirements, cpuCap int64,
 			resources.Requests[corev1.ResourceMemory] = memoryRequestCap
 		}
 	}
+
+	if _, ok := resources.Requests[corev1.ResourceEphemeralStorage]; ok {
+		ephemeralStorageRequestCap := resource.MustParse(ephemeralStorageCap)
+		if resources.Requests.StorageEphemeral().Cmp(ephemeralStorageRequestCap) == 1 {
+			logger.Debugf("setting original ephemeral storage request of: %s to cap", resources.Requests.StorageEphemeral())
+			resources.Requests[corev1.ResourceEphemeralStorage] = ephemeralStorageRequestCap
+		}
+	}
 }
 
-func mutatePodResources(pod *corev1.Pod, server *resourceServer, mutateResourceLimits bool, cpuCap int64, memoryCap string, reporter results.PodScalerReporter, logger *logrus.Entry) {
+func mutatePodResources(pod *corev1.Pod, server *resourceServer, mutateResourceLimits bool, cpuCap int64, memoryCap, ephemeralStorageCap string, reporter results.PodScalerReporter, logger *logrus.Entry) {
 	mutateResources := func(containers []corev1.Container) {
 		for i := range containers {
 			meta := podscaler.MetadataFor(pod.ObjectMeta.Labels, pod.ObjectMeta.Name, containers[i].Name)
@@ -301,12 +362,21 @@ func mutatePodResources(pod *corev1.Pod, server *resourceServer, mutateResourceL
 				logger.Debugf("recommendation exists for: %s", containers[i].Name)
 				workloadType := determineWorkloadType(pod.Annotations, pod.Labels)
 				workloadName := determineWorkloadName(pod.Name, containers[i].Name, workloadType, pod.Labels)
+				adjustments := server.recommendedAdjustmentsFor(meta)
+				if mutateResourceLimits {
+					// CPU throttling is caused by the CPU limit, which reconcileLimits removes, so
+					// raising the CPU request in response to it would not help the workload
+					delete(adjustments, corev1.ResourceCPU)
+				}
+				resources = applyAdjustments(resources, adjustments, logger.WithField("workloadName", workloadName))
 				useOursIfLarger(&resources, &containers[i].Resources, workloadName, workloadType, reporter, logger)
 				if mutateResourceLimits {
 					reconcileLimits(&containers[i].Resources)
+				} else if a, throttled := adjustments[corev1.ResourceCPU]; throttled {
+					raiseCPULimit(&containers[i].Resources, a, logger.WithField("workloadName", workloadName))
 				}
 			}
-			preventUnschedulable(&containers[i].Resources, cpuCap, memoryCap, logger)
+			preventUnschedulable(&containers[i].Resources, cpuCap, memoryCap, ephemeralStorageCap, logger)
 		}
 	}
 	mutateResources(pod.Spec.InitContainers)
@@ -104,6 +104,7 @@ func TestMutatePods(t *testing.T) {
 		decoder:               decoder,
 		cpuCap:                10,
 		memoryCap:             "20Gi",
+		ephemeralStorageCap:   "100Gi",
 		cpuPriorityScheduling: 8,
 		reporter:              &defaultReporter,
 	}
@@ -549,12 +550,145 @@ func TestMutatePodResources(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "resources to add, recently starved",
+			server: &resourceServer{
+				logger: logger,
+				lock:   sync.RWMutex{},
+				byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{
+					baseWithContainer(&metaBase, "oomkilled"): {
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(1, resource.DecimalSI),
+							corev1.ResourceMemory: *resource.NewQuantity(2e8, resource.BinarySI),
+						},
+					},
+					baseWithContainer(&metaBase, "throttled"): {
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+							corev1.ResourceMemory: *resource.NewQuantity(2e8, resource.BinarySI),
+						},
+					},
+				},
+				adjustments: map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment{
+					baseWithContainer(&metaBase, "oomkilled"): {
+						corev1.ResourceMemory: {Factor: 1.5, Reason: "OOMKilled"},
+					},
+					baseWithContainer(&metaBase, "throttled"): {
+						corev1.ResourceCPU: {Factor: 1.25, Reason: "throttled"},
+					},
+				},
+			},
+			mutateResourceLimits: true,
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "tomutate",
+					Labels: map[string]string{
+						"ci.openshift.io/metadata.org":     "org",
+						"ci.openshift.io/metadata.repo":    "repo",
+						"ci.openshift.io/metadata.branch":  "branch",
+						"ci.openshift.io/metadata.variant": "variant",
+						"ci.openshift.io/metadata.target":  "target",
+						"ci.openshift.io/metadata.step":    "step",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "oomkilled", // memory is increased beyond the recommendation
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    *resource.NewQuantity(1, resource.DecimalSI),
+									corev1.ResourceMemory: *resource.NewQuantity(2e8, resource.BinarySI),
+								},
+							},
+						},
+						{
+							Name: "throttled", // CPU limits are removed, so throttling does not increase CPU
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+									corev1.ResourceMemory: *resource.NewQuantity(2e8, resource.BinarySI),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "resources to add, recently starved, limits kept",
+			server: &resourceServer{
+				logger: logger,
+				lock:   sync.RWMutex{},
+				byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{
+					baseWithContainer(&metaBase, "oomkilled"): {
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(1, resource.DecimalSI),
+							corev1.ResourceMemory: *resource.NewQuantity(2e8, resource.BinarySI),
+						},
+					},
+					baseWithContainer(&metaBase, "throttled"): {
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+							corev1.ResourceMemory: *resource.NewQuantity(2e8, resource.BinarySI),
+						},
+					},
+				},
+				adjustments: map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment{
+					baseWithContainer(&metaBase, "oomkilled"): {
+						corev1.ResourceMemory: {Factor: 1.5, Reason: "OOMKilled"},
+					},
+					baseWithContainer(&metaBase, "throttled"): {
+						corev1.ResourceCPU: {Factor: 1.25, Reason: "throttled"},
+					},
+				},
+			},
+			mutateResourceLimits: false,
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "tomutate",
+					Labels: map[string]string{
+						"ci.openshift.io/metadata.org":     "org",
+						"ci.openshift.io/metadata.repo":    "repo",
+						"ci.openshift.io/metadata.branch":  "branch",
+						"ci.openshift.io/metadata.variant": "variant",
+						"ci.openshift.io/metadata.target":  "target",
+						"ci.openshift.io/metadata.step":    "step",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "oomkilled", // memory is increased beyond the recommendation
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    *resource.NewQuantity(1, resource.DecimalSI),
+									corev1.ResourceMemory: *resource.NewQuantity(2e8, resource.BinarySI),
+								},
+							},
+						},
+						{
+							Name: "throttled", // CPU request and limit are increased beyond the recommendation
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+									corev1.ResourceMemory: *resource.NewQuantity(2e8, resource.BinarySI),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU: *resource.NewQuantity(4, resource.DecimalSI),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			original := testCase.pod.DeepCopy()
-			mutatePodResources(testCase.pod, testCase.server, testCase.mutateResourceLimits, 10, "20Gi", &defaultReporter, logrus.WithField("test", testCase.name))
+			mutatePodResources(testCase.pod, testCase.server, testCase.mutateResourceLimits, 10, "20Gi", "100Gi", &defaultReporter, logrus.WithField("test", testCase.name))
 			diff := cmp.Diff(original, testCase.pod)
 			// In some cases, cmp.Diff decides to use non-breaking spaces, and it's not
 			// particularly deterministic about this. We don't care.
@@ -595,15 +729,34 @@ func TestUseOursIfLarger(t *testing.T) {
 			},
 			expected: corev1.ResourceRequirements{
 				Limits: corev1.ResourceList{
-					corev1.ResourceCPU:    *resource.NewQuantity(240, resource.DecimalSI),
-					corev1.ResourceMemory: *resource.NewQuantity(36e9, resource.BinarySI),
+					corev1.ResourceCPU:    *resource.NewQuantity(200, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(3e10, resource.BinarySI),
 				},
 				Requests: corev1.ResourceList{
-					corev1.ResourceCPU:    *resource.NewQuantity(120, resource.DecimalSI),
-					corev1.ResourceMemory: *resource.NewQuantity(24e9, resource.BinarySI),
+					corev1.ResourceCPU:    *resource.NewQuantity(100, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(2e10, resource.BinarySI),
 				},
 			},
 		},
+		{
+			name: "ephemeral storage in ours is larger",
+			ours: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(2e10, resource.BinarySI),
+				},
+			},
+			theirs: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(1e10, resource.BinarySI),
+				},
+			},
+			expected: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(2e10, resource.BinarySI),
+				},
+				Limits: corev1.ResourceList{},
+			},
+		},
 		{
 			name: "nothing in ours",
 			theirs: corev1.ResourceRequirements{
@@ -651,12 +804,12 @@ func TestUseOursIfLarger(t *testing.T) {
 			},
 			expected: corev1.ResourceRequirements{
 				Limits: corev1.ResourceList{
-					corev1.ResourceCPU:    *resource.NewQuantity(240, resource.DecimalSI),
-					corev1.ResourceMemory: *resource.NewQuantity(36e9, resource.BinarySI),
+					corev1.ResourceCPU:    *resource.NewQuantity(200, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(3e10, resource.BinarySI),
 				},
 				Requests: corev1.ResourceList{
-					corev1.ResourceCPU:    *resource.NewQuantity(120, resource.DecimalSI),
-					corev1.ResourceMemory: *resource.NewQuantity(24e9, resource.BinarySI),
+					corev1.ResourceCPU:    *resource.NewQuantity(100, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(2e10, resource.BinarySI),
 				},
 			},
 		},
@@ -717,12 +870,12 @@ func TestUseOursIfLarger(t *testing.T) {
 			},
 			expected: corev1.ResourceRequirements{
 				Limits: corev1.ResourceList{
-					corev1.ResourceCPU:    *resource.NewQuantity(480, resource.DecimalSI),
+					corev1.ResourceCPU:    *resource.NewQuantity(400, resource.DecimalSI),
 					corev1.ResourceMemory: *resource.NewQuantity(3e10, resource.BinarySI),
 				},
 				Requests: corev1.ResourceList{
-					corev1.ResourceCPU:    *resource.NewQuantity(1200, resource.DecimalSI),
-					corev1.ResourceMemory: *resource.NewQuantity(48e9, resource.BinarySI),
+					corev1.ResourceCPU:    *resource.NewQuantity(1000, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(4e10, resource.BinarySI),
 				},
 			},
 		},
@@ -861,6 +1014,25 @@ func TestReconcileLimits(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "increase low ephemeral storage limits",
+			input: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(1e10, resource.BinarySI),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(2e10, resource.BinarySI),
+				},
+			},
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(4e10, resource.BinarySI),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(2e10, resource.BinarySI),
+				},
+			},
+		},
 		{
 			name: "do nothing for adequate memory limits",
 			input: corev1.ResourceRequirements{
@@ -942,6 +1114,7 @@ func TestRehearsalMetadata(t *testing.T) {
 func TestPreventUnschedulable(t *testing.T) {
 	cpuCap := int64(10)
 	memoryCap := "20Gi"
+	ephemeralStorageCap := "100Gi"
 	testCases := []struct {
 		name      string
 		resources *corev1.ResourceRequirements
@@ -1003,6 +1176,19 @@ func TestPreventUnschedulable(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "too much ephemeral storage",
+			resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse("250Gi"),
+				},
+			},
+			expected: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse(ephemeralStorageCap),
+				},
+			},
+		},
 		{
 			name:      "no requests",
 			resources: &corev1.ResourceRequirements{},
@@ -1011,7 +1197,7 @@ func TestPreventUnschedulable(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			preventUnschedulable(tc.resources, cpuCap, memoryCap, logrus.WithField("test", tc.name))
+			preventUnschedulable(tc.resources, cpuCap, memoryCap, ephemeralStorageCap, logrus.WithField("test", tc.name))
 			if diff := cmp.Diff(tc.expected, tc.resources); diff != "" {
 				t.Fatalf("result doesn't match expected, diff: %s", diff)
 			}
@@ -8,7 +8,6 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/prow/pkg/interrupts"
-	"sigs.k8s.io/prow/pkg/pjutil"
 
 	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
 )
@@ -84,7 +83,7 @@ func (c *cacheReloader) reload() {
 	logger.Debug("Newer update loaded.")
 }
 
-func digestAll(data map[string][]*cacheReloader, digesters map[string]digester, health *pjutil.Health, logger *logrus.Entry) {
+func digestAll(data map[string][]*cacheReloader, digesters map[string]digester, ready func(), logger *logrus.Entry) {
 	var infos []digestInfo
 	for id, d := range digesters {
 		for _, item := range data[id] {
@@ -111,12 +110,13 @@ func digestAll(data map[string][]*cacheReloader, digesters map[string]digester,
 			return
 		case <-loadDone:
 			logger.Debug("Ready to serve.")
-			health.ServeReady()
+			ready()
 		}
 	})
 }
 
-type digester func(query *podscaler.CachedQuery)
+// digester consumes data loaded by the named reloader
+type digester func(source string, query *podscaler.CachedQuery)
 
 type digestInfo struct {
 	name         string
@@ -153,7 +153,7 @@ func digest(logger *logrus.Entry, infos ...digestInfo) <-chan interface{} {
 					return
 				case data := <-info.subscription:
 					subLogger.Debug("Digesting new data from subscription.")
-					info.digest(data)
+					info.digest(info.name, data)
 					thisOnce.Do(update)
 				}
 			}
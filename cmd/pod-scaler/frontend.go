@@ -204,17 +204,21 @@ var (
 func serveUI(port, healthPort int, dataDir string, loaders map[string][]*cacheReloader) {
 	logger := logrus.WithField("component", "pod-scaler frontend")
 	server := &frontendServer{
-		logger:   logger,
-		lock:     sync.RWMutex{},
-		mappings: endpoints(),
-		indices:  map[string][]*IndexNode{},
-		dataDir:  dataDir,
+		logger:      logger,
+		lock:        sync.RWMutex{},
+		mappings:    endpoints(),
+		indices:     map[string][]*IndexNode{},
+		dataDir:     dataDir,
+		adjustments: map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment{},
 	}
 	health := pjutil.NewHealthOnPort(healthPort)
 	digestAll(loaders, map[string]digester{
 		MetricNameCPUUsage:         server.digestCPU,
 		MetricNameMemoryWorkingSet: server.digestMemory,
-	}, health, logger)
+		MetricNameFilesystemUsage:  server.digestEphemeralStorage,
+		MetricNameOOMKilled:        server.digestOOMKilled,
+		MetricNameCPUThrottling:    server.digestCPUThrottling,
+	}, func() { health.ServeReady() }, logger)
 
 	var nodes []simplifypath.Node
 	for name := range server.mappings {
@@ -323,6 +327,9 @@ type frontendServer struct {
 
 	// dataDir is where we hold sharded data by metadata identifier
 	dataDir string
+
+	// adjustments hold the increases we make to recommendations by metadata identifier
+	adjustments map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment
 }
 
 // dataForDisplay caches precomputed values for displaying data
@@ -331,6 +338,8 @@ type dataForDisplay struct {
 	LowerBound float64                     `json:"lower_bound"`
 	Merged     *circonusllhist.Histogram   `json:"merged"`
 	Histograms []*circonusllhist.Histogram `json:"histograms"`
+	// Adjustment is the increase we make to the recommendation, if any
+	Adjustment *adjustment `json:"adjustment,omitempty"`
 }
 
 func (s *frontendServer) getIndex(index string) http.HandlerFunc {
@@ -410,6 +419,7 @@ func hashed(meta podscaler.FullMetadata) (string, error) {
 	return base32.StdEncoding.EncodeToString(hash.Sum(nil)), nil
 }
 
+// getDatum loads the data for display for a workload; callers must hold the read lock.
 func (s *frontendServer) getDatum(meta podscaler.FullMetadata) (map[corev1.ResourceName]dataForDisplay, bool, error) {
 	hash, err := hashed(meta)
 	if err != nil {
@@ -419,6 +429,7 @@ func (s *frontendServer) getDatum(meta podscaler.FullMetadata) (map[corev1.Resou
 	if _, err := os.Stat(subDir); os.IsNotExist(err) {
 		return nil, false, nil
 	}
+	adjustments := s.adjustments[meta]
 	datum := map[corev1.ResourceName]dataForDisplay{}
 	if err := filepath.Walk(subDir, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
@@ -437,6 +448,9 @@ func (s *frontendServer) getDatum(meta podscaler.FullMetadata) (map[corev1.Resou
 		if err := json.Unmarshal(raw, &subDatum); err != nil {
 			return fmt.Errorf("could not unmarshal file: %w", err)
 		}
+		if a, adjusted := adjustments[corev1.ResourceName(filename)]; adjusted {
+			subDatum.Adjustment = &a
+		}
 		datum[corev1.ResourceName(filename)] = subDatum
 		return nil
 	}); err != nil {
@@ -461,16 +475,39 @@ func (s *frontendServer) setDatum(meta podscaler.FullMetadata, resource corev1.R
 	return os.WriteFile(filepath.Join(subDir, fmt.Sprintf("%s.json", string(resource))), raw, 0777)
 }
 
-func (s *frontendServer) digestCPU(data *podscaler.CachedQuery) {
+func (s *frontendServer) digestCPU(_ string, data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new CPU consumption metrics.")
 	s.digestData(data, corev1.ResourceCPU, cpuRequestQuantile)
 }
 
-func (s *frontendServer) digestMemory(data *podscaler.CachedQuery) {
+func (s *frontendServer) digestMemory(_ string, data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new Memory consumption metrics.")
 	s.digestData(data, corev1.ResourceMemory, memRequestQuantile)
 }
 
+func (s *frontendServer) digestEphemeralStorage(_ string, data *podscaler.CachedQuery) {
+	s.logger.Debugf("Digesting new ephemeral storage consumption metrics.")
+	s.digestData(data, corev1.ResourceEphemeralStorage, ephemeralStorageRequestQuantile)
+}
+
+func (s *frontendServer) digestOOMKilled(_ string, data *podscaler.CachedQuery) {
+	s.logger.Debugf("Digesting new OOMKilled termination metrics.")
+	s.digestAdjustments(data, corev1.ResourceMemory, oomKilledAdjustment)
+}
+
+func (s *frontendServer) digestCPUThrottling(_ string, data *podscaler.CachedQuery) {
+	s.logger.Debugf("Digesting new CPU throttling metrics.")
+	s.digestAdjustments(data, corev1.ResourceCPU, cpuThrottlingAdjustment)
+}
+
+func (s *frontendServer) digestAdjustments(data *podscaler.CachedQuery, metric corev1.ResourceName, adjust adjuster) {
+	adjustments := adjustmentsFor(data, time.Now(), adjust)
+	s.lock.Lock()
+	updateAdjustments(s.adjustments, data, metric, adjustments)
+	s.lock.Unlock()
+	s.logger.Debugf("Finished digesting new data, %d identifiers adjusted.", len(adjustments))
+}
+
 func (s *frontendServer) digestData(data *podscaler.CachedQuery, metric corev1.ResourceName, quantile float64) {
 	s.logger.Debugf("Digesting %d identifiers.", len(data.DataByMetaData))
 	for meta, fingerprintTimes := range data.DataByMetaData {
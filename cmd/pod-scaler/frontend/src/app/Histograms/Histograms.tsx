@@ -11,11 +11,19 @@ export interface HistogramsProps {
     parameters: string;
 }
 
+export interface Adjustment {
+    /** the multiplier applied to the recommendation */
+    factor: number;
+    /** why the recommendation was increased */
+    reason: string;
+}
+
 export interface rawData {
     cutoff: string;
     lower_bound: string;
     merged: string;
     histograms: string[];
+    adjustment?: Adjustment;
 }
 
 export interface Data {
@@ -23,6 +31,7 @@ export interface Data {
     lower_bound: number;
     merged: Histogram;
     histograms: Histogram[];
+    adjustment?: Adjustment;
 }
 
 export type HistogramData = Record<string, Data>;
@@ -35,6 +44,7 @@ const process = (raw: Record<string, rawData>): HistogramData => {
                 lower_bound: parseFloat(raw[resource].lower_bound),
                 merged: DeserializeHistogram(Buffer.from(raw[resource].merged, 'base64')),
                 histograms: [],
+                adjustment: raw[resource].adjustment,
             };
             for (const histogram of raw[resource].histograms) {
                 datum.histograms.push(DeserializeHistogram(Buffer.from(histogram, 'base64')))
@@ -82,42 +92,62 @@ export const Histograms: React.FunctionComponent<HistogramsProps> = (
         return <div><Spinner isSVG size="xl"/>Loading resource usage data...</div>
     }
 
-    return <Flex direction={{default: 'row'}}
-                 flexWrap={{default: 'wrap', lg: "nowrap", xl: "nowrap", '2xl': "nowrap"}}
-                 justifyContent={{default: 'justifyContentSpaceAround'}}
-                 alignItems={{default: 'alignItemsCenter'}}
-                 alignContent={{default: 'alignContentStretch'}}>
-        {data["cpu"] && <LogarithmicComparativePlot
-            {...data["cpu"]}
-            canvasProps={{
-                title: "CPU Usage",
-                yAxisFormatter(value: number): string {
-                    const n: number = value * 1000;
-                    if (value > 10) {
-                        Math.round(n).toString();
-                    }
-                    return n.toFixed(2);
-                },
-                yAxisMin: 1e-5,
-                yAxisTitle: "CPU Used",
-                yAxisUnit: "mCPU",
-            }}/>}
-        {data["memory"] && <LogarithmicComparativePlot
-            {...data["memory"]}
-            canvasProps={{
-                title: "Memory Usage",
-                yAxisFormatter(value: number): string {
-                    const n: number = value / Math.pow(2, 20);
-                    if (value > 10) {
-                        Math.round(n).toString();
-                    }
-                    return n.toFixed(2);
-                },
-                yAxisMin: 10 * Math.pow(2, 20),
-                yAxisTitle: "Memory Used",
-                yAxisUnit: "MiB",
-            }}/>}
-    </Flex>;
+    const adjustments = Object.keys(data).filter((resource) => data[resource].adjustment).map((resource) => {
+        const adjustment = data[resource].adjustment as Adjustment;
+        return <Alert key={resource} isInline variant="warning"
+                      title={`The ${resource} recommendation is increased by ${adjustment.factor}x: ${adjustment.reason}`}/>
+    });
+
+    return <React.Fragment>
+        {adjustments}
+        <Flex direction={{default: 'row'}}
+              flexWrap={{default: 'wrap', lg: "nowrap", xl: "nowrap", '2xl': "nowrap"}}
+              justifyContent={{default: 'justifyContentSpaceAround'}}
+              alignItems={{default: 'alignItemsCenter'}}
+              alignContent={{default: 'alignContentStretch'}}>
+            {data["cpu"] && <LogarithmicComparativePlot
+                {...data["cpu"]}
+                canvasProps={{
+                    title: "CPU Usage",
+                    yAxisFormatter(value: number): string {
+                        const n: number = value * 1000;
+                        if (value > 10) {
+                            Math.round(n).toString();
+                        }
+                        return n.toFixed(2);
+                    },
+                    yAxisMin: 1e-5,
+                    yAxisTitle: "CPU Used",
+                    yAxisUnit: "mCPU",
+                }}/>}
+            {data["memory"] && <LogarithmicComparativePlot
+                {...data["memory"]}
+                canvasProps={{
+                    title: "Memory Usage",
+                    yAxisFormatter(value: number): string {
+                        const n: number = value / Math.pow(2, 20);
+                        if (value > 10) {
+                            Math.round(n).toString();
+                        }
+                        return n.toFixed(2);
+                    },
+                    yAxisMin: 10 * Math.pow(2, 20),
+                    yAxisTitle: "Memory Used",
+                    yAxisUnit: "MiB",
+                }}/>}
+            {data["ephemeral-storage"] && <LogarithmicComparativePlot
+                {...data["ephemeral-storage"]}
+                canvasProps={{
+                    title: "Ephemeral Storage Usage",
+                    yAxisFormatter(value: number): string {
+                        return (value / Math.pow(2, 20)).toFixed(2);
+                    },
+                    yAxisMin: Math.pow(2, 20),
+                    yAxisTitle: "Ephemeral Storage Used",
+                    yAxisUnit: "MiB",
+                }}/>}
+        </Flex>
+    </React.Fragment>;
 };
 
 Histograms.displayName = 'Histograms';
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"google.golang.org/api/option"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/transport"
@@ -25,10 +27,12 @@ import (
 	"sigs.k8s.io/prow/pkg/metrics"
 	pprofutil "sigs.k8s.io/prow/pkg/pjutil/pprof"
 	"sigs.k8s.io/prow/pkg/version"
+	"sigs.k8s.io/yaml"
 
 	buildclientset "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 
+	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/prowconfigutils"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/util"
@@ -66,7 +70,12 @@ type consumerOptions struct {
 	mutateResourceLimits  bool
 	cpuCap                int64
 	memoryCap             string
+	ephemeralStorageCap   string
 	cpuPriorityScheduling int64
+
+	reportPod    string
+	reportConfig string
+	reportTest   string
 }
 
 func bindOptions(fs *flag.FlagSet) *options {
@@ -88,6 +97,10 @@ func bindOptions(fs *flag.FlagSet) *options {
 	fs.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "File where GCS credentials are stored.")
 	fs.Int64Var(&o.cpuCap, "cpu-cap", 10, "The maximum CPU request value, ex: 10")
 	fs.StringVar(&o.memoryCap, "memory-cap", "20Gi", "The maximum memory request value, ex: '20Gi'")
+	fs.StringVar(&o.ephemeralStorageCap, "ephemeral-storage-cap", "100Gi", "The maximum ephemeral storage request value, ex: '100Gi'")
+	fs.StringVar(&o.reportPod, "report-pod", "", "Path to a Pod manifest to report recommendations for (for report mode).")
+	fs.StringVar(&o.reportConfig, "report-config", "", "Path to a resolved ci-operator configuration to report recommendations for (for report mode).")
+	fs.StringVar(&o.reportTest, "report-test", "", "Name of the test in --report-config to report recommendations for (for report mode).")
 	fs.Int64Var(&o.cpuPriorityScheduling, "cpu-priority-scheduling", 8, "Pods with CPU requests at, or above, this value will be admitted with priority scheduling")
 	o.resultsOptions.Bind(fs)
 	return &o
@@ -116,18 +129,25 @@ func (o *options) validate() error {
 		if o.certDir == "" {
 			return errors.New("--serving-cert-dir is required")
 		}
-		if cpuCap := resource.NewQuantity(o.cpuCap, resource.DecimalSI); cpuCap.Sign() <= 0 {
-			return errors.New("--cpu-cap must be greater than 0")
-		}
-		if memoryCap := resource.MustParse(o.memoryCap); memoryCap.Sign() <= 0 {
-			return errors.New("--memory-cap must be greater than 0")
+		if err := o.validateCaps(); err != nil {
+			return err
 		}
 		if err := o.resultsOptions.Validate(); err != nil {
 			return err
 		}
+	case "report":
+		if (o.reportPod == "") == (o.reportConfig == "") {
+			return errors.New("exactly one of --report-pod or --report-config is required")
+		}
+		if (o.reportConfig == "") != (o.reportTest == "") {
+			return errors.New("--report-test is required with, and only with, --report-config")
+		}
+		if err := o.validateCaps(); err != nil {
+			return err
+		}
 
 	default:
-		return errors.New("--mode must be either \"producer\", \"consumer.ui\", or \"consumer.admission\"")
+		return errors.New("--mode must be either \"producer\", \"consumer.ui\", \"consumer.admission\", or \"report\"")
 	}
 	if o.cacheDir == "" {
 		if o.cacheBucket == "" {
@@ -149,6 +169,19 @@ func (o *options) validate() error {
 	return o.instrumentationOptions.Validate(false)
 }
 
+func (o *options) validateCaps() error {
+	if cpuCap := resource.NewQuantity(o.cpuCap, resource.DecimalSI); cpuCap.Sign() <= 0 {
+		return errors.New("--cpu-cap must be greater than 0")
+	}
+	if memoryCap := resource.MustParse(o.memoryCap); memoryCap.Sign() <= 0 {
+		return errors.New("--memory-cap must be greater than 0")
+	}
+	if ephemeralStorageCap, err := resource.ParseQuantity(o.ephemeralStorageCap); err != nil || ephemeralStorageCap.Sign() <= 0 {
+		return errors.New("--ephemeral-storage-cap must be a quantity greater than 0")
+	}
+	return nil
+}
+
 func main() {
 	flagSet := flag.NewFlagSet("", flag.ExitOnError)
 	opts := bindOptions(flagSet)
@@ -193,6 +226,9 @@ func main() {
 		mainUI(opts, cache)
 	case "consumer.admission":
 		mainAdmission(opts, cache)
+	case "report":
+		mainReport(opts, cache)
+		return
 	}
 	if !opts.once {
 		interrupts.WaitForGracefulShutdown()
@@ -265,14 +301,63 @@ func mainAdmission(opts *options, cache Cache) {
 		logrus.WithError(err).Fatal("Failed to create pod-scaler reporter.")
 	}
 
-	go admit(opts.port, opts.instrumentationOptions.HealthPort, opts.certDir, client, loaders(cache), opts.mutateResourceLimits, opts.cpuCap, opts.memoryCap, opts.cpuPriorityScheduling, reporter)
+	go admit(opts.port, opts.instrumentationOptions.HealthPort, opts.certDir, client, loaders(cache), opts.mutateResourceLimits, opts.cpuCap, opts.memoryCap, opts.ephemeralStorageCap, opts.cpuPriorityScheduling, reporter)
+}
+
+func mainReport(opts *options, cache Cache) {
+	var request reportRequest
+	if opts.reportPod != "" {
+		request.Pod = &corev1.Pod{}
+		if err := loadYAML(opts.reportPod, request.Pod); err != nil {
+			logrus.WithError(err).Fatal("Failed to load Pod.")
+		}
+	} else {
+		request.Config = &api.ReleaseBuildConfiguration{}
+		if err := loadYAML(opts.reportConfig, request.Config); err != nil {
+			logrus.WithError(err).Fatal("Failed to load configuration.")
+		}
+		request.Test = opts.reportTest
+	}
+
+	loaded := make(chan struct{})
+	server := &reportServer{
+		resources:            newResourceServer(loaders(cache), func() { close(loaded) }),
+		mutateResourceLimits: opts.mutateResourceLimits,
+		cpuCap:               opts.cpuCap,
+		memoryCap:            opts.memoryCap,
+		ephemeralStorageCap:  opts.ephemeralStorageCap,
+		logger:               logrus.WithField("component", "pod-scaler report"),
+	}
+	select {
+	case <-interrupts.Context().Done():
+		logrus.Fatal("Interrupted while loading data.")
+	case <-loaded:
+	}
+	out, err := server.reportFor(request)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to determine report.")
+	}
+	raw, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to marshal report.")
+	}
+	fmt.Println(string(raw))
+}
+
+func loadYAML(path string, into interface{}) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(raw, into)
 }
 
 func loaders(cache Cache) map[string][]*cacheReloader {
 	l := map[string][]*cacheReloader{}
 	for _, prefix := range []string{ProwjobsCachePrefix, PodsCachePrefix, StepsCachePrefix} {
-		l[MetricNameCPUUsage] = append(l[MetricNameCPUUsage], newReloader(prefix+"/"+MetricNameCPUUsage, cache))
-		l[MetricNameMemoryWorkingSet] = append(l[MetricNameMemoryWorkingSet], newReloader(prefix+"/"+MetricNameMemoryWorkingSet, cache))
+		for _, metric := range []string{MetricNameCPUUsage, MetricNameMemoryWorkingSet, MetricNameFilesystemUsage, MetricNameOOMKilled, MetricNameCPUThrottling} {
+			l[metric] = append(l[metric], newReloader(prefix+"/"+metric, cache))
+		}
 	}
 	return l
 }
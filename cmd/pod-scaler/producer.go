@@ -23,8 +23,12 @@ import (
 const (
 	MetricNameCPUUsage         = `container_cpu_usage_seconds_total`
 	MetricNameMemoryWorkingSet = `container_memory_working_set_bytes`
+	MetricNameOOMKilled        = `kube_pod_container_status_last_terminated_reason`
+	MetricNameCPUThrottling    = `container_cpu_cfs_throttled_periods_total`
+	MetricNameFilesystemUsage  = `container_fs_usage_bytes`
 
 	containerFilter = `{container!="POD",container!=""}`
+	oomKilledFilter = `{container!="POD",container!="",reason="OOMKilled"}`
 
 	// MaxSamplesPerRequest is the maximum number of samples that Prometheus will allow a client to ask for in
 	// one request. We also use this to approximate the maximum number of samples we should be asking any one
@@ -63,6 +67,12 @@ func queriesByMetric() map[string]string {
 		for name, metric := range map[string]string{
 			MetricNameCPUUsage:         `rate(` + MetricNameCPUUsage + containerFilter + `[3m])`,
 			MetricNameMemoryWorkingSet: MetricNameMemoryWorkingSet + containerFilter,
+			// the writable layer and logs of the container, which count against its ephemeral storage
+			MetricNameFilesystemUsage: MetricNameFilesystemUsage + containerFilter,
+			// containers that were last terminated for running out of memory
+			MetricNameOOMKilled: MetricNameOOMKilled + oomKilledFilter,
+			// the ratio of CPU scheduling periods in which the container was throttled
+			MetricNameCPUThrottling: `rate(` + MetricNameCPUThrottling + containerFilter + `[3m]) / rate(container_cpu_cfs_periods_total` + containerFilter + `[3m])`,
 		} {
 			queries[fmt.Sprintf("%s/%s", info.prefix, name)] = queryFor(metric, info.selector, info.labels)
 		}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+	"github.com/openshift/ci-tools/pkg/steps"
+)
+
+// reportRequest asks what the admission webhook would do to a workload, which is
+// given either as a raw Pod or as a test in a ci-operator configuration
+type reportRequest struct {
+	Pod    *corev1.Pod                    `json:"pod,omitempty"`
+	Config *api.ReleaseBuildConfiguration `json:"config,omitempty"`
+	Test   string                         `json:"test,omitempty"`
+}
+
+// report describes what the admission webhook would do to the resources of Pods
+type report struct {
+	Pods []podReport `json:"pods"`
+	// Notes describe the limits of the report, like containers which were not modelled.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// modelledContainersNote explains that only the test container is modelled for
+// Pods determined from a configuration: the init containers and the sidecar are
+// added by Prow decoration and ci-operator with resources from the decoration
+// configuration, which we do not have here
+const modelledContainersNote = "Only the test container of each Pod is included: the init containers (place-entrypoint, cp-entrypoint-wrapper) and the sidecar container added when the Pods are created are missing from this report."
+
+type podReport struct {
+	Name       string            `json:"name"`
+	Containers []containerReport `json:"containers"`
+}
+
+// reportedMetadata drops the text marshalling of the full metadata, so that it
+// is rendered as an object in the report and not as an opaque string
+type reportedMetadata podscaler.FullMetadata
+
+type containerReport struct {
+	Name          string           `json:"name"`
+	InitContainer bool             `json:"init_container,omitempty"`
+	Metadata      reportedMetadata `json:"metadata"`
+	// Configured are the resources the container was created with.
+	Configured corev1.ResourceRequirements `json:"configured"`
+	// Recommended are the resources we determined from historical data, if any.
+	Recommended *corev1.ResourceRequirements `json:"recommended,omitempty"`
+	// Adjustments are the increases made to the recommendation for recent resource starvation.
+	Adjustments map[corev1.ResourceName]adjustment `json:"adjustments,omitempty"`
+	// Sources describes the data behind each recommended value.
+	Sources map[corev1.ResourceName]recommendationSource `json:"sources,omitempty"`
+	// Mutated are the resources the container would be admitted with.
+	Mutated corev1.ResourceRequirements `json:"mutated"`
+}
+
+// discardingReporter drops warnings, as reports must not have side effects
+type discardingReporter struct{}
+
+func (discardingReporter) ReportResourceConfigurationWarning(string, string, string, string, string) {
+}
+
+// reportServer determines what the admission webhook would do to a workload
+type reportServer struct {
+	resources            *resourceServer
+	mutateResourceLimits bool
+	cpuCap               int64
+	memoryCap            string
+	ephemeralStorageCap  string
+	logger               *logrus.Entry
+}
+
+func (r *reportServer) reportFor(request reportRequest) (*report, error) {
+	var pods []corev1.Pod
+	switch {
+	case request.Pod != nil && request.Config != nil:
+		return nil, errors.New("either a pod or a configuration must be provided, not both")
+	case request.Pod != nil:
+		pods = append(pods, *request.Pod)
+	case request.Config != nil:
+		if request.Test == "" {
+			return nil, errors.New("a test is required when a configuration is provided")
+		}
+		var err error
+		if pods, err = podsForTest(request.Config, request.Test); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("either a pod or a configuration must be provided")
+	}
+	out := report{}
+	if request.Config != nil {
+		out.Notes = append(out.Notes, modelledContainersNote)
+	}
+	for i := range pods {
+		reported, err := r.reportForPod(&pods[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not report for pod %s: %w", pods[i].Name, err)
+		}
+		out.Pods = append(out.Pods, reported)
+	}
+	return &out, nil
+}
+
+// reportForPod resolves metadata and mutates resources for a copy of the Pod exactly
+// as the admission webhook would. We have no access to Builds here, so Pods created
+// for them are expected to carry the labels of the Build already.
+func (r *reportServer) reportForPod(original *corev1.Pod) (podReport, error) {
+	pod := original.DeepCopy()
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	logger := r.logger.WithField("name", pod.Name)
+	if err := mutatePodMetadata(pod, logger); err != nil {
+		return podReport{}, err
+	}
+	var containers []containerReport
+	for _, set := range []struct {
+		containers []corev1.Container
+		init       bool
+	}{
+		{containers: pod.Spec.InitContainers, init: true},
+		{containers: pod.Spec.Containers},
+	} {
+		for _, container := range set.containers {
+			meta := podscaler.MetadataFor(pod.ObjectMeta.Labels, pod.ObjectMeta.Name, container.Name)
+			reported := containerReport{
+				Name:          container.Name,
+				InitContainer: set.init,
+				Metadata:      reportedMetadata(meta),
+				Configured:    *container.Resources.DeepCopy(),
+			}
+			if recommended, exists := r.resources.recommendedRequestFor(meta); exists {
+				reported.Recommended = &recommended
+				reported.Adjustments = r.resources.recommendedAdjustmentsFor(meta)
+				reported.Sources = r.resources.recommendationSourcesFor(meta)
+			}
+			containers = append(containers, reported)
+		}
+	}
+	mutatePodResources(pod, r.resources, r.mutateResourceLimits, r.cpuCap, r.memoryCap, r.ephemeralStorageCap, discardingReporter{}, logger)
+	for i, container := range pod.Spec.InitContainers {
+		containers[i].Mutated = container.Resources
+	}
+	for i, container := range pod.Spec.Containers {
+		containers[len(pod.Spec.InitContainers)+i].Mutated = container.Resources
+	}
+	return podReport{Name: pod.Name, Containers: containers}, nil
+}
+
+// podsForTest determines the Pods, labelled as ci-operator would label them, that
+// run for a test. Only the names, labels and resources of the test containers are
+// set, the init containers and sidecar that are added to the Pods are missing.
+func podsForTest(config *api.ReleaseBuildConfiguration, test string) ([]corev1.Pod, error) {
+	for _, t := range config.Tests {
+		if t.As != test {
+			continue
+		}
+		jobSpec := &api.JobSpec{Metadata: config.Metadata, Target: test}
+		switch {
+		case t.ContainerTestConfiguration != nil:
+			resources, err := steps.ResourcesFor(config.Resources.RequirementsForStep(test))
+			if err != nil {
+				return nil, fmt.Errorf("invalid resources for test %s: %w", test, err)
+			}
+			return []corev1.Pod{podFor(steps.LabelsFor(jobSpec, nil, ""), test, resources)}, nil
+		case t.MultiStageTestConfigurationLiteral != nil:
+			var pods []corev1.Pod
+			literal := t.MultiStageTestConfigurationLiteral
+			for _, phase := range [][]api.LiteralTestStep{literal.Pre, literal.Test, literal.Post} {
+				for _, step := range phase {
+					resources, err := steps.ResourcesFor(step.Resources)
+					if err != nil {
+						return nil, fmt.Errorf("invalid resources for step %s: %w", step.As, err)
+					}
+					labels := steps.LabelsFor(jobSpec, map[string]string{steps.LabelMetadataStep: step.As}, "")
+					pods = append(pods, podFor(labels, fmt.Sprintf("%s-%s", test, step.As), resources))
+				}
+			}
+			return pods, nil
+		case t.MultiStageTestConfiguration != nil:
+			return nil, fmt.Errorf("test %s has unresolved steps, resolve the configuration first", test)
+		default:
+			return nil, fmt.Errorf("test %s is neither a container nor a multi-stage test", test)
+		}
+	}
+	return nil, fmt.Errorf("no test %s found in the configuration", test)
+}
+
+func podFor(labels map[string]string, name string, resources corev1.ResourceRequirements) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "test", Resources: resources}},
+		},
+	}
+}
+
+func (r *reportServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	var request reportRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+	out, err := r.reportFor(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		r.logger.WithError(err).Error("Could not marshal report.")
+		http.Error(w, fmt.Sprintf("could not marshal report: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(raw); err != nil {
+		r.logger.WithError(err).Warn("Could not write report.")
+	}
+}
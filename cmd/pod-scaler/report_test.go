@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+)
+
+func TestPodsForTest(t *testing.T) {
+	config := &api.ReleaseBuildConfiguration{
+		Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "branch"},
+		Resources: api.ResourceConfiguration{
+			"*":    {Requests: api.ResourceList{"cpu": "100m"}},
+			"unit": {Requests: api.ResourceList{"memory": "1Gi"}},
+		},
+		Tests: []api.TestStepConfiguration{
+			{As: "unit", ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"}},
+			{As: "e2e", MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+				Pre:  []api.LiteralTestStep{{As: "setup", Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}}}},
+				Test: []api.LiteralTestStep{{As: "test", Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "2"}}}},
+			}},
+			{As: "unresolved", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{}},
+		},
+	}
+	labelsFor := func(target, step string) map[string]string {
+		labels := map[string]string{
+			"ci.openshift.io/metadata.org":     "org",
+			"ci.openshift.io/metadata.repo":    "repo",
+			"ci.openshift.io/metadata.branch":  "branch",
+			"ci.openshift.io/metadata.variant": "",
+			"ci.openshift.io/metadata.target":  target,
+			"ci.openshift.io/jobid":            "",
+			"ci.openshift.io/jobtype":          "",
+			"ci.openshift.io/jobname":          "",
+			"created-by-ci":                    "true",
+			"OPENSHIFT_CI":                     "true",
+		}
+		if step != "" {
+			labels["ci.openshift.io/metadata.step"] = step
+		}
+		return labels
+	}
+	var testCases = []struct {
+		name          string
+		test          string
+		expected      []corev1.Pod
+		expectedError string
+	}{
+		{
+			name: "container test",
+			test: "unit",
+			expected: []corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "unit", Labels: labelsFor("unit", "")},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+				}}}},
+			}},
+		},
+		{
+			name: "multi-stage test",
+			test: "e2e",
+			expected: []corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "e2e-setup", Labels: labelsFor("e2e", "setup")},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					}}}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "e2e-test", Labels: labelsFor("e2e", "test")},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					}}}},
+				},
+			},
+		},
+		{
+			name:          "unresolved test",
+			test:          "unresolved",
+			expectedError: "test unresolved has unresolved steps, resolve the configuration first",
+		},
+		{
+			name:          "missing test",
+			test:          "missing",
+			expectedError: "no test missing found in the configuration",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			pods, err := podsForTest(config, testCase.test)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expectedError, actualError); diff != "" {
+				t.Fatalf("got incorrect error: %v", diff)
+			}
+			if diff := cmp.Diff(testCase.expected, pods); diff != "" {
+				t.Errorf("got incorrect pods: %v", diff)
+			}
+		})
+	}
+}
+
+func TestReportFor(t *testing.T) {
+	logger := logrus.WithField("test", "TestReportFor")
+	meta := podscaler.FullMetadata{
+		Metadata:  api.Metadata{Org: "org", Repo: "repo", Branch: "branch"},
+		Target:    "e2e",
+		Step:      "test",
+		Pod:       "e2e-test",
+		Container: "test",
+	}
+	added := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	server := &reportServer{
+		resources: &resourceServer{
+			logger: logger,
+			byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{
+				meta: {Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				}},
+			},
+			adjustments: map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment{
+				meta: {corev1.ResourceMemory: {Factor: 2, Reason: "OOMKilled"}},
+			},
+			sources: map[podscaler.FullMetadata]map[corev1.ResourceName]recommendationSource{
+				meta: {
+					corev1.ResourceCPU:    {Reloader: "steps/container_cpu_usage_seconds_total", Executions: 2, Start: added, End: added.Add(time.Hour)},
+					corev1.ResourceMemory: {Reloader: "steps/container_memory_working_set_bytes", Executions: 1, Start: added, End: added},
+				},
+			},
+		},
+		cpuCap:              3,
+		memoryCap:           "20Gi",
+		ephemeralStorageCap: "100Gi",
+		logger:              logger,
+	}
+	config := &api.ReleaseBuildConfiguration{
+		Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "branch"},
+		Tests: []api.TestStepConfiguration{
+			{As: "e2e", MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+				Test: []api.LiteralTestStep{{As: "test", Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1", "memory": "4Gi"}}}},
+			}},
+		},
+	}
+	expected := &report{Pods: []podReport{{
+		Name: "e2e-test",
+		Containers: []containerReport{{
+			Name:     "test",
+			Metadata: reportedMetadata(meta),
+			Configured: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+			},
+			Recommended: &corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}},
+			Adjustments: map[corev1.ResourceName]adjustment{corev1.ResourceMemory: {Factor: 2, Reason: "OOMKilled"}},
+			Sources: map[corev1.ResourceName]recommendationSource{
+				corev1.ResourceCPU:    {Reloader: "steps/container_cpu_usage_seconds_total", Executions: 2, Start: added, End: added.Add(time.Hour)},
+				corev1.ResourceMemory: {Reloader: "steps/container_memory_working_set_bytes", Executions: 1, Start: added, End: added},
+			},
+			Mutated: corev1.ResourceRequirements{
+				// the CPU recommendation is capped, and the configured memory is larger than the adjusted recommendation
+				Requests: corev1.ResourceList{corev1.ResourceCPU: *resource.NewQuantity(3, resource.DecimalSI), corev1.ResourceMemory: resource.MustParse("4Gi")},
+				Limits:   corev1.ResourceList{},
+			},
+		}},
+	}}, Notes: []string{modelledContainersNote}}
+
+	actual, err := server.reportFor(reportRequest{Config: config, Test: "e2e"})
+	if err != nil {
+		t.Fatalf("failed to report: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("got incorrect report: %v", diff)
+	}
+	if configured := config.Tests[0].MultiStageTestConfigurationLiteral.Test[0].Resources.Requests["cpu"]; configured != "1" {
+		t.Errorf("reporting mutated the configuration, cpu request is now %s", configured)
+	}
+	if recommended := server.resources.byMetaData[meta].Requests[corev1.ResourceMemory]; recommended.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("reporting mutated the recommendation, memory request is now %s", recommended.String())
+	}
+
+	raw, err := json.Marshal(reportRequest{Config: config, Test: "e2e"})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/report", bytes.NewReader(raw)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	var served report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &served); err != nil {
+		t.Fatalf("failed to unmarshal served report: %v", err)
+	}
+	if diff := cmp.Diff(expected.Pods[0].Containers[0].Metadata, served.Pods[0].Containers[0].Metadata); diff != "" {
+		t.Errorf("served report has incorrect metadata: %v", diff)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/report", bytes.NewReader([]byte(`{}`))))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an empty request, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
@@ -1,29 +1,36 @@
 package main
 
 import (
+	"fmt"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/openhistogram/circonusllhist"
 	"github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
-	"sigs.k8s.io/prow/pkg/pjutil"
 
 	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
 )
 
-func newResourceServer(loaders map[string][]*cacheReloader, health *pjutil.Health) *resourceServer {
+func newResourceServer(loaders map[string][]*cacheReloader, ready func()) *resourceServer {
 	logger := logrus.WithField("component", "pod-scaler request server")
 	server := &resourceServer{
-		logger:     logger,
-		lock:       sync.RWMutex{},
-		byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{},
+		logger:      logger,
+		lock:        sync.RWMutex{},
+		byMetaData:  map[podscaler.FullMetadata]corev1.ResourceRequirements{},
+		adjustments: map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment{},
+		sources:     map[podscaler.FullMetadata]map[corev1.ResourceName]recommendationSource{},
 	}
 	digestAll(loaders, map[string]digester{
 		MetricNameCPUUsage:         server.digestCPU,
 		MetricNameMemoryWorkingSet: server.digestMemory,
-	}, health, logger)
+		MetricNameFilesystemUsage:  server.digestEphemeralStorage,
+		MetricNameOOMKilled:        server.digestOOMKilled,
+		MetricNameCPUThrottling:    server.digestCPUThrottling,
+	}, ready, logger)
 
 	return server
 }
@@ -34,6 +41,23 @@ type resourceServer struct {
 	// byMetaData caches resource requirements calculated for the full assortment of
 	// metadata labels.
 	byMetaData map[podscaler.FullMetadata]corev1.ResourceRequirements
+	// adjustments holds increases to the recommendations for workloads which
+	// recently were starved of a resource.
+	adjustments map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment
+	// sources records where the data behind each recommendation came from.
+	sources map[podscaler.FullMetadata]map[corev1.ResourceName]recommendationSource
+}
+
+// recommendationSource describes the data from which a recommendation was determined
+type recommendationSource struct {
+	// Reloader is the name of the cache reloader which supplied the data
+	Reloader string `json:"reloader"`
+	// Executions is the number of executions for which data was digested
+	Executions int `json:"executions"`
+	// Start is when the oldest of the data was added
+	Start time.Time `json:"start"`
+	// End is when the newest of the data was added
+	End time.Time `json:"end"`
 }
 
 const (
@@ -47,9 +71,9 @@ func formatCPU() toQuantity {
 	}
 }
 
-func (s *resourceServer) digestCPU(data *podscaler.CachedQuery) {
+func (s *resourceServer) digestCPU(source string, data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new CPU consumption metrics.")
-	s.digestData(data, cpuRequestQuantile, corev1.ResourceCPU, formatCPU())
+	s.digestData(source, data, cpuRequestQuantile, corev1.ResourceCPU, formatCPU())
 }
 
 const (
@@ -63,22 +87,39 @@ func formatMemory() toQuantity {
 	}
 }
 
-func (s *resourceServer) digestMemory(data *podscaler.CachedQuery) {
+func (s *resourceServer) digestMemory(source string, data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new memory consumption metrics.")
-	s.digestData(data, memRequestQuantile, corev1.ResourceMemory, formatMemory())
+	s.digestData(source, data, memRequestQuantile, corev1.ResourceMemory, formatMemory())
+}
+
+const (
+	// ephemeralStorageRequestQuantile is the quantile of filesystem usage data to use as the ephemeral storage request
+	ephemeralStorageRequestQuantile = 0.8
+)
+
+func (s *resourceServer) digestEphemeralStorage(source string, data *podscaler.CachedQuery) {
+	s.logger.Debugf("Digesting new ephemeral storage consumption metrics.")
+	s.digestData(source, data, ephemeralStorageRequestQuantile, corev1.ResourceEphemeralStorage, formatMemory())
 }
 
 type toQuantity func(valueAtQuantile float64) (quantity *resource.Quantity)
 
-func (s *resourceServer) digestData(data *podscaler.CachedQuery, quantile float64, request corev1.ResourceName, quantity toQuantity) {
+func (s *resourceServer) digestData(source string, data *podscaler.CachedQuery, quantile float64, request corev1.ResourceName, quantity toQuantity) {
 	logger := s.logger.WithField("resource", request)
 	logger.Debugf("Digesting %d identifiers.", len(data.DataByMetaData))
 	for meta, fingerprintTimes := range data.DataByMetaData {
 		overall := circonusllhist.New()
 		metaLogger := logger.WithField("meta", meta)
 		metaLogger.Tracef("digesting %d fingerprints", len(fingerprintTimes))
+		from := recommendationSource{Reloader: source, Executions: len(fingerprintTimes)}
 		for _, fingerprintTime := range fingerprintTimes {
 			overall.Merge(data.Data[fingerprintTime.Fingerprint].Histogram())
+			if from.Start.IsZero() || fingerprintTime.Added.Before(from.Start) {
+				from.Start = fingerprintTime.Added
+			}
+			if fingerprintTime.Added.After(from.End) {
+				from.End = fingerprintTime.Added
+			}
 		}
 		metaLogger.Trace("merged all fingerprints")
 		valueAtQuantile := overall.ValueAtQuantile(quantile)
@@ -92,15 +133,179 @@ func (s *resourceServer) digestData(data *podscaler.CachedQuery, quantile float6
 		}
 		q := quantity(valueAtQuantile)
 		s.byMetaData[meta].Requests[request] = *q
+		if _, exists := s.sources[meta]; !exists {
+			s.sources[meta] = map[corev1.ResourceName]recommendationSource{}
+		}
+		s.sources[meta][request] = from
 		metaLogger.Trace("unlocking for meta")
 		s.lock.Unlock()
 	}
 	logger.Debug("Finished digesting new data.")
 }
 
+// recommendedRequestFor returns the recommended resources for a workload.
+// The result is a copy, as the digesters update the recommendations concurrently.
 func (s *resourceServer) recommendedRequestFor(meta podscaler.FullMetadata) (corev1.ResourceRequirements, bool) {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 	data, ok := s.byMetaData[meta]
-	return data, ok
+	if !ok {
+		return corev1.ResourceRequirements{}, false
+	}
+	return *data.DeepCopy(), true
+}
+
+// recommendationSourcesFor returns where the data behind the recommendations for a workload came from.
+// The result is a copy, as the digesters update the sources concurrently.
+func (s *resourceServer) recommendationSourcesFor(meta podscaler.FullMetadata) map[corev1.ResourceName]recommendationSource {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if len(s.sources[meta]) == 0 {
+		return nil
+	}
+	out := make(map[corev1.ResourceName]recommendationSource, len(s.sources[meta]))
+	for resource, from := range s.sources[meta] {
+		out[resource] = from
+	}
+	return out
+}
+
+func (s *resourceServer) digestOOMKilled(_ string, data *podscaler.CachedQuery) {
+	s.logger.Debugf("Digesting new OOMKilled termination metrics.")
+	s.digestAdjustments(data, corev1.ResourceMemory, oomKilledAdjustment)
+}
+
+func (s *resourceServer) digestCPUThrottling(_ string, data *podscaler.CachedQuery) {
+	s.logger.Debugf("Digesting new CPU throttling metrics.")
+	s.digestAdjustments(data, corev1.ResourceCPU, cpuThrottlingAdjustment)
+}
+
+func (s *resourceServer) digestAdjustments(data *podscaler.CachedQuery, request corev1.ResourceName, adjust adjuster) {
+	logger := s.logger.WithField("resource", request)
+	logger.Debugf("Digesting %d identifiers.", len(data.DataByMetaData))
+	adjustments := adjustmentsFor(data, time.Now(), adjust)
+	s.lock.Lock()
+	updateAdjustments(s.adjustments, data, request, adjustments)
+	s.lock.Unlock()
+	logger.Debugf("Finished digesting new data, %d identifiers adjusted.", len(adjustments))
+}
+
+// updateAdjustments records the adjustments of a resource for all workloads in the
+// data, removing those which are no longer needed
+func updateAdjustments(into map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment, data *podscaler.CachedQuery, request corev1.ResourceName, adjustments map[podscaler.FullMetadata]adjustment) {
+	for meta := range data.DataByMetaData {
+		a, adjusted := adjustments[meta]
+		if !adjusted {
+			delete(into[meta], request)
+			continue
+		}
+		if _, exists := into[meta]; !exists {
+			into[meta] = map[corev1.ResourceName]adjustment{}
+		}
+		into[meta][request] = a
+	}
+}
+
+// recommendedAdjustmentsFor returns the increases to make to the recommendations for a workload.
+// The result is a copy, as the digesters update the adjustments concurrently.
+func (s *resourceServer) recommendedAdjustmentsFor(meta podscaler.FullMetadata) map[corev1.ResourceName]adjustment {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return copyAdjustments(s.adjustments[meta])
+}
+
+func copyAdjustments(in map[corev1.ResourceName]adjustment) map[corev1.ResourceName]adjustment {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[corev1.ResourceName]adjustment, len(in))
+	for resource, a := range in {
+		out[resource] = a
+	}
+	return out
+}
+
+const (
+	// adjustmentLookback is how recent the signs of resource starvation need to be
+	// for us to increase the recommendation for a workload
+	adjustmentLookback = 7 * 24 * time.Hour
+
+	// oomKilledFactor is the factor by which we increase the memory recommendation
+	// for workloads that were recently OOMKilled
+	oomKilledFactor = 1.5
+
+	// cpuThrottlingQuantile is the quantile of the ratio of throttled CPU periods we
+	// consider when determining if a workload is throttled
+	cpuThrottlingQuantile = 0.8
+	// cpuThrottlingThreshold is the ratio of throttled CPU periods above which
+	// we consider a workload to be throttled
+	cpuThrottlingThreshold = 0.25
+	// cpuThrottlingFactor is the factor by which we increase the CPU recommendation
+	// for workloads that were recently throttled
+	cpuThrottlingFactor = 1.25
+)
+
+// adjustment is an increase to the recommendation for a resource
+type adjustment struct {
+	// Factor is the multiplier applied to the recommendation
+	Factor float64 `json:"factor"`
+	// Reason is a human-readable explanation of the increase
+	Reason string `json:"reason"`
+}
+
+// adjuster determines the adjustment to make given the recent data for a workload
+type adjuster func(recent *circonusllhist.Histogram) (adjustment, bool)
+
+// adjustmentsFor determines the adjustments for all workloads, using only data
+// sourced within the lookback period
+func adjustmentsFor(data *podscaler.CachedQuery, now time.Time, adjust adjuster) map[podscaler.FullMetadata]adjustment {
+	cutoff := now.Add(-adjustmentLookback)
+	adjustments := map[podscaler.FullMetadata]adjustment{}
+	for meta, fingerprintTimes := range data.DataByMetaData {
+		recent := circonusllhist.New()
+		var found bool
+		for _, fingerprintTime := range fingerprintTimes {
+			if fingerprintTime.Added.Before(cutoff) {
+				continue
+			}
+			histogram, exists := data.Data[fingerprintTime.Fingerprint]
+			if !exists {
+				continue
+			}
+			recent.Merge(histogram.Histogram())
+			found = true
+		}
+		if !found {
+			continue
+		}
+		if a, adjusted := adjust(recent); adjusted {
+			adjustments[meta] = a
+		}
+	}
+	return adjustments
+}
+
+// oomKilledAdjustment increases memory for workloads with any recent OOMKilled
+// terminations; the series only exists for containers last terminated that way
+func oomKilledAdjustment(recent *circonusllhist.Histogram) (adjustment, bool) {
+	if recent.Count() == 0 || recent.Max() <= 0 {
+		return adjustment{}, false
+	}
+	return adjustment{
+		Factor: oomKilledFactor,
+		Reason: fmt.Sprintf("OOMKilled in the last %d days", int(adjustmentLookback.Hours()/24)),
+	}, true
+}
+
+// cpuThrottlingAdjustment increases CPU for workloads that are often throttled
+func cpuThrottlingAdjustment(recent *circonusllhist.Histogram) (adjustment, bool) {
+	// histogram bins are approximate, so the value may be slightly larger than the largest possible ratio
+	throttled := math.Min(recent.ValueAtQuantile(cpuThrottlingQuantile), 1)
+	if throttled <= cpuThrottlingThreshold {
+		return adjustment{}, false
+	}
+	return adjustment{
+		Factor: cpuThrottlingFactor,
+		Reason: fmt.Sprintf("CPU throttled in %.0f%% of periods in the last %d days", throttled*100, int(adjustmentLookback.Hours()/24)),
+	}, true
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+)
+
+func TestAdjustmentsFor(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	histogramOf := func(values ...float64) *circonusllhist.HistogramWithoutLookups {
+		hist := circonusllhist.New(circonusllhist.NoLookup())
+		for _, value := range values {
+			if err := hist.RecordValue(value); err != nil {
+				t.Fatalf("failed to record value: %v", err)
+			}
+		}
+		return circonusllhist.NewHistogramWithoutLookups(hist)
+	}
+	metaFor := func(container string) podscaler.FullMetadata {
+		return podscaler.FullMetadata{Target: "target", Container: container}
+	}
+	data := &podscaler.CachedQuery{
+		Data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+			1: histogramOf(1),
+			2: histogramOf(1),
+			3: histogramOf(0.5, 0.6, 0.7),
+			4: histogramOf(0.01, 0.02, 0.01),
+		},
+		DataByMetaData: map[podscaler.FullMetadata][]podscaler.FingerprintTime{
+			metaFor("recent"):   {{Fingerprint: 1, Added: now.Add(-time.Hour)}},
+			metaFor("stale"):    {{Fingerprint: 2, Added: now.Add(-30 * 24 * time.Hour)}},
+			metaFor("high"):     {{Fingerprint: 3, Added: now.Add(-time.Hour)}},
+			metaFor("low"):      {{Fingerprint: 4, Added: now.Add(-time.Hour)}},
+			metaFor("no-data"):  {{Fingerprint: 5, Added: now.Add(-time.Hour)}},
+			metaFor("no-times"): {},
+		},
+	}
+
+	var testCases = []struct {
+		name     string
+		adjust   adjuster
+		expected map[podscaler.FullMetadata]adjustment
+	}{
+		{
+			name:   "OOMKilled",
+			adjust: oomKilledAdjustment,
+			expected: map[podscaler.FullMetadata]adjustment{
+				metaFor("recent"): {Factor: oomKilledFactor, Reason: "OOMKilled in the last 7 days"},
+				metaFor("high"):   {Factor: oomKilledFactor, Reason: "OOMKilled in the last 7 days"},
+				metaFor("low"):    {Factor: oomKilledFactor, Reason: "OOMKilled in the last 7 days"},
+			},
+		},
+		{
+			name:   "CPU throttling",
+			adjust: cpuThrottlingAdjustment,
+			expected: map[podscaler.FullMetadata]adjustment{
+				metaFor("recent"): {Factor: cpuThrottlingFactor, Reason: "CPU throttled in 100% of periods in the last 7 days"},
+				metaFor("high"):   {Factor: cpuThrottlingFactor, Reason: "CPU throttled in 70% of periods in the last 7 days"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, adjustmentsFor(data, now, testCase.adjust)); diff != "" {
+				t.Errorf("got incorrect adjustments: %v", diff)
+			}
+		})
+	}
+}
+
+func TestRecommendedRequestFor(t *testing.T) {
+	meta := podscaler.FullMetadata{Target: "target", Container: "container"}
+	server := &resourceServer{
+		byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{
+			meta: {Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}},
+		},
+	}
+	recommended, exists := server.recommendedRequestFor(meta)
+	if !exists {
+		t.Fatal("expected a recommendation for the workload")
+	}
+	recommended.Requests[corev1.ResourceMemory] = resource.MustParse("2Gi")
+	if memory := server.byMetaData[meta].Requests[corev1.ResourceMemory]; memory.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("mutating the returned recommendation changed the server's recommendation to %s", memory.String())
+	}
+	if _, exists := server.recommendedRequestFor(podscaler.FullMetadata{}); exists {
+		t.Error("expected no recommendation for unknown workload")
+	}
+}
+
+func TestRecommendedAdjustmentsFor(t *testing.T) {
+	meta := podscaler.FullMetadata{Target: "target", Container: "container"}
+	server := &resourceServer{
+		adjustments: map[podscaler.FullMetadata]map[corev1.ResourceName]adjustment{
+			meta: {corev1.ResourceMemory: {Factor: oomKilledFactor, Reason: "OOMKilled"}},
+		},
+	}
+	adjustments := server.recommendedAdjustmentsFor(meta)
+	delete(adjustments, corev1.ResourceMemory)
+	if _, adjusted := server.adjustments[meta][corev1.ResourceMemory]; !adjusted {
+		t.Error("mutating the returned adjustments changed the server's adjustments")
+	}
+	if adjustments := server.recommendedAdjustmentsFor(podscaler.FullMetadata{}); adjustments != nil {
+		t.Errorf("expected no adjustments for unknown workload, got %v", adjustments)
+	}
+}
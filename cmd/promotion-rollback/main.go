@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/prow/pkg/logrusutil"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/promotion/rollback"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+type options struct {
+	tag    string
+	to     string
+	reason string
+	clear  bool
+	dryRun bool
+
+	ref api.ImageStreamTagReference
+}
+
+func parseOptions() *options {
+	o := &options{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&o.tag, "tag", "", "The tag promoted to, in namespace/name:tag format (e.g. `ocp/4.17:cli`).")
+	fs.StringVar(&o.to, "to", "", "Roll the tag back to this digest, which must have been promoted to it before.")
+	fs.StringVar(&o.reason, "reason", "", "Why the tag is rolled back, recorded with the rollback.")
+	fs.BoolVar(&o.clear, "clear", false, "Clear the rollback of the tag, so it is promoted to again.")
+	fs.BoolVar(&o.dryRun, "dry-run", true, "Only print what would be done.")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		logrus.WithError(err).Fatal("could not parse args")
+	}
+	return o
+}
+
+func (o *options) validate() error {
+	if o.tag == "" {
+		return errors.New("--tag must be set")
+	}
+	namespace, name, found := strings.Cut(o.tag, "/")
+	if !found || namespace == "" {
+		return fmt.Errorf("--tag %s was not in namespace/name:tag format", o.tag)
+	}
+	ref, err := util.ParseImageStreamTagReference(name)
+	if err != nil {
+		return fmt.Errorf("invalid --tag: %w", err)
+	}
+	ref.Namespace = namespace
+	o.ref = ref
+	if o.to != "" && o.clear {
+		return errors.New("--to and --clear are mutually exclusive")
+	}
+	if o.to != "" && o.reason == "" {
+		return errors.New("--reason must be set when rolling back")
+	}
+	return nil
+}
+
+func printHistory(history []rollback.Entry, records map[string]rollback.Record, tag string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DIGEST\tCOMMIT\tPROMOTED\tNOTE")
+	for i, entry := range history {
+		var note string
+		if i == 0 {
+			note = "current"
+			if record, rolledBack := records[tag]; rolledBack {
+				note = fmt.Sprintf("current, rolled back from %s at %s: %s", record.From, record.Time.Format(time.RFC3339), record.Reason)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Digest, entry.Commit, entry.Created.Format(time.RFC3339), note)
+	}
+	return w.Flush()
+}
+
+func main() {
+	logrusutil.ComponentInit()
+	o := parseOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		logrus.WithError(err).Fatal("Failed to add imagev1 to scheme")
+	}
+	clusterConfig, err := util.LoadClusterConfig()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load cluster config")
+	}
+	client, err := ctrlruntimeclient.New(clusterConfig, ctrlruntimeclient.Options{})
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create client")
+	}
+	if o.dryRun {
+		client = ctrlruntimeclient.NewDryRunClient(client)
+	}
+	ctx := context.Background()
+	logger := logrus.WithField("tag", o.ref.ISTagName())
+
+	switch {
+	case o.to != "":
+		record, err := rollback.Apply(ctx, client, o.ref, o.to, o.reason, time.Now())
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to roll back the tag")
+		}
+		logger.WithField("dry-run", o.dryRun).Infof("Rolled the tag back from %s to %s, it will not be promoted to until the rollback is cleared", record.From, record.Digest)
+	case o.clear:
+		if err := rollback.Clear(ctx, client, o.ref); err != nil {
+			logger.WithError(err).Fatal("Failed to clear the rollback of the tag")
+		}
+		logger.WithField("dry-run", o.dryRun).Info("Cleared the rollback of the tag, it will be promoted to again")
+	default:
+		history, err := rollback.History(ctx, client, o.ref)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to get the history of the tag")
+		}
+		stream := &imagev1.ImageStream{}
+		if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: o.ref.Namespace, Name: o.ref.Name}, stream); err != nil {
+			logger.WithError(err).Fatal("Failed to get the image stream")
+		}
+		records, err := rollback.Records(stream)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to get the rollbacks of the image stream")
+		}
+		if err := printHistory(history, records, o.ref.Tag); err != nil {
+			logger.WithError(err).Fatal("Failed to print the history of the tag")
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+
+	"github.com/openshift/ci-tools/pkg/kubernetes/pkg/credentialprovider"
+	"github.com/openshift/ci-tools/pkg/provenance"
+)
+
+type options struct {
+	image                 string
+	publicKey             string
+	insecureSkipSignature bool
+	registryConfig        string
+	timeout               time.Duration
+}
+
+func (o options) validate() error {
+	if o.image == "" {
+		return errors.New("--image is not specified")
+	}
+	if image, err := reference.Parse(o.image); err == nil && image.Registry == "" {
+		return fmt.Errorf("--image %s does not specify a registry, use the full pull spec like docker.io/%s", o.image, image.Exact())
+	}
+	if o.publicKey == "" && !o.insecureSkipSignature {
+		return errors.New("--public-key is not specified, set --insecure-skip-signature to not verify the signature")
+	}
+	if o.publicKey != "" && o.insecureSkipSignature {
+		return errors.New("--public-key and --insecure-skip-signature are mutually exclusive")
+	}
+	return nil
+}
+
+func parseOptions() options {
+	var o options
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&o.image, "image", "", "Pull spec of the promoted image, by tag or by digest.")
+	fs.StringVar(&o.publicKey, "public-key", "", "Path to the PEM-encoded public key the provenance must be signed with.")
+	fs.BoolVar(&o.insecureSkipSignature, "insecure-skip-signature", false, "Do not verify the signature of the provenance, only that it describes the image.")
+	fs.StringVar(&o.registryConfig, "registry-config", "", "Path to a dockercfg file with credentials to pull from the registry.")
+	fs.DurationVar(&o.timeout, "timeout", 2*time.Minute, "Timeout for the requests to the registry.")
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		logrus.WithError(err).Fatalf("cannot parse args: '%s'", os.Args[1:])
+	}
+	return o
+}
+
+func loadRegistryConfig(path string) (credentialprovider.DockerConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry config: %w", err)
+	}
+	var config credentialprovider.DockerConfigJSON
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse registry config: %w", err)
+	}
+	return config.Auths, nil
+}
+
+func main() {
+	o := parseOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("couldn't validate options")
+	}
+	image, err := reference.Parse(o.image)
+	if err != nil {
+		logrus.WithError(err).Fatalf("couldn't parse image %s", o.image)
+	}
+	if image.Tag == "" && image.ID == "" {
+		image.Tag = "latest"
+	}
+	var key crypto.PublicKey
+	if o.insecureSkipSignature {
+		logrus.Warn("The signature of the provenance is not verified.")
+	} else if key, err = provenance.LoadPublicKey(o.publicKey); err != nil {
+		logrus.WithError(err).Fatal("couldn't load public key")
+	}
+	auths, err := loadRegistryConfig(o.registryConfig)
+	if err != nil {
+		logrus.WithError(err).Fatal("couldn't load registry config")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+	client := provenance.NewRegistryClient(&http.Client{}, auths)
+	digest, envelopes, err := client.Provenance(ctx, image)
+	if err != nil {
+		logrus.WithError(err).Fatal("couldn't get the provenance of the image")
+	}
+	if len(envelopes) == 0 {
+		logrus.Fatalf("No provenance is attached to %s@%s.", image.AsRepository().Exact(), digest)
+	}
+	var errs []error
+	for _, envelope := range envelopes {
+		statement, err := provenance.Verify(envelope, key, digest)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		raw, err := json.MarshalIndent(statement, "", "  ")
+		if err != nil {
+			logrus.WithError(err).Fatal("couldn't serialize the statement")
+		}
+		logrus.Infof("Verified the provenance of %s@%s.", image.AsRepository().Exact(), digest)
+		fmt.Println(string(raw))
+		return
+	}
+	logrus.WithError(errors.Join(errs...)).Fatalf("None of the %d statements attached to %s@%s could be verified.", len(envelopes), image.AsRepository().Exact(), digest)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io/fs"
@@ -54,6 +55,7 @@ type options struct {
 	jobsStoragePath   string
 
 	prometheusDaysBefore int
+	weightBy             string
 
 	createPR    bool
 	githubLogin string
@@ -70,6 +72,13 @@ type options struct {
 
 	slackTokenPath string
 	opsChannelId   string
+
+	kubernetesOptions   flagutil.KubernetesOptions
+	healthProbeInterval time.Duration
+	healthProbeTimeout  time.Duration
+	healthThreshold     int
+	maxPendingPods      int
+	pendingPodThreshold time.Duration
 }
 
 type slackClient interface {
@@ -77,7 +86,7 @@ type slackClient interface {
 }
 
 func gatherOptions() options {
-	o := options{}
+	o := options{kubernetesOptions: flagutil.KubernetesOptions{NOInClusterConfigDefault: true}}
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 
 	fs.StringVar(&o.prowJobConfigDir, "prow-jobs-dir", "", "Path to a root of directory structure with Prow job config files (ci-operator/jobs in openshift/release)")
@@ -85,6 +94,7 @@ func gatherOptions() options {
 	fs.StringVar(&o.clusterConfigPath, "cluster-config-path", "core-services/sanitize-prow-jobs/_clusters.yaml", "Path to the config file (core-services/sanitize-prow-jobs/_clusters.yaml in openshift/release)")
 	fs.StringVar(&o.jobsStoragePath, "jobs-storage-path", "", "Path to the file holding only job assignments in Gob format")
 	fs.IntVar(&o.prometheusDaysBefore, "prometheus-days-before", 1, "Number [1,15] of days before. Time 00-00-00 of that day will be used as time to query Prometheus. E.g., 1 means 00-00-00 of yesterday.")
+	fs.StringVar(&o.weightBy, "weight-by", weightByJobCount, fmt.Sprintf("How to weight jobs when balancing clusters: %q by the number of runs or %q by the CPU consumed by their Pods.", weightByJobCount, weightByCPUHours))
 
 	fs.BoolVar(&o.createPR, "create-pr", false, "Create a pull request to the change made with this tool.")
 	fs.StringVar(&o.githubLogin, "github-login", githubLogin, "The GitHub username to use.")
@@ -97,6 +107,13 @@ func gatherOptions() options {
 	fs.StringVar(&o.slackTokenPath, "slack-token-path", "", "Path to the file containing the Slack token to use.")
 	fs.StringVar(&o.opsChannelId, "ops-channel-id", "CHY2E1BL4", "Channel ID for #ops-testplatform")
 
+	fs.DurationVar(&o.healthProbeInterval, "health-probe-interval", 0, "How often to probe the health of build clusters, redirecting jobs which may be relocated away from degraded ones. Probing is disabled when zero.")
+	fs.DurationVar(&o.healthProbeTimeout, "health-probe-timeout", 30*time.Second, "How long probing the health of a build cluster may take before the cluster is considered degraded.")
+	fs.IntVar(&o.healthThreshold, "health-threshold", 3, "How many consecutive probes of a build cluster need to fail for it to be degraded, or succeed for it to recover.")
+	fs.IntVar(&o.maxPendingPods, "max-pending-pods", 200, "The number of pods pending for longer than --pending-pod-threshold above which a cluster is considered degraded.")
+	fs.DurationVar(&o.pendingPodThreshold, "pending-pod-threshold", 10*time.Minute, "How long a pod needs to be pending to count towards the backlog of a cluster.")
+
+	o.kubernetesOptions.AddFlags(fs)
 	o.GitAuthorOptions.AddFlags(fs)
 	o.PrometheusOptions.AddFlags(fs)
 	o.PRCreationOptions.AddFlags(fs)
@@ -120,6 +137,10 @@ func (o *options) validate() error {
 		return fmt.Errorf("--prometheus-days-before must be between 1 and 15")
 	}
 
+	if o.weightBy != weightByJobCount && o.weightBy != weightByCPUHours {
+		return fmt.Errorf("--weight-by must be either %q or %q", weightByJobCount, weightByCPUHours)
+	}
+
 	if o.clusterConfigPath == "" {
 		logrus.Fatal("mandatory argument --cluster-config-path wasn't set")
 	}
@@ -142,6 +163,21 @@ func (o *options) validate() error {
 		return fmt.Errorf("--default-cluster value cannot be also be in --disable-cluster")
 	}
 
+	if o.healthProbeInterval < 0 {
+		return fmt.Errorf("--health-probe-interval cannot be negative")
+	}
+	if o.healthProbeInterval > 0 && o.healthProbeTimeout <= 0 {
+		return fmt.Errorf("--health-probe-timeout must be positive")
+	}
+	if o.healthProbeInterval > 0 && o.healthThreshold < 1 {
+		return fmt.Errorf("--health-threshold must be at least 1")
+	}
+	if o.healthProbeInterval > 0 || o.weightBy == weightByCPUHours {
+		if err := o.kubernetesOptions.Validate(false); err != nil {
+			return err
+		}
+	}
+
 	if o.createPR {
 		if o.githubLogin == "" {
 			return fmt.Errorf("--github-login cannot be empty string")
@@ -283,7 +319,7 @@ func findClusterAssigmentsForJobs(jc *prowconfig.JobConfig, path string, config
 		}
 
 		c := dispatcher.DetermineTargetCluster(cluster, string(determinedCluster), string(config.Default), canBeRelocated, blocked)
-		pjs[jobBase.Name] = dispatcher.ProwJobData{Cluster: c, Capabilities: extractCapabilities(jobBase.Labels)}
+		pjs[jobBase.Name] = dispatcher.ProwJobData{Cluster: c, Capabilities: extractCapabilities(jobBase.Labels), MayBeRelocated: canBeRelocated}
 		logrus.WithField("job", jobBase.Name).WithField("cluster", c).Info("found cluster for job")
 		return nil
 	}
@@ -327,7 +363,7 @@ func (cv *clusterVolume) addToVolume(cluster string, jobBase prowconfig.JobBase,
 	}
 
 	c := dispatcher.DetermineTargetCluster(cluster, string(determinedCluster), string(config.Default), canBeRelocated, cv.blocked)
-	cv.pjs[jobBase.Name] = dispatcher.ProwJobData{Cluster: c, Capabilities: extractCapabilities(jobBase.Labels)}
+	cv.pjs[jobBase.Name] = dispatcher.ProwJobData{Cluster: c, Capabilities: extractCapabilities(jobBase.Labels), MayBeRelocated: canBeRelocated}
 	if determinedCloudProvider := config.IsInBuildFarm(api.Cluster(c)); determinedCloudProvider != "" {
 		cv.clusterVolumeMap[string(determinedCloudProvider)][c] = cv.clusterVolumeMap[string(determinedCloudProvider)][c] + jobVolumes[jobBase.Name]
 		return nil
@@ -484,6 +520,31 @@ func dispatchEveryFile(fileList []fileSizeInfo, dispatch func(jobConfig *prowcon
 	return utilerrors.NewAggregate(errs)
 }
 
+// jobNames returns the names of all the jobs in the Prow job config directory
+func jobNames(prowJobConfigDir string) (sets.Set[string], error) {
+	fileList, err := composeFileInfoList(prowJobConfigDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Prow job config files: %w", err)
+	}
+	names := sets.New[string]()
+	err = dispatchEveryFile(fileList, func(jobConfig *prowconfig.JobConfig, _ string, _ fs.DirEntry) {
+		for _, jobs := range jobConfig.PresubmitsStatic {
+			for _, job := range jobs {
+				names.Insert(job.Name)
+			}
+		}
+		for _, jobs := range jobConfig.PostsubmitsStatic {
+			for _, job := range jobs {
+				names.Insert(job.Name)
+			}
+		}
+		for _, job := range jobConfig.Periodics {
+			names.Insert(job.Name)
+		}
+	})
+	return names, err
+}
+
 func composeFileInfoList(prowJobConfigDir string) ([]fileSizeInfo, error) {
 	fileList := make([]fileSizeInfo, 0)
 	var errs []error
@@ -603,7 +664,7 @@ func cleanup(directory string) {
 
 // createPR creates PR with config changes and sanitizer changes, it causes app to exit in
 // case of failure to trigger re-run of logic
-func createPR(o options, config *dispatcher.Config, pjs map[string]dispatcher.ProwJobData, cm dispatcher.ClusterMap) {
+func createPR(o options, config *dispatcher.Config, pjs map[string]dispatcher.ProwJobData, cm dispatcher.ClusterMap, body string) {
 	targetDirWithRelease := filepath.Join(o.targetDir, "/release")
 	cleanup(targetDirWithRelease)
 	defer cleanup(targetDirWithRelease)
@@ -626,7 +687,7 @@ func createPR(o options, config *dispatcher.Config, pjs map[string]dispatcher.Pr
 	}
 
 	title := fmt.Sprintf("%s at %s", matchTitle, time.Now().Format(time.RFC1123))
-	if err := o.PRCreationOptions.UpsertPR(targetDirWithRelease, githubOrg, githubRepo, upstreamBranch, title, prcreation.PrAssignee(o.assign), prcreation.MatchTitle(matchTitle), prcreation.AdditionalLabels([]string{rehearse.RehearsalsAckLabel})); err != nil {
+	if err := o.PRCreationOptions.UpsertPR(targetDirWithRelease, githubOrg, githubRepo, upstreamBranch, title, prcreation.PrAssignee(o.assign), prcreation.PrBody(body), prcreation.GitCommitMessage(matchTitle), prcreation.MatchTitle(matchTitle), prcreation.AdditionalLabels([]string{rehearse.RehearsalsAckLabel})); err != nil {
 		logrus.WithError(err).Fatal("failed to upsert PR")
 	}
 }
@@ -676,7 +737,19 @@ func main() {
 		}
 	}
 
-	promVolumes, err := newPrometheusVolumes(o.PrometheusOptions, o.prometheusDaysBefore)
+	var buildFarmAPIs map[string]dispatcher.PrometheusAPI
+	if o.weightBy == weightByCPUHours {
+		kubeconfigs, err := o.kubernetesOptions.LoadClusterConfigs()
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to load kubeconfigs")
+		}
+		if buildFarmAPIs, err = newBuildFarmPrometheusAPIs(context.Background(), kubeconfigs); err != nil {
+			logrus.WithError(err).Fatal("failed to create build farm Prometheus clients")
+		}
+	}
+	promVolumes, err := newPrometheusVolumes(o.PrometheusOptions, o.prometheusDaysBefore, o.weightBy, buildFarmAPIs, func() (sets.Set[string], error) {
+		return jobNames(o.prowJobConfigDir)
+	})
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to create prometheus volumes")
 	}
@@ -773,12 +846,17 @@ func main() {
 			}
 			prowjobs.Regenerate(pjs)
 
+			utilisation := projectUtilisation(pjs, jobVolumes, configClusterMap)
+			for _, u := range utilisation {
+				logrus.WithFields(logrus.Fields{"cluster": u.cluster, "load": u.load, "share": u.share, "capacityShare": u.capacityShare}).Info("projected utilisation of the cluster")
+			}
+
 			if err := dispatcher.WriteGob(o.jobsStoragePath, pjs); err != nil {
 				logrus.WithError(err).Errorf("continuing on cache memory, error writing Gob file")
 			}
 
 			if o.createPR {
-				createPR(o, config, pjs, configClusterMap)
+				createPR(o, config, pjs, configClusterMap, formatUtilisation(utilisation, o.weightBy))
 				if err := sendSlackMessage(slackClient, o.opsChannelId); err != nil {
 					logrus.WithError(err).Error("Failed to post message in ops channel")
 				}
@@ -838,9 +916,29 @@ func main() {
 		}
 	}(o.clusterConfigPath)
 
-	server := dispatcher.NewServer(prowjobs, dispatchWrapper)
+	var health *dispatcher.HealthMonitor
+	if o.healthProbeInterval > 0 {
+		clients, err := o.kubernetesOptions.BuildClusterCoreV1Clients(false)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to create build cluster clients")
+		}
+		health = dispatcher.NewHealthMonitor(
+			dispatcher.NewKubernetesProbe(clients, o.maxPendingPods, o.pendingPodThreshold),
+			func() (dispatcher.ClusterMap, error) {
+				cm, _, err := dispatcher.LoadClusterConfig(o.clusterConfigPath)
+				return cm, err
+			},
+			o.disableClusters.StringSet(),
+			o.healthProbeTimeout,
+			o.healthThreshold,
+		)
+		go health.Run(context.Background(), o.healthProbeInterval)
+	}
+
+	server := dispatcher.NewServer(prowjobs, dispatchWrapper, health)
 	http.HandleFunc("/", server.RequestHandler)
 	http.HandleFunc("/event", server.EventHandler)
+	http.HandleFunc("/status", server.StatusHandler)
 	logrus.Fatal(http.ListenAndServe(":8080", nil))
 
 }
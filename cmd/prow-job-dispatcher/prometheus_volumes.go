@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -9,20 +10,39 @@ import (
 	prometheusapi "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/sirupsen/logrus"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
 	"sigs.k8s.io/prow/pkg/config/secret"
 
+	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+
 	"github.com/openshift/ci-tools/pkg/dispatcher"
 )
 
+const (
+	// weightByJobCount weights jobs by how many times they ran
+	weightByJobCount = "job-count"
+	// weightByCPUHours weights jobs by how much CPU their Pods consumed
+	weightByCPUHours = "cpu-hours"
+)
+
 type prometheusVolumes struct {
 	jobVolumes           map[string]float64
+	weightBy             string
 	timestamp            time.Time
 	promClient           promapi.Client
 	prometheusDaysBefore int
 	m                    sync.Mutex
+
+	// buildFarmAPIs are the Prometheus servers of the build farm clusters, by cluster, queried when weighting by CPU-hours
+	buildFarmAPIs map[string]dispatcher.PrometheusAPI
+	// jobNames returns the names of the jobs which CPU-hours are attributed to
+	jobNames func() (sets.Set[string], error)
 }
 
-func newPrometheusVolumes(promOptions dispatcher.PrometheusOptions, prometheusDaysBefore int) (prometheusVolumes, error) {
+func newPrometheusVolumes(promOptions dispatcher.PrometheusOptions, prometheusDaysBefore int, weightBy string, buildFarmAPIs map[string]dispatcher.PrometheusAPI, jobNames func() (sets.Set[string], error)) (prometheusVolumes, error) {
 	promClient, err := promOptions.NewPrometheusClient(secret.GetSecret)
 	if err != nil {
 		return prometheusVolumes{}, err
@@ -30,11 +50,42 @@ func newPrometheusVolumes(promOptions dispatcher.PrometheusOptions, prometheusDa
 	return prometheusVolumes{
 		promClient:           promClient,
 		jobVolumes:           map[string]float64{},
+		weightBy:             weightBy,
 		prometheusDaysBefore: prometheusDaysBefore,
 		m:                    sync.Mutex{},
+		buildFarmAPIs:        buildFarmAPIs,
+		jobNames:             jobNames,
 	}, nil
 }
 
+// newBuildFarmPrometheusAPIs returns clients for the Prometheus servers of the build farm clusters, by cluster
+func newBuildFarmPrometheusAPIs(ctx context.Context, kubeconfigs map[string]rest.Config) (map[string]dispatcher.PrometheusAPI, error) {
+	apis := map[string]dispatcher.PrometheusAPI{}
+	for cluster, config := range kubeconfigs {
+		client, err := routeclientset.NewForConfig(&config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct route client for cluster %s: %w", cluster, err)
+		}
+		route, err := client.Routes("openshift-monitoring").Get(ctx, "prometheus-k8s", metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Prometheus route on cluster %s: %w", cluster, err)
+		}
+		addr := "http://" + route.Spec.Host
+		if route.Spec.TLS != nil {
+			addr = "https://" + route.Spec.Host
+		}
+		promClient, err := promapi.NewClient(promapi.Config{
+			Address:      addr,
+			RoundTripper: transport.NewBearerAuthRoundTripper(config.BearerToken, promapi.DefaultRoundTripper),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Prometheus client for cluster %s: %w", cluster, err)
+		}
+		apis[cluster] = prometheusapi.NewAPI(promClient)
+	}
+	return apis, nil
+}
+
 func (pv *prometheusVolumes) GetJobVolumes() (map[string]float64, error) {
 	pv.m.Lock()
 	defer pv.m.Unlock()
@@ -47,13 +98,23 @@ func (pv *prometheusVolumes) GetJobVolumes() (map[string]float64, error) {
 	defer cancel()
 	y, m, d := time.Now().Add(-time.Duration(24*pv.prometheusDaysBefore) * time.Hour).Date()
 	ts := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
-	jv, err := dispatcher.GetJobVolumesFromPrometheus(ctx, v1api, ts)
+	var jv map[string]float64
+	var err error
+	if pv.weightBy == weightByCPUHours {
+		var jobNames sets.Set[string]
+		if jobNames, err = pv.jobNames(); err != nil {
+			return nil, fmt.Errorf("failed to determine job names: %w", err)
+		}
+		jv, err = dispatcher.GetJobCPUHoursFromPrometheus(ctx, pv.buildFarmAPIs, ts, jobNames)
+	} else {
+		jv, err = dispatcher.GetJobVolumesFromPrometheus(ctx, v1api, ts)
+	}
 	if err != nil {
 		return nil, err
 	}
 	pv.jobVolumes = jv
 	pv.timestamp = time.Now()
-	logrus.Info("Fetched new job volumes")
+	logrus.WithField("weightBy", pv.weightBy).Info("Fetched new job volumes")
 	return pv.jobVolumes, nil
 }
 
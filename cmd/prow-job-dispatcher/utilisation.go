@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift/ci-tools/pkg/dispatcher"
+)
+
+// clusterUtilisation is the projected load on a cluster after dispatching
+type clusterUtilisation struct {
+	cluster string
+	// load is the sum of the weights of all jobs dispatched to the cluster
+	load float64
+	// share is the fraction of the total load dispatched to the cluster
+	share float64
+	// capacityShare is the fraction of the total load the cluster should take given its capacity
+	capacityShare float64
+}
+
+// projectUtilisation determines the load each cluster would receive with the given assignments,
+// weighting each job by its volume. Clusters are sorted by name.
+func projectUtilisation(pjs map[string]dispatcher.ProwJobData, jobVolumes map[string]float64, cm dispatcher.ClusterMap) []clusterUtilisation {
+	loads := map[string]float64{}
+	for cluster := range cm {
+		loads[cluster] = 0
+	}
+	var totalLoad float64
+	for job, data := range pjs {
+		loads[data.Cluster] += jobVolumes[job]
+		totalLoad += jobVolumes[job]
+	}
+	var totalCapacity int
+	for _, info := range cm {
+		totalCapacity += info.Capacity
+	}
+
+	var utilisation []clusterUtilisation
+	for cluster, load := range loads {
+		u := clusterUtilisation{cluster: cluster, load: load}
+		if totalLoad > 0 {
+			u.share = load / totalLoad
+		}
+		if totalCapacity > 0 {
+			u.capacityShare = float64(cm[cluster].Capacity) / float64(totalCapacity)
+		}
+		utilisation = append(utilisation, u)
+	}
+	sort.Slice(utilisation, func(i, j int) bool { return utilisation[i].cluster < utilisation[j].cluster })
+	return utilisation
+}
+
+// formatUtilisation renders the projected utilisation as a Markdown table, where the utilisation
+// of a cluster is its share of the load relative to the share its capacity entitles it to
+func formatUtilisation(utilisation []clusterUtilisation, weightBy string) string {
+	unit := "runs"
+	if weightBy == weightByCPUHours {
+		unit = "CPU-hours"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Projected weekly load per cluster, weighted by %s:\n\n", weightBy)
+	fmt.Fprintf(&b, "| Cluster | Load (%s) | Share | Capacity Share | Utilisation |\n", unit)
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, u := range utilisation {
+		relative := "n/a"
+		if u.capacityShare > 0 {
+			relative = fmt.Sprintf("%.0f%%", 100*u.share/u.capacityShare)
+		}
+		fmt.Fprintf(&b, "| %s | %.1f | %.1f%% | %.1f%% | %s |\n", u.cluster, u.load, 100*u.share, 100*u.capacityShare, relative)
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/dispatcher"
+)
+
+func TestProjectUtilisation(t *testing.T) {
+	pjs := map[string]dispatcher.ProwJobData{
+		"e2e-a":  {Cluster: "build01"},
+		"e2e-b":  {Cluster: "build02"},
+		"unit-a": {Cluster: "build02"},
+		"other":  {Cluster: "app.ci"},
+	}
+	jobVolumes := map[string]float64{"e2e-a": 60, "e2e-b": 30, "unit-a": 10}
+	cm := dispatcher.ClusterMap{
+		"build01": {Capacity: 100},
+		"build02": {Capacity: 50},
+		"build03": {Capacity: 50},
+	}
+	expected := []clusterUtilisation{
+		{cluster: "app.ci"},
+		{cluster: "build01", load: 60, share: 0.6, capacityShare: 0.5},
+		{cluster: "build02", load: 40, share: 0.4, capacityShare: 0.25},
+		{cluster: "build03", capacityShare: 0.25},
+	}
+	actual := projectUtilisation(pjs, jobVolumes, cm)
+	if diff := cmp.Diff(expected, actual, cmp.AllowUnexported(clusterUtilisation{})); diff != "" {
+		t.Errorf("unexpected utilisation: %s", diff)
+	}
+
+	expectedTable := `Projected weekly load per cluster, weighted by cpu-hours:
+
+| Cluster | Load (CPU-hours) | Share | Capacity Share | Utilisation |
+|---|---|---|---|---|
+| app.ci | 0.0 | 0.0% | 0.0% | n/a |
+| build01 | 60.0 | 60.0% | 50.0% | 120% |
+| build02 | 40.0 | 40.0% | 25.0% | 160% |
+| build03 | 0.0 | 0.0% | 25.0% | 0% |
+`
+	if diff := cmp.Diff(expectedTable, formatUtilisation(actual, weightByCPUHours)); diff != "" {
+		t.Errorf("unexpected table: %s", diff)
+	}
+}
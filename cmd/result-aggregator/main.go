@@ -40,10 +40,17 @@ var (
 		},
 		[]string{"workload_name", "workload_type", "configured_amount", "determined_amount", "resource_type"},
 	)
+	leaseAcquisitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ci_operator_lease_acquisitions",
+			Help: "number of leases with fallback resource types acquired, sorted by preferred and leased resource type",
+		},
+		[]string{"type", "cluster", "resource_type", "leased_type"},
+	)
 )
 
 func init() {
-	prometheus.MustRegister(errorRate, podScalerHighResourceCounter)
+	prometheus.MustRegister(errorRate, podScalerHighResourceCounter, leaseAcquisitions)
 }
 
 type options struct {
@@ -115,6 +122,16 @@ func validatePodScalerRequest(request *results.PodScalerRequest) error {
 	return nil
 }
 
+func validateLeaseRequest(request *results.LeaseRequest) error {
+	if request.ResourceType == "" {
+		return fmt.Errorf("resource_type field in request is empty")
+	}
+	if request.LeasedType == "" {
+		return fmt.Errorf("leased_type field in request is empty")
+	}
+	return nil
+}
+
 func handleError(w http.ResponseWriter, err error) {
 	w.WriteHeader(http.StatusBadRequest)
 	fmt.Fprint(w, html.EscapeString(err.Error()))
@@ -142,6 +159,16 @@ func recordHighResource(request *results.PodScalerRequest) {
 	podScalerHighResourceCounter.With(labels).Inc()
 }
 
+func recordLeaseAcquisition(request *results.LeaseRequest) {
+	labels := prometheus.Labels{
+		"type":          request.Type,
+		"cluster":       request.Cluster,
+		"resource_type": request.ResourceType,
+		"leased_type":   request.LeasedType,
+	}
+	leaseAcquisitions.With(labels).Inc()
+}
+
 type validator interface {
 	Validate(username, password string) bool
 }
@@ -212,6 +239,32 @@ func handlePodScalerResult() http.HandlerFunc {
 	}
 }
 
+func handleLeaseResult() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		bytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, fmt.Errorf("unable to read lease request body: %w", err))
+			return
+		}
+
+		request := &results.LeaseRequest{}
+		if err = json.Unmarshal(bytes, request); err != nil {
+			handleError(w, fmt.Errorf("unable to decode lease request body: %w", err))
+			return
+		}
+
+		if err := validateLeaseRequest(request); err != nil {
+			handleError(w, err)
+			return
+		}
+
+		recordLeaseAcquisition(request)
+		w.WriteHeader(http.StatusOK)
+		log.WithFields(log.Fields{"request": request, "duration": time.Since(start).String()}).Info("Lease request processed")
+	}
+}
+
 func main() {
 	o, err := gatherOptions()
 	if err != nil {
@@ -232,6 +285,7 @@ func main() {
 
 	http.Handle("/result", loginHandler(validator, handleCIOperatorResult()))
 	http.Handle("/pod-scaler", loginHandler(validator, handlePodScalerResult()))
+	http.Handle("/lease", loginHandler(validator, handleLeaseResult()))
 
 	metrics.ExposeMetrics("result-aggregator", prowConfig.PushGateway{}, flagutil.DefaultMetricsPort)
 
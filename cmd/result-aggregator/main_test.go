@@ -234,3 +234,35 @@ func TestValidatePodScalerRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateLeaseRequest(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		request  *results.LeaseRequest
+		expected error
+	}{
+		{
+			name:    "everything ok",
+			request: &results.LeaseRequest{JobName: "job", Type: "presubmit", Cluster: "build01", ResourceType: "aws-quota-slice", LeasedType: "aws-2-quota-slice"},
+		},
+		{
+			name:     "empty resource type",
+			request:  &results.LeaseRequest{JobName: "job", Type: "presubmit", Cluster: "build01", LeasedType: "aws-2-quota-slice"},
+			expected: fmt.Errorf("resource_type field in request is empty"),
+		},
+		{
+			name:     "empty leased type",
+			request:  &results.LeaseRequest{JobName: "job", Type: "presubmit", Cluster: "build01", ResourceType: "aws-quota-slice"},
+			expected: fmt.Errorf("leased_type field in request is empty"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := validateLeaseRequest(testCase.request)
+			if diff := cmp.Diff(testCase.expected, actual, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("actual error doesn't match expected error, diff: %v", diff)
+			}
+		})
+	}
+}
@@ -16,14 +16,16 @@ import (
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/interrupts"
+	"sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/metrics"
 
 	"github.com/openshift/ci-tools/pkg/retester"
 )
 
 type options struct {
-	config configflagutil.ConfigOptions
-	github prowflagutil.GitHubOptions
+	config  configflagutil.ConfigOptions
+	github  prowflagutil.GitHubOptions
+	storage prowflagutil.StorageClientOptions
 
 	runOnce bool
 	dryRun  bool
@@ -41,7 +43,7 @@ type options struct {
 }
 
 func (o *options) Validate() error {
-	for _, group := range []flagutil.OptionGroup{&o.github, &o.config} {
+	for _, group := range []flagutil.OptionGroup{&o.github, &o.config, &o.storage} {
 		if err := group.Validate(o.dryRun); err != nil {
 			return err
 		}
@@ -80,7 +82,7 @@ func gatherOptions() options {
 	fs.StringVar(&o.cacheRecordAgeRaw, "cache-record-age", "168h", "Parseable duration string that specifies how long a cache record lives in cache after the last time it was considered")
 	fs.StringVar(&o.configFile, "config-file", "", "Path to the configure file of the retest.")
 
-	for _, group := range []flagutil.OptionGroup{&o.github, &o.config} {
+	for _, group := range []flagutil.OptionGroup{&o.github, &o.config, &o.storage} {
 		group.AddFlags(fs)
 	}
 
@@ -134,7 +136,14 @@ func main() {
 		}
 	}
 
-	c := retester.NewController(ctx, gc, configAgent.Config, gitClient, o.github.AppPrivateKeyPath != "", o.cacheFile, o.cacheRecordAge, config, &awsConfig)
+	var opener io.Opener
+	if o.storage.HasGCSCredentials() || o.storage.HasS3Credentials() {
+		if opener, err = o.storage.StorageClient(ctx); err != nil {
+			logrus.WithError(err).Fatal("Failed to create the storage client.")
+		}
+	}
+
+	c := retester.NewController(ctx, gc, configAgent.Config, gitClient, o.github.AppPrivateKeyPath != "", o.cacheFile, o.cacheRecordAge, config, &awsConfig, opener)
 
 	metrics.ExposeMetrics("retester", prowConfig.PushGateway{}, prowflagutil.DefaultMetricsPort)
 
@@ -53,6 +53,8 @@ const (
 	NamespaceDir = "build-resources"
 
 	APPCIKubeAPIURL = "https://api.ci.l2s4.p1.openshiftapps.com:6443"
+	// ProwJobNamespace is the namespace of app.ci Prow creates ProwJobs in
+	ProwJobNamespace = "ci"
 
 	// ReasonPending is the error reason for pods not scheduled in time.
 	// It is generated when pods are for whatever reason not scheduled before
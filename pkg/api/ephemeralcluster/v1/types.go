@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"fmt"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -12,6 +15,9 @@ const (
 	ClusterProvisioning EphemeralClusterConditionType = "ClusterProvisioning"
 	// ContainersReady indicates whether the cluster is up and running.
 	ClusterReady EphemeralClusterConditionType = "ClusterReady"
+	// ClusterTearingDown indicates whether the cluster is being torn down, either
+	// because its lifetime expired or because a tear down has been requested.
+	ClusterTearingDown EphemeralClusterConditionType = "ClusterTearingDown"
 )
 
 type ConditionStatus string
@@ -25,6 +31,24 @@ const (
 
 const (
 	CIOperatorJobsGenerateFailure = "CIOperatorJobsGenerateFailure"
+	// Expired is the reason a cluster is torn down once its lifetime is over.
+	Expired = "Expired"
+	// TearDownRequested is the reason a cluster is torn down on user request.
+	TearDownRequested = "TearDownRequested"
+	// CIOperatorNamespaceNotFound means the cluster cannot be torn down yet because
+	// the namespace ci-operator runs the workflow in is not known.
+	CIOperatorNamespaceNotFound = "CIOperatorNamespaceNotFound"
+	// TearDownFailure means the cluster could not be torn down.
+	TearDownFailure = "TearDownFailure"
+	// InvalidTTL means the cluster is not provisioned because its TTL is invalid.
+	InvalidTTL = "InvalidTTL"
+)
+
+const (
+	// DefaultTTL is the lifetime of a cluster which does not specify one.
+	DefaultTTL = 4 * time.Hour
+	// MaxTTL is the longest lifetime a cluster can be extended to.
+	MaxTTL = 24 * time.Hour
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -48,11 +72,132 @@ type EphemeralClusterList struct {
 
 type EphemeralClusterSpec struct {
 	CIOperator CIOperatorSpec `json:"ciOperator"`
+
+	// TTL is how long the cluster lives, counted from the creation of the EphemeralCluster.
+	// Once it is over the cluster is torn down. Increasing it extends the lifetime of the
+	// cluster. It cannot be negative or longer than 24h. Defaults to 4h.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s') && duration(self) <= duration('24h')",message="must be between 0s and 24h"
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// TearDown requests the cluster to be torn down immediately, running the post
+	// steps of the workflow, regardless of its remaining lifetime.
+	// +optional
+	TearDown bool `json:"tearDown,omitempty"`
+}
+
+// LifetimeTTL is the lifetime of the cluster, accounting for the default. It fails
+// when the TTL is negative or longer than MaxTTL.
+func (s *EphemeralClusterSpec) LifetimeTTL() (time.Duration, error) {
+	if s.TTL == nil {
+		return DefaultTTL, nil
+	}
+	if s.TTL.Duration < 0 {
+		return 0, fmt.Errorf("ttl: %s must not be negative", s.TTL.Duration)
+	}
+	if s.TTL.Duration > MaxTTL {
+		return 0, fmt.Errorf("ttl: %s must not be longer than %s", s.TTL.Duration, MaxTTL)
+	}
+	return s.TTL.Duration, nil
 }
 
 // CIOperatorSpec contains what is needed to run ci-operator
 type CIOperatorSpec struct {
 	Workflow Workflow `json:"workflow"`
+
+	// Releases selects the release payloads the workflow installs and upgrades between,
+	// mirroring ci-operator's `releases` stanza, where "initial" and "latest" are the
+	// usual names. Defaults to assembling both from the OCP integration stream.
+	// +optional
+	Releases map[string]Release `json:"releases,omitempty"`
+
+	// PullRequests are built and their images included in the "latest" release, which
+	// has to be assembled from an integration stream. All of them must target the same
+	// repository and branch.
+	// +optional
+	PullRequests []PullRequest `json:"pullRequests,omitempty"`
+}
+
+// Release describes a release payload. Exactly one of the fields must be set.
+type Release struct {
+	// Integration describes an integration stream which a payload is assembled out of.
+	// +optional
+	Integration *Integration `json:"integration,omitempty"`
+	// Candidate describes a candidate release payload.
+	// +optional
+	Candidate *Candidate `json:"candidate,omitempty"`
+	// Prerelease describes a yet-to-be released payload.
+	// +optional
+	Prerelease *Prerelease `json:"prerelease,omitempty"`
+	// PullSpec is the pull spec of an explicit release payload.
+	// +optional
+	PullSpec string `json:"pullSpec,omitempty"`
+}
+
+// Integration is an ImageStream holding the latest images from development builds of OCP.
+type Integration struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Candidate describes a validated candidate release payload.
+type Candidate struct {
+	// Product is the name of the product being released. Defaults to ocp.
+	// +optional
+	Product string `json:"product,omitempty"`
+	// Architecture is the architecture of the product. Defaults to amd64.
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+	// Stream is the stream from which the latest candidate is picked.
+	Stream string `json:"stream"`
+	// Version is the minor version to search for.
+	Version string `json:"version"`
+	// Relative optionally picks an older release from the stream, e.g. 1 for the previous one.
+	// +optional
+	Relative int `json:"relative,omitempty"`
+}
+
+// Prerelease describes a validated release payload before it is exposed.
+type Prerelease struct {
+	// Product is the name of the product being released. Defaults to ocp.
+	// +optional
+	Product string `json:"product,omitempty"`
+	// Architecture is the architecture of the product. Defaults to amd64.
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+	// VersionBounds describe the allowable version bounds to search in.
+	VersionBounds VersionBounds `json:"versionBounds"`
+	// Relative optionally picks an older release within the bounds, e.g. 1 for the previous one.
+	// +optional
+	Relative int `json:"relative,omitempty"`
+}
+
+// VersionBounds describe the upper and lower bounds and stream on a version search.
+type VersionBounds struct {
+	Lower string `json:"lower"`
+	Upper string `json:"upper"`
+	// Stream dictates which stream to search for a version within the specified bounds.
+	// Defaults to 4-stable.
+	// +optional
+	Stream string `json:"stream,omitempty"`
+}
+
+// PullRequest identifies a pull request whose changes are built into the release payload.
+type PullRequest struct {
+	// Org is something like "openshift" in github.com/openshift/kubernetes
+	Org string `json:"org"`
+	// Repo is something like "kubernetes" in github.com/openshift/kubernetes
+	Repo string `json:"repo"`
+	// BaseRef identifies the target branch for the PR
+	BaseRef string `json:"baseRef"`
+	// BaseSHA identifies the HEAD of BaseRef at the time
+	BaseSHA string `json:"baseSHA"`
+	Number  int    `json:"number"`
+	SHA     string `json:"sha"`
+	// +optional
+	Author string `json:"author,omitempty"`
+	// +optional
+	Title string `json:"title,omitempty"`
 }
 
 // Workflow determines the workflow will be executed by the ci-operator
@@ -68,6 +213,9 @@ type EphemeralClusterStatus struct {
 	CIOperator CIOperatorStatus            `json:"ciOperator,omitempty"`
 	// Kubeconfig to access the ephemeral cluster
 	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// ExpirationTime is when the lifetime of the cluster is over.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
 }
 
 // CIOperatorStatus contains information about a ci-operator's running instance
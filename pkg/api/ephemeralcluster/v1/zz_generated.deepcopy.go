@@ -5,6 +5,7 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -12,6 +13,18 @@ import (
 func (in *CIOperatorSpec) DeepCopyInto(out *CIOperatorSpec) {
 	*out = *in
 	in.Workflow.DeepCopyInto(&out.Workflow)
+	if in.Releases != nil {
+		in, out := &in.Releases, &out.Releases
+		*out = make(map[string]Release, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.PullRequests != nil {
+		in, out := &in.PullRequests, &out.PullRequests
+		*out = make([]PullRequest, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CIOperatorSpec.
@@ -39,6 +52,21 @@ func (in *CIOperatorStatus) DeepCopy() *CIOperatorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Candidate) DeepCopyInto(out *Candidate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Candidate.
+func (in *Candidate) DeepCopy() *Candidate {
+	if in == nil {
+		return nil
+	}
+	out := new(Candidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EphemeralCluster) DeepCopyInto(out *EphemeralCluster) {
 	*out = *in
@@ -118,6 +146,11 @@ func (in *EphemeralClusterList) DeepCopyObject() runtime.Object {
 func (in *EphemeralClusterSpec) DeepCopyInto(out *EphemeralClusterSpec) {
 	*out = *in
 	in.CIOperator.DeepCopyInto(&out.CIOperator)
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralClusterSpec.
@@ -141,6 +174,10 @@ func (in *EphemeralClusterStatus) DeepCopyInto(out *EphemeralClusterStatus) {
 		}
 	}
 	out.CIOperator = in.CIOperator
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralClusterStatus.
@@ -153,6 +190,97 @@ func (in *EphemeralClusterStatus) DeepCopy() *EphemeralClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Integration) DeepCopyInto(out *Integration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Integration.
+func (in *Integration) DeepCopy() *Integration {
+	if in == nil {
+		return nil
+	}
+	out := new(Integration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Prerelease) DeepCopyInto(out *Prerelease) {
+	*out = *in
+	out.VersionBounds = in.VersionBounds
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Prerelease.
+func (in *Prerelease) DeepCopy() *Prerelease {
+	if in == nil {
+		return nil
+	}
+	out := new(Prerelease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequest) DeepCopyInto(out *PullRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequest.
+func (in *PullRequest) DeepCopy() *PullRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Release) DeepCopyInto(out *Release) {
+	*out = *in
+	if in.Integration != nil {
+		in, out := &in.Integration, &out.Integration
+		*out = new(Integration)
+		**out = **in
+	}
+	if in.Candidate != nil {
+		in, out := &in.Candidate, &out.Candidate
+		*out = new(Candidate)
+		**out = **in
+	}
+	if in.Prerelease != nil {
+		in, out := &in.Prerelease, &out.Prerelease
+		*out = new(Prerelease)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Release.
+func (in *Release) DeepCopy() *Release {
+	if in == nil {
+		return nil
+	}
+	out := new(Release)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionBounds) DeepCopyInto(out *VersionBounds) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionBounds.
+func (in *VersionBounds) DeepCopy() *VersionBounds {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionBounds)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Workflow) DeepCopyInto(out *Workflow) {
 	*out = *in
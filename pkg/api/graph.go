@@ -632,6 +632,9 @@ func mergeSteps(into, from CIOperatorStepDetails) CIOperatorStepDetails {
 	if into.Failed == nil {
 		into.Failed = from.Failed
 	}
+	if into.Metadata == nil {
+		into.Metadata = from.Metadata
+	}
 	if into.Substeps == nil {
 		into.Substeps = from.Substeps
 	}
@@ -656,6 +659,9 @@ type CIOperatorStepDetailInfo struct {
 	Manifests    []ctrlruntimeclient.Object `json:"manifests,omitempty"`
 	LogURL       string                     `json:"log_url,omitempty"`
 	Failed       *bool                      `json:"failed,omitempty"`
+	// Metadata holds details specific to the type of the step, like
+	// whether an image build was avoided.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 func (c *CIOperatorStepDetailInfo) UnmarshalJSON(data []byte) error {
@@ -10,10 +10,15 @@ import (
 // unique values.
 func LeasesForTest(s *MultiStageTestConfigurationLiteral) (ret []StepLease) {
 	if p := s.ClusterProfile; p != "" {
+		var fallbacks []string
+		for _, fallback := range s.FallbackClusterProfiles {
+			fallbacks = append(fallbacks, fallback.LeaseType())
+		}
 		ret = append(ret, StepLease{
-			ResourceType: p.LeaseType(),
-			Env:          DefaultLeaseEnv,
-			Count:        1,
+			ResourceType:          p.LeaseType(),
+			Env:                   DefaultLeaseEnv,
+			Count:                 1,
+			FallbackResourceTypes: fallbacks,
 		})
 	}
 	for _, step := range append(s.Pre, append(s.Test, s.Post...)...) {
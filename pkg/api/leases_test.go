@@ -24,6 +24,18 @@ func TestLeasesForTest(t *testing.T) {
 			Env:          DefaultLeaseEnv,
 			Count:        1,
 		}},
+	}, {
+		name: "cluster profile with fallbacks, lease with fallback types",
+		tests: MultiStageTestConfigurationLiteral{
+			ClusterProfile:          ClusterProfileAWS,
+			FallbackClusterProfiles: []ClusterProfile{ClusterProfileAWS3, ClusterProfileAWS2},
+		},
+		expected: []StepLease{{
+			ResourceType:          "aws-quota-slice",
+			Env:                   DefaultLeaseEnv,
+			Count:                 1,
+			FallbackResourceTypes: []string{"aws-3-quota-slice", "aws-2-quota-slice"},
+		}},
 	}, {
 		name: "explicit configuration, lease",
 		tests: MultiStageTestConfigurationLiteral{
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RegistryLifecycleDateFormat is the format of the removal date of a deprecated registry component
+const RegistryLifecycleDateFormat = "2006-01-02"
+
+// RegistryLifecycle describes the deprecation of a step, chain or workflow
+// in the registry.
+type RegistryLifecycle struct {
+	// Deprecated explains why the component should no longer be used.
+	Deprecated string `json:"deprecated"`
+	// ReplacedBy is the name of the component of the same type that should
+	// be used instead, e.g. the new name of a renamed component.
+	ReplacedBy string `json:"replaced_by,omitempty"`
+	// RemovalDate is the date (YYYY-MM-DD) when the component is scheduled
+	// to be removed. Configurations still using it after this date fail
+	// validation.
+	RemovalDate string `json:"removal_date,omitempty"`
+}
+
+// Validate checks that the lifecycle is complete and its removal date can be parsed
+func (l *RegistryLifecycle) Validate() error {
+	var errs []string
+	if l.Deprecated == "" {
+		errs = append(errs, "deprecated: a reason must be provided")
+	}
+	if l.RemovalDate != "" {
+		if _, err := time.Parse(RegistryLifecycleDateFormat, l.RemovalDate); err != nil {
+			errs = append(errs, fmt.Sprintf("removal_date: must be in the %s format", RegistryLifecycleDateFormat))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Removed determines whether the removal date of the component has passed
+func (l *RegistryLifecycle) Removed(now time.Time) bool {
+	if l.RemovalDate == "" {
+		return false
+	}
+	removal, err := time.Parse(RegistryLifecycleDateFormat, l.RemovalDate)
+	if err != nil {
+		return false
+	}
+	return !now.Before(removal)
+}
+
+// Describe explains the deprecation of the component of the type and name for users of it
+func (l *RegistryLifecycle) Describe(componentType, name string) string {
+	message := fmt.Sprintf("%s %s is deprecated: %s", componentType, name, l.Deprecated)
+	if l.ReplacedBy != "" {
+		message += fmt.Sprintf("; use %s %s instead", componentType, l.ReplacedBy)
+	}
+	if l.RemovalDate != "" {
+		message += fmt.Sprintf("; it is scheduled for removal on %s", l.RemovalDate)
+	}
+	return message
+}
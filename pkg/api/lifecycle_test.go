@@ -0,0 +1,32 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistryLifecycleRemoved(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		date     string
+		expected bool
+	}{
+		{date: "", expected: false},
+		{date: "2026-03-02", expected: false},
+		{date: "2026-03-01", expected: true},
+		{date: "2026-02-28", expected: true},
+		{date: "invalid", expected: false},
+	} {
+		if actual := (&RegistryLifecycle{Deprecated: "old", RemovalDate: tc.date}).Removed(now); actual != tc.expected {
+			t.Errorf("%q: expected removed to be %t, got %t", tc.date, tc.expected, actual)
+		}
+	}
+}
+
+func TestRegistryLifecycleDescribe(t *testing.T) {
+	lifecycle := RegistryLifecycle{Deprecated: "it was renamed", ReplacedBy: "new", RemovalDate: "2026-03-01"}
+	expected := "chain old is deprecated: it was renamed; use chain new instead; it is scheduled for removal on 2026-03-01"
+	if actual := lifecycle.Describe("chain", "old"); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
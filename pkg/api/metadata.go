@@ -144,6 +144,10 @@ func BuildCacheFor(metadata Metadata) ImageStreamTagReference {
 	}
 }
 
+// ContentDigestLabel is the label on images built from the repository holding the digest
+// of everything that went into the build, used to find images that need not be rebuilt
+const ContentDigestLabel = "io.openshift.ci.content-digest"
+
 func ImageVersionLabel(fromTag PipelineImageStreamTagReference) string {
 	return utils.Trim63(fmt.Sprintf("io.openshift.ci.from.%s", fromTag))
 }
@@ -589,6 +589,31 @@ type PromotionTarget struct {
 	// never concurrently, and you want to have promotion config
 	// in the ci-operator configuration files all the time.
 	Disabled bool `json:"disabled,omitempty"`
+
+	// Gates hold back the promotion to this target until the
+	// postsubmit tests they require have passed, so a broken
+	// merge does not flow into the target.
+	Gates *PromotionGates `json:"gates,omitempty"`
+}
+
+// PromotionGates are conditions on the results of postsubmit tests
+// which must hold for images to be promoted to a target.
+type PromotionGates struct {
+	// RequiredTests are the names of tests in this configuration,
+	// run as postsubmit jobs, which must have passed on the commit
+	// the images are built from.
+	RequiredTests []string `json:"required_tests,omitempty"`
+
+	// MinimumPasses additionally requires each of the required tests
+	// to have passed on at least this many of the most recent commits
+	// it ran on. The tests must still have run on the commit the images
+	// are built from, and their last run on it must not have failed.
+	MinimumPasses int `json:"minimum_passes,omitempty"`
+
+	// RecentCommits is the number of most recent commits the tests
+	// ran on which are considered for MinimumPasses. It is required
+	// when MinimumPasses is set.
+	RecentCommits int `json:"recent_commits,omitempty"`
 }
 
 // StepConfiguration holds one step configuration.
@@ -945,6 +970,8 @@ type RegistryChain struct {
 	Environment []StepParameter `json:"env,omitempty"`
 	// Leases lists resources that should be acquired for the test.
 	Leases []StepLease `json:"leases,omitempty"`
+	// Lifecycle marks the chain as deprecated.
+	Lifecycle *RegistryLifecycle `json:"lifecycle,omitempty"`
 }
 
 // RegistryWorkflowConfig is the struct that workflow references are unmarshalled into.
@@ -961,6 +988,8 @@ type RegistryWorkflow struct {
 	Steps MultiStageTestConfiguration `json:"steps,omitempty"`
 	// Documentation describes what the workflow does.
 	Documentation string `json:"documentation,omitempty"`
+	// Lifecycle marks the workflow as deprecated.
+	Lifecycle *RegistryLifecycle `json:"lifecycle,omitempty"`
 }
 
 // RegistryObserverConfig is the struct that observer configs are unmarshalled into
@@ -1076,6 +1105,58 @@ type LiteralTestStep struct {
 	// NodeArchitecture is the architecture for the node where the test will run.
 	// If set, the generated test pod will include a nodeSelector for this architecture.
 	NodeArchitecture *NodeArchitecture `json:"node_architecture,omitempty"`
+	// ParallelGroup is the name of the parallel group this step is part of.
+	// Consecutive steps in a phase with the same group name are executed
+	// concurrently. This field is populated when a `parallel` group is
+	// resolved and cannot be set directly.
+	ParallelGroup string `json:"parallel_group,omitempty"`
+	// ParallelBranch is the name of the branch of a parallel group this step
+	// is part of. Consecutive steps in the same branch are executed in order,
+	// while branches are executed concurrently. This field is populated when
+	// a `parallel` group is resolved and cannot be set directly.
+	ParallelBranch string `json:"parallel_branch,omitempty"`
+	// Retry configures the step to be executed again when it fails because
+	// of an infrastructure problem.
+	Retry *StepRetryPolicy `json:"retry,omitempty"`
+	// Lifecycle marks the step as deprecated. It is only valid for steps in
+	// the registry and is carried over to the steps resolved from them.
+	Lifecycle *RegistryLifecycle `json:"lifecycle,omitempty"`
+}
+
+// StepRetryMaxCount is the maximum number of times a step can be retried.
+// The backoff doubles after each retry, so a larger number would hold the
+// job until it times out.
+const StepRetryMaxCount = 5
+
+// StepRetryReason is a class of failures for which a step can be retried.
+type StepRetryReason string
+
+const (
+	// StepRetryReasonEvicted matches pods evicted from their node.
+	StepRetryReasonEvicted StepRetryReason = "evicted"
+	// StepRetryReasonImagePull matches pods whose images could not be pulled.
+	StepRetryReasonImagePull StepRetryReason = "image_pull"
+	// StepRetryReasonOOMKilled matches pods with a container killed for
+	// exceeding its memory limit.
+	StepRetryReasonOOMKilled StepRetryReason = "oom_killed"
+)
+
+// StepRetryPolicy determines when and how many times a failed step is
+// executed again.  Every attempt is reported separately and the result of the
+// last one determines the result of the step.  Before a retry, the shared
+// directory is restored to the content it had before the first attempt,
+// unless it is backed by a volume claim or the step is in a parallel group.
+type StepRetryPolicy struct {
+	// Count is the maximum number of times the step is retried after the
+	// initial attempt, at most 5.
+	Count int `json:"count"`
+	// Backoff is how long to wait before each retry, doubled after each one.
+	Backoff *prowv1.Duration `json:"backoff,omitempty"`
+	// Reasons are the failures for which the step is retried.
+	Reasons []StepRetryReason `json:"reasons,omitempty"`
+	// LogPattern is a regular expression: the step is also retried if it
+	// matches the logs of the failed test container.
+	LogPattern string `json:"log_pattern,omitempty"`
 }
 
 // StepParameter is a variable set by the test, with an optional default.
@@ -1128,6 +1209,30 @@ type StepLease struct {
 	Env string `json:"env"`
 	// Count is the number of resources to acquire (optional, defaults to 1).
 	Count uint `json:"count,omitempty"`
+	// FallbackResourceTypes are alternative types of resource, in order of
+	// preference, that will be leased when no resource of ResourceType is free.
+	// The type that was leased and the region of the resources will be exposed
+	// to the step via the environment variables named by LeaseTypeEnv and
+	// LeaseRegionEnv.
+	FallbackResourceTypes []string `json:"fallback_resource_types,omitempty"`
+}
+
+// LeaseTypeEnv is the environment variable that will contain the type of the
+// resource that was leased for a lease with fallback resource types.
+func LeaseTypeEnv(env string) string {
+	return env + "_TYPE"
+}
+
+// LeaseRegionEnv is the environment variable that will contain the region of the
+// resources that were leased for a lease with fallback resource types.
+func LeaseRegionEnv(env string) string {
+	return env + "_REGION"
+}
+
+// LeaseFallbackEnvs are all the environment variables, besides the one holding
+// the names of the resources, exposing a lease with fallback resource types.
+func LeaseFallbackEnvs(env string) []string {
+	return []string{LeaseTypeEnv(env), LeaseRegionEnv(env)}
 }
 
 // FromImageTag returns the internal name for the image tag that will be used
@@ -1149,6 +1254,59 @@ type TestStep struct {
 	Reference *string `json:"ref,omitempty"`
 	// Chain is the name of a step chain reference.
 	Chain *string `json:"chain,omitempty"`
+	// Parallel is a group of steps which are executed concurrently.
+	Parallel *ParallelTestSteps `json:"parallel,omitempty"`
+}
+
+// ParallelTestSteps is a group of steps that are executed concurrently. Each
+// member of the group is a branch: a chain is executed as a single branch, its
+// steps running in order, while a reference or literal step is a branch of its
+// own. All branches start with the same content in the shared directory.
+// When a step finishes, the files it created or modified are merged into the
+// shared directory, in the order in which the steps finish; files removed by a
+// step are not removed from the shared directory. A failure of a step does not
+// interrupt the other steps in the group, but the group as a whole fails and
+// subsequent `pre` and `test` steps are not executed.
+type ParallelTestSteps struct {
+	// As is the name of the group.
+	As string `json:"as"`
+	// Steps are the branches that are executed concurrently.
+	Steps []ParallelTestStep `json:"steps"`
+}
+
+// ParallelTestStep is a member of a parallel group. It can contain either a
+// LiteralTestStep, Reference, or Chain, exactly like a TestStep, but not
+// another parallel group.
+type ParallelTestStep struct {
+	// LiteralTestStep is a full test step definition.
+	*LiteralTestStep `json:",inline,omitempty"`
+	// Reference is the name of a step reference.
+	Reference *string `json:"ref,omitempty"`
+	// Chain is the name of a step chain reference.
+	Chain *string `json:"chain,omitempty"`
+}
+
+// TestSteps returns the members of the group as regular steps.
+func (p *ParallelTestSteps) TestSteps() []TestStep {
+	ret := make([]TestStep, 0, len(p.Steps))
+	for _, s := range p.Steps {
+		ret = append(ret, TestStep{LiteralTestStep: s.LiteralTestStep, Reference: s.Reference, Chain: s.Chain})
+	}
+	return ret
+}
+
+// FlattenTestSteps returns the list of steps with all parallel groups replaced
+// by their members.
+func FlattenTestSteps(steps []TestStep) []TestStep {
+	var ret []TestStep
+	for _, s := range steps {
+		if s.Parallel != nil {
+			ret = append(ret, s.Parallel.TestSteps()...)
+		} else {
+			ret = append(ret, s)
+		}
+	}
+	return ret
 }
 
 // MultiStageTestConfiguration is a flexible configuration mode that allows tighter control over
@@ -1156,6 +1314,10 @@ type TestStep struct {
 type MultiStageTestConfiguration struct {
 	// ClusterProfile defines the profile/cloud provider for end-to-end test steps.
 	ClusterProfile ClusterProfile `json:"cluster_profile,omitempty"`
+	// FallbackClusterProfiles are profiles, in order of preference, whose lease
+	// will be acquired when none of the ClusterProfile is free. Steps are given
+	// the credentials of the profile whose lease was acquired.
+	FallbackClusterProfiles []ClusterProfile `json:"fallback_cluster_profiles,omitempty"`
 	// Pre is the array of test steps run to set up the environment for the test.
 	Pre []TestStep `json:"pre,omitempty"`
 	// Test is the array of test steps that define the actual test.
@@ -1191,15 +1353,58 @@ type MultiStageTestConfiguration struct {
 	// NodeArchitecture is the architecture for the node where the test will run.
 	// If set, the generated test pod will include a nodeSelector for this architecture.
 	NodeArchitecture *NodeArchitecture `json:"node_architecture,omitempty"`
+	// SharedDir configures the storage backing the shared directory of the test.
+	SharedDir *SharedDir `json:"shared_dir,omitempty"`
+	// Lifecycle is set from the lifecycle of a workflow when it is loaded
+	// from the registry and is not part of the configuration of tests.
+	Lifecycle *RegistryLifecycle `json:"-"`
 }
 type DependencyOverrides map[string]string
 
+// SharedDirBackend is the storage used for the shared directory of a multi-stage test.
+type SharedDirBackend string
+
+const (
+	// SharedDirBackendSecret stores the shared directory in a Secret. Its
+	// content is limited to the maximum size of a Secret and cannot contain
+	// subdirectories.
+	SharedDirBackendSecret SharedDirBackend = "secret"
+	// SharedDirBackendPVC stores the shared directory in a PersistentVolumeClaim
+	// created in the test namespace. The claim uses the `ReadWriteMany` access
+	// mode, since observers and parallel steps may run on different nodes.
+	SharedDirBackendPVC SharedDirBackend = "pvc"
+)
+
+// SharedDirSecretSizeLimit is the maximum size of the shared directory when it
+// is stored in a Secret.
+const SharedDirSecretSizeLimit = "1Mi"
+
+// SharedDir configures the shared directory of a multi-stage test.
+type SharedDir struct {
+	// Backend is the storage used for the shared directory, `secret` (the
+	// default) or `pvc`.
+	Backend SharedDirBackend `json:"backend,omitempty"`
+	// SizeLimit is the maximum total size of the content of the shared
+	// directory, as a Kubernetes quantity. A step fails if it leaves more
+	// content than this in the shared directory. Required for the `pvc`
+	// backend, where it also determines the size of the volume.
+	SizeLimit string `json:"size_limit,omitempty"`
+	// StorageClass is the storage class of the volume claim of the `pvc`
+	// backend, which must support the `ReadWriteMany` access mode. The
+	// default storage class of the cluster is used if unset.
+	StorageClass string `json:"storage_class,omitempty"`
+}
+
 // MultiStageTestConfigurationLiteral is a form of the MultiStageTestConfiguration that does not include
 // references. It is the type that MultiStageTestConfigurations are converted to when parsed by the
 // ci-operator-configresolver.
 type MultiStageTestConfigurationLiteral struct {
 	// ClusterProfile defines the profile/cloud provider for end-to-end test steps.
 	ClusterProfile ClusterProfile `json:"cluster_profile"`
+	// FallbackClusterProfiles are profiles, in order of preference, whose lease
+	// will be acquired when none of the ClusterProfile is free. Steps are given
+	// the credentials of the profile whose lease was acquired.
+	FallbackClusterProfiles []ClusterProfile `json:"fallback_cluster_profiles,omitempty"`
 	// Pre is the array of test steps run to set up the environment for the test.
 	Pre []LiteralTestStep `json:"pre,omitempty"`
 	// Test is the array of test steps that define the actual test.
@@ -1231,9 +1436,14 @@ type MultiStageTestConfigurationLiteral struct {
 	// NodeArchitecture is the architecture for the node where the test will run.
 	// If set, the generated test pod will include a nodeSelector for this architecture.
 	NodeArchitecture *NodeArchitecture `json:"node_architecture,omitempty"`
+	// SharedDir configures the storage backing the shared directory of the test.
+	SharedDir *SharedDir `json:"shared_dir,omitempty"`
 
 	// Override job timeout
 	Timeout *prowv1.Duration `json:"timeout,omitempty"`
+	// Deprecations describe the deprecated workflow and chains of the registry
+	// the test was resolved from. Deprecated steps carry their own lifecycle.
+	Deprecations []string `json:"deprecations,omitempty"`
 }
 
 // TestEnvironment has the values of parameters for multi-stage tests.
@@ -689,7 +689,9 @@ func (in *LiteralTestStep) DeepCopyInto(out *LiteralTestStep) {
 	if in.Leases != nil {
 		in, out := &in.Leases, &out.Leases
 		*out = make([]StepLease, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.OptionalOnSuccess != nil {
 		in, out := &in.OptionalOnSuccess, &out.OptionalOnSuccess
@@ -721,6 +723,16 @@ func (in *LiteralTestStep) DeepCopyInto(out *LiteralTestStep) {
 		*out = new(NodeArchitecture)
 		**out = **in
 	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(StepRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(RegistryLifecycle)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiteralTestStep.
@@ -782,6 +794,11 @@ func (in *MetadataWithTest) DeepCopy() *MetadataWithTest {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultiStageTestConfiguration) DeepCopyInto(out *MultiStageTestConfiguration) {
 	*out = *in
+	if in.FallbackClusterProfiles != nil {
+		in, out := &in.FallbackClusterProfiles, &out.FallbackClusterProfiles
+		*out = make([]ClusterProfile, len(*in))
+		copy(*out, *in)
+	}
 	if in.Pre != nil {
 		in, out := &in.Pre, &out.Pre
 		*out = make([]TestStep, len(*in))
@@ -830,7 +847,9 @@ func (in *MultiStageTestConfiguration) DeepCopyInto(out *MultiStageTestConfigura
 	if in.Leases != nil {
 		in, out := &in.Leases, &out.Leases
 		*out = make([]StepLease, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.AllowSkipOnSuccess != nil {
 		in, out := &in.AllowSkipOnSuccess, &out.AllowSkipOnSuccess
@@ -859,6 +878,16 @@ func (in *MultiStageTestConfiguration) DeepCopyInto(out *MultiStageTestConfigura
 		*out = new(NodeArchitecture)
 		**out = **in
 	}
+	if in.SharedDir != nil {
+		in, out := &in.SharedDir, &out.SharedDir
+		*out = new(SharedDir)
+		**out = **in
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(RegistryLifecycle)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiStageTestConfiguration.
@@ -874,6 +903,11 @@ func (in *MultiStageTestConfiguration) DeepCopy() *MultiStageTestConfiguration {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultiStageTestConfigurationLiteral) DeepCopyInto(out *MultiStageTestConfigurationLiteral) {
 	*out = *in
+	if in.FallbackClusterProfiles != nil {
+		in, out := &in.FallbackClusterProfiles, &out.FallbackClusterProfiles
+		*out = make([]ClusterProfile, len(*in))
+		copy(*out, *in)
+	}
 	if in.Pre != nil {
 		in, out := &in.Pre, &out.Pre
 		*out = make([]LiteralTestStep, len(*in))
@@ -917,7 +951,9 @@ func (in *MultiStageTestConfigurationLiteral) DeepCopyInto(out *MultiStageTestCo
 	if in.Leases != nil {
 		in, out := &in.Leases, &out.Leases
 		*out = make([]StepLease, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.AllowSkipOnSuccess != nil {
 		in, out := &in.AllowSkipOnSuccess, &out.AllowSkipOnSuccess
@@ -948,11 +984,21 @@ func (in *MultiStageTestConfigurationLiteral) DeepCopyInto(out *MultiStageTestCo
 		*out = new(NodeArchitecture)
 		**out = **in
 	}
+	if in.SharedDir != nil {
+		in, out := &in.SharedDir, &out.SharedDir
+		*out = new(SharedDir)
+		**out = **in
+	}
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.Deprecations != nil {
+		in, out := &in.Deprecations, &out.Deprecations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiStageTestConfigurationLiteral.
@@ -1260,6 +1306,58 @@ func (in *OutputImageTagStepConfiguration) DeepCopy() *OutputImageTagStepConfigu
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParallelTestStep) DeepCopyInto(out *ParallelTestStep) {
+	*out = *in
+	if in.LiteralTestStep != nil {
+		in, out := &in.LiteralTestStep, &out.LiteralTestStep
+		*out = new(LiteralTestStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reference != nil {
+		in, out := &in.Reference, &out.Reference
+		*out = new(string)
+		**out = **in
+	}
+	if in.Chain != nil {
+		in, out := &in.Chain, &out.Chain
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParallelTestStep.
+func (in *ParallelTestStep) DeepCopy() *ParallelTestStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ParallelTestStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParallelTestSteps) DeepCopyInto(out *ParallelTestSteps) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]ParallelTestStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParallelTestSteps.
+func (in *ParallelTestSteps) DeepCopy() *ParallelTestSteps {
+	if in == nil {
+		return nil
+	}
+	out := new(ParallelTestSteps)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineImageCacheStepConfiguration) DeepCopyInto(out *PipelineImageCacheStepConfiguration) {
 	*out = *in
@@ -1367,6 +1465,26 @@ func (in *PromotionConfiguration) DeepCopy() *PromotionConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionGates) DeepCopyInto(out *PromotionGates) {
+	*out = *in
+	if in.RequiredTests != nil {
+		in, out := &in.RequiredTests, &out.RequiredTests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionGates.
+func (in *PromotionGates) DeepCopy() *PromotionGates {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionGates)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PromotionTarget) DeepCopyInto(out *PromotionTarget) {
 	*out = *in
@@ -1382,6 +1500,11 @@ func (in *PromotionTarget) DeepCopyInto(out *PromotionTarget) {
 			(*out)[key] = val
 		}
 	}
+	if in.Gates != nil {
+		in, out := &in.Gates, &out.Gates
+		*out = new(PromotionGates)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionTarget.
@@ -1504,7 +1627,14 @@ func (in *RegistryChain) DeepCopyInto(out *RegistryChain) {
 	if in.Leases != nil {
 		in, out := &in.Leases, &out.Leases
 		*out = make([]StepLease, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(RegistryLifecycle)
+		**out = **in
 	}
 }
 
@@ -1534,6 +1664,21 @@ func (in *RegistryChainConfig) DeepCopy() *RegistryChainConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryLifecycle) DeepCopyInto(out *RegistryLifecycle) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryLifecycle.
+func (in *RegistryLifecycle) DeepCopy() *RegistryLifecycle {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryLifecycle)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistryObserver) DeepCopyInto(out *RegistryObserver) {
 	*out = *in
@@ -1602,6 +1747,11 @@ func (in *RegistryReferenceConfig) DeepCopy() *RegistryReferenceConfig {
 func (in *RegistryWorkflow) DeepCopyInto(out *RegistryWorkflow) {
 	*out = *in
 	in.Steps.DeepCopyInto(&out.Steps)
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(RegistryLifecycle)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryWorkflow.
@@ -1862,6 +2012,21 @@ func (in *Secret) DeepCopy() *Secret {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedDir) DeepCopyInto(out *SharedDir) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedDir.
+func (in *SharedDir) DeepCopy() *SharedDir {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedDir)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SourceStepConfiguration) DeepCopyInto(out *SourceStepConfiguration) {
 	*out = *in
@@ -2001,6 +2166,11 @@ func (in *StepDependency) DeepCopy() *StepDependency {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepLease) DeepCopyInto(out *StepLease) {
 	*out = *in
+	if in.FallbackResourceTypes != nil {
+		in, out := &in.FallbackResourceTypes, &out.FallbackResourceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepLease.
@@ -2048,6 +2218,31 @@ func (in *StepParameter) DeepCopy() *StepParameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepRetryPolicy) DeepCopyInto(out *StepRetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]StepRetryReason, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepRetryPolicy.
+func (in *StepRetryPolicy) DeepCopy() *StepRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StepRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in TestDependencies) DeepCopyInto(out *TestDependencies) {
 	{
@@ -2108,6 +2303,11 @@ func (in *TestStep) DeepCopyInto(out *TestStep) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Parallel != nil {
+		in, out := &in.Parallel, &out.Parallel
+		*out = new(ParallelTestSteps)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestStep.
@@ -187,6 +187,9 @@ func printTreeSteps(o *options, steps []api.TestStep, level uint) {
 			printTreeStep(*s.Reference, level)
 		} else if s.LiteralTestStep != nil {
 			printTreeStep(s.LiteralTestStep.As, level)
+		} else if s.Parallel != nil {
+			printTreeLevel(level, "parallel: %s\n", s.Parallel.As)
+			printTreeSteps(o, s.Parallel.TestSteps(), level+1)
 		}
 	}
 }
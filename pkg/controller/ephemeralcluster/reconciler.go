@@ -5,6 +5,8 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/ghodss/yaml"
@@ -26,11 +28,23 @@ import (
 
 	"github.com/openshift/ci-tools/pkg/api"
 	ephemeralclusterv1 "github.com/openshift/ci-tools/pkg/api/ephemeralcluster/v1"
+	"github.com/openshift/ci-tools/pkg/jobconfig"
 	"github.com/openshift/ci-tools/pkg/prowgen"
+	registryserver "github.com/openshift/ci-tools/pkg/registry/server"
+	"github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/steps/utils"
 )
 
 const (
 	ProwJobNamespace = "ci"
+	// clusterProvisioningTest is the test of the generated ci-operator config which provisions the cluster.
+	clusterProvisioningTest = "cluster-provisioning"
+	// TestDoneSecretName is the secret wait-test-complete.sh waits for before letting the
+	// workflow carry on with its post steps, tearing the cluster down.
+	TestDoneSecretName = "test-done-keep-going"
+
+	// tearDownRetryInterval is how long to wait before trying to tear a cluster down again.
+	tearDownRetryInterval = time.Minute
 )
 
 var (
@@ -43,39 +57,63 @@ type ConfigSpecUploader interface {
 	UploadConfigSpec(ctx context.Context, location, ciOpConfigContent string) (string, error)
 }
 
+// ConfigResolver provides the ci-operator configuration of a repository
+type ConfigResolver interface {
+	Config(info *api.Metadata) (*api.ReleaseBuildConfiguration, error)
+}
+
 type reconciler struct {
 	logger         *logrus.Entry
 	masterClient   ctrlruntimeclient.Client
 	buildClients   map[string]ctrlruntimeclient.Client
 	newPresubmit   NewPresubmitFunc
 	configUploader ConfigSpecUploader
+	configResolver ConfigResolver
 
 	// Mock for testing
 	now func() time.Time
 }
 
-func AddToManager(logger *logrus.Entry, mgr manager.Manager, allManagers map[string]manager.Manager) error {
+// Options configures the EphemeralCluster controller
+type Options struct {
+	// ConfigResolverAddress is the address of the ci-operator configuration
+	// resolver the configuration of the repositories of pull requests is
+	// resolved with, the one in the CI cluster if not set.
+	ConfigResolverAddress string
+}
+
+func AddToManager(logger *logrus.Entry, mgr manager.Manager, allManagers map[string]manager.Manager, opts Options) error {
 	buildClients := make(map[string]ctrlruntimeclient.Client)
 	for clusterName, clusterManager := range allManagers {
 		buildClients[clusterName] = clusterManager.GetClient()
 	}
 
-	r := reconciler{
-		logger:       logger,
-		masterClient: mgr.GetClient(),
-		buildClients: buildClients,
-	}
+	r := newReconciler(logger, mgr.GetClient(), buildClients, opts)
 
 	if err := ctrlbldr.ControllerManagedBy(mgr).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
 		For(&ephemeralclusterv1.EphemeralCluster{}).
-		Complete(&r); err != nil {
+		Complete(r); err != nil {
 		return fmt.Errorf("build controller: %w", err)
 	}
 
 	return nil
 }
 
+func newReconciler(logger *logrus.Entry, masterClient ctrlruntimeclient.Client, buildClients map[string]ctrlruntimeclient.Client, opts Options) *reconciler {
+	configResolverAddress := opts.ConfigResolverAddress
+	if configResolverAddress == "" {
+		configResolverAddress = api.URLForService(api.ServiceConfig)
+	}
+	return &reconciler{
+		logger:         logger,
+		masterClient:   masterClient,
+		buildClients:   buildClients,
+		configResolver: registryserver.NewResolverClient(configResolverAddress),
+		now:            time.Now,
+	}
+}
+
 func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	logger := r.logger.WithField("request", req.String())
 
@@ -98,6 +136,13 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			return r.handleGetProwJobError(err)
 		}
 	} else {
+		if _, err := ec.Spec.LifetimeTTL(); err != nil {
+			r.upsertCondition(ec, ephemeralclusterv1.ClusterProvisioning, ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.InvalidTTL, err.Error())
+			if err := r.masterClient.Update(ctx, ec); err != nil {
+				return reconcile.Result{}, fmt.Errorf("update ephemeral cluster: %w", err)
+			}
+			return reconcile.Result{}, nil
+		}
 		r.logger.Info("ProwJob not found, creating")
 		r.createProwJob(ctx, ec)
 		err := r.masterClient.Update(ctx, ec)
@@ -107,8 +152,93 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
+	if err := r.findCIOperatorNamespace(ctx, ec, &pj); err != nil {
+		logger.WithError(err).Warn("Failed to find the ci-operator namespace")
+	}
+	result := r.reconcileLifetime(ctx, ec, &pj)
+	if err := r.masterClient.Update(ctx, ec); err != nil {
+		return reconcile.Result{}, fmt.Errorf("update ephemeral cluster: %w", err)
+	}
+
 	logger.Info("Finished reconciliation")
-	return reconcile.Result{}, nil
+	return result, nil
+}
+
+// findCIOperatorNamespace looks the namespace ci-operator runs the workflow in up on the
+// build cluster the ProwJob is scheduled on, by the label ci-operator puts on it.
+func (r *reconciler) findCIOperatorNamespace(ctx context.Context, ec *ephemeralclusterv1.EphemeralCluster, pj *prowv1.ProwJob) error {
+	if ec.Status.CIOperator.Namespace != "" || pj.Spec.Cluster == "" {
+		return nil
+	}
+	client, ok := r.buildClients[pj.Spec.Cluster]
+	if !ok {
+		return fmt.Errorf("unknown build cluster %s", pj.Spec.Cluster)
+	}
+	namespaces := corev1.NamespaceList{}
+	if err := client.List(ctx, &namespaces, ctrlruntimeclient.MatchingLabels{steps.LabelJobID: pj.Name}); err != nil {
+		return fmt.Errorf("list namespaces: %w", err)
+	}
+	if len(namespaces.Items) == 0 {
+		return nil
+	}
+	ec.Status.CIOperator = ephemeralclusterv1.CIOperatorStatus{Cluster: pj.Spec.Cluster, Namespace: namespaces.Items[0].Name}
+	return nil
+}
+
+// reconcileLifetime records when the lifetime of the cluster is over and tears it down then
+// or when the user asks to. The result requeues the request on expiration. An invalid TTL
+// does not change the expiration time set by the last valid one.
+func (r *reconciler) reconcileLifetime(ctx context.Context, ec *ephemeralclusterv1.EphemeralCluster, pj *prowv1.ProwJob) reconcile.Result {
+	if ttl, err := ec.Spec.LifetimeTTL(); err != nil {
+		r.logger.WithError(err).Warn("Ignoring invalid TTL")
+	} else {
+		ec.Status.ExpirationTime = &v1.Time{Time: ec.CreationTimestamp.Add(ttl)}
+	}
+
+	if pj.Complete() {
+		return reconcile.Result{}
+	}
+
+	var reason, msg string
+	switch {
+	case ec.Spec.TearDown:
+		reason, msg = ephemeralclusterv1.TearDownRequested, "Tear down requested"
+	case ec.Status.ExpirationTime == nil:
+		return reconcile.Result{}
+	case !r.now().Before(ec.Status.ExpirationTime.Time):
+		reason, msg = ephemeralclusterv1.Expired, fmt.Sprintf("Lifetime expired at %s", ec.Status.ExpirationTime.UTC().Format(time.RFC3339))
+	default:
+		return reconcile.Result{RequeueAfter: ec.Status.ExpirationTime.Sub(r.now())}
+	}
+
+	upsertTearingDownCond := func(status ephemeralclusterv1.ConditionStatus, reason, msg string) {
+		r.upsertCondition(ec, ephemeralclusterv1.ClusterTearingDown, status, reason, msg)
+	}
+
+	if ec.Status.CIOperator.Namespace == "" {
+		upsertTearingDownCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorNamespaceNotFound, "The namespace ci-operator runs the workflow in has not been found yet")
+		return reconcile.Result{RequeueAfter: tearDownRetryInterval}
+	}
+	if err := r.createTestDoneSecret(ctx, ec.Status.CIOperator); err != nil {
+		upsertTearingDownCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.TearDownFailure, err.Error())
+		return reconcile.Result{RequeueAfter: tearDownRetryInterval}
+	}
+	upsertTearingDownCond(ephemeralclusterv1.ConditionTrue, reason, msg)
+	return reconcile.Result{}
+}
+
+// createTestDoneSecret unblocks the wait-test-complete step, which makes the workflow
+// run its post steps and deprovision the cluster.
+func (r *reconciler) createTestDoneSecret(ctx context.Context, ciOperator ephemeralclusterv1.CIOperatorStatus) error {
+	client, ok := r.buildClients[ciOperator.Cluster]
+	if !ok {
+		return fmt.Errorf("unknown build cluster %s", ciOperator.Cluster)
+	}
+	secret := &corev1.Secret{ObjectMeta: v1.ObjectMeta{Namespace: ciOperator.Namespace, Name: TestDoneSecretName}}
+	if err := client.Create(ctx, secret); err != nil && !kerrors.IsAlreadyExists(err) {
+		return fmt.Errorf("create secret %s/%s: %w", ciOperator.Namespace, TestDoneSecretName, err)
+	}
+	return nil
 }
 
 func (r *reconciler) handleGetProwJobError(err error) (reconcile.Result, error) {
@@ -121,12 +251,64 @@ func (r *reconciler) handleGetProwJobError(err error) (reconcile.Result, error)
 }
 
 func (r *reconciler) createProwJob(ctx context.Context, ec *ephemeralclusterv1.EphemeralCluster) {
+	upsertProvisioningCond := func(status ephemeralclusterv1.ConditionStatus, reason, msg string) {
+		r.upsertCondition(ec, ephemeralclusterv1.ClusterProvisioning, status, reason, msg)
+	}
+
+	ciOperatorConfig, releasePullSpecs, err := r.generateCIOperatorConfig(ec)
+	if err != nil {
+		err := fmt.Errorf("generate ci-operator config: %w", err)
+		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, err.Error())
+		return
+	}
+
+	pj, err := r.makeProwJob(ciOperatorConfig, ec.Spec.CIOperator.PullRequests)
+	if err != nil {
+		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, err.Error())
+		return
+	}
+
+	location, err := r.uploadCIOperatorConfig(ctx, ciOperatorConfig)
+	if err != nil {
+		err := fmt.Errorf("upload ci config: %w", err)
+		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, err.Error())
+		return
+	}
+	r.logger.WithField("Path", location).Info("Config uploaded to GCS")
+
+	if len(pj.Spec.PodSpec.Containers) != 1 {
+		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, "too many presubmit containers")
+		return
+	}
+	container := &pj.Spec.PodSpec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{Name: "CONFIG_SPEC_GCS_URL", Value: location})
+	for _, name := range slices.Sorted(maps.Keys(releasePullSpecs)) {
+		container.Env = append(container.Env, corev1.EnvVar{Name: utils.ReleaseImageEnv(name), Value: releasePullSpecs[name]})
+	}
+
+	if err := r.masterClient.Create(ctx, pj); err != nil {
+		err = fmt.Errorf("create prowjob: %w", err)
+		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, err.Error())
+		return
+	}
+
+	ec.Status.ProwJobID = pj.Name
+	upsertProvisioningCond(ephemeralclusterv1.ConditionTrue, "", "")
+}
+
+// generateCIOperatorConfig generates the configuration ci-operator provisions the cluster with,
+// along with the pull specs of the explicit release payloads, which ci-operator takes from the
+// environment. When pull requests are to be built into the payload, the configuration of the
+// repository they target is used to build them.
+func (r *reconciler) generateCIOperatorConfig(ec *ephemeralclusterv1.EphemeralCluster) (*api.ReleaseBuildConfiguration, map[string]string, error) {
+	releases, releasePullSpecs, err := ciOperatorReleases(ec.Spec.CIOperator.Releases)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	ciOperatorConfig := &api.ReleaseBuildConfiguration{
 		InputConfiguration: api.InputConfiguration{
-			Releases: map[string]api.UnresolvedRelease{
-				"initial": {Integration: &api.Integration{Name: "4.17", Namespace: "ocp"}},
-				"latest":  {Integration: &api.Integration{Name: "4.17", Namespace: "ocp"}},
-			},
+			Releases: releases,
 		},
 		Resources: api.ResourceConfiguration{
 			"*": api.ResourceRequirements{
@@ -135,7 +317,7 @@ func (r *reconciler) createProwJob(ctx context.Context, ec *ephemeralclusterv1.E
 			},
 		},
 		Tests: []api.TestStepConfiguration{{
-			As: "cluster-provisioning",
+			As: clusterProvisioningTest,
 			MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
 				Test: []api.LiteralTestStep{{
 					As:       "wait-test-complete",
@@ -151,49 +333,118 @@ func (r *reconciler) createProwJob(ctx context.Context, ec *ephemeralclusterv1.E
 		Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "branch"},
 	}
 
-	upsertProvisioningCond := func(status ephemeralclusterv1.ConditionStatus, reason, msg string) {
-		r.upsertCondition(ec, ephemeralclusterv1.ClusterProvisioning, status, reason, msg)
+	prs := ec.Spec.CIOperator.PullRequests
+	if len(prs) == 0 {
+		return ciOperatorConfig, releasePullSpecs, nil
 	}
 
-	pj, err := r.makeProwJob(ciOperatorConfig)
-	if err != nil {
-		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, err.Error())
-		return
+	latest, ok := releases[api.LatestReleaseName]
+	if !ok || latest.Integration == nil || releasePullSpecs[api.LatestReleaseName] != "" {
+		return nil, nil, fmt.Errorf("pull requests can only be built into the %s release assembled from an integration stream", api.LatestReleaseName)
 	}
+	latest.Integration.IncludeBuiltImages = true
 
-	location, err := r.uploadCIOperatorConfig(ctx, ciOperatorConfig)
+	metadata, err := metadataForPullRequests(prs)
 	if err != nil {
-		err := fmt.Errorf("upload ci config: %w", err)
-		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, err.Error())
-		return
+		return nil, nil, err
 	}
-	r.logger.WithField("Path", location).Info("Config uploaded to GCS")
+	if r.configResolver == nil {
+		return nil, nil, errors.New("no ci-operator configuration resolver to build pull requests with")
+	}
+	repoConfig, err := r.configResolver.Config(metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get ci-operator config for %s: %w", metadata.AsString(), err)
+	}
+	repoConfig.Releases = releases
+	repoConfig.ReleaseTagConfiguration = nil
+	repoConfig.PromotionConfiguration = nil
+	repoConfig.Tests = ciOperatorConfig.Tests
+	if _, ok := repoConfig.Resources["*"]; !ok {
+		if repoConfig.Resources == nil {
+			repoConfig.Resources = api.ResourceConfiguration{}
+		}
+		repoConfig.Resources["*"] = ciOperatorConfig.Resources["*"]
+	}
+	return repoConfig, releasePullSpecs, nil
+}
 
-	if len(pj.Spec.PodSpec.Containers) != 1 {
-		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, "too many presubmit containers")
-		return
+// ciOperatorReleases translates the releases of the spec into ci-operator's `releases` stanza.
+// Explicit pull specs replace a release assembled from the default integration stream.
+func ciOperatorReleases(releases map[string]ephemeralclusterv1.Release) (map[string]api.UnresolvedRelease, map[string]string, error) {
+	if len(releases) == 0 {
+		return map[string]api.UnresolvedRelease{
+			api.InitialReleaseName: {Integration: defaultIntegration()},
+			api.LatestReleaseName:  {Integration: defaultIntegration()},
+		}, nil, nil
 	}
-	container := &pj.Spec.PodSpec.Containers[0]
-	container.Env = append(container.Env, corev1.EnvVar{Name: "CONFIG_SPEC_GCS_URL", Value: location})
 
-	if err := r.masterClient.Create(ctx, pj); err != nil {
-		err = fmt.Errorf("create prowjob: %w", err)
-		upsertProvisioningCond(ephemeralclusterv1.ConditionFalse, ephemeralclusterv1.CIOperatorJobsGenerateFailure, err.Error())
-		return
+	ret, pullSpecs := make(map[string]api.UnresolvedRelease, len(releases)), make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(releases)) {
+		release, set := releases[name], 0
+		if release.Integration != nil {
+			set++
+			ret[name] = api.UnresolvedRelease{Integration: &api.Integration{Namespace: release.Integration.Namespace, Name: release.Integration.Name}}
+		}
+		if release.Candidate != nil {
+			set++
+			ret[name] = api.UnresolvedRelease{Candidate: &api.Candidate{
+				ReleaseDescriptor: releaseDescriptor(release.Candidate.Product, release.Candidate.Architecture, release.Candidate.Relative),
+				Stream:            api.ReleaseStream(release.Candidate.Stream),
+				Version:           release.Candidate.Version,
+			}}
+		}
+		if release.Prerelease != nil {
+			set++
+			ret[name] = api.UnresolvedRelease{Prerelease: &api.Prerelease{
+				ReleaseDescriptor: releaseDescriptor(release.Prerelease.Product, release.Prerelease.Architecture, release.Prerelease.Relative),
+				VersionBounds: api.VersionBounds{
+					Lower:  release.Prerelease.VersionBounds.Lower,
+					Upper:  release.Prerelease.VersionBounds.Upper,
+					Stream: release.Prerelease.VersionBounds.Stream,
+				},
+			}}
+		}
+		if release.PullSpec != "" {
+			set++
+			ret[name] = api.UnresolvedRelease{Integration: defaultIntegration()}
+			pullSpecs[name] = release.PullSpec
+		}
+		if set != 1 {
+			return nil, nil, fmt.Errorf("release %s: exactly one of integration, candidate, prerelease or pullSpec must be set", name)
+		}
 	}
+	return ret, pullSpecs, nil
+}
 
-	ec.Status.ProwJobID = pj.Name
-	upsertProvisioningCond(ephemeralclusterv1.ConditionTrue, "", "")
+func defaultIntegration() *api.Integration {
+	return &api.Integration{Name: "4.17", Namespace: "ocp"}
 }
 
-func (r *reconciler) makeProwJob(ciOperatorConfig *api.ReleaseBuildConfiguration) (*prowv1.ProwJob, error) {
-	jobConfig, err := prowgen.GenerateJobs(ciOperatorConfig, &prowgen.ProwgenInfo{
-		Metadata: api.Metadata{
-			Org:    "org",
-			Repo:   "repo",
-			Branch: "branch",
-		},
-	})
+func releaseDescriptor(product, architecture string, relative int) api.ReleaseDescriptor {
+	descriptor := api.ReleaseDescriptor{
+		Product:      api.ReleaseProduct(product),
+		Architecture: api.ReleaseArchitecture(architecture),
+		Relative:     relative,
+	}
+	if descriptor.Product == "" {
+		descriptor.Product = api.ReleaseProductOCP
+	}
+	return descriptor
+}
+
+// metadataForPullRequests determines the repository and branch the pull requests target
+func metadataForPullRequests(prs []ephemeralclusterv1.PullRequest) (*api.Metadata, error) {
+	first := prs[0]
+	for _, pr := range prs[1:] {
+		if pr.Org != first.Org || pr.Repo != first.Repo || pr.BaseRef != first.BaseRef || pr.BaseSHA != first.BaseSHA {
+			return nil, fmt.Errorf("pull requests must target the same repository, branch and base commit: %s/%s@%s (%s) and %s/%s@%s (%s)", first.Org, first.Repo, first.BaseRef, first.BaseSHA, pr.Org, pr.Repo, pr.BaseRef, pr.BaseSHA)
+		}
+	}
+	return &api.Metadata{Org: first.Org, Repo: first.Repo, Branch: first.BaseRef}, nil
+}
+
+func (r *reconciler) makeProwJob(ciOperatorConfig *api.ReleaseBuildConfiguration, prs []ephemeralclusterv1.PullRequest) (*prowv1.ProwJob, error) {
+	jobConfig, err := prowgen.GenerateJobs(ciOperatorConfig, &prowgen.ProwgenInfo{Metadata: ciOperatorConfig.Metadata})
 	if err != nil {
 		return nil, fmt.Errorf("generate jobs: %w", err)
 	}
@@ -204,10 +455,11 @@ func (r *reconciler) makeProwJob(ciOperatorConfig *api.ReleaseBuildConfiguration
 		return nil, errors.New("no presubmits generated")
 	}
 
+	jobName := ciOperatorConfig.Metadata.JobName(jobconfig.PresubmitPrefix, clusterProvisioningTest)
 	var presubmit *prowconfig.Presubmit
 	for i := range presubs {
 		p := &presubs[i]
-		if p.Name == "pull-ci-org-repo-branch-cluster-provisioning" {
+		if p.Name == jobName {
 			presubmit = p
 			break
 		}
@@ -220,6 +472,20 @@ func (r *reconciler) makeProwJob(ciOperatorConfig *api.ReleaseBuildConfiguration
 	pj.Spec.Refs = nil
 	pj.Spec.Report = false
 
+	if len(prs) > 0 {
+		refs := &prowv1.Refs{
+			Org:       prs[0].Org,
+			Repo:      prs[0].Repo,
+			BaseRef:   prs[0].BaseRef,
+			BaseSHA:   prs[0].BaseSHA,
+			PathAlias: ciOperatorConfig.DeterminePathAlias(prs[0].Org, prs[0].Repo),
+		}
+		for _, pr := range prs {
+			refs.Pulls = append(refs.Pulls, prowv1.Pull{Number: pr.Number, SHA: pr.SHA, Author: pr.Author, Title: pr.Title})
+		}
+		pj.Spec.Refs = refs
+	}
+
 	return &pj, nil
 }
 
@@ -2,12 +2,18 @@ package ephemeralcluster
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -20,21 +26,37 @@ import (
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/pjutil"
 
+	"github.com/openshift/ci-tools/pkg/api"
 	ephemeralclusterv1 "github.com/openshift/ci-tools/pkg/api/ephemeralcluster/v1"
+	"github.com/openshift/ci-tools/pkg/steps"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
 type fakeGCSUploader struct {
-	err error
+	err     error
+	content string
 }
 
-func (u *fakeGCSUploader) UploadConfigSpec(context.Context, string, string) (string, error) {
+func (u *fakeGCSUploader) UploadConfigSpec(_ context.Context, _, content string) (string, error) {
 	if u.err != nil {
 		return "", u.err
 	}
+	u.content = content
 	return "gs://fake/gcs/path", nil
 }
 
+type fakeConfigResolver struct {
+	configs map[string]api.ReleaseBuildConfiguration
+}
+
+func (r *fakeConfigResolver) Config(info *api.Metadata) (*api.ReleaseBuildConfiguration, error) {
+	config, ok := r.configs[info.AsString()]
+	if !ok {
+		return nil, fmt.Errorf("config for %s not found", info.AsString())
+	}
+	return &config, nil
+}
+
 func newPresubmitFaker(name string, now time.Time) NewPresubmitFunc {
 	return func(pr github.PullRequest, baseSHA string, job prowconfig.Presubmit, eventGUID string, additionalLabels map[string]string, modifiers ...pjutil.Modifier) prowv1.ProwJob {
 		pj := pjutil.NewPresubmit(pr, baseSHA, job, eventGUID, additionalLabels, modifiers...)
@@ -62,6 +84,7 @@ func TestCreateProwJob(t *testing.T) {
 		req             reconcile.Request
 		interceptors    interceptor.Funcs
 		configUploadErr error
+		configResolver  ConfigResolver
 	}{
 		{
 			name: "An EphemeralCluster request creates a ProwJob",
@@ -121,6 +144,111 @@ func TestCreateProwJob(t *testing.T) {
 			}},
 			req: reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "ec"}},
 		},
+		{
+			name: "Releases are translated into the ci-operator config",
+			ec: ephemeralclusterv1.EphemeralCluster{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "ns",
+					Name:      "ec",
+				},
+				Spec: ephemeralclusterv1.EphemeralClusterSpec{
+					CIOperator: ephemeralclusterv1.CIOperatorSpec{
+						Workflow: ephemeralclusterv1.Workflow{
+							Name: "test-workflow",
+						},
+						Releases: map[string]ephemeralclusterv1.Release{
+							"initial": {PullSpec: "quay.io/openshift-release-dev/ocp-release:4.18.1-x86_64"},
+							"latest":  {Candidate: &ephemeralclusterv1.Candidate{Stream: "nightly", Version: "4.19"}},
+							"previous": {Prerelease: &ephemeralclusterv1.Prerelease{
+								Architecture:  "arm64",
+								VersionBounds: ephemeralclusterv1.VersionBounds{Lower: "4.17.0-0", Upper: "4.18.0-0"},
+							}},
+						},
+					},
+				},
+			},
+			req: reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "ec"}},
+		},
+		{
+			name: "A release selecting more than one payload is rejected",
+			ec: ephemeralclusterv1.EphemeralCluster{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "ns",
+					Name:      "ec",
+				},
+				Spec: ephemeralclusterv1.EphemeralClusterSpec{
+					CIOperator: ephemeralclusterv1.CIOperatorSpec{
+						Workflow: ephemeralclusterv1.Workflow{
+							Name: "test-workflow",
+						},
+						Releases: map[string]ephemeralclusterv1.Release{
+							"latest": {
+								PullSpec:  "quay.io/openshift-release-dev/ocp-release:4.18.1-x86_64",
+								Candidate: &ephemeralclusterv1.Candidate{Stream: "nightly", Version: "4.19"},
+							},
+						},
+					},
+				},
+			},
+			req: reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "ec"}},
+		},
+		{
+			name: "Pull requests are built into the payload",
+			ec: ephemeralclusterv1.EphemeralCluster{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "ns",
+					Name:      "ec",
+				},
+				Spec: ephemeralclusterv1.EphemeralClusterSpec{
+					CIOperator: ephemeralclusterv1.CIOperatorSpec{
+						Workflow: ephemeralclusterv1.Workflow{
+							Name: "test-workflow",
+						},
+						PullRequests: []ephemeralclusterv1.PullRequest{
+							{Org: "openshift", Repo: "installer", BaseRef: "main", BaseSHA: "base", Number: 1, SHA: "one", Author: "alice"},
+							{Org: "openshift", Repo: "installer", BaseRef: "main", BaseSHA: "base", Number: 2, SHA: "two", Author: "bob"},
+						},
+					},
+				},
+			},
+			configResolver: &fakeConfigResolver{configs: map[string]api.ReleaseBuildConfiguration{
+				"openshift/installer@main": {
+					InputConfiguration: api.InputConfiguration{
+						BuildRootImage:          &api.BuildRootImageConfiguration{ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "ocp", Name: "builder", Tag: "golang"}},
+						ReleaseTagConfiguration: &api.ReleaseTagConfiguration{Namespace: "ocp", Name: "4.17"},
+					},
+					Images:                 []api.ProjectDirectoryImageBuildStepConfiguration{{To: "installer"}},
+					PromotionConfiguration: &api.PromotionConfiguration{Targets: []api.PromotionTarget{{Namespace: "ocp", Name: "4.17"}}},
+					Tests:                  []api.TestStepConfiguration{{As: "unit", Commands: "make test", ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"}}},
+					Metadata:               api.Metadata{Org: "openshift", Repo: "installer", Branch: "main"},
+				},
+			}},
+			req: reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "ec"}},
+		},
+		{
+			name: "Pull requests cannot be built into an explicit payload",
+			ec: ephemeralclusterv1.EphemeralCluster{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "ns",
+					Name:      "ec",
+				},
+				Spec: ephemeralclusterv1.EphemeralClusterSpec{
+					CIOperator: ephemeralclusterv1.CIOperatorSpec{
+						Workflow: ephemeralclusterv1.Workflow{
+							Name: "test-workflow",
+						},
+						Releases: map[string]ephemeralclusterv1.Release{
+							"latest": {PullSpec: "quay.io/openshift-release-dev/ocp-release:4.18.1-x86_64"},
+						},
+						PullRequests: []ephemeralclusterv1.PullRequest{
+							{Org: "openshift", Repo: "installer", BaseRef: "main", BaseSHA: "base", Number: 1, SHA: "one"},
+						},
+					},
+				},
+			},
+			configResolver: &fakeConfigResolver{},
+			req:            reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "ec"}},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			client := fake.NewClientBuilder().
@@ -129,12 +257,14 @@ func TestCreateProwJob(t *testing.T) {
 				WithInterceptorFuncs(tc.interceptors).
 				Build()
 
+			uploader := &fakeGCSUploader{err: tc.configUploadErr}
 			r := reconciler{
 				logger:         logrus.NewEntry(logrus.StandardLogger()),
 				masterClient:   client,
 				now:            func() time.Time { return fakeNow },
 				newPresubmit:   newPresubmitFaker("foobar", fakeNow),
-				configUploader: &fakeGCSUploader{err: tc.configUploadErr},
+				configUploader: uploader,
+				configResolver: tc.configResolver,
 			}
 
 			_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: tc.ec.Namespace, Name: tc.ec.Name}})
@@ -155,6 +285,283 @@ func TestCreateProwJob(t *testing.T) {
 			}
 
 			testhelper.CompareWithFixture(t, pjs, testhelper.WithPrefix("pj-"))
+			testhelper.CompareWithFixture(t, uploader.content, testhelper.WithPrefix("config-"))
 		})
 	}
 }
+
+func TestReconcileLifetime(t *testing.T) {
+	fakeNow, err := time.Parse("2006-01-02 15:04:05", "2025-04-02 12:12:12")
+	if err != nil {
+		t.Fatalf("parse fake now: %s", err)
+	}
+
+	scheme := runtime.NewScheme()
+	sb := runtime.NewSchemeBuilder(ephemeralclusterv1.AddToScheme, prowv1.AddToScheme, corev1.AddToScheme)
+	if err := sb.AddToScheme(scheme); err != nil {
+		t.Fatal("build scheme")
+	}
+
+	ec := func(created time.Time, mutate func(*ephemeralclusterv1.EphemeralCluster)) *ephemeralclusterv1.EphemeralCluster {
+		ec := &ephemeralclusterv1.EphemeralCluster{
+			ObjectMeta: v1.ObjectMeta{Namespace: "ns", Name: "ec", CreationTimestamp: v1.NewTime(created)},
+			Spec: ephemeralclusterv1.EphemeralClusterSpec{
+				CIOperator: ephemeralclusterv1.CIOperatorSpec{Workflow: ephemeralclusterv1.Workflow{Name: "test-workflow"}},
+			},
+			Status: ephemeralclusterv1.EphemeralClusterStatus{ProwJobID: "foobar"},
+		}
+		if mutate != nil {
+			mutate(ec)
+		}
+		return ec
+	}
+	pj := func(state prowv1.ProwJobState) *prowv1.ProwJob {
+		pj := &prowv1.ProwJob{
+			ObjectMeta: v1.ObjectMeta{Namespace: ProwJobNamespace, Name: "foobar"},
+			Spec:       prowv1.ProwJobSpec{Cluster: "build01"},
+			Status:     prowv1.ProwJobStatus{State: state},
+		}
+		if state == prowv1.SuccessState {
+			pj.Status.CompletionTime = &v1.Time{Time: fakeNow}
+		}
+		return pj
+	}
+	ciOperatorNS := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "ci-op-1234", Labels: map[string]string{steps.LabelJobID: "foobar"}}}
+	ciOperatorStatus := ephemeralclusterv1.CIOperatorStatus{Cluster: "build01", Namespace: "ci-op-1234"}
+
+	for _, tc := range []struct {
+		name         string
+		ec           *ephemeralclusterv1.EphemeralCluster
+		pj           *prowv1.ProwJob
+		buildObjects []ctrlclient.Object
+		wantResult   reconcile.Result
+		wantStatus   ephemeralclusterv1.EphemeralClusterStatus
+		wantTestDone bool
+	}{
+		{
+			name:         "A cluster alive is requeued on expiration",
+			ec:           ec(fakeNow.Add(-time.Hour), nil),
+			pj:           pj(prowv1.PendingState),
+			buildObjects: []ctrlclient.Object{ciOperatorNS},
+			wantResult:   reconcile.Result{RequeueAfter: 3 * time.Hour},
+			wantStatus: ephemeralclusterv1.EphemeralClusterStatus{
+				ProwJobID:      "foobar",
+				CIOperator:     ciOperatorStatus,
+				ExpirationTime: &v1.Time{Time: fakeNow.Add(3 * time.Hour)},
+			},
+		},
+		{
+			name: "An extension beyond the maximum TTL is ignored",
+			ec: ec(fakeNow.Add(-time.Hour), func(ec *ephemeralclusterv1.EphemeralCluster) {
+				ec.Spec.TTL = &v1.Duration{Duration: 48 * time.Hour}
+				ec.Status.ExpirationTime = &v1.Time{Time: fakeNow.Add(3 * time.Hour)}
+			}),
+			pj:         pj(prowv1.PendingState),
+			wantResult: reconcile.Result{RequeueAfter: 3 * time.Hour},
+			wantStatus: ephemeralclusterv1.EphemeralClusterStatus{
+				ProwJobID:      "foobar",
+				ExpirationTime: &v1.Time{Time: fakeNow.Add(3 * time.Hour)},
+			},
+		},
+		{
+			name: "A cluster with a negative TTL is not provisioned",
+			ec: ec(fakeNow, func(ec *ephemeralclusterv1.EphemeralCluster) {
+				ec.Spec.TTL = &v1.Duration{Duration: -time.Hour}
+				ec.Status.ProwJobID = ""
+			}),
+			pj: pj(prowv1.PendingState),
+			wantStatus: ephemeralclusterv1.EphemeralClusterStatus{
+				Conditions: []ephemeralclusterv1.EphemeralClusterCondition{{
+					Type:               ephemeralclusterv1.ClusterProvisioning,
+					Status:             ephemeralclusterv1.ConditionFalse,
+					LastTransitionTime: v1.NewTime(fakeNow),
+					Reason:             ephemeralclusterv1.InvalidTTL,
+					Message:            "ttl: -1h0m0s must not be negative",
+				}},
+			},
+		},
+		{
+			name: "A cluster about to expire is requeued on expiration",
+			ec: ec(fakeNow.Add(-time.Hour), func(ec *ephemeralclusterv1.EphemeralCluster) {
+				ec.Spec.TTL = &v1.Duration{Duration: time.Hour + 30*time.Second}
+			}),
+			pj:         pj(prowv1.PendingState),
+			wantResult: reconcile.Result{RequeueAfter: 30 * time.Second},
+			wantStatus: ephemeralclusterv1.EphemeralClusterStatus{
+				ProwJobID:      "foobar",
+				ExpirationTime: &v1.Time{Time: fakeNow.Add(30 * time.Second)},
+			},
+		},
+		{
+			name:         "An expired cluster is torn down",
+			ec:           ec(fakeNow.Add(-5*time.Hour), nil),
+			pj:           pj(prowv1.PendingState),
+			buildObjects: []ctrlclient.Object{ciOperatorNS},
+			wantStatus: ephemeralclusterv1.EphemeralClusterStatus{
+				ProwJobID:      "foobar",
+				CIOperator:     ciOperatorStatus,
+				ExpirationTime: &v1.Time{Time: fakeNow.Add(-time.Hour)},
+				Conditions: []ephemeralclusterv1.EphemeralClusterCondition{{
+					Type:               ephemeralclusterv1.ClusterTearingDown,
+					Status:             ephemeralclusterv1.ConditionTrue,
+					LastTransitionTime: v1.NewTime(fakeNow),
+					Reason:             ephemeralclusterv1.Expired,
+					Message:            "Lifetime expired at 2025-04-02T11:12:12Z",
+				}},
+			},
+			wantTestDone: true,
+		},
+		{
+			name: "A tear down is requested",
+			ec: ec(fakeNow.Add(-time.Hour), func(ec *ephemeralclusterv1.EphemeralCluster) {
+				ec.Spec.TearDown = true
+			}),
+			pj:           pj(prowv1.PendingState),
+			buildObjects: []ctrlclient.Object{ciOperatorNS},
+			wantStatus: ephemeralclusterv1.EphemeralClusterStatus{
+				ProwJobID:      "foobar",
+				CIOperator:     ciOperatorStatus,
+				ExpirationTime: &v1.Time{Time: fakeNow.Add(3 * time.Hour)},
+				Conditions: []ephemeralclusterv1.EphemeralClusterCondition{{
+					Type:               ephemeralclusterv1.ClusterTearingDown,
+					Status:             ephemeralclusterv1.ConditionTrue,
+					LastTransitionTime: v1.NewTime(fakeNow),
+					Reason:             ephemeralclusterv1.TearDownRequested,
+					Message:            "Tear down requested",
+				}},
+			},
+			wantTestDone: true,
+		},
+		{
+			name: "A tear down waits for the ci-operator namespace",
+			ec: ec(fakeNow.Add(-time.Hour), func(ec *ephemeralclusterv1.EphemeralCluster) {
+				ec.Spec.TearDown = true
+			}),
+			pj:         pj(prowv1.PendingState),
+			wantResult: reconcile.Result{RequeueAfter: time.Minute},
+			wantStatus: ephemeralclusterv1.EphemeralClusterStatus{
+				ProwJobID:      "foobar",
+				ExpirationTime: &v1.Time{Time: fakeNow.Add(3 * time.Hour)},
+				Conditions: []ephemeralclusterv1.EphemeralClusterCondition{{
+					Type:               ephemeralclusterv1.ClusterTearingDown,
+					Status:             ephemeralclusterv1.ConditionFalse,
+					LastTransitionTime: v1.NewTime(fakeNow),
+					Reason:             ephemeralclusterv1.CIOperatorNamespaceNotFound,
+					Message:            "The namespace ci-operator runs the workflow in has not been found yet",
+				}},
+			},
+		},
+		{
+			name: "Nothing is torn down once the ProwJob completes",
+			ec: ec(fakeNow.Add(-5*time.Hour), func(ec *ephemeralclusterv1.EphemeralCluster) {
+				ec.Status.CIOperator = ciOperatorStatus
+			}),
+			pj: pj(prowv1.SuccessState),
+			wantStatus: ephemeralclusterv1.EphemeralClusterStatus{
+				ProwJobID:      "foobar",
+				CIOperator:     ciOperatorStatus,
+				ExpirationTime: &v1.Time{Time: fakeNow.Add(-time.Hour)},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewClientBuilder().WithObjects(tc.ec, tc.pj).WithScheme(scheme).Build()
+			buildClient := fake.NewClientBuilder().WithObjects(tc.buildObjects...).WithScheme(scheme).Build()
+
+			r := reconciler{
+				logger:       logrus.NewEntry(logrus.StandardLogger()),
+				masterClient: client,
+				buildClients: map[string]ctrlclient.Client{"build01": buildClient},
+				now:          func() time.Time { return fakeNow },
+			}
+
+			result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: tc.ec.Namespace, Name: tc.ec.Name}})
+			if err != nil {
+				t.Fatalf("unexpected reconcile error: %s", err)
+			}
+			if diff := cmp.Diff(tc.wantResult, result); diff != "" {
+				t.Errorf("unexpected result: %s", diff)
+			}
+
+			gotEC := ephemeralclusterv1.EphemeralCluster{}
+			if err := client.Get(context.TODO(), types.NamespacedName{Namespace: tc.ec.Namespace, Name: tc.ec.Name}, &gotEC); err != nil {
+				t.Fatalf("unexpected get ephemeralcluster error: %s", err)
+			}
+			if diff := cmp.Diff(tc.wantStatus, gotEC.Status); diff != "" {
+				t.Errorf("unexpected status: %s", diff)
+			}
+
+			secret := corev1.Secret{}
+			err = buildClient.Get(context.TODO(), types.NamespacedName{Namespace: "ci-op-1234", Name: TestDoneSecretName}, &secret)
+			if gotTestDone := err == nil; gotTestDone != tc.wantTestDone {
+				t.Errorf("expected the test done secret to exist: %t, got error: %v", tc.wantTestDone, err)
+			}
+		})
+	}
+}
+
+func TestMetadataForPullRequests(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prs      []ephemeralclusterv1.PullRequest
+		wantMeta *api.Metadata
+		wantErr  error
+	}{
+		{
+			name: "Pull requests on the same base",
+			prs: []ephemeralclusterv1.PullRequest{
+				{Org: "openshift", Repo: "installer", BaseRef: "main", BaseSHA: "base", Number: 1},
+				{Org: "openshift", Repo: "installer", BaseRef: "main", BaseSHA: "base", Number: 2},
+			},
+			wantMeta: &api.Metadata{Org: "openshift", Repo: "installer", Branch: "main"},
+		},
+		{
+			name: "Pull requests on different base commits",
+			prs: []ephemeralclusterv1.PullRequest{
+				{Org: "openshift", Repo: "installer", BaseRef: "main", BaseSHA: "base", Number: 1},
+				{Org: "openshift", Repo: "installer", BaseRef: "main", BaseSHA: "other", Number: 2},
+			},
+			wantErr: errors.New("pull requests must target the same repository, branch and base commit: openshift/installer@main (base) and openshift/installer@main (other)"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			meta, err := metadataForPullRequests(tc.prs)
+			if diff := cmp.Diff(tc.wantErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantMeta, meta); diff != "" {
+				t.Errorf("unexpected metadata: %s", diff)
+			}
+		})
+	}
+}
+
+func TestNewReconcilerResolvesConfigs(t *testing.T) {
+	expected := api.ReleaseBuildConfiguration{
+		Metadata:  api.Metadata{Org: "openshift", Repo: "installer", Branch: "main"},
+		Resources: api.ResourceConfiguration{"*": {Requests: api.ResourceList{"cpu": "100m"}}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if req.URL.Path != "/config" || query.Get("org") != "openshift" || query.Get("repo") != "installer" || query.Get("branch") != "main" {
+			http.Error(w, fmt.Sprintf("unexpected request %s", req.URL), http.StatusNotFound)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(expected); err != nil {
+			t.Errorf("failed to encode config: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	r := newReconciler(logrus.NewEntry(logrus.StandardLogger()), fake.NewClientBuilder().Build(), nil, Options{ConfigResolverAddress: server.URL})
+	if r.configResolver == nil {
+		t.Fatal("expected the reconciler to have a configuration resolver")
+	}
+	config, err := r.configResolver.Config(&api.Metadata{Org: "openshift", Repo: "installer", Branch: "main"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&expected, config); diff != "" {
+		t.Errorf("unexpected config: %s", diff)
+	}
+}
@@ -1,8 +1,9 @@
 #!/bin/bash
 
 # This loop keeps the ephemeral cluster up and running and then waits for
-# a konflux test to complete. Once the test is done, the EphemeralCluster 
-# controller creates a synthetic secret 'test-done-keep-going' into this ci-operator NS,
+# a konflux test to complete. Once the test is done, the lifetime of the cluster
+# is over or a tear down is requested, the EphemeralCluster controller creates
+# a synthetic secret 'test-done-keep-going' into this ci-operator NS,
 # unbloking the workflow and starting the deprovisioning procedures.
 
 i=0
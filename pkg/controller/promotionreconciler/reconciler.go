@@ -29,6 +29,8 @@ import (
 	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
 	"github.com/openshift/ci-tools/pkg/load/agents"
 	"github.com/openshift/ci-tools/pkg/promotion"
+	"github.com/openshift/ci-tools/pkg/promotion/gates"
+	"github.com/openshift/ci-tools/pkg/promotion/rollback"
 	"github.com/openshift/ci-tools/pkg/steps/release"
 	"github.com/openshift/ci-tools/pkg/util/imagestreamtagmapper"
 	"github.com/openshift/ci-tools/pkg/util/imagestreamtagwrapper"
@@ -50,6 +52,10 @@ type Options struct {
 
 const ControllerName = "promotionreconciler"
 
+// gatesRequeueInterval is how long to wait before checking the promotion gates
+// of a stale imagestreamtag again when they are not satisfied
+const gatesRequeueInterval = 10 * time.Minute
+
 func AddToManager(mgr controllerruntime.Manager, opts Options) error {
 	// Pre-Allocate the Image informer rather than letting it allocate on demand, because
 	// starting the watch takes very long (~2 minutes) and having that delay added to our
@@ -86,7 +92,9 @@ func AddToManager(mgr controllerruntime.Manager, opts Options) error {
 		releaseBuildConfigs: releaseBuildConfigs,
 		gitHubClient:        opts.GitHubClient,
 		enqueueJob:          prowJobEnqueuer,
+		prowJobs:            gates.ClientProwJobLister(mgr.GetClient(), opts.ConfigGetter().ProwJobNamespace),
 		since:               opts.Since,
+		dryRun:              opts.DryRun,
 	}
 	c, err := controller.New(ControllerName, opts.RegistryManager, controller.Options{
 		Reconciler: r,
@@ -140,7 +148,9 @@ type reconciler struct {
 	releaseBuildConfigs ciOperatorConfigGetter
 	gitHubClient        githubClient
 	enqueueJob          prowjobreconciler.Enqueuer
+	prowJobs            gates.ProwJobLister
 	since               time.Duration
+	dryRun              bool
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req controllerruntime.Request) (controllerruntime.Result, error) {
@@ -149,7 +159,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req controllerruntime.Reques
 	startTime := time.Now()
 	defer func() { log.WithField("duration", time.Since(startTime)).Trace("Finished reconciliation") }()
 
-	err := r.reconcile(ctx, req, log)
+	result, err := r.reconcile(ctx, req, log)
 	if err != nil {
 		log := log.WithError(err)
 		// Degrade terminal errors to debug, they most lilely just mean a given imageStreamTag wasn't built
@@ -161,54 +171,70 @@ func (r *reconciler) Reconcile(ctx context.Context, req controllerruntime.Reques
 		}
 	}
 
-	return controllerruntime.Result{}, controllerutil.SwallowIfTerminal(err)
+	return result, controllerutil.SwallowIfTerminal(err)
 }
 
-func (r *reconciler) reconcile(ctx context.Context, req controllerruntime.Request, log *logrus.Entry) error {
+func (r *reconciler) reconcile(ctx context.Context, req controllerruntime.Request, log *logrus.Entry) (controllerruntime.Result, error) {
 	ist := &imagev1.ImageStreamTag{}
 	if err := r.client.Get(ctx, req.NamespacedName, ist); err != nil {
 		// Object got deleted while it was in the workqueue
 		if apierrors.IsNotFound(err) {
-			return nil
+			return controllerruntime.Result{}, nil
 		}
-		return fmt.Errorf("failed to get object: %w", err)
+		return controllerruntime.Result{}, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	if rolledBack, err := r.enforceRollback(ctx, ist, log); err != nil || rolledBack {
+		return controllerruntime.Result{}, err
 	}
 
 	if !ist.CreationTimestamp.After(time.Now().Add(-r.since)) {
 		log.WithField("creationTimestamp", ist.CreationTimestamp).Trace("Ignored old imageStreamTag")
-		return nil
+		return controllerruntime.Result{}, nil
 	}
 
 	ciOPConfig, err := r.promotionConfig(ist)
 	if err != nil {
-		return fmt.Errorf("failed to get promotionConfig: %w", err)
+		return controllerruntime.Result{}, fmt.Errorf("failed to get promotionConfig: %w", err)
 	}
 	if ciOPConfig == nil || !promotion.AllPromotionImageStreamTags(ciOPConfig).Has(req.String()) {
 		// We don't know how to build this
 		log.Trace("No promotionConfig found")
-		return nil
+		return controllerruntime.Result{}, nil
 	}
 	log = log.WithField("org", ciOPConfig.Metadata.Org).WithField("repo", ciOPConfig.Metadata.Repo).WithField("branch", ciOPConfig.Metadata.Branch)
 
 	istCommit, err := commitForIST(ist, r.client)
 	if err != nil {
-		return controllerutil.TerminalError(fmt.Errorf("failed to get commit for imageStreamTag: %w", err))
+		return controllerruntime.Result{}, controllerutil.TerminalError(fmt.Errorf("failed to get commit for imageStreamTag: %w", err))
 	}
 	log = log.WithField("istCommit", istCommit)
 
 	currentHEAD, found, err := r.currentHEADForBranch(ciOPConfig.Metadata, log)
 	if err != nil {
-		return fmt.Errorf("failed to get current git head for imageStreamTag: %w", err)
+		return controllerruntime.Result{}, fmt.Errorf("failed to get current git head for imageStreamTag: %w", err)
 	}
 	if !found {
-		return controllerutil.TerminalError(fmt.Errorf("got 404 for %s/%s/%s from github, this likely means the repo or branch got deleted or we are not allowed to access it", ciOPConfig.Metadata.Org, ciOPConfig.Metadata.Repo, ciOPConfig.Metadata.Branch))
+		return controllerruntime.Result{}, controllerutil.TerminalError(fmt.Errorf("got 404 for %s/%s/%s from github, this likely means the repo or branch got deleted or we are not allowed to access it", ciOPConfig.Metadata.Org, ciOPConfig.Metadata.Repo, ciOPConfig.Metadata.Branch))
 	}
 	// ImageStreamTag is current, nothing to do
 	if currentHEAD == istCommit {
-		return nil
+		return controllerruntime.Result{}, nil
 	}
 	log = log.WithField("currentHEAD", currentHEAD)
 
+	if promotionGates := gatesFor(ciOPConfig, req.String()); promotionGates != nil {
+		prowJobs, err := r.prowJobs(ctx, ciOPConfig.Metadata.Org, ciOPConfig.Metadata.Repo)
+		if err != nil {
+			return controllerruntime.Result{}, fmt.Errorf("failed to list prowjobs: %w", err)
+		}
+		if err := gates.Check(ciOPConfig.Metadata, promotionGates, currentHEAD, prowJobs); err != nil {
+			log.WithError(err).Info("Not requesting prowjob creation for a stale imagestreamtag, as its promotion gates are not satisfied")
+			// the gated jobs may still be running, check again later
+			return controllerruntime.Result{RequeueAfter: gatesRequeueInterval}, nil
+		}
+	}
+
 	log.Info("Requesting prowjob creation for a stale imagestreamtag")
 	r.enqueueJob(prowjobreconciler.OrgRepoBranchCommit{
 		Org:    ciOPConfig.Metadata.Org,
@@ -216,6 +242,62 @@ func (r *reconciler) reconcile(ctx context.Context, req controllerruntime.Reques
 		Branch: ciOPConfig.Metadata.Branch,
 		Commit: currentHEAD,
 	})
+	return controllerruntime.Result{}, nil
+}
+
+// enforceRollback keeps a rolled back imagestreamtag pointing to the digest it was rolled back to,
+// as nothing is promoted to it until the rollback is cleared
+func (r *reconciler) enforceRollback(ctx context.Context, ist *imagev1.ImageStreamTag, log *logrus.Entry) (bool, error) {
+	name, tag, found := strings.Cut(ist.Name, ":")
+	if !found {
+		return false, controllerutil.TerminalError(fmt.Errorf("invalid imagestreamtag name %s", ist.Name))
+	}
+	stream := &imagev1.ImageStream{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: ist.Namespace, Name: name}, stream); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get imagestream: %w", err)
+	}
+	records, err := rollback.Records(stream)
+	if err != nil {
+		return false, controllerutil.TerminalError(err)
+	}
+	record, rolledBack := records[tag]
+	if !rolledBack {
+		return false, nil
+	}
+	log = log.WithField("digest", record.Digest)
+	if r.dryRun {
+		log.Debug("Not enforcing the rollback of the imagestreamtag in dry-run mode")
+		return true, nil
+	}
+	enforced, err := rollback.Enforce(ctx, r.client, stream, tag, record)
+	if err != nil {
+		return true, fmt.Errorf("failed to enforce rollback: %w", err)
+	}
+	if enforced {
+		log.Info("Pointed a rolled back imagestreamtag back to the digest it was rolled back to")
+	} else {
+		log.Debug("Not requesting prowjob creation for a rolled back imagestreamtag")
+	}
+	return true, nil
+}
+
+// gatesFor returns the gates of the promotion target the imagestreamtag is promoted by
+func gatesFor(ciOPConfig *cioperatorapi.ReleaseBuildConfiguration, istName string) *cioperatorapi.PromotionGates {
+	for _, target := range cioperatorapi.PromotionTargets(ciOPConfig.PromotionConfiguration) {
+		if target.Gates == nil {
+			continue
+		}
+		single := *ciOPConfig
+		single.PromotionConfiguration = &cioperatorapi.PromotionConfiguration{Targets: []cioperatorapi.PromotionTarget{target}}
+		for _, tag := range release.PromotedTags(&single) {
+			if tag.ISTagName() == istName {
+				return target.Gates
+			}
+		}
+	}
 	return nil
 }
 
@@ -19,6 +19,7 @@ import (
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/yaml"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/controller/promotionreconciler/prowjobreconciler"
 	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
 	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/promotion/rollback"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
@@ -99,6 +101,10 @@ func TestReconcile(t *testing.T) {
 		name              string
 		githubClient      func(owner, repo, ref string) (string, error)
 		promotionDisabled bool
+		gates             *cioperatorapi.PromotionGates
+		prowJobs          []prowv1.ProwJob
+		rolledBack        bool
+		expectedResult    reconcile.Result
 		verify            func(error, *prowjobreconciler.OrgRepoBranchCommit) error
 	}{
 		{
@@ -182,6 +188,57 @@ func TestReconcile(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name:         "Ist outdated, promotion gated on a test that passed, prowjob created",
+			githubClient: func(_, _, _ string) (string, error) { return "newer", nil },
+			gates:        &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e"}},
+			prowJobs: []prowv1.ProwJob{{
+				Spec:   prowv1.ProwJobSpec{Type: prowv1.PostsubmitJob, Job: "branch-ci-ci-op-org-ci-op-repo-ci-op-branch-e2e", Refs: &prowv1.Refs{BaseRef: ciOpBranch, BaseSHA: "newer"}},
+				Status: prowv1.ProwJobStatus{State: prowv1.SuccessState, CompletionTime: &metav1.Time{}},
+			}},
+			verify: func(e error, req *prowjobreconciler.OrgRepoBranchCommit) error {
+				if e != nil {
+					return fmt.Errorf("expected error to be nil, was %w", e)
+				}
+				if req == nil {
+					return errors.New("expected to get request, was nil")
+				}
+				return nil
+			},
+		},
+		{
+			name:         "Ist outdated, promotion gated on a test that failed, no prowjob created",
+			githubClient: func(_, _, _ string) (string, error) { return "newer", nil },
+			gates:        &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e"}},
+			prowJobs: []prowv1.ProwJob{{
+				Spec:   prowv1.ProwJobSpec{Type: prowv1.PostsubmitJob, Job: "branch-ci-ci-op-org-ci-op-repo-ci-op-branch-e2e", Refs: &prowv1.Refs{BaseRef: ciOpBranch, BaseSHA: "newer"}},
+				Status: prowv1.ProwJobStatus{State: prowv1.FailureState, CompletionTime: &metav1.Time{}},
+			}},
+			expectedResult: reconcile.Result{RequeueAfter: gatesRequeueInterval},
+			verify: func(e error, req *prowjobreconciler.OrgRepoBranchCommit) error {
+				if e != nil {
+					return fmt.Errorf("expected error to be nil, was %w", e)
+				}
+				if req != nil {
+					return fmt.Errorf("expected no request, got %v", req)
+				}
+				return nil
+			},
+		},
+		{
+			name:         "Ist outdated, rolled back, no prowjob created",
+			githubClient: func(_, _, _ string) (string, error) { return "newer", nil },
+			rolledBack:   true,
+			verify: func(e error, req *prowjobreconciler.OrgRepoBranchCommit) error {
+				if e != nil {
+					return fmt.Errorf("expected error to be nil, was %w", e)
+				}
+				if req != nil {
+					return fmt.Errorf("expected no request, got %v", req)
+				}
+				return nil
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -316,6 +373,18 @@ func TestReconcile(t *testing.T) {
 					},
 				}).Build()
 			}
+			if tc.rolledBack {
+				client = fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(imageStreamTag, &imagev1.ImageStream{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   "namespace",
+						Name:        "name",
+						Annotations: map[string]string{rollback.Annotation: `{"tag": {"digest": "sha256:good", "from": "sha256:bad", "time": "2024-06-01T00:00:00Z"}}`},
+					},
+					Status: imagev1.ImageStreamStatus{
+						Tags: []imagev1.NamedTagEventList{{Tag: "tag", Items: []imagev1.TagEvent{{Image: "sha256:newer"}, {Image: "sha256:good"}}}},
+					},
+				}).Build()
+			}
 			r := &reconciler{
 				log:    logrus.NewEntry(logrus.New()),
 				client: client,
@@ -332,6 +401,7 @@ func TestReconcile(t *testing.T) {
 								Name:             "name",
 								AdditionalImages: map[string]string{"tag": ""},
 								Disabled:         tc.promotionDisabled,
+								Gates:            tc.gates,
 							}},
 						},
 					},
@@ -339,10 +409,13 @@ func TestReconcile(t *testing.T) {
 				},
 				gitHubClient: fakeGithubClient{getGef: tc.githubClient},
 				enqueueJob:   func(orbc prowjobreconciler.OrgRepoBranchCommit) { req = &orbc },
-				since:        since,
+				prowJobs: func(_ context.Context, _, _ string) ([]prowv1.ProwJob, error) {
+					return tc.prowJobs, nil
+				},
+				since: since,
 			}
 
-			err := r.reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{
+			result, err := r.reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{
 				Namespace: "namespace",
 				Name:      "name:tag",
 			}}, r.log)
@@ -350,6 +423,18 @@ func TestReconcile(t *testing.T) {
 			if err := tc.verify(err, req); err != nil {
 				t.Fatal(err)
 			}
+			if diff := cmp.Diff(tc.expectedResult, result); diff != "" {
+				t.Errorf("unexpected result: %s", diff)
+			}
+			if tc.rolledBack {
+				stream := &imagev1.ImageStream{}
+				if err := client.Get(context.Background(), types.NamespacedName{Namespace: "namespace", Name: "name"}, stream); err != nil {
+					t.Fatal(err)
+				}
+				if len(stream.Spec.Tags) != 1 || stream.Spec.Tags[0].From.Name != "name@sha256:good" {
+					t.Errorf("expected the tag to be pointed back to the digest it was rolled back to, got %v", stream.Spec.Tags)
+				}
+			}
 		})
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -14,11 +15,13 @@ import (
 
 	coreapi "k8s.io/api/core/v1"
 	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	coreclientset "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	utilpointer "k8s.io/utils/pointer"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/pod-utils/decorate"
 	"sigs.k8s.io/yaml"
@@ -34,6 +37,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/kubernetes"
 	"github.com/openshift/ci-tools/pkg/labeledclient"
 	"github.com/openshift/ci-tools/pkg/lease"
+	"github.com/openshift/ci-tools/pkg/provenance"
 	"github.com/openshift/ci-tools/pkg/release"
 	"github.com/openshift/ci-tools/pkg/release/official"
 	"github.com/openshift/ci-tools/pkg/results"
@@ -65,6 +69,7 @@ func FromConfig(
 	clusterConfig *rest.Config,
 	podPendingTimeout time.Duration,
 	leaseClient *lease.Client,
+	leaseReporter results.LeaseReporter,
 	requiredTargets []string,
 	cloneAuthConfig *steps.CloneAuthConfig,
 	pullSecret, pushSecret *coreapi.Secret,
@@ -78,6 +83,8 @@ func FromConfig(
 	integratedStreams map[string]*configresolver.IntegratedStream,
 	injectedTest bool,
 	enableSecretsStoreCSIDriver bool,
+	enableBuildAvoidance bool,
+	provenanceOptions *provenance.Options,
 ) ([]api.Step, []api.Step, error) {
 	crclient, err := ctrlruntimeclient.NewWithWatch(clusterConfig, ctrlruntimeclient.Options{})
 	crclient = secretrecordingclient.Wrap(crclient, censor)
@@ -115,7 +122,43 @@ func FromConfig(
 	httpClient := retryablehttp.NewClient()
 	httpClient.Logger = nil
 
-	return fromConfig(ctx, config, graphConf, jobSpec, templates, paramFile, promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, httpClient.StandardClient(), requiredTargets, cloneAuthConfig, pullSecret, pushSecret, api.NewDeferredParameters(nil), censor, nodeName, targetAdditionalSuffix, nodeArchitectures, integratedStreams, injectedTest, enableSecretsStoreCSIDriver)
+	return fromConfig(ctx, config, graphConf, jobSpec, templates, paramFile, promote, client, buildClient, templateClient, podClient, leaseClient, leaseReporter, hiveClient, httpClient.StandardClient(), requiredTargets, cloneAuthConfig, pullSecret, pushSecret, api.NewDeferredParameters(nil), censor, nodeName, targetAdditionalSuffix, nodeArchitectures, integratedStreams, injectedTest, enableSecretsStoreCSIDriver, enableBuildAvoidance, provenanceOptions)
+}
+
+// FromConfigForRender generates the execution graph like FromConfig, with the
+// steps using clients backed by an empty in-memory cluster, for the objects the
+// steps submit to be rendered without a cluster.  The steps cannot be run.
+func FromConfigForRender(
+	ctx context.Context,
+	config *api.ReleaseBuildConfiguration,
+	graphConf *api.GraphConfiguration,
+	jobSpec *api.JobSpec,
+	templates []*templateapi.Template,
+	promote bool,
+	requiredTargets []string,
+	cloneAuthConfig *steps.CloneAuthConfig,
+	pullSecret, pushSecret *coreapi.Secret,
+	censor *secrets.DynamicCensor,
+	nodeName string,
+	nodeArchitectures []string,
+	targetAdditionalSuffix string,
+	integratedStreams map[string]*configresolver.IntegratedStream,
+	injectedTest bool,
+	enableSecretsStoreCSIDriver bool,
+) ([]api.Step, []api.Step, error) {
+	if pushSecret == nil {
+		// the promotion pod only references the push secret by name
+		pushSecret = &coreapi.Secret{ObjectMeta: metav1.ObjectMeta{Name: api.RegistryPushCredentialsCICentralSecret}}
+	}
+	client := loggingclient.New(fakectrlruntimeclient.NewClientBuilder().Build())
+	buildClient := steps.NewBuildClient(client, nil, nodeArchitectures, "", "")
+	templateClient := steps.NewTemplateClient(client, nil)
+	podClient := kubernetes.NewPodClient(client, nil, nil, 0)
+	hiveClient := fakectrlruntimeclient.NewClientBuilder().Build()
+	httpClient := release.NewFakeHTTPClient(func(req *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("cannot request %s when rendering", req.URL)
+	})
+	return fromConfig(ctx, config, graphConf, jobSpec, templates, "", promote, client, buildClient, templateClient, podClient, nil, nil, hiveClient, httpClient, requiredTargets, cloneAuthConfig, pullSecret, pushSecret, api.NewDeferredParameters(nil), censor, nodeName, targetAdditionalSuffix, nodeArchitectures, integratedStreams, injectedTest, enableSecretsStoreCSIDriver, false, nil)
 }
 
 func fromConfig(
@@ -131,6 +174,7 @@ func fromConfig(
 	templateClient steps.TemplateClient,
 	podClient kubernetes.PodClient,
 	leaseClient *lease.Client,
+	leaseReporter results.LeaseReporter,
 	hiveClient ctrlruntimeclient.WithWatch,
 	httpClient release.HTTPClient,
 	requiredTargets []string,
@@ -144,6 +188,8 @@ func fromConfig(
 	integratedStreams map[string]*configresolver.IntegratedStream,
 	injectedTest bool,
 	enableSecretsStoreCSIDriver bool,
+	enableBuildAvoidance bool,
+	provenanceOptions *provenance.Options,
 ) ([]api.Step, []api.Step, error) {
 	requiredNames := sets.New[string]()
 	for _, target := range requiredTargets {
@@ -167,10 +213,14 @@ func fromConfig(
 	}
 	rawSteps = append(graphConf.Steps, rawSteps...)
 	rawSteps = append(rawSteps, stepsForImageOverrides(utils.GetOverriddenImages())...)
+	// images built from the repository are compared to the ones promoted from it to avoid building them,
+	// which are found by the pipeline tag they are promoted from: the names of the promoted images are not
+	// needed for that, the promotion step only uses them to report what it promotes
+	promotedTags, _ /* promoted image names */ := releasesteps.PromotedTagsWithRequiredImages(config)
 
 	for _, rawStep := range rawSteps {
 		if testStep := rawStep.TestStepConfiguration; testStep != nil {
-			steps, err := stepForTest(config, params, podClient, leaseClient, templateClient, client, hiveClient, jobSpec, inputImages, testStep, &imageConfigs, pullSecret, censor, nodeName, targetAdditionalSuffix, enableSecretsStoreCSIDriver)
+			steps, err := stepForTest(config, params, podClient, leaseClient, leaseReporter, templateClient, client, hiveClient, jobSpec, inputImages, testStep, &imageConfigs, pullSecret, censor, nodeName, targetAdditionalSuffix, enableSecretsStoreCSIDriver)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -255,7 +305,11 @@ func fromConfig(
 		} else if rawStep.IndexGeneratorStepConfiguration != nil {
 			step = steps.IndexGeneratorStep(*rawStep.IndexGeneratorStepConfiguration, config, config.Resources, buildClient, podClient, jobSpec, pullSecret)
 		} else if rawStep.ProjectDirectoryImageBuildStepConfiguration != nil {
-			step = steps.ProjectDirectoryImageBuildStep(*rawStep.ProjectDirectoryImageBuildStepConfiguration, config, config.Resources, buildClient, podClient, jobSpec, pullSecret)
+			var reusableImages []api.ImageStreamTagReference
+			if enableBuildAvoidance {
+				reusableImages = promotedTags[string(rawStep.ProjectDirectoryImageBuildStepConfiguration.To)]
+			}
+			step = steps.ProjectDirectoryImageBuildStep(*rawStep.ProjectDirectoryImageBuildStepConfiguration, config, config.Resources, buildClient, podClient, jobSpec, pullSecret, reusableImages)
 		} else if rawStep.ProjectDirectoryImageBuildInputs != nil {
 			step = steps.GitSourceStep(*rawStep.ProjectDirectoryImageBuildInputs, config.Resources, buildClient, podClient, jobSpec, cloneAuthConfig, pullSecret)
 		} else if rawStep.RPMImageInjectionStepConfiguration != nil {
@@ -331,7 +385,7 @@ func fromConfig(
 					Env:          api.DefaultLeaseEnv,
 					Count:        1,
 				}}
-				step = steps.LeaseStep(leaseClient, leases, step, jobSpec.Namespace)
+				step = steps.LeaseStep(leaseClient, leases, step, jobSpec.Namespace, leaseReporter)
 				break
 			}
 		}
@@ -364,12 +418,12 @@ func fromConfig(
 			return nil, nil, fmt.Errorf("cannot promote images, no promotion configuration defined")
 		}
 
-		promotionSteps = append(promotionSteps, releasesteps.PromotionStep(api.PromotionStepName, config, requiredNames, jobSpec, podClient, pushSecret, registryDomain(config.PromotionConfiguration), api.DefaultMirrorFunc, api.DefaultTargetNameFunc, nodeArchitectures))
+		promotionSteps = append(promotionSteps, releasesteps.PromotionStep(api.PromotionStepName, config, requiredNames, jobSpec, podClient, pushSecret, registryDomain(config.PromotionConfiguration), api.DefaultMirrorFunc, api.DefaultTargetNameFunc, nodeArchitectures, provenanceOptions))
 		// Used primarily (only?) by the ci-chat-bot
 		if config.PromotionConfiguration.RegistryOverride != "" {
 			logrus.Info("No images to promote to quay.io if the registry is overridden")
 		} else {
-			promotionSteps = append(promotionSteps, releasesteps.PromotionStep(api.PromotionQuayStepName, config, requiredNames, jobSpec, podClient, pushSecret, api.QuayOpenShiftCIRepo, api.QuayMirrorFunc, api.QuayTargetNameFunc, nodeArchitectures))
+			promotionSteps = append(promotionSteps, releasesteps.PromotionStep(api.PromotionQuayStepName, config, requiredNames, jobSpec, podClient, pushSecret, api.QuayOpenShiftCIRepo, api.QuayMirrorFunc, api.QuayTargetNameFunc, nodeArchitectures, provenanceOptions))
 		}
 	}
 
@@ -418,6 +472,7 @@ func stepForTest(
 	params *api.DeferredParameters,
 	podClient kubernetes.PodClient,
 	leaseClient *lease.Client,
+	leaseReporter results.LeaseReporter,
 	templateClient steps.TemplateClient,
 	client loggingclient.LoggingClient,
 	hiveClient ctrlruntimeclient.WithWatch,
@@ -443,7 +498,7 @@ func stepForTest(
 			step = steps.IPPoolStep(leaseClient, podClient, ipPoolLease, step, params, jobSpec.Namespace)
 		}
 		if len(leases) != 0 {
-			step = steps.LeaseStep(leaseClient, leases, step, jobSpec.Namespace)
+			step = steps.LeaseStep(leaseClient, leases, step, jobSpec.Namespace, leaseReporter)
 		}
 		if c.ClusterClaim != nil {
 			step = steps.ClusterClaimStep(c.As, c.ClusterClaim, hiveClient, client, jobSpec, step, censor)
@@ -470,7 +525,7 @@ func stepForTest(
 			ResourceType: test.ClusterProfile.LeaseType(),
 			Env:          api.DefaultLeaseEnv,
 			Count:        1,
-		}}, step, jobSpec.Namespace)
+		}}, step, jobSpec.Namespace, leaseReporter)
 		addProvidesForStep(step, params)
 		return []api.Step{step}, nil
 	}
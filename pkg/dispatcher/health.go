@@ -0,0 +1,292 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// ClusterProbe determines whether a build cluster is healthy, returning
+// an error describing the problem if it is not
+type ClusterProbe func(ctx context.Context, cluster string) error
+
+// ErrClusterUnknown is returned by probes for clusters they have no way to probe,
+// whose health is then unknown rather than degraded
+var ErrClusterUnknown = errors.New("cluster cannot be probed")
+
+// NewKubernetesProbe probes clusters by checking that their API servers are ready and that
+// no more than maxPendingPods Pods have been pending for longer than pendingFor
+func NewKubernetesProbe(clients map[string]corev1client.CoreV1Interface, maxPendingPods int, pendingFor time.Duration) ClusterProbe {
+	return func(ctx context.Context, cluster string) error {
+		client, ok := clients[cluster]
+		if !ok {
+			return fmt.Errorf("no client for cluster: %w", ErrClusterUnknown)
+		}
+		if err := client.RESTClient().Get().AbsPath("/readyz").Do(ctx).Error(); err != nil {
+			return fmt.Errorf("API server is not ready: %w", err)
+		}
+		pods, err := client.Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: "status.phase=" + string(corev1.PodPending)})
+		if err != nil {
+			return fmt.Errorf("could not list pending pods: %w", err)
+		}
+		var backlog int
+		for _, pod := range pods.Items {
+			if time.Since(pod.CreationTimestamp.Time) > pendingFor {
+				backlog++
+			}
+		}
+		if backlog > maxPendingPods {
+			return fmt.Errorf("%d pods have been pending for longer than %s", backlog, pendingFor)
+		}
+		return nil
+	}
+}
+
+// DegradedCluster describes a cluster which failed its last probes
+type DegradedCluster struct {
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+}
+
+// Override is a job which is temporarily sent to a cluster other than its assigned one
+type Override struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// HealthStatus is the current state of the build clusters and the jobs redirected because of it
+type HealthStatus struct {
+	Degraded  map[string]DegradedCluster `json:"degraded"`
+	Unknown   []string                   `json:"unknown,omitempty"`
+	Overrides map[string]Override        `json:"overrides"`
+}
+
+// HealthMonitor periodically probes build clusters and temporarily redirects
+// jobs which may be relocated away from the ones which are degraded
+type HealthMonitor struct {
+	probe    ClusterProbe
+	clusters func() (ClusterMap, error)
+	// disabled clusters never have jobs redirected to them
+	disabled     sets.Set[string]
+	probeTimeout time.Duration
+	// threshold is the number of consecutive probes which must fail for a cluster
+	// to be degraded, or succeed for a degraded cluster to recover
+	threshold int
+
+	lock       sync.RWMutex
+	clusterMap ClusterMap
+	degraded   map[string]DegradedCluster
+	unknown    sets.Set[string]
+	// streaks counts the consecutive probes of each cluster whose result differs from its current state
+	streaks map[string]int
+}
+
+// NewHealthMonitor creates a monitor probing the clusters loaded by the given function, where a probe
+// taking longer than the timeout fails. A cluster is degraded after threshold consecutive failed probes
+// and recovers after as many consecutive successful ones. Jobs are never redirected to the disabled clusters.
+func NewHealthMonitor(probe ClusterProbe, clusters func() (ClusterMap, error), disabled sets.Set[string], probeTimeout time.Duration, threshold int) *HealthMonitor {
+	return &HealthMonitor{
+		probe:        probe,
+		clusters:     clusters,
+		disabled:     disabled,
+		probeTimeout: probeTimeout,
+		threshold:    threshold,
+		degraded:     map[string]DegradedCluster{},
+		unknown:      sets.New[string](),
+		streaks:      map[string]int{},
+	}
+}
+
+// Run probes all clusters at the given interval until the context is cancelled
+func (m *HealthMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.ProbeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeAll probes all clusters concurrently once, updating which of them are degraded
+func (m *HealthMonitor) ProbeAll(ctx context.Context) {
+	clusterMap, err := m.clusters()
+	if err != nil {
+		logrus.WithError(err).Error("failed to load clusters to probe, keeping the previous health status")
+		return
+	}
+	results := map[string]error{}
+	var resultsLock sync.Mutex
+	var wg sync.WaitGroup
+	for cluster := range clusterMap {
+		wg.Add(1)
+		go func(cluster string) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, m.probeTimeout)
+			defer cancel()
+			err := m.probe(probeCtx, cluster)
+			resultsLock.Lock()
+			results[cluster] = err
+			resultsLock.Unlock()
+		}(cluster)
+	}
+	wg.Wait()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.clusterMap = clusterMap
+	for cluster := range m.degraded {
+		if _, exists := clusterMap[cluster]; !exists {
+			delete(m.degraded, cluster)
+		}
+	}
+	for cluster := range m.streaks {
+		if _, exists := clusterMap[cluster]; !exists {
+			delete(m.streaks, cluster)
+		}
+	}
+	m.unknown = sets.New[string]()
+	for cluster, err := range results {
+		m.record(cluster, err)
+	}
+}
+
+// record updates the state of a cluster with the result of a probe, the lock must be held by the caller
+func (m *HealthMonitor) record(cluster string, err error) {
+	logger := logrus.WithField("cluster", cluster)
+	if errors.Is(err, ErrClusterUnknown) {
+		if _, degraded := m.degraded[cluster]; degraded {
+			logger.WithError(err).Info("health of degraded cluster is unknown")
+		}
+		delete(m.degraded, cluster)
+		delete(m.streaks, cluster)
+		m.unknown.Insert(cluster)
+		return
+	}
+	existing, degraded := m.degraded[cluster]
+	if degraded && err != nil {
+		existing.Reason = err.Error()
+		m.degraded[cluster] = existing
+	}
+	if degraded == (err != nil) {
+		delete(m.streaks, cluster)
+		return
+	}
+	m.streaks[cluster]++
+	if m.streaks[cluster] < m.threshold {
+		return
+	}
+	delete(m.streaks, cluster)
+	if degraded {
+		logger.Info("cluster recovered")
+		delete(m.degraded, cluster)
+		return
+	}
+	logger.WithError(err).Warn("cluster is degraded")
+	m.degraded[cluster] = DegradedCluster{Reason: err.Error(), Since: time.Now()}
+}
+
+// ClusterFor returns the cluster a job should run on, which is the assigned one unless
+// that is degraded and the job may be relocated to a healthy cluster
+func (m *HealthMonitor) ClusterFor(job string, data ProwJobData) string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.clusterFor(job, data)
+}
+
+func (m *HealthMonitor) clusterFor(job string, data ProwJobData) string {
+	if _, degraded := m.degraded[data.Cluster]; !degraded || !data.MayBeRelocated {
+		return data.Cluster
+	}
+	if alternative := m.nextBestCluster(job, data); alternative != "" {
+		return alternative
+	}
+	return data.Cluster
+}
+
+// nextBestCluster finds a healthy cluster which is not disabled and has all the capabilities
+// of the job, preferring clusters on the same cloud provider. Jobs are spread between the
+// candidates in proportion to their capacity, each job always going to the same cluster
+// for as long as the candidates do not change.
+func (m *HealthMonitor) nextBestCluster(job string, data ProwJobData) string {
+	provider := m.clusterMap[data.Cluster].Provider
+	var candidates, sameProvider []string
+	for cluster, info := range m.clusterMap {
+		if _, degraded := m.degraded[cluster]; degraded {
+			continue
+		}
+		if m.unknown.Has(cluster) || m.disabled.Has(cluster) {
+			continue
+		}
+		if !sets.New[string](info.Capabilities...).HasAll(data.Capabilities...) {
+			continue
+		}
+		candidates = append(candidates, cluster)
+		if info.Provider == provider {
+			sameProvider = append(sameProvider, cluster)
+		}
+	}
+	if len(sameProvider) != 0 {
+		candidates = sameProvider
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Strings(candidates)
+	weight := func(cluster string) uint64 {
+		if capacity := m.clusterMap[cluster].Capacity; capacity > 0 {
+			return uint64(capacity)
+		}
+		return 1
+	}
+	var total uint64
+	for _, cluster := range candidates {
+		total += weight(cluster)
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(job))
+	point := hash.Sum64() % total
+	for _, cluster := range candidates {
+		if point < weight(cluster) {
+			return cluster
+		}
+		point -= weight(cluster)
+	}
+	return candidates[len(candidates)-1]
+}
+
+// Status returns the degraded clusters and the jobs currently redirected away from them
+func (m *HealthMonitor) Status(jobs map[string]ProwJobData) HealthStatus {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	status := HealthStatus{Degraded: map[string]DegradedCluster{}, Overrides: map[string]Override{}}
+	for cluster, degraded := range m.degraded {
+		status.Degraded[cluster] = degraded
+	}
+	if m.unknown.Len() != 0 {
+		status.Unknown = sets.List(m.unknown)
+	}
+	if len(m.degraded) == 0 {
+		return status
+	}
+	for job, data := range jobs {
+		if cluster := m.clusterFor(job, data); cluster != data.Cluster {
+			status.Overrides[job] = Override{From: data.Cluster, To: cluster}
+		}
+	}
+	return status
+}
@@ -0,0 +1,287 @@
+package dispatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestHealthMonitorClusterFor(t *testing.T) {
+	clusterMap := ClusterMap{
+		"build01": {Provider: "aws", Capacity: 100},
+		"build02": {Provider: "gcp", Capacity: 100},
+		"build03": {Provider: "aws", Capacity: 50, Capabilities: []string{"arm64"}},
+		"build04": {Provider: "aws", Capacity: 100, Capabilities: []string{"arm64"}},
+		"build05": {Provider: "gcp", Capacity: 100, Capabilities: []string{"arm64"}},
+	}
+	testCases := []struct {
+		name     string
+		failing  map[string]error
+		disabled sets.Set[string]
+		data     ProwJobData
+		expected string
+	}{
+		{
+			name:     "healthy cluster is kept",
+			data:     ProwJobData{Cluster: "build01", MayBeRelocated: true},
+			expected: "build01",
+		},
+		{
+			name:     "job pinned to degraded cluster is kept",
+			failing:  map[string]error{"build01": errors.New("down")},
+			data:     ProwJobData{Cluster: "build01"},
+			expected: "build01",
+		},
+		{
+			name:     "relocatable job prefers cluster on the same provider",
+			failing:  map[string]error{"build01": errors.New("down")},
+			disabled: sets.New[string]("build03"),
+			data:     ProwJobData{Cluster: "build01", MayBeRelocated: true},
+			expected: "build04",
+		},
+		{
+			name:     "relocatable job is not sent to a cluster whose health is unknown",
+			failing:  map[string]error{"build01": errors.New("down"), "build03": ErrClusterUnknown},
+			data:     ProwJobData{Cluster: "build01", MayBeRelocated: true},
+			expected: "build04",
+		},
+		{
+			name:     "relocatable job needs a cluster with its capabilities",
+			failing:  map[string]error{"build04": errors.New("down")},
+			data:     ProwJobData{Cluster: "build04", Capabilities: []string{"arm64"}, MayBeRelocated: true},
+			expected: "build03",
+		},
+		{
+			name:     "relocatable job goes to another provider when its own has no healthy cluster",
+			failing:  map[string]error{"build03": errors.New("down"), "build04": errors.New("down")},
+			data:     ProwJobData{Cluster: "build04", Capabilities: []string{"arm64"}, MayBeRelocated: true},
+			expected: "build05",
+		},
+		{
+			name:     "relocatable job is not sent to a disabled cluster",
+			failing:  map[string]error{"build01": errors.New("down")},
+			disabled: sets.New[string]("build04"),
+			data:     ProwJobData{Cluster: "build01", MayBeRelocated: true},
+			expected: "build03",
+		},
+		{
+			name:     "relocatable job is kept when no cluster has its capabilities",
+			failing:  map[string]error{"build03": errors.New("down"), "build04": errors.New("down"), "build05": errors.New("down")},
+			data:     ProwJobData{Cluster: "build04", Capabilities: []string{"arm64"}, MayBeRelocated: true},
+			expected: "build04",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			monitor := NewHealthMonitor(func(_ context.Context, cluster string) error {
+				return tc.failing[cluster]
+			}, func() (ClusterMap, error) {
+				return clusterMap, nil
+			}, tc.disabled, time.Minute, 1)
+			monitor.ProbeAll(context.Background())
+			if diff := cmp.Diff(tc.expected, monitor.ClusterFor("job", tc.data)); diff != "" {
+				t.Errorf("unexpected cluster: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHealthMonitorRecovery(t *testing.T) {
+	failing := map[string]error{"build01": errors.New("API server is not ready")}
+	loadErr := error(nil)
+	monitor := NewHealthMonitor(func(_ context.Context, cluster string) error {
+		return failing[cluster]
+	}, func() (ClusterMap, error) {
+		return ClusterMap{"build01": {Provider: "aws"}, "build02": {Provider: "aws"}}, loadErr
+	}, nil, time.Minute, 1)
+	jobs := map[string]ProwJobData{
+		"relocatable": {Cluster: "build01", MayBeRelocated: true},
+		"pinned":      {Cluster: "build01"},
+		"elsewhere":   {Cluster: "build02", MayBeRelocated: true},
+	}
+
+	monitor.ProbeAll(context.Background())
+	expected := HealthStatus{
+		Degraded:  map[string]DegradedCluster{"build01": {Reason: "API server is not ready"}},
+		Overrides: map[string]Override{"relocatable": {From: "build01", To: "build02"}},
+	}
+	if diff := cmp.Diff(expected, monitor.Status(jobs), cmpopts.IgnoreFields(DegradedCluster{}, "Since")); diff != "" {
+		t.Errorf("unexpected status while degraded: %s", diff)
+	}
+	since := monitor.Status(jobs).Degraded["build01"].Since
+
+	failing["build01"] = errors.New("300 pods have been pending for longer than 10m0s")
+	monitor.ProbeAll(context.Background())
+	if degraded := monitor.Status(jobs).Degraded["build01"]; !degraded.Since.Equal(since) || degraded.Reason != failing["build01"].Error() {
+		t.Errorf("expected the reason to be updated and the time to be kept, got %v", degraded)
+	}
+
+	loadErr = errors.New("could not read")
+	delete(failing, "build01")
+	monitor.ProbeAll(context.Background())
+	if _, degraded := monitor.Status(jobs).Degraded["build01"]; !degraded {
+		t.Error("expected the status to be kept when the clusters cannot be loaded")
+	}
+
+	loadErr = nil
+	monitor.ProbeAll(context.Background())
+	if diff := cmp.Diff(HealthStatus{Degraded: map[string]DegradedCluster{}, Overrides: map[string]Override{}}, monitor.Status(jobs)); diff != "" {
+		t.Errorf("unexpected status after recovery: %s", diff)
+	}
+}
+
+func TestHealthMonitorThreshold(t *testing.T) {
+	failing := map[string]error{}
+	monitor := NewHealthMonitor(func(_ context.Context, cluster string) error {
+		return failing[cluster]
+	}, func() (ClusterMap, error) {
+		return ClusterMap{"build01": {Provider: "aws"}, "build02": {Provider: "aws"}}, nil
+	}, nil, time.Minute, 2)
+	isDegraded := func() bool {
+		_, degraded := monitor.Status(nil).Degraded["build01"]
+		return degraded
+	}
+
+	for i, step := range []struct {
+		failure  error
+		degraded bool
+	}{
+		{failure: errors.New("down"), degraded: false},
+		{failure: nil, degraded: false},
+		{failure: errors.New("down"), degraded: false},
+		{failure: errors.New("down"), degraded: true},
+		{failure: nil, degraded: true},
+		{failure: errors.New("down"), degraded: true},
+		{failure: nil, degraded: true},
+		{failure: nil, degraded: false},
+	} {
+		failing["build01"] = step.failure
+		monitor.ProbeAll(context.Background())
+		if degraded := isDegraded(); degraded != step.degraded {
+			t.Errorf("probe %d: expected degraded to be %t, got %t", i, step.degraded, degraded)
+		}
+	}
+}
+
+func TestHealthMonitorUnknownCluster(t *testing.T) {
+	failing := map[string]error{"build01": errors.New("down")}
+	monitor := NewHealthMonitor(func(_ context.Context, cluster string) error {
+		return failing[cluster]
+	}, func() (ClusterMap, error) {
+		return ClusterMap{"build01": {Provider: "aws"}, "build02": {Provider: "aws"}}, nil
+	}, nil, time.Minute, 1)
+	jobs := map[string]ProwJobData{"relocatable": {Cluster: "build01", MayBeRelocated: true}}
+
+	monitor.ProbeAll(context.Background())
+	failing["build01"] = fmt.Errorf("no client for cluster: %w", ErrClusterUnknown)
+	monitor.ProbeAll(context.Background())
+	expected := HealthStatus{Degraded: map[string]DegradedCluster{}, Unknown: []string{"build01"}, Overrides: map[string]Override{}}
+	if diff := cmp.Diff(expected, monitor.Status(jobs)); diff != "" {
+		t.Errorf("unexpected status: %s", diff)
+	}
+}
+
+func TestHealthMonitorSpreadsRedirects(t *testing.T) {
+	monitor := NewHealthMonitor(func(_ context.Context, cluster string) error {
+		if cluster == "build01" {
+			return errors.New("down")
+		}
+		return nil
+	}, func() (ClusterMap, error) {
+		return ClusterMap{
+			"build01": {Provider: "aws", Capacity: 100},
+			"build02": {Provider: "aws", Capacity: 75},
+			"build03": {Provider: "aws", Capacity: 25},
+		}, nil
+	}, nil, time.Minute, 1)
+	monitor.ProbeAll(context.Background())
+
+	counts := map[string]int{}
+	data := ProwJobData{Cluster: "build01", MayBeRelocated: true}
+	for i := 0; i < 1000; i++ {
+		job := fmt.Sprintf("job-%d", i)
+		cluster := monitor.ClusterFor(job, data)
+		if again := monitor.ClusterFor(job, data); again != cluster {
+			t.Fatalf("%s: expected the same cluster on every call, got %s and %s", job, cluster, again)
+		}
+		counts[cluster]++
+	}
+	if counts["build01"] != 0 {
+		t.Errorf("expected no job to stay on the degraded cluster, got %d", counts["build01"])
+	}
+	if counts["build02"] < 650 || counts["build02"] > 850 || counts["build03"] < 150 || counts["build03"] > 350 {
+		t.Errorf("expected jobs to be spread in proportion to capacity, got %v", counts)
+	}
+}
+
+func TestHealthMonitorProbeTimeout(t *testing.T) {
+	monitor := NewHealthMonitor(func(ctx context.Context, cluster string) error {
+		if cluster == "build01" {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}, func() (ClusterMap, error) {
+		return ClusterMap{"build01": {Provider: "aws"}, "build02": {Provider: "aws"}}, nil
+	}, nil, 10*time.Millisecond, 1)
+
+	monitor.ProbeAll(context.Background())
+	expected := map[string]DegradedCluster{"build01": {Reason: context.DeadlineExceeded.Error()}}
+	if diff := cmp.Diff(expected, monitor.Status(nil).Degraded, cmpopts.IgnoreFields(DegradedCluster{}, "Since")); diff != "" {
+		t.Errorf("unexpected degraded clusters: %s", diff)
+	}
+}
+
+func TestServerRedirectsAwayFromDegradedClusters(t *testing.T) {
+	pjs := &Prowjobs{data: map[string]ProwJobData{
+		"relocatable": {Cluster: "build01", MayBeRelocated: true},
+		"pinned":      {Cluster: "build01"},
+	}}
+	monitor := NewHealthMonitor(func(_ context.Context, cluster string) error {
+		if cluster == "build01" {
+			return errors.New("down")
+		}
+		return nil
+	}, func() (ClusterMap, error) {
+		return ClusterMap{"build01": {Provider: "aws"}, "build02": {Provider: "aws"}}, nil
+	}, nil, time.Minute, 1)
+	monitor.ProbeAll(context.Background())
+	server := NewServer(pjs, func(bool) {}, monitor)
+
+	for job, expected := range map[string]string{"rehearse-1234-relocatable": "build02", "pinned": "build01"} {
+		raw, err := json.Marshal(SchedulingRequest{Job: job})
+		if err != nil {
+			t.Fatalf("failed to marshal request: %v", err)
+		}
+		recorder := httptest.NewRecorder()
+		server.RequestHandler(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(raw)))
+		var response SchedulingResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response %q: %v", recorder.Body.String(), err)
+		}
+		if response.Cluster != expected {
+			t.Errorf("%s: expected cluster %s, got %s", job, expected, response.Cluster)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	server.StatusHandler(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status HealthStatus
+	if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to unmarshal status %q: %v", recorder.Body.String(), err)
+	}
+	if diff := cmp.Diff(map[string]Override{"relocatable": {From: "build01", To: "build02"}}, status.Overrides); diff != "" {
+		t.Errorf("unexpected overrides: %s", diff)
+	}
+}
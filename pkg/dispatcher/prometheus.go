@@ -11,6 +11,11 @@ import (
 	prometheusapi "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	"github.com/sirupsen/logrus"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apiutils "github.com/openshift/ci-tools/pkg/api/utils"
 )
 
 // PrometheusOptions exposes options used in contacting a Prometheus instance
@@ -71,7 +76,51 @@ type PrometheusAPI interface {
 
 // GetJobVolumesFromPrometheus gets job volumes from a Prometheus server for the given time
 func GetJobVolumesFromPrometheus(ctx context.Context, prometheusAPI PrometheusAPI, ts time.Time) (map[string]float64, error) {
-	result, warnings, err := prometheusAPI.Query(ctx, `sum(increase(prowjob_state_transitions{state="pending"}[7d])) by (job_name)`, ts)
+	return getJobValuesFromPrometheus(ctx, prometheusAPI, `sum(increase(prowjob_state_transitions{state="pending"}[7d])) by (job_name)`, ts)
+}
+
+// JobCPUHoursQuery sums the CPU used in the week before the query time by all containers in Pods
+// labelled by ci-operator with the name of the job they run for, in core-hours
+const JobCPUHoursQuery = `sum by (job_name) (label_replace(sum by (namespace, pod) (increase(container_cpu_usage_seconds_total{container!="POD",container!=""}[7d])) * on(namespace, pod) group_left(label_ci_openshift_io_jobname) max by (namespace, pod, label_ci_openshift_io_jobname) (kube_pod_labels{label_ci_openshift_io_jobname!=""}), "job_name", "$1", "label_ci_openshift_io_jobname", "(.*)")) / 3600`
+
+// GetJobCPUHoursFromPrometheus gets the CPU-hours consumed by each of the jobs from the Prometheus servers of
+// the build farm clusters, by cluster, for the given time. Pods run on the build farm clusters, so only their
+// Prometheus servers know how much CPU they used. ci-operator labels Pods with the name of their job, which is
+// truncated when it does not fit in a label: the CPU-hours of a truncated name are split evenly between the
+// jobs that share it, as there is no way to tell them apart.
+func GetJobCPUHoursFromPrometheus(ctx context.Context, prometheusAPIs map[string]PrometheusAPI, ts time.Time, jobs sets.Set[string]) (map[string]float64, error) {
+	jobsByLabel := map[string][]string{}
+	for _, job := range sets.List(jobs) {
+		label := jobNameLabelValue(job)
+		jobsByLabel[label] = append(jobsByLabel[label], job)
+	}
+	jobValues := map[string]float64{}
+	var errs []error
+	for _, cluster := range sets.List(sets.KeySet(prometheusAPIs)) {
+		values, err := getJobValuesFromPrometheus(ctx, prometheusAPIs[cluster], JobCPUHoursQuery, ts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to query Prometheus on cluster %s: %w", cluster, err))
+			continue
+		}
+		for label, value := range values {
+			for _, job := range jobsByLabel[label] {
+				jobValues[job] += value / float64(len(jobsByLabel[label]))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return jobValues, nil
+}
+
+// jobNameLabelValue returns the value ci-operator sets for the job name label on the Pods of a job
+func jobNameLabelValue(job string) string {
+	return apiutils.SanitizeLabels(map[string]string{"job": job})["job"]
+}
+
+func getJobValuesFromPrometheus(ctx context.Context, prometheusAPI PrometheusAPI, query string, ts time.Time) (map[string]float64, error) {
+	result, warnings, err := prometheusAPI.Query(ctx, query, ts)
 	if err != nil {
 		return nil, err
 	}
@@ -84,12 +133,12 @@ func GetJobVolumesFromPrometheus(ctx context.Context, prometheusAPI PrometheusAP
 		return nil, fmt.Errorf("returned result of type %T from Prometheus cannot be cast to vector", result)
 	}
 
-	jobVolumes := map[string]float64{}
+	jobValues := map[string]float64{}
 	for _, v := range vector {
-		jobVolumes[string(v.Metric[model.LabelName("job_name")])] = float64(v.Value)
+		jobValues[string(v.Metric[model.LabelName("job_name")])] = float64(v.Value)
 	}
 
-	return jobVolumes, nil
+	return jobValues, nil
 }
 
 // NewPrometheusClient return a Prometheus client
@@ -20,7 +20,7 @@ type prometheusAPIForTest struct {
 }
 
 var (
-	supportedQueries = sets.New[string](`sum(increase(prowjob_state_transitions{state="pending"}[7d])) by (job_name)`)
+	supportedQueries = sets.New[string](`sum(increase(prowjob_state_transitions{state="pending"}[7d])) by (job_name)`, JobCPUHoursQuery)
 )
 
 func (prometheusAPI *prometheusAPIForTest) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusapi.Option) (model.Value, prometheusapi.Warnings, error) {
@@ -90,3 +90,73 @@ func TestGetJobVolumesFromPrometheus(t *testing.T) {
 		})
 	}
 }
+
+func TestGetJobCPUHoursFromPrometheus(t *testing.T) {
+	e2e, unit := "pull-ci-some-e2e-job", "pull-ci-some-unit-job"
+	long, longer := "pull-ci-some-e2e-job-whose-name-is-too-long-to-fit-in-a-label-value", "pull-ci-some-e2e-job-whose-name-is-too-long-to-fit-in-a-label-value-too"
+	queryFuncFor := func(samples map[string]float64) func(ctx context.Context, query string, ts time.Time) (model.Value, prometheusapi.Warnings, error) {
+		return func(ctx context.Context, query string, ts time.Time) (model.Value, prometheusapi.Warnings, error) {
+			if query != JobCPUHoursQuery {
+				return nil, nil, fmt.Errorf("expected to query %q, got %q", JobCPUHoursQuery, query)
+			}
+			var vector model.Vector
+			for name, value := range samples {
+				vector = append(vector, &model.Sample{
+					Metric: model.Metric(map[model.LabelName]model.LabelValue{model.LabelName("job_name"): model.LabelValue(name)}),
+					Value:  model.SampleValue(value),
+				})
+			}
+			return vector, nil, nil
+		}
+	}
+	testCases := []struct {
+		name          string
+		clusters      map[string]PrometheusAPI
+		expected      map[string]float64
+		expectedError error
+	}{
+		{
+			name: "CPU-hours are summed over the build farm",
+			clusters: map[string]PrometheusAPI{
+				"build01": &prometheusAPIForTest{queryFuncFor(map[string]float64{e2e: 120.5, unit: 0.25})},
+				"build02": &prometheusAPIForTest{queryFuncFor(map[string]float64{e2e: 10})},
+			},
+			expected: map[string]float64{e2e: 130.5, unit: 0.25},
+		},
+		{
+			name: "CPU-hours of unknown jobs are ignored",
+			clusters: map[string]PrometheusAPI{
+				"build01": &prometheusAPIForTest{queryFuncFor(map[string]float64{e2e: 120.5, "removed-job": 3})},
+			},
+			expected: map[string]float64{e2e: 120.5},
+		},
+		{
+			name: "CPU-hours of a truncated job name are split between the jobs sharing it",
+			clusters: map[string]PrometheusAPI{
+				"build01": &prometheusAPIForTest{queryFuncFor(map[string]float64{"pull-ci-some-e2e-job-whose-name-is-too-long-to-fit-in-a-labexxx": 10})},
+			},
+			expected: map[string]float64{long: 5, longer: 5},
+		},
+		{
+			name: "failing to query a cluster is an error",
+			clusters: map[string]PrometheusAPI{
+				"build01": &prometheusAPIForTest{queryFuncFor(map[string]float64{e2e: 120.5})},
+				"build02": &prometheusAPIForTest{func(ctx context.Context, query string, ts time.Time) (model.Value, prometheusapi.Warnings, error) {
+					return nil, nil, fmt.Errorf("connection refused")
+				}},
+			},
+			expectedError: fmt.Errorf("failed to query Prometheus on cluster build02: connection refused"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := GetJobCPUHoursFromPrometheus(context.Background(), tc.clusters, time.Now(), sets.New[string](e2e, unit, long, longer))
+			if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("actual error does not match expected, diff: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("actual does not match expected, diff: %s", diff)
+			}
+		})
+	}
+}
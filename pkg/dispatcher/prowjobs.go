@@ -17,6 +17,9 @@ type Prowjobs struct {
 type ProwJobData struct {
 	Cluster      string
 	Capabilities []string
+	// MayBeRelocated is set when the job was not pinned to its cluster and
+	// may run on any other cluster with the same capabilities
+	MayBeRelocated bool
 }
 
 func NewProwjobs(jobsStoragePath string) *Prowjobs {
@@ -63,6 +66,15 @@ func (pjs *Prowjobs) GetCluster(pj string) string {
 	return ""
 }
 
+// Get returns the data for a job, if it exists
+func (pjs *Prowjobs) Get(pj string) (ProwJobData, bool) {
+	pjs.mu.Lock()
+	defer pjs.mu.Unlock()
+
+	data, exists := pjs.data[pj]
+	return data, exists
+}
+
 func (pjs *Prowjobs) HasAnyOfClusters(clusters sets.Set[string]) bool {
 	pjs.mu.Lock()
 	defer pjs.mu.Unlock()
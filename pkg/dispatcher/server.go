@@ -12,12 +12,16 @@ import (
 type Server struct {
 	pjs      *Prowjobs
 	dispatch func(bool)
+	health   *HealthMonitor
 }
 
-func NewServer(jobs *Prowjobs, dispatch func(bool)) *Server {
+// NewServer creates a server answering with the dispatched clusters for jobs. When the
+// health monitor is not nil, jobs are redirected away from degraded clusters.
+func NewServer(jobs *Prowjobs, dispatch func(bool), health *HealthMonitor) *Server {
 	return &Server{
 		pjs:      jobs,
 		dispatch: dispatch,
+		health:   health,
 	}
 }
 
@@ -61,11 +65,16 @@ func (s *Server) RequestHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	cluster := s.pjs.GetCluster(removeRehearsePrefix(req.Job))
-	if cluster == "" {
+	job := removeRehearsePrefix(req.Job)
+	data, exists := s.pjs.Get(job)
+	if !exists || data.Cluster == "" {
 		http.Error(w, "Cluster not found", http.StatusNotFound)
 		return
 	}
+	cluster := data.Cluster
+	if s.health != nil {
+		cluster = s.health.ClusterFor(job, data)
+	}
 
 	response := SchedulingResponse{Cluster: cluster}
 	w.Header().Set("Content-Type", "application/json")
@@ -102,3 +111,27 @@ func (s *Server) EventHandler(w http.ResponseWriter, r *http.Request) {
 		s.dispatch(true)
 	}
 }
+
+// StatusHandler handles the /status route, serving the health of clusters and the current overrides
+func (s *Server) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Path != "/status" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	if s.health == nil {
+		http.Error(w, "Health probing is disabled", http.StatusNotFound)
+		return
+	}
+
+	status := s.health.Status(s.pjs.GetDataCopy())
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		logrus.WithError(err).Error("failed to encode status")
+	}
+}
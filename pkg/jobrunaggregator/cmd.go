@@ -37,5 +37,7 @@ func NewJobAggregatorCommand() *cobra.Command {
 	cmd.AddCommand(jobruntestcaseanalyzer.NewJobRunsTestCaseAnalyzerCommand())
 
 	cmd.AddCommand(jobrunhistoricaldataanalyzer.NewJobRunHistoricalDataAnalyzerCommand())
+
+	cmd.AddCommand(NewExportCommand())
 	return cmd
 }
@@ -0,0 +1,45 @@
+package jobrunaggregator
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatoranalyzer"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunhistoricaldataanalyzer"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobruntestcaseanalyzer"
+)
+
+func NewExportCommand() *cobra.Command {
+	var outputDir string
+	cmd := &cobra.Command{
+		Use: "export",
+		Long: `Run an analyzer against BigQuery and GCS and export all the CI data it reads into a directory.
+The analysis can then be reproduced offline by passing the directory as --local-data-dir to the same analyzer
+with the same flags.`,
+		Example: `./job-run-aggregator export --output-dir=./exported analyze-job-runs
+--google-service-account-credential-file=credential.json
+--job=periodic-ci-openshift-release-master-ci-4.14-e2e-gcp-ovn-upgrade
+--payload-tag=4.14.0-0.ci-2023-06-18-131345
+--job-start-time=2023-06-18T13:15:00Z
+
+./job-run-aggregator analyze-job-runs --local-data-dir=./exported
+--job=periodic-ci-openshift-release-master-ci-4.14-e2e-gcp-ovn-upgrade
+--payload-tag=4.14.0-0.ci-2023-06-18-131345
+--job-start-time=2023-06-18T13:15:00Z
+`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(outputDir) == 0 {
+				return fmt.Errorf("missing --%s", jobrunaggregatorlib.ExportDirFlag)
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVar(&outputDir, jobrunaggregatorlib.ExportDirFlag, outputDir, "The directory to export the CI data read by the analyzer to")
+
+	cmd.AddCommand(jobrunaggregatoranalyzer.NewJobRunsAnalyzerCommand())
+	cmd.AddCommand(jobruntestcaseanalyzer.NewJobRunsTestCaseAnalyzerCommand())
+	cmd.AddCommand(jobrunhistoricaldataanalyzer.NewJobRunHistoricalDataAnalyzerCommand())
+	return cmd
+}
@@ -19,6 +19,7 @@ import (
 type JobRunsAnalyzerFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+	LocalData       *jobrunaggregatorlib.LocalDataFlags
 
 	JobName                     string
 	WorkingDir                  string
@@ -38,6 +39,7 @@ func NewJobRunsAnalyzerFlags() *JobRunsAnalyzerFlags {
 	return &JobRunsAnalyzerFlags{
 		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
 		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		LocalData:       jobrunaggregatorlib.NewLocalDataFlags(),
 
 		WorkingDir:                  "job-aggregator-working-dir",
 		EstimatedJobStartTimeString: time.Now().Format(kubeTimeSerializationLayout),
@@ -50,6 +52,7 @@ const kubeTimeSerializationLayout = time.RFC3339
 func (f *JobRunsAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.LocalData.BindFlags(fs)
 
 	fs.StringVar(&f.JobName, "job", f.JobName, "The name of the job to inspect, like periodic-ci-openshift-release-master-ci-4.9-e2e-gcp-upgrade")
 	fs.StringVar(&f.WorkingDir, "working-dir", f.WorkingDir, "The directory to store caches, output, and the like.")
@@ -78,6 +81,9 @@ func NewJobRunsAnalyzerCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
+			if err := f.LocalData.Complete(cmd); err != nil {
+				logrus.WithError(err).Fatal("Flags are invalid")
+			}
 			if err := f.Validate(); err != nil {
 				logrus.WithError(err).Fatal("Flags are invalid")
 			}
@@ -112,11 +118,16 @@ func (f *JobRunsAnalyzerFlags) Validate() error {
 	if _, err := time.Parse(kubeTimeSerializationLayout, f.EstimatedJobStartTimeString); err != nil {
 		return err
 	}
-	if err := f.DataCoordinates.Validate(); err != nil {
+	if err := f.LocalData.Validate(); err != nil {
 		return err
 	}
-	if err := f.Authentication.Validate(); err != nil {
-		return err
+	if !f.LocalData.Local() {
+		if err := f.DataCoordinates.Validate(); err != nil {
+			return err
+		}
+		if err := f.Authentication.Validate(); err != nil {
+			return err
+		}
 	}
 	if len(f.PayloadTag) > 0 && len(f.AggregationID) > 0 {
 		return fmt.Errorf("cannot specify both --payload-tag and --aggregation-id")
@@ -144,15 +155,12 @@ func (f *JobRunsAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunAggregator
 		return nil, err
 	}
 
-	bigQueryClient, err := f.Authentication.NewBigQueryClient(ctx, f.DataCoordinates.ProjectID)
+	ciDataClient, err := f.LocalData.NewCIDataClient(ctx, f.Authentication, f.DataCoordinates)
 	if err != nil {
 		return nil, err
 	}
-	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
-		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
-	)
 
-	ciGCSClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket)
+	ciGCSClient, err := f.LocalData.NewCIGCSClient(ctx, f.Authentication, f.GCSBucket)
 	if err != nil {
 		return nil, err
 	}
@@ -21,9 +21,17 @@ import (
 	"github.com/openshift/ci-tools/pkg/junit"
 )
 
+// objectStore lists and reads the artifacts of job runs
+type objectStore interface {
+	// list returns the names of all the objects whose name starts with the prefix
+	list(ctx context.Context, prefix string) ([]string, error)
+	// read returns the latest content of the object
+	read(ctx context.Context, path string) ([]byte, error)
+}
+
 type gcsJobRun struct {
 	// retrieval mechanisms
-	bkt *storage.BucketHandle
+	store objectStore
 
 	jobRunGCSBucketRoot string
 	jobName             string
@@ -39,7 +47,7 @@ type gcsJobRun struct {
 
 func NewGCSJobRun(bkt *storage.BucketHandle, jobGCSBucketRoot string, jobName, jobRunID string, jobRunGCSBucket string) JobRunInfo {
 	return &gcsJobRun{
-		bkt:                 bkt,
+		store:               &bucketStore{bkt: bkt},
 		jobRunGCSBucketRoot: path.Join(jobGCSBucketRoot, jobRunID),
 		jobName:             jobName,
 		jobRunID:            jobRunID,
@@ -148,49 +156,23 @@ func (j *gcsJobRun) writeCache(ctx context.Context, parentDir string) error {
 }
 
 func (j *gcsJobRun) GetJobRunFromGCS(ctx context.Context) error {
-	query := &storage.Query{
-		// This ends up being the equivalent of:
-		// https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/logs/periodic-ci-openshift-release-master-nightly-4.9-upgrade-from-stable-4.8-e2e-metal-ipi-upgrade/1671747590984568832
-		// the next directory step is based on some bit of metadata I don't recognize
-		Prefix: j.jobRunGCSBucketRoot,
-
-		// TODO this field is apparently missing from this level of go/storage
-		// Omit owner and ACL fields for performance
-		// Projection: storage.ProjectionNoACL,
-	}
-
-	// Only retrieve the name and creation time for performance
-	if err := query.SetAttrSelection([]string{"Name", "Created"}); err != nil {
+	// This ends up being the equivalent of:
+	// https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/logs/periodic-ci-openshift-release-master-nightly-4.9-upgrade-from-stable-4.8-e2e-metal-ipi-upgrade/1671747590984568832
+	// the next directory step is based on some bit of metadata I don't recognize
+	names, err := j.store.list(ctx, j.jobRunGCSBucketRoot)
+	if err != nil {
 		return err
 	}
 
-	// Returns an iterator which iterates over the bucket query results.
-	// this will list *all* files with the query prefix.
-	it := j.bkt.Objects(ctx, query)
-
 	// Find the query results we're the most interested in.
-	for {
-		attrs, err := it.Next()
-		if err == iterator.Done {
-			// we're done adding values
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		// if we have a directory then skip
-		if len(attrs.Name) == 0 {
-			continue
-		}
-
+	for _, name := range names {
 		// add the name
-		j.AddGCSProwJobFileNames(attrs.Name)
+		j.AddGCSProwJobFileNames(name)
 
 		// see if it is a junit
-		if strings.HasSuffix(attrs.Name, ".xml") && strings.Contains(attrs.Name, "/junit") {
-			logrus.Debugf("found %s", attrs.Name)
-			j.AddGCSJunitPaths(attrs.Name)
+		if strings.HasSuffix(name, ".xml") && strings.Contains(name, "/junit") {
+			logrus.Debugf("found %s", name)
+			j.AddGCSJunitPaths(name)
 		}
 	}
 
@@ -330,27 +312,11 @@ func (j *gcsJobRun) GetContent(ctx context.Context, path string) ([]byte, error)
 }
 
 func (j *gcsJobRun) getCurrentContent(ctx context.Context, path string) ([]byte, error) {
-	// Get an Object handle for the path
-	obj := j.bkt.Object(path)
-
-	// use the object attributes to try to get the latest generation to try to retrieve the data without getting a cached
-	// version of data that does not match the latest content.  I don't know if this will work, but in the easy case
-	// it doesn't seem to fail.
-	objAttrs, err := obj.Attrs(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error reading GCS attributes for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), path, err)
-	}
-	obj = obj.Generation(objAttrs.Generation)
-
-	// Get an io.Reader for the object.
-	gcsReader, err := obj.NewReader(ctx)
+	content, err := j.store.read(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("error reading GCS content for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), path, err)
 	}
-	defer gcsReader.Close()
-
-	return io.ReadAll(gcsReader)
-
+	return content, nil
 }
 
 func (j *gcsJobRun) getAllContent(ctx context.Context) (map[string][]byte, error) {
@@ -413,3 +379,69 @@ func GetGCSArtifactURLForLocation(jobRunGCSBucketRoot, jobRunGCSBucket string) s
 	// https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/logs/periodic-ci-openshift-release-master-ci-4.9-e2e-gcp-upgrade/1420676206029705216/artifacts/e2e-gcp-upgrade/
 	return fmt.Sprintf("https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/%s/%s/artifacts", jobRunGCSBucket, jobRunGCSBucketRoot)
 }
+
+// bucketStore reads the artifacts of job runs from a GCS bucket
+type bucketStore struct {
+	bkt *storage.BucketHandle
+}
+
+func (b *bucketStore) list(ctx context.Context, prefix string) ([]string, error) {
+	query := &storage.Query{
+		Prefix: prefix,
+
+		// TODO this field is apparently missing from this level of go/storage
+		// Omit owner and ACL fields for performance
+		// Projection: storage.ProjectionNoACL,
+	}
+
+	// Only retrieve the name and creation time for performance
+	if err := query.SetAttrSelection([]string{"Name", "Created"}); err != nil {
+		return nil, err
+	}
+
+	// Returns an iterator which iterates over the bucket query results.
+	// this will list *all* files with the query prefix.
+	it := b.bkt.Objects(ctx, query)
+
+	var names []string
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			// we're done adding values
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// if we have a directory then skip
+		if len(attrs.Name) == 0 {
+			continue
+		}
+		names = append(names, attrs.Name)
+	}
+	return names, nil
+}
+
+func (b *bucketStore) read(ctx context.Context, path string) ([]byte, error) {
+	// Get an Object handle for the path
+	obj := b.bkt.Object(path)
+
+	// use the object attributes to try to get the latest generation to try to retrieve the data without getting a cached
+	// version of data that does not match the latest content.  I don't know if this will work, but in the easy case
+	// it doesn't seem to fail.
+	objAttrs, err := obj.Attrs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading attributes: %w", err)
+	}
+	obj = obj.Generation(objAttrs.Generation)
+
+	// Get an io.Reader for the object.
+	gcsReader, err := obj.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer gcsReader.Close()
+
+	return io.ReadAll(gcsReader)
+}
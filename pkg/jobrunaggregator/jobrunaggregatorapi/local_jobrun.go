@@ -0,0 +1,97 @@
+package jobrunaggregatorapi
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// NewLocalJobRun reads the artifacts of a job run from a local directory laid out like the GCS bucket,
+// for instance one exported by the export command.
+func NewLocalJobRun(dir, jobGCSBucketRoot string, jobName, jobRunID string, jobRunGCSBucket string) JobRunInfo {
+	return &gcsJobRun{
+		store:               &localStore{dir: dir},
+		jobRunGCSBucketRoot: path.Join(jobGCSBucketRoot, jobRunID),
+		jobName:             jobName,
+		jobRunID:            jobRunID,
+		jobRunGCSBucket:     jobRunGCSBucket,
+	}
+}
+
+// ExportJobRun copies the artifacts of a job run the analyzers read, its prowjob.json, finished.json
+// and junit directories, into a local directory laid out like the GCS bucket, for NewLocalJobRun to read
+func ExportJobRun(ctx context.Context, jobRun JobRunInfo, dir string) error {
+	j, ok := jobRun.(*gcsJobRun)
+	if !ok {
+		return fmt.Errorf("cannot export jobrun/%v/%v: not read from GCS", jobRun.GetJobName(), jobRun.GetJobRunID())
+	}
+	names, err := j.store.list(ctx, j.jobRunGCSBucketRoot+"/")
+	if err != nil {
+		return fmt.Errorf("error listing content for jobrun/%v/%v: %w", j.GetJobName(), j.GetJobRunID(), err)
+	}
+	for _, name := range names {
+		if base := path.Base(name); base != "prowjob.json" && base != "finished.json" && !strings.Contains(name, "/junit") {
+			continue
+		}
+		content, err := j.getCurrentContent(ctx, name)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("error making directory for %q: %w", j.GetJobRunID(), err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("error writing file for %q %q: %w", j.GetJobRunID(), target, err)
+		}
+	}
+	return nil
+}
+
+// localStore reads the artifacts of job runs from a local directory laid out like the GCS bucket
+type localStore struct {
+	dir string
+}
+
+func (l *localStore) list(_ context.Context, prefix string) ([]string, error) {
+	// like in GCS, the prefix is not necessarily a directory: everything in the
+	// directory it is in whose name starts with it is listed
+	prefixDir, prefixBase := path.Split(prefix)
+	parent := filepath.Join(l.dir, filepath.FromSlash(prefixDir))
+	entries, err := os.ReadDir(parent)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefixBase) {
+			continue
+		}
+		err := filepath.WalkDir(filepath.Join(parent, entry.Name()), func(file string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			relative, err := filepath.Rel(l.dir, file)
+			if err != nil {
+				return err
+			}
+			names = append(names, filepath.ToSlash(relative))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+func (l *localStore) read(_ context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(l.dir, filepath.FromSlash(name)))
+}
@@ -0,0 +1,296 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// recordedResults stores the results of CIDataClient calls in a directory: one JSON file per
+// method, mapping the JSON-serialized arguments of each call to its result
+type recordedResults struct {
+	dir string
+
+	lock    sync.Mutex
+	methods map[string]map[string]json.RawMessage
+}
+
+func newRecordedResults(dir string) *recordedResults {
+	return &recordedResults{dir: dir, methods: map[string]map[string]json.RawMessage{}}
+}
+
+func (r *recordedResults) path(method string) string {
+	return filepath.Join(r.dir, method+".json")
+}
+
+// load must be called with the lock held
+func (r *recordedResults) load(method string) (map[string]json.RawMessage, error) {
+	if results, ok := r.methods[method]; ok {
+		return results, nil
+	}
+	results := map[string]json.RawMessage{}
+	raw, err := os.ReadFile(r.path(method))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read results of %s: %w", method, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(raw, &results); err != nil {
+			return nil, fmt.Errorf("failed to parse results of %s: %w", method, err)
+		}
+	}
+	r.methods[method] = results
+	return results, nil
+}
+
+func callKey(args []interface{}) (string, error) {
+	key, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize arguments: %w", err)
+	}
+	return string(key), nil
+}
+
+// record stores the result of a successful call, passing the result and error of the call through
+func record[T any](r *recordedResults, method string, result T, callErr error, args ...interface{}) (T, error) {
+	if callErr != nil {
+		return result, callErr
+	}
+	key, err := callKey(args)
+	if err != nil {
+		return result, fmt.Errorf("failed to export result of %s: %w", method, err)
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return result, fmt.Errorf("failed to export result of %s%s: %w", method, key, err)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	results, err := r.load(method)
+	if err != nil {
+		return result, err
+	}
+	results[key] = raw
+	serialized, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return result, fmt.Errorf("failed to export results of %s: %w", method, err)
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return result, fmt.Errorf("failed to export results of %s: %w", method, err)
+	}
+	if err := os.WriteFile(r.path(method), serialized, 0644); err != nil {
+		return result, fmt.Errorf("failed to export results of %s: %w", method, err)
+	}
+	return result, nil
+}
+
+// replay returns the recorded result of the call
+func replay[T any](r *recordedResults, method string, args ...interface{}) (T, error) {
+	var ret T
+	key, err := callKey(args)
+	if err != nil {
+		return ret, fmt.Errorf("failed to look %s up: %w", method, err)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	results, err := r.load(method)
+	if err != nil {
+		return ret, err
+	}
+	raw, ok := results[key]
+	if !ok {
+		return ret, fmt.Errorf("no result of %s%s was exported to %s", method, key, r.dir)
+	}
+	if err := json.Unmarshal(raw, &ret); err != nil {
+		return ret, fmt.Errorf("failed to parse result of %s%s: %w", method, key, err)
+	}
+	return ret, nil
+}
+
+// exportingCIDataClient exports the results of all the calls it makes to the
+// delegate, for a localCIDataClient to read them
+type exportingCIDataClient struct {
+	delegate CIDataClient
+	results  *recordedResults
+}
+
+var _ CIDataClient = &exportingCIDataClient{}
+
+// NewExportingCIDataClient exports the results of the calls to the delegate into the directory
+func NewExportingCIDataClient(delegate CIDataClient, dir string) CIDataClient {
+	return &exportingCIDataClient{
+		delegate: delegate,
+		results:  newRecordedResults(dir),
+	}
+}
+
+func (c *exportingCIDataClient) GetJobRunForJobNameBeforeTime(ctx context.Context, jobName string, targetTime time.Time) (string, error) {
+	ret, err := c.delegate.GetJobRunForJobNameBeforeTime(ctx, jobName, targetTime)
+	return record(c.results, "GetJobRunForJobNameBeforeTime", ret, err, jobName, targetTime)
+}
+
+func (c *exportingCIDataClient) GetJobRunForJobNameAfterTime(ctx context.Context, jobName string, targetTime time.Time) (string, error) {
+	ret, err := c.delegate.GetJobRunForJobNameAfterTime(ctx, jobName, targetTime)
+	return record(c.results, "GetJobRunForJobNameAfterTime", ret, err, jobName, targetTime)
+}
+
+func (c *exportingCIDataClient) GetBackendDisruptionRowCountByJob(ctx context.Context, jobName, masterNodesUpdated string) (uint64, error) {
+	ret, err := c.delegate.GetBackendDisruptionRowCountByJob(ctx, jobName, masterNodesUpdated)
+	return record(c.results, "GetBackendDisruptionRowCountByJob", ret, err, jobName, masterNodesUpdated)
+}
+
+func (c *exportingCIDataClient) GetBackendDisruptionStatisticsByJob(ctx context.Context, jobName, masterNodesUpdated string) ([]jobrunaggregatorapi.BackendDisruptionStatisticsRow, error) {
+	ret, err := c.delegate.GetBackendDisruptionStatisticsByJob(ctx, jobName, masterNodesUpdated)
+	return record(c.results, "GetBackendDisruptionStatisticsByJob", ret, err, jobName, masterNodesUpdated)
+}
+
+func (c *exportingCIDataClient) ListAggregatedTestRunsForJob(ctx context.Context, frequency, jobName string, startDay time.Time) ([]jobrunaggregatorapi.AggregatedTestRunRow, error) {
+	ret, err := c.delegate.ListAggregatedTestRunsForJob(ctx, frequency, jobName, startDay)
+	return record(c.results, "ListAggregatedTestRunsForJob", ret, err, frequency, jobName, startDay)
+}
+
+func (c *exportingCIDataClient) ListAllJobsWithVariants(ctx context.Context) ([]jobrunaggregatorapi.JobRowWithVariants, error) {
+	ret, err := c.delegate.ListAllJobsWithVariants(ctx)
+	return record(c.results, "ListAllJobsWithVariants", ret, err)
+}
+
+func (c *exportingCIDataClient) GetJobVariants(ctx context.Context, jobName string) (*jobrunaggregatorapi.JobRowWithVariants, error) {
+	ret, err := c.delegate.GetJobVariants(ctx, jobName)
+	return record(c.results, "GetJobVariants", ret, err, jobName)
+}
+
+func (c *exportingCIDataClient) ListAllJobs(ctx context.Context) ([]jobrunaggregatorapi.JobRow, error) {
+	ret, err := c.delegate.ListAllJobs(ctx)
+	return record(c.results, "ListAllJobs", ret, err)
+}
+
+func (c *exportingCIDataClient) ListProwJobRunsSince(ctx context.Context, since *time.Time) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error) {
+	ret, err := c.delegate.ListProwJobRunsSince(ctx, since)
+	return record(c.results, "ListProwJobRunsSince", ret, err, since)
+}
+
+func (c *exportingCIDataClient) ListDisruptionHistoricalData(ctx context.Context) ([]jobrunaggregatorapi.HistoricalData, error) {
+	ret, err := c.delegate.ListDisruptionHistoricalData(ctx)
+	return record(c.results, "ListDisruptionHistoricalData", ret, err)
+}
+
+func (c *exportingCIDataClient) ListAlertHistoricalData(ctx context.Context) ([]*jobrunaggregatorapi.AlertHistoricalDataRow, error) {
+	ret, err := c.delegate.ListAlertHistoricalData(ctx)
+	return record(c.results, "ListAlertHistoricalData", ret, err)
+}
+
+func (c *exportingCIDataClient) ListReleaseTags(ctx context.Context) (map[string]bool, error) {
+	ret, err := c.delegate.ListReleaseTags(ctx)
+	return record(c.results, "ListReleaseTags", ret, err)
+}
+
+func (c *exportingCIDataClient) GetLastJobRunEndTimeFromTable(ctx context.Context, table string) (*time.Time, error) {
+	ret, err := c.delegate.GetLastJobRunEndTimeFromTable(ctx, table)
+	return record(c.results, "GetLastJobRunEndTimeFromTable", ret, err, table)
+}
+
+func (c *exportingCIDataClient) ListUploadedJobRunIDsSinceFromTable(ctx context.Context, table string, since *time.Time) (map[string]bool, error) {
+	ret, err := c.delegate.ListUploadedJobRunIDsSinceFromTable(ctx, table, since)
+	return record(c.results, "ListUploadedJobRunIDsSinceFromTable", ret, err, table, since)
+}
+
+func (c *exportingCIDataClient) ListAllKnownAlerts(ctx context.Context) ([]*jobrunaggregatorapi.KnownAlertRow, error) {
+	ret, err := c.delegate.ListAllKnownAlerts(ctx)
+	return record(c.results, "ListAllKnownAlerts", ret, err)
+}
+
+func (c *exportingCIDataClient) ListReleases(ctx context.Context) ([]jobrunaggregatorapi.ReleaseRow, error) {
+	ret, err := c.delegate.ListReleases(ctx)
+	return record(c.results, "ListReleases", ret, err)
+}
+
+// localCIDataClient answers calls with the results exported by an exportingCIDataClient,
+// so that the analysis of CI data can be reproduced without access to BigQuery
+type localCIDataClient struct {
+	results *recordedResults
+}
+
+var _ CIDataClient = &localCIDataClient{}
+
+// NewLocalCIDataClient reads the results of the calls from the directory they were exported to
+func NewLocalCIDataClient(dir string) CIDataClient {
+	return &localCIDataClient{
+		results: newRecordedResults(dir),
+	}
+}
+
+func (c *localCIDataClient) GetJobRunForJobNameBeforeTime(_ context.Context, jobName string, targetTime time.Time) (string, error) {
+	return replay[string](c.results, "GetJobRunForJobNameBeforeTime", jobName, targetTime)
+}
+
+func (c *localCIDataClient) GetJobRunForJobNameAfterTime(_ context.Context, jobName string, targetTime time.Time) (string, error) {
+	return replay[string](c.results, "GetJobRunForJobNameAfterTime", jobName, targetTime)
+}
+
+func (c *localCIDataClient) GetBackendDisruptionRowCountByJob(_ context.Context, jobName, masterNodesUpdated string) (uint64, error) {
+	return replay[uint64](c.results, "GetBackendDisruptionRowCountByJob", jobName, masterNodesUpdated)
+}
+
+func (c *localCIDataClient) GetBackendDisruptionStatisticsByJob(_ context.Context, jobName, masterNodesUpdated string) ([]jobrunaggregatorapi.BackendDisruptionStatisticsRow, error) {
+	return replay[[]jobrunaggregatorapi.BackendDisruptionStatisticsRow](c.results, "GetBackendDisruptionStatisticsByJob", jobName, masterNodesUpdated)
+}
+
+func (c *localCIDataClient) ListAggregatedTestRunsForJob(_ context.Context, frequency, jobName string, startDay time.Time) ([]jobrunaggregatorapi.AggregatedTestRunRow, error) {
+	return replay[[]jobrunaggregatorapi.AggregatedTestRunRow](c.results, "ListAggregatedTestRunsForJob", frequency, jobName, startDay)
+}
+
+func (c *localCIDataClient) ListAllJobsWithVariants(context.Context) ([]jobrunaggregatorapi.JobRowWithVariants, error) {
+	return replay[[]jobrunaggregatorapi.JobRowWithVariants](c.results, "ListAllJobsWithVariants")
+}
+
+func (c *localCIDataClient) GetJobVariants(_ context.Context, jobName string) (*jobrunaggregatorapi.JobRowWithVariants, error) {
+	return replay[*jobrunaggregatorapi.JobRowWithVariants](c.results, "GetJobVariants", jobName)
+}
+
+func (c *localCIDataClient) ListAllJobs(context.Context) ([]jobrunaggregatorapi.JobRow, error) {
+	return replay[[]jobrunaggregatorapi.JobRow](c.results, "ListAllJobs")
+}
+
+func (c *localCIDataClient) ListProwJobRunsSince(_ context.Context, since *time.Time) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error) {
+	return replay[[]*jobrunaggregatorapi.TestPlatformProwJobRow](c.results, "ListProwJobRunsSince", since)
+}
+
+func (c *localCIDataClient) ListDisruptionHistoricalData(context.Context) ([]jobrunaggregatorapi.HistoricalData, error) {
+	// the interface cannot be deserialized, but the rows behind it are all disruption rows
+	rows, err := replay[[]*jobrunaggregatorapi.DisruptionHistoricalDataRow](c.results, "ListDisruptionHistoricalData")
+	if err != nil {
+		return nil, err
+	}
+	return jobrunaggregatorapi.ConvertToHistoricalData(rows), nil
+}
+
+func (c *localCIDataClient) ListAlertHistoricalData(context.Context) ([]*jobrunaggregatorapi.AlertHistoricalDataRow, error) {
+	return replay[[]*jobrunaggregatorapi.AlertHistoricalDataRow](c.results, "ListAlertHistoricalData")
+}
+
+func (c *localCIDataClient) ListReleaseTags(context.Context) (map[string]bool, error) {
+	return replay[map[string]bool](c.results, "ListReleaseTags")
+}
+
+func (c *localCIDataClient) GetLastJobRunEndTimeFromTable(_ context.Context, table string) (*time.Time, error) {
+	return replay[*time.Time](c.results, "GetLastJobRunEndTimeFromTable", table)
+}
+
+func (c *localCIDataClient) ListUploadedJobRunIDsSinceFromTable(_ context.Context, table string, since *time.Time) (map[string]bool, error) {
+	return replay[map[string]bool](c.results, "ListUploadedJobRunIDsSinceFromTable", table, since)
+}
+
+func (c *localCIDataClient) ListAllKnownAlerts(context.Context) ([]*jobrunaggregatorapi.KnownAlertRow, error) {
+	return replay[[]*jobrunaggregatorapi.KnownAlertRow](c.results, "ListAllKnownAlerts")
+}
+
+func (c *localCIDataClient) ListReleases(context.Context) ([]jobrunaggregatorapi.ReleaseRow, error) {
+	return replay[[]jobrunaggregatorapi.ReleaseRow](c.results, "ListReleases")
+}
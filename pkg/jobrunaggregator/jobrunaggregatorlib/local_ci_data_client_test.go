@@ -0,0 +1,140 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestLocalCIDataClient(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	targetTime := time.Date(2023, 6, 18, 13, 15, 0, 0, time.UTC)
+	jobs := []jobrunaggregatorapi.JobRowWithVariants{{JobName: "periodic-ci-job", Platform: "aws", Network: "ovn"}}
+	disruptions := []jobrunaggregatorapi.HistoricalData{&jobrunaggregatorapi.DisruptionHistoricalDataRow{
+		BackendName: "kube-api-new-connections",
+		HistoricalJobData: jobrunaggregatorapi.HistoricalJobData{
+			Release:            "4.14",
+			MasterNodesUpdated: bigquery.NullString{StringVal: "Y", Valid: true},
+			JobRuns:            100,
+		},
+		P99: "2",
+	}}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	delegate := NewMockCIDataClient(mockCtrl)
+	delegate.EXPECT().GetJobRunForJobNameBeforeTime(gomock.Any(), "periodic-ci-job", targetTime).Return("1670000000000000000", nil)
+	delegate.EXPECT().GetJobRunForJobNameBeforeTime(gomock.Any(), "periodic-ci-other-job", targetTime).Return("1680000000000000000", nil)
+	delegate.EXPECT().ListAllJobsWithVariants(gomock.Any()).Return(jobs, nil)
+	delegate.EXPECT().ListDisruptionHistoricalData(gomock.Any()).Return(disruptions, nil)
+
+	exporting := NewExportingCIDataClient(delegate, dir)
+	for _, jobName := range []string{"periodic-ci-job", "periodic-ci-other-job"} {
+		if _, err := exporting.GetJobRunForJobNameBeforeTime(ctx, jobName, targetTime); err != nil {
+			t.Fatalf("failed to export job run before time: %v", err)
+		}
+	}
+	if _, err := exporting.ListAllJobsWithVariants(ctx); err != nil {
+		t.Fatalf("failed to export jobs: %v", err)
+	}
+	if _, err := exporting.ListDisruptionHistoricalData(ctx); err != nil {
+		t.Fatalf("failed to export disruption data: %v", err)
+	}
+
+	local := NewLocalCIDataClient(dir)
+	jobRunID, err := local.GetJobRunForJobNameBeforeTime(ctx, "periodic-ci-other-job", targetTime)
+	if err != nil {
+		t.Fatalf("failed to read job run before time: %v", err)
+	}
+	if jobRunID != "1680000000000000000" {
+		t.Errorf("expected job run 1680000000000000000, got %s", jobRunID)
+	}
+	if _, err := local.GetJobRunForJobNameBeforeTime(ctx, "periodic-ci-job", targetTime.Add(time.Hour)); err == nil {
+		t.Error("expected an error reading a result that was not exported, got none")
+	}
+	actualJobs, err := local.ListAllJobsWithVariants(ctx)
+	if err != nil {
+		t.Fatalf("failed to read jobs: %v", err)
+	}
+	if diff := cmp.Diff(jobs, actualJobs); diff != "" {
+		t.Errorf("unexpected jobs: %s", diff)
+	}
+	actualDisruptions, err := local.ListDisruptionHistoricalData(ctx)
+	if err != nil {
+		t.Fatalf("failed to read disruption data: %v", err)
+	}
+	if diff := cmp.Diff(disruptions, actualDisruptions); diff != "" {
+		t.Errorf("unexpected disruption data: %s", diff)
+	}
+}
+
+func TestLocalCIGCSClient(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	prefix := "logs/periodic-ci-job"
+	for _, jobRun := range []struct {
+		id, payload string
+	}{
+		{id: "1670000000000000000", payload: "match"},
+		{id: "1680000000000000000", payload: "match"},
+		{id: "1690000000000000000", payload: "other"},
+		{id: "1700000000000000000", payload: "match"},
+	} {
+		runDir := filepath.Join(dir, filepath.FromSlash(prefix), jobRun.id)
+		if err := os.MkdirAll(filepath.Join(runDir, "artifacts", "junit"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		prowJob := `{"apiVersion":"prow.k8s.io/v1","kind":"ProwJob","metadata":{"name":"` + jobRun.id + `","labels":{"` + fakeMatchingLabel + `":"` + jobRun.payload + `"}}}`
+		if err := os.WriteFile(filepath.Join(runDir, "prowjob.json"), []byte(prowJob), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(runDir, "artifacts", "junit", "junit_e2e.xml"), []byte(`<testsuite name="e2e"></testsuite>`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := NewLocalCIGCSClient(dir, "test-platform-results")
+	jobRuns, err := client.ReadRelatedJobRuns(ctx, "periodic-ci-job", prefix, "1680000000000000000", "1700000000000000000", fakeProwJobMatcherFunc)
+	if err != nil {
+		t.Fatalf("failed to read related job runs: %v", err)
+	}
+	var ids []string
+	for _, jobRun := range jobRuns {
+		ids = append(ids, jobRun.GetJobRunID())
+	}
+	if diff := cmp.Diff([]string{"1680000000000000000"}, ids); diff != "" {
+		t.Errorf("unexpected job runs: %s", diff)
+	}
+
+	jobRun, err := client.ReadJobRunFromGCS(ctx, prefix, "periodic-ci-job", "1670000000000000000", logrus.New())
+	if err != nil {
+		t.Fatalf("failed to read job run: %v", err)
+	}
+	suites, err := jobRun.GetCombinedJUnitTestSuites(ctx)
+	if err != nil {
+		t.Fatalf("failed to read junit: %v", err)
+	}
+	if len(suites.Suites) != 1 || suites.Suites[0].Name != "e2e" {
+		t.Errorf("expected the e2e suite, got %v", suites.Suites)
+	}
+
+	exportDir := t.TempDir()
+	if err := jobrunaggregatorapi.ExportJobRun(ctx, jobRun, exportDir); err != nil {
+		t.Fatalf("failed to export job run: %v", err)
+	}
+	for _, name := range []string{"prowjob.json", "artifacts/junit/junit_e2e.xml"} {
+		if _, err := os.Stat(filepath.Join(exportDir, filepath.FromSlash(prefix), "1670000000000000000", filepath.FromSlash(name))); err != nil {
+			t.Errorf("expected %s to be exported: %v", name, err)
+		}
+	}
+}
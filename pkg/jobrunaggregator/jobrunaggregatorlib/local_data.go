@@ -0,0 +1,89 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ExportDirFlag is the flag of the export command holding the directory the CI data used
+// by the analyzers it runs is exported to
+const ExportDirFlag = "output-dir"
+
+// LocalDataFlags select whether CI data is read from BigQuery and GCS, exported from them
+// into a directory while doing so, or read from a directory it was exported to before.
+// In the directory, the results of the BigQuery queries are in bigquery/ and the artifacts
+// of the job runs are in gcs/, laid out like the GCS bucket.
+type LocalDataFlags struct {
+	// Dir is the directory CI data is read from instead of BigQuery and GCS
+	Dir string
+	// ExportDir is the directory CI data read from BigQuery and GCS is exported to
+	ExportDir string
+}
+
+func NewLocalDataFlags() *LocalDataFlags {
+	return &LocalDataFlags{}
+}
+
+func (f *LocalDataFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.Dir, "local-data-dir", f.Dir, "The optional directory CI data was exported to with the export command, read instead of BigQuery and GCS")
+}
+
+// Complete picks up the directory to export CI data to when the command is run by the export command
+func (f *LocalDataFlags) Complete(cmd *cobra.Command) error {
+	if cmd.Flags().Lookup(ExportDirFlag) == nil {
+		return nil
+	}
+	exportDir, err := cmd.Flags().GetString(ExportDirFlag)
+	if err != nil {
+		return err
+	}
+	f.ExportDir = exportDir
+	return nil
+}
+
+func (f *LocalDataFlags) Validate() error {
+	if len(f.Dir) > 0 && len(f.ExportDir) > 0 {
+		return fmt.Errorf("cannot export CI data read from --local-data-dir")
+	}
+	return nil
+}
+
+// Local determines whether CI data is read from a local directory
+func (f *LocalDataFlags) Local() bool {
+	return len(f.Dir) > 0
+}
+
+// NewCIDataClient creates a client reading CI data from the local directory or from BigQuery, exporting it when requested
+func (f *LocalDataFlags) NewCIDataClient(ctx context.Context, authentication *GoogleAuthenticationFlags, dataCoordinates *BigQueryDataCoordinates) (CIDataClient, error) {
+	if f.Local() {
+		return NewLocalCIDataClient(filepath.Join(f.Dir, "bigquery")), nil
+	}
+	bigQueryClient, err := authentication.NewBigQueryClient(ctx, dataCoordinates.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	ciDataClient := NewRetryingCIDataClient(NewCIDataClient(*dataCoordinates, bigQueryClient))
+	if len(f.ExportDir) > 0 {
+		ciDataClient = NewExportingCIDataClient(ciDataClient, filepath.Join(f.ExportDir, "bigquery"))
+	}
+	return ciDataClient, nil
+}
+
+// NewCIGCSClient creates a client reading job runs from the local directory or from GCS, exporting them when requested
+func (f *LocalDataFlags) NewCIGCSClient(ctx context.Context, authentication *GoogleAuthenticationFlags, gcsBucketName string) (CIGCSClient, error) {
+	if f.Local() {
+		return NewLocalCIGCSClient(filepath.Join(f.Dir, "gcs"), gcsBucketName), nil
+	}
+	ciGCSClient, err := authentication.NewCIGCSClient(ctx, gcsBucketName)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.ExportDir) > 0 {
+		ciGCSClient = NewExportingCIGCSClient(ciGCSClient, filepath.Join(f.ExportDir, "gcs"))
+	}
+	return ciGCSClient, nil
+}
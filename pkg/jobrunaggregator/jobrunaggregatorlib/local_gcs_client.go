@@ -0,0 +1,129 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// localCIGCSClient reads job runs from a local directory laid out like the GCS bucket,
+// for instance one the artifacts were exported to by an exportingCIGCSClient
+type localCIGCSClient struct {
+	dir           string
+	gcsBucketName string
+}
+
+var _ CIGCSClient = &localCIGCSClient{}
+
+// NewLocalCIGCSClient reads the job runs of the bucket from the directory they were exported to
+func NewLocalCIGCSClient(dir, gcsBucketName string) CIGCSClient {
+	return &localCIGCSClient{
+		dir:           dir,
+		gcsBucketName: gcsBucketName,
+	}
+}
+
+func (o *localCIGCSClient) ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation, jobName, jobRunID string, logger logrus.FieldLogger) (jobrunaggregatorapi.JobRunInfo, error) {
+	logger.Debugf("reading job run %s/%s from %s", jobGCSRootLocation, jobRunID, o.dir)
+
+	jobRun := jobrunaggregatorapi.NewLocalJobRun(o.dir, jobGCSRootLocation, jobName, jobRunID, o.gcsBucketName)
+	jobRun.SetGCSProwJobPath(fmt.Sprintf("%s/%s/prowjob.json", jobGCSRootLocation, jobRunID))
+	if _, err := jobRun.GetProwJob(ctx); err != nil {
+		logger.WithError(err).Error("failed to get prowjob")
+		return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err)
+	}
+
+	return jobRun, nil
+}
+
+func (o *localCIGCSClient) ReadRelatedJobRuns(ctx context.Context,
+	jobName, gcsPrefix, startingJobRunID, endingJobRunID string,
+	matcherFunc ProwJobMatcherFunc) ([]jobrunaggregatorapi.JobRunInfo, error) {
+
+	logrus.Debugf("searching %s for related job runs in %s between %s and %s", o.dir, gcsPrefix, startingJobRunID, endingJobRunID)
+	entries, err := os.ReadDir(filepath.Join(o.dir, filepath.FromSlash(gcsPrefix)))
+	if os.IsNotExist(err) {
+		return []jobrunaggregatorapi.JobRunInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the offsets behave like the ones of the GCS query: the start is inclusive and the end exclusive
+	if startingJobRunID == "" {
+		startingJobRunID = "0"
+	}
+	var jobRunIDs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() < startingJobRunID || (endingJobRunID != "" && entry.Name() >= endingJobRunID) {
+			continue
+		}
+		jobRunIDs = append(jobRunIDs, entry.Name())
+	}
+	sort.Strings(jobRunIDs)
+
+	relatedJobRuns := []jobrunaggregatorapi.JobRunInfo{}
+	for _, jobRunID := range jobRunIDs {
+		jobRun := jobrunaggregatorapi.NewLocalJobRun(o.dir, gcsPrefix, jobName, jobRunID, o.gcsBucketName)
+		jobRun.SetGCSProwJobPath(fmt.Sprintf("%s/%s/prowjob.json", gcsPrefix, jobRunID))
+
+		prowJob, err := jobRun.GetProwJob(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err)
+		}
+
+		if matcherFunc(prowJob) {
+			relatedJobRuns = append(relatedJobRuns, jobRun)
+		}
+	}
+	return relatedJobRuns, nil
+}
+
+// exportingCIGCSClient exports the artifacts of the job runs read by the delegate,
+// for a localCIGCSClient to read them
+type exportingCIGCSClient struct {
+	delegate CIGCSClient
+	dir      string
+}
+
+var _ CIGCSClient = &exportingCIGCSClient{}
+
+// NewExportingCIGCSClient exports the artifacts of the job runs read by the delegate into the directory
+func NewExportingCIGCSClient(delegate CIGCSClient, dir string) CIGCSClient {
+	return &exportingCIGCSClient{
+		delegate: delegate,
+		dir:      dir,
+	}
+}
+
+func (o *exportingCIGCSClient) ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation, jobName, jobRunID string, logger logrus.FieldLogger) (jobrunaggregatorapi.JobRunInfo, error) {
+	jobRun, err := o.delegate.ReadJobRunFromGCS(ctx, jobGCSRootLocation, jobName, jobRunID, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := jobrunaggregatorapi.ExportJobRun(ctx, jobRun, o.dir); err != nil {
+		return nil, fmt.Errorf("failed to export %q/%q: %w", jobName, jobRunID, err)
+	}
+	return jobRun, nil
+}
+
+func (o *exportingCIGCSClient) ReadRelatedJobRuns(ctx context.Context,
+	jobName, gcsPrefix, startingJobRunID, endingJobRunID string,
+	matcherFunc ProwJobMatcherFunc) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	jobRuns, err := o.delegate.ReadRelatedJobRuns(ctx, jobName, gcsPrefix, startingJobRunID, endingJobRunID, matcherFunc)
+	if err != nil {
+		return nil, err
+	}
+	for _, jobRun := range jobRuns {
+		if err := jobrunaggregatorapi.ExportJobRun(ctx, jobRun, o.dir); err != nil {
+			return nil, fmt.Errorf("failed to export %q/%q: %w", jobName, jobRun.GetJobRunID(), err)
+		}
+	}
+	return jobRuns, nil
+}
@@ -16,6 +16,7 @@ import (
 type JobRunHistoricalDataAnalyzerFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+	LocalData       *jobrunaggregatorlib.LocalDataFlags
 
 	NewFile         string
 	CurrentFile     string
@@ -32,12 +33,14 @@ func NewJobRunHistoricalDataAnalyzerFlags() *JobRunHistoricalDataAnalyzerFlags {
 	return &JobRunHistoricalDataAnalyzerFlags{
 		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
 		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		LocalData:       jobrunaggregatorlib.NewLocalDataFlags(),
 	}
 }
 
 func (f *JobRunHistoricalDataAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.LocalData.BindFlags(fs)
 
 	fs.StringVar(&f.DataType, "data-type", f.DataType, fmt.Sprintf("data type we are fetching %s", sets.List(supportedDataTypes)))
 	fs.StringVar(&f.NewFile, "new", f.NewFile, "local file with the new query results to compare against")
@@ -49,10 +52,13 @@ func (f *JobRunHistoricalDataAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 }
 
 func (f *JobRunHistoricalDataAnalyzerFlags) Validate() error {
-	if err := f.DataCoordinates.Validate(); err != nil && f.NewFile == "" {
+	if err := f.LocalData.Validate(); err != nil {
 		return err
 	}
-	if err := f.Authentication.Validate(); err != nil && f.NewFile == "" {
+	if err := f.DataCoordinates.Validate(); err != nil && f.NewFile == "" && !f.LocalData.Local() {
+		return err
+	}
+	if err := f.Authentication.Validate(); err != nil && f.NewFile == "" && !f.LocalData.Local() {
 		return err
 	}
 
@@ -76,15 +82,11 @@ func (f *JobRunHistoricalDataAnalyzerFlags) Validate() error {
 }
 
 func (f *JobRunHistoricalDataAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunHistoricalDataAnalyzerOptions, error) {
-	bigQueryClient, err := f.Authentication.NewBigQueryClient(ctx, f.DataCoordinates.ProjectID)
+	ciDataClient, err := f.LocalData.NewCIDataClient(ctx, f.Authentication, f.DataCoordinates)
 	if err != nil && f.NewFile == "" {
 		return nil, err
 	}
 
-	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
-		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
-	)
-
 	if f.OutputFile == "" {
 		f.OutputFile = fmt.Sprintf("results_%s.json", f.DataType)
 	}
@@ -112,6 +114,9 @@ func NewJobRunHistoricalDataAnalyzerCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
+			if err := f.LocalData.Complete(cmd); err != nil {
+				logrus.WithError(err).Fatal("Flags are invalid")
+			}
 			if err := f.Validate(); err != nil {
 				logrus.WithError(err).Fatal("Flags are invalid")
 			}
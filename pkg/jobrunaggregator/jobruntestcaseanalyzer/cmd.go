@@ -82,6 +82,7 @@ func (s *jobGCSPrefixSlice) Type() string {
 type JobRunsTestCaseAnalyzerFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+	LocalData       *jobrunaggregatorlib.LocalDataFlags
 
 	TestGroup                   string
 	WorkingDir                  string
@@ -107,6 +108,7 @@ func NewJobRunsTestCaseAnalyzerFlags() *JobRunsTestCaseAnalyzerFlags {
 	return &JobRunsTestCaseAnalyzerFlags{
 		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
 		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		LocalData:       jobrunaggregatorlib.NewLocalDataFlags(),
 
 		WorkingDir:                  "test-case-analyzer-working-dir",
 		EstimatedJobStartTimeString: time.Now().Format(kubeTimeSerializationLayout),
@@ -120,6 +122,7 @@ const kubeTimeSerializationLayout = time.RFC3339
 func (f *JobRunsTestCaseAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.LocalData.BindFlags(fs)
 
 	fs.StringVar(&f.TestGroup, "test-group", "install", "Test group to analyze, like install or overall")
 	fs.StringVar(&f.PayloadTag, "payload-tag", f.PayloadTag, "The release controller payload tag to analyze test case status, like 4.9.0-0.ci-2021-07-19-185802")
@@ -206,6 +209,9 @@ payload 4.11.0-0.nightly-2022-04-28-102605, run this command:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
+			if err := f.LocalData.Complete(cmd); err != nil {
+				logrus.WithError(err).Fatal("Flags are invalid")
+			}
 			if err := f.Validate(); err != nil {
 				logrus.WithError(err).Fatal("Flags are invalid")
 			}
@@ -237,11 +243,16 @@ func (f *JobRunsTestCaseAnalyzerFlags) Validate() error {
 	if _, err := time.Parse(kubeTimeSerializationLayout, f.EstimatedJobStartTimeString); err != nil {
 		return err
 	}
-	if err := f.DataCoordinates.Validate(); err != nil {
+	if err := f.LocalData.Validate(); err != nil {
 		return err
 	}
-	if err := f.Authentication.Validate(); err != nil {
-		return err
+	if !f.LocalData.Local() {
+		if err := f.DataCoordinates.Validate(); err != nil {
+			return err
+		}
+		if err := f.Authentication.Validate(); err != nil {
+			return err
+		}
 	}
 	if f.TestGroup == "" {
 		return fmt.Errorf("test group has to be specified")
@@ -321,15 +332,12 @@ func (f *JobRunsTestCaseAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunTe
 		return nil, err
 	}
 
-	bigQueryClient, err := f.Authentication.NewBigQueryClient(ctx, f.DataCoordinates.ProjectID)
+	ciDataClient, err := f.LocalData.NewCIDataClient(ctx, f.Authentication, f.DataCoordinates)
 	if err != nil {
 		return nil, err
 	}
-	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
-		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
-	)
 
-	ciGCSClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket)
+	ciGCSClient, err := f.LocalData.NewCIGCSClient(ctx, f.Authentication, f.GCSBucket)
 	if err != nil {
 		return nil, err
 	}
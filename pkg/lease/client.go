@@ -2,6 +2,7 @@ package lease
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strconv"
@@ -46,6 +47,11 @@ type Client interface {
 	//AcquireIfAvailableImmediately leases `n` resources and returns the lease names.
 	// Does not block, and only leases the resources if they are available right away.
 	AcquireIfAvailableImmediately(rtype string, n uint, cancel context.CancelFunc) ([]string, error)
+	// AcquireFirstAvailable leases `n` resources of the first of `rtypes`, in order
+	// of preference, which has that many free and returns the lease names and their
+	// type. Will block until resources of any of the types are available or the
+	// acquisition times out, like Acquire.
+	AcquireFirstAvailable(rtypes []string, n uint, ctx context.Context, cancel context.CancelFunc) ([]string, string, error)
 	// Heartbeat updates all leases. It calls the cancellation function of each
 	// lease it fails to update.
 	Heartbeat() error
@@ -59,8 +65,9 @@ type Client interface {
 	Metrics(rtype string) (Metrics, error)
 }
 
-// NewClient creates a client that leases resources with the specified owner.
-func NewClient(owner, url, username string, passwordGetter func() []byte, retries int, acquireTimeout time.Duration) (Client, error) {
+// NewClient creates a client that leases resources with the specified owner,
+// competing for them with the given priority.
+func NewClient(owner, url, username string, passwordGetter func() []byte, retries int, acquireTimeout time.Duration, priority Priority) (Client, error) {
 	randId = func() string {
 		return strconv.Itoa(rand.Int())
 	}
@@ -68,17 +75,33 @@ func NewClient(owner, url, username string, passwordGetter func() []byte, retrie
 	if err != nil {
 		return nil, err
 	}
-	return newClient(c, retries, acquireTimeout), nil
+	return newClient(c, retries, acquireTimeout, priority), nil
 }
 
 // for test mocking
 var randId func() string
 
-func newClient(boskos boskosClient, retries int, acquireTimeout time.Duration) Client {
+// requestTTL is longer than the time after which the lease server drops
+// requests which are not polled, 30s by default
+const requestTTL = 45 * time.Second
+
+// maxRequeues is how many times a request gives up its place in the queue of the
+// lease server, after which it keeps it so that no job waits indefinitely for
+// those with higher priority
+const maxRequeues = 3
+
+// pollInterval is how often the lease server is asked for any of multiple types
+// of resource, which it cannot wait for with a single request
+const pollInterval = 10 * time.Second
+
+func newClient(boskos boskosClient, retries int, acquireTimeout time.Duration, priority Priority) Client {
 	return &client{
 		boskos:         boskos,
 		retries:        retries,
 		acquireTimeout: acquireTimeout,
+		requeueAfter:   priority.requeueAfter(),
+		requestTTL:     requestTTL,
+		pollInterval:   pollInterval,
 		leases:         make(map[string]*lease),
 	}
 }
@@ -88,7 +111,15 @@ type client struct {
 	boskos         boskosClient
 	retries        int
 	acquireTimeout time.Duration
-	leases         map[string]*lease
+	// requeueAfter is how long a request keeps its place in the queue of
+	// the lease server, if set
+	requeueAfter time.Duration
+	// requestTTL is how long a request which is no longer polled is kept
+	// in the queue of the lease server
+	requestTTL time.Duration
+	// pollInterval is how long to wait between attempts to lease any of multiple types
+	pollInterval time.Duration
+	leases       map[string]*lease
 }
 
 type lease struct {
@@ -107,7 +138,7 @@ func (c *client) Acquire(rtype string, n uint, ctx context.Context, cancel conte
 	var ret []string
 	// TODO `m` processes may fight for the last `m * n` remaining leases
 	for i := uint(0); i < n; i++ {
-		r, err := c.boskos.AcquireWaitWithPriority(ctx, rtype, freeState, leasedState, randId())
+		r, err := c.acquireWait(ctx, rtype)
 		if err != nil {
 			return nil, err
 		}
@@ -119,6 +150,31 @@ func (c *client) Acquire(rtype string, n uint, ctx context.Context, cancel conte
 	return ret, nil
 }
 
+// acquireWait waits for a resource with a single request. Whenever requeueAfter
+// passes, the request stops polling the lease server until it is dropped from
+// the queue, then rejoins at its end, up to maxRequeues times.
+func (c *client) acquireWait(ctx context.Context, rtype string) (*common.Resource, error) {
+	id := randId()
+	for requeues := 0; ; requeues++ {
+		if c.requeueAfter == 0 || requeues == maxRequeues {
+			return c.boskos.AcquireWaitWithPriority(ctx, rtype, freeState, leasedState, id)
+		}
+		requestCtx, cancel := context.WithTimeout(ctx, c.requeueAfter)
+		r, err := c.boskos.AcquireWaitWithPriority(requestCtx, rtype, freeState, leasedState, id)
+		expired := errors.Is(requestCtx.Err(), context.DeadlineExceeded)
+		cancel()
+		if err == nil || ctx.Err() != nil || !expired {
+			return r, err
+		}
+		logrus.Debugf("Yielding the place in the queue for a %s lease to requests with higher priority.", rtype)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(c.requestTTL):
+		}
+	}
+}
+
 func (c *client) AcquireIfAvailableImmediately(rtype string, n uint, cancel context.CancelFunc) ([]string, error) {
 	var ret []string
 	for i := uint(0); i < n; i++ {
@@ -134,6 +190,46 @@ func (c *client) AcquireIfAvailableImmediately(rtype string, n uint, cancel cont
 	return ret, nil
 }
 
+func (c *client) AcquireFirstAvailable(rtypes []string, n uint, ctx context.Context, cancel context.CancelFunc) ([]string, string, error) {
+	ctx, cancelAcquire := context.WithTimeout(ctx, c.acquireTimeout)
+	defer cancelAcquire()
+	for {
+		for _, rtype := range rtypes {
+			names, err := c.acquireAllImmediately(rtype, n, cancel)
+			if err == nil {
+				return names, rtype, nil
+			}
+			if !errors.Is(err, ErrNotFound) {
+				logrus.WithError(err).Warnf("Could not acquire %d lease(s) for %s", n, rtype)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, "", fmt.Errorf("no %d lease(s) of any of %v became free: %w", n, rtypes, ErrNotFound)
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// acquireAllImmediately leases `n` resources of a type if they are all free,
+// releasing those acquired already when one is not.
+func (c *client) acquireAllImmediately(rtype string, n uint, cancel context.CancelFunc) ([]string, error) {
+	var names []string
+	for i := uint(0); i < n; i++ {
+		acquired, err := c.AcquireIfAvailableImmediately(rtype, 1, cancel)
+		if err != nil {
+			for _, name := range names {
+				if err := c.Release(name); err != nil {
+					logrus.WithError(err).Warnf("Could not release lease %s", name)
+				}
+			}
+			return nil, err
+		}
+		names = append(names, acquired...)
+	}
+	return names, nil
+}
+
 func (c *client) Heartbeat() error {
 	c.Lock()
 	defer c.Unlock()
@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 
 	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/boskos/common"
 )
 
 func TestAcquire(t *testing.T) {
@@ -131,3 +136,118 @@ func TestHeartbeatRetries(t *testing.T) {
 		})
 	}
 }
+
+// queueingBoskos only hands out a resource once it was asked for it three times
+type queueingBoskos struct {
+	fakeClient
+	requests []string
+}
+
+func (c *queueingBoskos) AcquireWaitWithPriority(ctx context.Context, rtype, state, dest, requestID string) (*common.Resource, error) {
+	c.requests = append(c.requests, requestID)
+	if len(c.requests) < 3 {
+		<-ctx.Done()
+		return nil, ErrNotFound
+	}
+	return &common.Resource{Name: rtype}, nil
+}
+
+func TestAcquireRequeues(t *testing.T) {
+	ids := 0
+	randId = func() string {
+		ids++
+		return strconv.Itoa(ids)
+	}
+	boskos := &queueingBoskos{}
+	c := newClient(boskos, 0, time.Minute, PriorityRehearsal).(*client)
+	c.requeueAfter = time.Millisecond
+	c.requestTTL = time.Millisecond
+	names, err := c.Acquire("rtype", 1, context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"rtype"}, names); diff != "" {
+		t.Errorf("unexpected leases: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"1", "1", "1"}, boskos.requests); diff != "" {
+		t.Errorf("expected the request to be reused each time it was requeued: %s", diff)
+	}
+
+	boskos = &queueingBoskos{}
+	c = newClient(boskos, 0, time.Millisecond, PriorityPayload).(*client)
+	if _, err := c.Acquire("rtype", 1, context.Background(), nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the acquisition to time out, got %v", err)
+	}
+	if diff := cmp.Diff([]string{"2"}, boskos.requests); diff != "" {
+		t.Errorf("expected a request with the highest priority to keep its place: %s", diff)
+	}
+}
+
+// patientBoskos only hands out a resource to a request which does not give up its place in the queue
+type patientBoskos struct {
+	fakeClient
+	requests int
+}
+
+func (c *patientBoskos) AcquireWaitWithPriority(ctx context.Context, rtype, state, dest, requestID string) (*common.Resource, error) {
+	c.requests++
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < time.Second {
+		<-ctx.Done()
+		return nil, ErrNotFound
+	}
+	return &common.Resource{Name: rtype}, nil
+}
+
+func TestAcquireRequeuesAreCapped(t *testing.T) {
+	boskos := &patientBoskos{}
+	c := newClient(boskos, 0, time.Minute, PriorityRehearsal).(*client)
+	c.requeueAfter = time.Millisecond
+	c.requestTTL = time.Millisecond
+	if _, err := c.Acquire("rtype", 1, context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := maxRequeues + 1; boskos.requests != expected {
+		t.Errorf("expected the request to keep its place after %d requeues, got %d requests", maxRequeues, boskos.requests)
+	}
+}
+
+// scarceBoskos only has a free resource of a type once it was asked for it three times
+type scarceBoskos struct {
+	fakeClient
+	requests map[string]int
+	free     string
+}
+
+func (c *scarceBoskos) Acquire(rtype, state, dest string) (*common.Resource, error) {
+	c.requests[rtype]++
+	if rtype != c.free || c.requests[rtype] < 3 {
+		return nil, ErrNotFound
+	}
+	return &common.Resource{Name: rtype}, nil
+}
+
+func TestAcquireFirstAvailable(t *testing.T) {
+	boskos := &scarceBoskos{requests: map[string]int{}, free: "fallback"}
+	c := newClient(boskos, 0, time.Minute, PriorityPayload).(*client)
+	c.pollInterval = time.Millisecond
+	names, rtype, err := c.AcquireFirstAvailable([]string{"preferred", "fallback"}, 1, context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"fallback"}, names); diff != "" {
+		t.Errorf("unexpected leases: %s", diff)
+	}
+	if rtype != "fallback" {
+		t.Errorf("expected a lease of the fallback type, got %s", rtype)
+	}
+	if diff := cmp.Diff(map[string]int{"preferred": 3, "fallback": 3}, boskos.requests); diff != "" {
+		t.Errorf("expected all types to be polled until one was free: %s", diff)
+	}
+
+	boskos = &scarceBoskos{requests: map[string]int{}}
+	c = newClient(boskos, 0, time.Millisecond, PriorityPayload).(*client)
+	c.pollInterval = time.Hour
+	if _, _, err := c.AcquireFirstAvailable([]string{"preferred", "fallback"}, 1, context.Background(), nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the acquisition to time out, got %v", err)
+	}
+}
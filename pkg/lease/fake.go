@@ -27,7 +27,7 @@ func NewFakeClient(owner, url string, retries int, failures map[string]error, ca
 		owner:    owner,
 		failures: failures,
 		calls:    calls,
-	}, retries, time.Duration(0))
+	}, retries, time.Duration(0), PriorityPayload)
 }
 
 func (c *fakeClient) addCall(call string, args ...string) error {
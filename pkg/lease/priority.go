@@ -0,0 +1,166 @@
+package lease
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Priority determines how a job competes with others for leases. The lease
+// server hands out resources in the order in which requests were first seen
+// and has no notion of priority, so jobs with lower priority give up their
+// place in the queue and rejoin at its end a limited number of times, letting
+// requests from more important jobs which arrived in the meantime go first.
+type Priority int
+
+const (
+	// PriorityUnknown is used for jobs which cannot be told apart from payload
+	// jobs, which keep their place in the queue
+	PriorityUnknown Priority = iota
+	PriorityRehearsal
+	PriorityPeriodic
+	PriorityPresubmit
+	PriorityPostsubmit
+	PriorityPayload
+)
+
+var priorityNames = map[Priority]string{
+	PriorityUnknown:    "unknown",
+	PriorityRehearsal:  "rehearsal",
+	PriorityPeriodic:   "periodic",
+	PriorityPresubmit:  "presubmit",
+	PriorityPostsubmit: "postsubmit",
+	PriorityPayload:    "payload",
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + p.String() + `"`), nil
+}
+
+func (p *Priority) UnmarshalJSON(raw []byte) error {
+	value := strings.Trim(string(raw), `"`)
+	for priority, name := range priorityNames {
+		if name == value {
+			*p = priority
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown priority %q", value)
+}
+
+// requeueAfter is how long a request waits in the queue of the lease server
+// before it rejoins at the end; requests which are never requeued keep their
+// place until they are fulfilled.
+func (p Priority) requeueAfter() time.Duration {
+	switch p {
+	case PriorityRehearsal:
+		return 2 * time.Minute
+	case PriorityPeriodic:
+		return 5 * time.Minute
+	case PriorityPresubmit:
+		return 10 * time.Minute
+	case PriorityPostsubmit:
+		return 30 * time.Minute
+	default:
+		return 0
+	}
+}
+
+// PriorityRule assigns a priority to jobs that have all the labels of the rule.
+// A label with an empty value only needs to be present on the job.
+type PriorityRule struct {
+	Labels   map[string]string `json:"labels"`
+	Priority Priority          `json:"priority"`
+}
+
+func (r PriorityRule) matches(labels map[string]string) bool {
+	for key, value := range r.Labels {
+		actual, ok := labels[key]
+		if !ok || (value != "" && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// PriorityPolicy maps job labels to priorities. The first matching rule wins;
+// jobs no rule matches are prioritized by their type.
+type PriorityPolicy struct {
+	Rules []PriorityRule `json:"rules"`
+}
+
+// DefaultPriorityPolicy recognizes jobs triggered by the release controller and rehearsals
+var DefaultPriorityPolicy = PriorityPolicy{Rules: []PriorityRule{
+	{Labels: map[string]string{"release.openshift.io/verify": "true"}, Priority: PriorityPayload},
+	{Labels: map[string]string{"ci.openshift.io/rehearse": ""}, Priority: PriorityRehearsal},
+}}
+
+// LoadPriorityPolicy reads a policy from a YAML file
+func LoadPriorityPolicy(path string) (*PriorityPolicy, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the lease priority policy: %w", err)
+	}
+	var policy PriorityPolicy
+	if err := yaml.UnmarshalStrict(raw, &policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the lease priority policy: %w", err)
+	}
+	for i, rule := range policy.Rules {
+		if len(rule.Labels) == 0 {
+			return nil, fmt.Errorf("rule %d of the lease priority policy does not match any labels", i)
+		}
+	}
+	return &policy, nil
+}
+
+// PriorityFor determines the priority of a job from its labels and type
+func (p *PriorityPolicy) PriorityFor(jobType prowapi.ProwJobType, jobName string, labels map[string]string) Priority {
+	for _, rule := range p.Rules {
+		if rule.matches(labels) {
+			return rule.Priority
+		}
+	}
+	switch {
+	case strings.HasPrefix(jobName, "rehearse-"):
+		return PriorityRehearsal
+	case jobType == prowapi.PostsubmitJob:
+		return PriorityPostsubmit
+	case jobType == prowapi.PeriodicJob:
+		return PriorityPeriodic
+	default:
+		return PriorityPresubmit
+	}
+}
+
+// ParseDownwardAPILabels parses labels in the format the Kubernetes downward
+// API projects them into files, one `key="value"` pair per line
+func ParseDownwardAPILabels(raw []byte) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, quoted, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid label %q", line)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of label %q: %w", key, err)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
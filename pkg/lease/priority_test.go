@@ -0,0 +1,143 @@
+package lease
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestPriorityFor(t *testing.T) {
+	custom := PriorityPolicy{Rules: []PriorityRule{
+		{Labels: map[string]string{"job-release": "4.17", "job-type": ""}, Priority: PriorityPostsubmit},
+	}}
+	for _, tc := range []struct {
+		name     string
+		policy   PriorityPolicy
+		jobType  prowapi.ProwJobType
+		jobName  string
+		labels   map[string]string
+		expected Priority
+	}{{
+		name:     "payload job",
+		policy:   DefaultPriorityPolicy,
+		jobType:  prowapi.PeriodicJob,
+		jobName:  "periodic-ci-openshift-release-master-nightly-4.17-e2e-aws",
+		labels:   map[string]string{"release.openshift.io/verify": "true"},
+		expected: PriorityPayload,
+	}, {
+		name:     "rehearsal of a periodic",
+		policy:   DefaultPriorityPolicy,
+		jobType:  prowapi.PeriodicJob,
+		jobName:  "rehearse-1234-periodic-ci-org-repo-master-e2e",
+		labels:   map[string]string{"ci.openshift.io/rehearse": "1234"},
+		expected: PriorityRehearsal,
+	}, {
+		name:     "rehearsal without labels is recognized by its name",
+		policy:   DefaultPriorityPolicy,
+		jobType:  prowapi.PresubmitJob,
+		jobName:  "rehearse-1234-pull-ci-org-repo-master-e2e",
+		expected: PriorityRehearsal,
+	}, {
+		name:     "postsubmit",
+		policy:   DefaultPriorityPolicy,
+		jobType:  prowapi.PostsubmitJob,
+		jobName:  "branch-ci-org-repo-master-images",
+		expected: PriorityPostsubmit,
+	}, {
+		name:     "presubmit",
+		policy:   DefaultPriorityPolicy,
+		jobType:  prowapi.PresubmitJob,
+		jobName:  "pull-ci-org-repo-master-e2e",
+		expected: PriorityPresubmit,
+	}, {
+		name:     "periodic",
+		policy:   DefaultPriorityPolicy,
+		jobType:  prowapi.PeriodicJob,
+		jobName:  "periodic-ci-org-repo-master-e2e",
+		expected: PriorityPeriodic,
+	}, {
+		name:     "custom rule matching all labels",
+		policy:   custom,
+		jobType:  prowapi.PeriodicJob,
+		jobName:  "periodic-ci-org-repo-master-e2e",
+		labels:   map[string]string{"job-release": "4.17", "job-type": "upgrade"},
+		expected: PriorityPostsubmit,
+	}, {
+		name:     "custom rule with a different value",
+		policy:   custom,
+		jobType:  prowapi.PeriodicJob,
+		jobName:  "periodic-ci-org-repo-master-e2e",
+		labels:   map[string]string{"job-release": "4.16", "job-type": "upgrade"},
+		expected: PriorityPeriodic,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, tc.policy.PriorityFor(tc.jobType, tc.jobName, tc.labels)); diff != "" {
+				t.Errorf("unexpected priority: %s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadPriorityPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		raw           string
+		expected      *PriorityPolicy
+		expectedError string
+	}{{
+		name: "valid policy",
+		raw: `rules:
+- labels:
+    release.openshift.io/verify: "true"
+  priority: payload
+- labels:
+    ci.openshift.io/rehearse: ""
+  priority: rehearsal
+`,
+		expected: &DefaultPriorityPolicy,
+	}, {
+		name:          "unknown priority",
+		raw:           "rules:\n- labels:\n    a: b\n  priority: urgent\n",
+		expectedError: `failed to unmarshal the lease priority policy: error unmarshaling JSON: while decoding JSON: unknown priority "urgent"`,
+	}, {
+		name:          "rule without labels",
+		raw:           "rules:\n- priority: payload\n",
+		expectedError: "rule 0 of the lease priority policy does not match any labels",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(path, []byte(tc.raw), 0644); err != nil {
+				t.Fatal(err)
+			}
+			policy, err := LoadPriorityPolicy(path)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedError, actualError); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, policy); diff != "" {
+				t.Errorf("unexpected policy: %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseDownwardAPILabels(t *testing.T) {
+	labels, err := ParseDownwardAPILabels([]byte("ci.openshift.io/rehearse=\"1234\"\nprow.k8s.io/type=\"presubmit\"\nquoted=\"a \\\"b\\\"\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"ci.openshift.io/rehearse": "1234", "prow.k8s.io/type": "presubmit", "quoted": `a "b"`}
+	if diff := cmp.Diff(expected, labels); diff != "" {
+		t.Errorf("unexpected labels: %s", diff)
+	}
+	if _, err := ParseDownwardAPILabels([]byte("invalid")); err == nil {
+		t.Error("expected an error for a line without a value")
+	}
+}
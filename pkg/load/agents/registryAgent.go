@@ -1,6 +1,7 @@
 package agents
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -19,8 +20,21 @@ import (
 // memory and resolve ReleaseBuildConfigurations using the registry
 type RegistryAgent interface {
 	ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error)
+	// ResolveConfigAtRevision resolves the config against the registry loaded from the revision of
+	// the release repository, or the current registry if the revision is empty. It returns the
+	// revision of the registry that was used, which is empty if the registry is not versioned.
+	// Only the most recent revisions loaded by the agent are held, so older revisions and any
+	// loaded before the agent was started are not available.
+	ResolveConfigAtRevision(config api.ReleaseBuildConfiguration, revision string) (api.ReleaseBuildConfiguration, string, error)
 	GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata)
+	// GetRegistryContent returns the components of the registry along with the generation they
+	// were loaded at, read at once so they are consistent with each other
+	GetRegistryContent() RegistryContent
+	// GetRegistryGraph returns the graph of the registry elements, used to find which elements contain others
+	GetRegistryGraph() registry.NodeByName
 	GetGeneration() int
+	// GetRevision returns the revision of the release repository the current registry was loaded from
+	GetRevision() string
 	GetClusterProfiles() api.ClusterProfilesMap
 	GetClusterProfileDetails(profileName string) (*api.ClusterProfileDetails, error)
 	registry.Resolver
@@ -31,11 +45,17 @@ type registryAgent struct {
 	resolver        registry.Resolver
 	registryPath    string
 	generation      int
+	revision        string
+	revisionFile    string
+	history         []registrySnapshot
+	historySize     int
 	errorMetrics    *prometheus.CounterVec
 	flags           load.RegistryFlag
 	references      registry.ReferenceByName
 	chains          registry.ChainByName
 	workflows       registry.WorkflowByName
+	observers       registry.ObserverByName
+	graph           registry.NodeByName
 	clusterProfiles api.ClusterProfilesMap
 	documentation   map[string]string
 	metadata        api.RegistryMetadata
@@ -53,6 +73,26 @@ func init() {
 	prometheus.MustRegister(registryReloadTimeMetric)
 }
 
+// ErrUnknownRevision is returned when resolving against a revision of the registry that is not held
+var ErrUnknownRevision = errors.New("registry revision is not among the recent revisions available")
+
+// RegistryContent is the content of one generation of the registry
+type RegistryContent struct {
+	Generation    int
+	References    registry.ReferenceByName
+	Chains        registry.ChainByName
+	Workflows     registry.WorkflowByName
+	Observers     registry.ObserverByName
+	Documentation map[string]string
+	Metadata      api.RegistryMetadata
+}
+
+// registrySnapshot is a version of the registry loaded in the past
+type registrySnapshot struct {
+	revision string
+	resolver registry.Resolver
+}
+
 type RegistryAgentOptions struct {
 	// ErrorMetric holds the CounterVec to count errors on. It must include a `error` label
 	// or the agent panics on the first error.
@@ -61,6 +101,14 @@ type RegistryAgentOptions struct {
 	// from the filepath. Defaults to true.
 	FlatRegistry            *bool
 	UniversalSymlinkWatcher *UniversalSymlinkWatcher
+	// History is the number of revisions of the registry, including the current one, that
+	// configurations can be resolved against. It is kept in memory, so only revisions loaded
+	// since the agent was created are available. Defaults to 1.
+	History int
+	// RevisionFile is the path to the file holding the revision of the registry, written by
+	// the sync of the repository before the registry is updated. Defaults to the version file
+	// of the registry.
+	RevisionFile string
 }
 
 type RegistryAgentOption func(*RegistryAgentOptions)
@@ -71,6 +119,18 @@ func WithRegistryMetrics(m *prometheus.CounterVec) RegistryAgentOption {
 	}
 }
 
+func WithRegistryHistory(revisions int) RegistryAgentOption {
+	return func(o *RegistryAgentOptions) {
+		o.History = revisions
+	}
+}
+
+func WithRegistryRevisionFile(path string) RegistryAgentOption {
+	return func(o *RegistryAgentOptions) {
+		o.RevisionFile = path
+	}
+}
+
 func WithRegistryFlat(v bool) RegistryAgentOption {
 	return func(o *RegistryAgentOptions) {
 		o.FlatRegistry = &v
@@ -90,6 +150,9 @@ func NewRegistryAgent(registryPath string, errCh chan error, opts ...RegistryAge
 	if opt.FlatRegistry == nil {
 		opt.FlatRegistry = utilpointer.Bool(true)
 	}
+	if opt.History < 1 {
+		opt.History = 1
+	}
 	flags := load.RegistryMetadata | load.RegistryDocumentation
 	if *opt.FlatRegistry {
 		flags |= load.RegistryFlat
@@ -99,6 +162,8 @@ func NewRegistryAgent(registryPath string, errCh chan error, opts ...RegistryAge
 		lock:         &sync.RWMutex{},
 		errorMetrics: opt.ErrorMetric,
 		flags:        flags,
+		historySize:  opt.History,
+		revisionFile: opt.RevisionFile,
 	}
 	// Load config once so we fail early if that doesn't work and are ready as soon as we return
 	if err := a.loadRegistry(); err != nil {
@@ -119,6 +184,22 @@ func (a *registryAgent) ResolveConfig(config api.ReleaseBuildConfiguration) (api
 	return registry.ResolveConfig(a.resolver, config)
 }
 
+func (a *registryAgent) ResolveConfigAtRevision(config api.ReleaseBuildConfiguration, revision string) (api.ReleaseBuildConfiguration, string, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if revision == "" || revision == a.revision {
+		resolved, err := registry.ResolveConfig(a.resolver, config)
+		return resolved, a.revision, err
+	}
+	for _, snapshot := range a.history {
+		if snapshot.revision == revision {
+			resolved, err := registry.ResolveConfig(snapshot.resolver, config)
+			return resolved, revision, err
+		}
+	}
+	return api.ReleaseBuildConfiguration{}, "", fmt.Errorf("%w: %s", ErrUnknownRevision, revision)
+}
+
 func (a *registryAgent) ResolveWorkflow(name string) (api.MultiStageTestConfigurationLiteral, error) {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
@@ -137,10 +218,36 @@ func (a *registryAgent) GetGeneration() int {
 	return a.generation
 }
 
+func (a *registryAgent) GetRevision() string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.revision
+}
+
 func (a *registryAgent) GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata) {
 	return a.references, a.chains, a.workflows, a.documentation, a.metadata
 }
 
+func (a *registryAgent) GetRegistryContent() RegistryContent {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return RegistryContent{
+		Generation:    a.generation,
+		References:    a.references,
+		Chains:        a.chains,
+		Workflows:     a.workflows,
+		Observers:     a.observers,
+		Documentation: a.documentation,
+		Metadata:      a.metadata,
+	}
+}
+
+func (a *registryAgent) GetRegistryGraph() registry.NodeByName {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.graph
+}
+
 // GetClusterProfiles returns a map containing all existing cluster profiles
 func (a *registryAgent) GetClusterProfiles() api.ClusterProfilesMap {
 	return a.clusterProfiles
@@ -166,13 +273,24 @@ func (a *registryAgent) loadRegistry() error {
 			recordErrorForMetric(a.errorMetrics, "failed to load ci-operator registry")
 			return time.Duration(0), fmt.Errorf("failed to load ci-operator registry (%w)", err)
 		}
+		graph, err := registry.NewGraph(references, chains, workflows, observers)
+		if err != nil {
+			// the graph is only used to find which tests use registry elements, which
+			// should not prevent the registry from being served
+			recordErrorForMetric(a.errorMetrics, "failed to create the registry graph")
+			logrus.WithError(err).Error("Failed to create the registry graph, usages of registry elements may be incomplete")
+		}
 		a.references = references
 		a.chains = chains
 		a.workflows = workflows
+		a.observers = observers
+		a.graph = graph
 		a.documentation = documentation
 		a.metadata = metadata
 		a.clusterProfiles = clusterProfiles
 		a.resolver = registry.NewResolver(references, chains, workflows, observers)
+		a.revision = a.registryRevision()
+		a.recordSnapshot()
 		a.generation++
 		return time.Since(startTime), nil
 	}()
@@ -184,6 +302,38 @@ func (a *registryAgent) loadRegistry() error {
 	return nil
 }
 
+// registryRevision determines the revision of the registry from the file written
+// by the sync if there is one, or from the version file of the registry
+func (a *registryAgent) registryRevision() string {
+	if a.revisionFile == "" {
+		return load.RegistryRevision(a.registryPath)
+	}
+	revision := load.RevisionFromFile(a.revisionFile)
+	if revision == "" {
+		logrus.WithField("path", a.revisionFile).Warn("Failed to read the revision of the registry, it will not be available to resolve against")
+	}
+	return revision
+}
+
+// recordSnapshot keeps the current registry in the history, dropping the oldest
+// revisions beyond its size. Registries without a revision cannot be requested
+// and are not recorded.
+func (a *registryAgent) recordSnapshot() {
+	if a.revision == "" {
+		return
+	}
+	history := []registrySnapshot{{revision: a.revision, resolver: a.resolver}}
+	for _, snapshot := range a.history {
+		if len(history) == a.historySize {
+			break
+		}
+		if snapshot.revision != a.revision {
+			history = append(history, snapshot)
+		}
+	}
+	a.history = history
+}
+
 func (a *registryAgent) Resolve(name string, config api.MultiStageTestConfiguration) (api.MultiStageTestConfigurationLiteral, error) {
 	return a.resolver.Resolve(name, config)
 }
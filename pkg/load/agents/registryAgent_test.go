@@ -2,11 +2,17 @@ package agents
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
+	"sigs.k8s.io/prow/pkg/config"
+
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
@@ -67,3 +73,79 @@ func TestGetClusterProfileDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveConfigAtRevision(t *testing.T) {
+	resolverWithStep := func(name string) registry.Resolver {
+		return registry.NewResolver(registry.ReferenceByName{"step": {As: name, From: "src", Commands: "true"}}, nil, nil, nil)
+	}
+	agent := &registryAgent{lock: &sync.RWMutex{}, historySize: 2}
+	for _, revision := range []string{"first", "second", "third"} {
+		agent.revision, agent.resolver = revision, resolverWithStep(revision)
+		agent.recordSnapshot()
+	}
+	step := "step"
+	config := api.ReleaseBuildConfiguration{Tests: []api.TestStepConfiguration{{
+		As:                          "e2e",
+		MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &step}}},
+	}}}
+
+	testCases := []struct {
+		name             string
+		revision         string
+		expectedRevision string
+		expectedStep     string
+		expectedError    error
+	}{
+		{name: "current registry", expectedRevision: "third", expectedStep: "third"},
+		{name: "current revision", revision: "third", expectedRevision: "third", expectedStep: "third"},
+		{name: "previous revision", revision: "second", expectedRevision: "second", expectedStep: "second"},
+		{name: "revision dropped from the history", revision: "first", expectedError: fmt.Errorf("%w: first", ErrUnknownRevision)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, revision, err := agent.ResolveConfigAtRevision(config, tc.revision)
+			if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected:\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if revision != tc.expectedRevision {
+				t.Errorf("expected revision %q, got %q", tc.expectedRevision, revision)
+			}
+			if step := resolved.Tests[0].MultiStageTestConfigurationLiteral.Test[0].As; step != tc.expectedStep {
+				t.Errorf("expected the step resolved from revision %q, got %q", tc.expectedStep, step)
+			}
+		})
+	}
+}
+
+func TestRegistryRevision(t *testing.T) {
+	dir := t.TempDir()
+	registryPath, revisionFile := filepath.Join(dir, "registry"), filepath.Join(dir, "revision")
+	if err := os.Mkdir(registryPath, 0755); err != nil {
+		t.Fatalf("failed to create the registry: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(registryPath, config.ConfigVersionFileName), []byte("version\n"), 0644); err != nil {
+		t.Fatalf("failed to write the version file: %v", err)
+	}
+	if err := os.WriteFile(revisionFile, []byte("synced\n"), 0644); err != nil {
+		t.Fatalf("failed to write the revision file: %v", err)
+	}
+	for _, tc := range []struct {
+		name         string
+		revisionFile string
+		expected     string
+	}{
+		{name: "version file of the registry", expected: "version"},
+		{name: "file written by the sync", revisionFile: revisionFile, expected: "synced"},
+		{name: "missing file written by the sync", revisionFile: filepath.Join(dir, "missing")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &registryAgent{registryPath: registryPath, revisionFile: tc.revisionFile}
+			if revision := agent.registryRevision(); revision != tc.expected {
+				t.Errorf("expected revision %q, got %q", tc.expected, revision)
+			}
+		})
+	}
+}
@@ -219,6 +219,24 @@ func Registry(root string, flags RegistryFlag) (registry.ReferenceByName, regist
 	return references, chains, workflows, profiles, documentation, metadata, observers, nil
 }
 
+// RegistryRevision determines the revision of the repository the registry at
+// the path was loaded from, recorded in its version file. It is empty when
+// the registry has no version file.
+func RegistryRevision(root string) string {
+	return RevisionFromFile(filepath.Join(root, config.ConfigVersionFileName))
+}
+
+// RevisionFromFile reads the revision of the repository recorded in the file,
+// like the one written by the sync of a git checkout. It is empty when the
+// file cannot be read.
+func RevisionFromFile(path string) string {
+	revision, err := gzip.ReadFileMaybeGZIP(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(revision))
+}
+
 func loadReference(bytes []byte, baseDir, prefix string, flat bool) (string, string, api.LiteralTestStep, error) {
 	step := api.RegistryReferenceConfig{}
 	err := yaml.UnmarshalStrict(bytes, &step)
@@ -245,6 +263,7 @@ func loadWorkflow(bytes []byte) (string, string, api.MultiStageTestConfiguration
 	if workflow.Workflow.Steps.Workflow != nil {
 		return "", "", api.MultiStageTestConfiguration{}, errors.New("workflows cannot contain other workflows")
 	}
+	workflow.Workflow.Steps.Lifecycle = workflow.Workflow.Lifecycle
 	return workflow.Workflow.As, workflow.Workflow.Documentation, workflow.Workflow.Steps, nil
 }
 
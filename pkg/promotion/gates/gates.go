@@ -0,0 +1,123 @@
+package gates
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/kube"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/jobconfig"
+)
+
+// Jobs returns the names of the postsubmit jobs running the tests required by the gates.
+func Jobs(metadata cioperatorapi.Metadata, gates *cioperatorapi.PromotionGates) []string {
+	if gates == nil {
+		return nil
+	}
+	var jobs []string
+	for _, test := range gates.RequiredTests {
+		jobs = append(jobs, metadata.JobName(jobconfig.PostsubmitPrefix, test))
+	}
+	return jobs
+}
+
+// Check determines whether the runs of the postsubmit jobs allow promoting the images built
+// from the commit, returning why they do not otherwise. Runs of other jobs are ignored.
+func Check(metadata cioperatorapi.Metadata, gates *cioperatorapi.PromotionGates, commit string, prowJobs []prowv1.ProwJob) error {
+	if gates == nil {
+		return nil
+	}
+	if commit == "" {
+		return fmt.Errorf("the commit the images are built from is unknown")
+	}
+	var errs []error
+	for i, job := range Jobs(metadata, gates) {
+		test := gates.RequiredTests[i]
+		runs := completedRuns(job, metadata.Branch, prowJobs)
+		if gates.MinimumPasses == 0 {
+			passed := false
+			for _, run := range runs {
+				if run.Spec.Refs.BaseSHA == commit && run.Status.State == prowv1.SuccessState {
+					passed = true
+					break
+				}
+			}
+			if !passed {
+				errs = append(errs, fmt.Errorf("test %s has not passed on commit %s", test, commit))
+			}
+			continue
+		}
+		// the test must not have failed on the commit even if it passed enough times before
+		var lastOnCommit *prowv1.ProwJob
+		for i := range runs {
+			if runs[i].Spec.Refs.BaseSHA == commit {
+				lastOnCommit = &runs[i]
+				break
+			}
+		}
+		if lastOnCommit == nil {
+			errs = append(errs, fmt.Errorf("test %s has not run on commit %s", test, commit))
+			continue
+		}
+		if lastOnCommit.Status.State != prowv1.SuccessState {
+			errs = append(errs, fmt.Errorf("test %s has failed on commit %s", test, commit))
+			continue
+		}
+		commits, passed := sets.New[string](), sets.New[string]()
+		for _, run := range runs {
+			sha := run.Spec.Refs.BaseSHA
+			if !commits.Has(sha) {
+				if commits.Len() == gates.RecentCommits {
+					continue
+				}
+				commits.Insert(sha)
+			}
+			if run.Status.State == prowv1.SuccessState {
+				passed.Insert(sha)
+			}
+		}
+		if passed.Len() < gates.MinimumPasses {
+			errs = append(errs, fmt.Errorf("test %s passed on %d of the %d most recent commits it ran on, %d passes are required", test, passed.Len(), commits.Len(), gates.MinimumPasses))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// completedRuns returns the completed postsubmit runs of the job on the branch, the most recent first
+func completedRuns(job, branch string, prowJobs []prowv1.ProwJob) []prowv1.ProwJob {
+	var runs []prowv1.ProwJob
+	for _, prowJob := range prowJobs {
+		if prowJob.Spec.Type != prowv1.PostsubmitJob || prowJob.Spec.Job != job || prowJob.Spec.Refs == nil || prowJob.Spec.Refs.BaseRef != branch || !prowJob.Complete() {
+			continue
+		}
+		runs = append(runs, prowJob)
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[j].Status.StartTime.Before(&runs[i].Status.StartTime)
+	})
+	return runs
+}
+
+// ProwJobLister lists the postsubmit ProwJobs of a repository, which gates are checked against
+type ProwJobLister func(ctx context.Context, org, repo string) ([]prowv1.ProwJob, error)
+
+// ClientProwJobLister lists the ProwJobs from the cluster Prow runs them in, selecting
+// them by the labels Prow sets so only the postsubmits of the repository are read.
+func ClientProwJobLister(client ctrlruntimeclient.Reader, namespace string) ProwJobLister {
+	return func(ctx context.Context, org, repo string) ([]prowv1.ProwJob, error) {
+		prowJobs := &prowv1.ProwJobList{}
+		if err := client.List(ctx, prowJobs,
+			ctrlruntimeclient.MatchingLabels{kube.OrgLabel: org, kube.RepoLabel: repo, kube.ProwJobTypeLabel: string(prowv1.PostsubmitJob)},
+			ctrlruntimeclient.InNamespace(namespace),
+		); err != nil {
+			return nil, fmt.Errorf("failed to list prowjobs: %w", err)
+		}
+		return prowJobs.Items, nil
+	}
+}
@@ -0,0 +1,169 @@
+package gates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/kube"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+)
+
+func prowJob(job, commit string, state prowv1.ProwJobState, started int) prowv1.ProwJob {
+	prowJob := prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Type: prowv1.PostsubmitJob,
+			Job:  job,
+			Refs: &prowv1.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: commit},
+		},
+		Status: prowv1.ProwJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(time.Date(2024, 6, 1, started, 0, 0, 0, time.UTC)),
+		},
+	}
+	if state != prowv1.PendingState {
+		prowJob.Status.CompletionTime = &metav1.Time{Time: prowJob.Status.StartTime.Add(time.Hour)}
+	}
+	return prowJob
+}
+
+func TestCheckGates(t *testing.T) {
+	metadata := cioperatorapi.Metadata{Org: "org", Repo: "repo", Branch: "main"}
+	const e2e, upgrade = "branch-ci-org-repo-main-e2e", "branch-ci-org-repo-main-upgrade"
+	var testCases = []struct {
+		name          string
+		gates         *cioperatorapi.PromotionGates
+		commit        string
+		prowJobs      []prowv1.ProwJob
+		expectedError string
+	}{
+		{
+			name:   "no gates",
+			commit: "c",
+		},
+		{
+			name:          "unknown commit",
+			gates:         &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e"}},
+			expectedError: "the commit the images are built from is unknown",
+		},
+		{
+			name:   "required tests passed on the commit",
+			gates:  &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e", "upgrade"}},
+			commit: "c",
+			prowJobs: []prowv1.ProwJob{
+				prowJob(e2e, "c", prowv1.FailureState, 1),
+				prowJob(e2e, "c", prowv1.SuccessState, 2),
+				prowJob(upgrade, "c", prowv1.SuccessState, 1),
+			},
+		},
+		{
+			name:   "required tests did not pass on the commit",
+			gates:  &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e", "upgrade"}},
+			commit: "c",
+			prowJobs: []prowv1.ProwJob{
+				prowJob(e2e, "b", prowv1.SuccessState, 1),
+				prowJob(e2e, "c", prowv1.FailureState, 2),
+				prowJob(upgrade, "c", prowv1.PendingState, 2),
+				prowJob("branch-ci-org-repo-other-upgrade", "c", prowv1.SuccessState, 2),
+			},
+			expectedError: "[test e2e has not passed on commit c, test upgrade has not passed on commit c]",
+		},
+		{
+			name:   "enough passes on recent commits",
+			gates:  &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e"}, MinimumPasses: 2, RecentCommits: 3},
+			commit: "d",
+			prowJobs: []prowv1.ProwJob{
+				prowJob(e2e, "a", prowv1.SuccessState, 1),
+				prowJob(e2e, "b", prowv1.SuccessState, 2),
+				prowJob(e2e, "b", prowv1.SuccessState, 3),
+				prowJob(e2e, "c", prowv1.FailureState, 4),
+				prowJob(e2e, "d", prowv1.SuccessState, 5),
+			},
+		},
+		{
+			name:   "not enough passes on recent commits",
+			gates:  &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e"}, MinimumPasses: 2, RecentCommits: 2},
+			commit: "d",
+			prowJobs: []prowv1.ProwJob{
+				prowJob(e2e, "a", prowv1.SuccessState, 1),
+				prowJob(e2e, "b", prowv1.SuccessState, 2),
+				prowJob(e2e, "c", prowv1.FailureState, 4),
+				prowJob(e2e, "d", prowv1.SuccessState, 5),
+			},
+			expectedError: "test e2e passed on 1 of the 2 most recent commits it ran on, 2 passes are required",
+		},
+		{
+			name:   "enough passes on recent commits but not run on the commit",
+			gates:  &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e"}, MinimumPasses: 2, RecentCommits: 3},
+			commit: "d",
+			prowJobs: []prowv1.ProwJob{
+				prowJob(e2e, "a", prowv1.SuccessState, 1),
+				prowJob(e2e, "b", prowv1.FailureState, 2),
+				prowJob(e2e, "c", prowv1.SuccessState, 3),
+				prowJob(e2e, "d", prowv1.PendingState, 4),
+			},
+			expectedError: "test e2e has not run on commit d",
+		},
+		{
+			name:   "enough passes on recent commits but failed on the commit",
+			gates:  &cioperatorapi.PromotionGates{RequiredTests: []string{"e2e"}, MinimumPasses: 2, RecentCommits: 3},
+			commit: "d",
+			prowJobs: []prowv1.ProwJob{
+				prowJob(e2e, "a", prowv1.SuccessState, 1),
+				prowJob(e2e, "b", prowv1.SuccessState, 2),
+				prowJob(e2e, "d", prowv1.SuccessState, 3),
+				prowJob(e2e, "d", prowv1.FailureState, 4),
+			},
+			expectedError: "test e2e has failed on commit d",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Check(metadata, testCase.gates, testCase.commit, testCase.prowJobs)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expectedError, actualError); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestClientProwJobLister(t *testing.T) {
+	labeled := func(name, org, repo string, jobType prowv1.ProwJobType) *prowv1.ProwJob {
+		return &prowv1.ProwJob{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ci",
+			Name:      name,
+			Labels:    map[string]string{kube.OrgLabel: org, kube.RepoLabel: repo, kube.ProwJobTypeLabel: string(jobType)},
+		}}
+	}
+	scheme := runtime.NewScheme()
+	if err := prowv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fakectrlruntimeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		labeled("postsubmit", "org", "repo", prowv1.PostsubmitJob),
+		labeled("presubmit", "org", "repo", prowv1.PresubmitJob),
+		labeled("other-repo", "org", "other", prowv1.PostsubmitJob),
+	).Build()
+	prowJobs, err := ClientProwJobLister(client, "ci")(context.Background(), "org", "repo")
+	if err != nil {
+		t.Fatalf("failed to list prowjobs: %v", err)
+	}
+	var names []string
+	for _, prowJob := range prowJobs {
+		names = append(names, prowJob.Name)
+	}
+	if diff := cmp.Diff([]string{"postsubmit"}, names); diff != "" {
+		t.Errorf("unexpected prowjobs: %s", diff)
+	}
+}
@@ -0,0 +1,269 @@
+package rollback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/helper"
+)
+
+// Annotation on an image stream holds the rollbacks of its tags, by tag
+const Annotation = "ci.openshift.io/promotion-rollbacks"
+
+// Label is set on the image streams with the annotation, so they can be listed
+const Label = "ci.openshift.io/promotion-rolled-back"
+
+// commitLabel is the label on the images built by ci-operator holding the commit they were built from
+const commitLabel = "io.openshift.build.commit.id"
+
+// commitTag matches the tags created for promotion targets with tag_by_commit
+var commitTag = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// Record describes the rollback of a tag promoted to. Until it is cleared, the tag
+// is kept pointing to the digest it was rolled back to instead of being promoted to.
+type Record struct {
+	// Digest is the digest the tag was rolled back to
+	Digest string `json:"digest"`
+	// From is the digest the tag pointed to before
+	From string `json:"from"`
+	// Reason explains the rollback
+	Reason string `json:"reason,omitempty"`
+	// Time is when the rollback happened
+	Time metav1.Time `json:"time"`
+}
+
+// Records returns the rollbacks of the tags of the image stream, by tag
+func Records(stream *imagev1.ImageStream) (map[string]Record, error) {
+	records := map[string]Record{}
+	raw, ok := stream.Annotations[Annotation]
+	if !ok {
+		return records, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &records); err != nil {
+		return nil, fmt.Errorf("malformed %s annotation on image stream %s/%s: %w", Annotation, stream.Namespace, stream.Name, err)
+	}
+	return records, nil
+}
+
+func setRecords(stream *imagev1.ImageStream, records map[string]Record) error {
+	if len(records) == 0 {
+		delete(stream.Annotations, Annotation)
+		delete(stream.Labels, Label)
+		return nil
+	}
+	raw, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollbacks: %w", err)
+	}
+	if stream.Annotations == nil {
+		stream.Annotations = map[string]string{}
+	}
+	stream.Annotations[Annotation] = string(raw)
+	if stream.Labels == nil {
+		stream.Labels = map[string]string{}
+	}
+	stream.Labels[Label] = "true"
+	return nil
+}
+
+// Entry is a digest which was promoted to a tag
+type Entry struct {
+	Digest string
+	// Commit is the commit the image was built from, if it can be determined
+	Commit  string
+	Created metav1.Time
+}
+
+// History returns the digests promoted to the tag, the current one first
+func History(ctx context.Context, client ctrlruntimeclient.Client, ref api.ImageStreamTagReference) ([]Entry, error) {
+	stream := &imagev1.ImageStream{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, stream); err != nil {
+		return nil, fmt.Errorf("failed to get image stream %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	events := tagEvents(stream, ref.Tag)
+	if len(events) == 0 {
+		return nil, fmt.Errorf("nothing was promoted to %s", ref.ISTagName())
+	}
+	commits, err := commitsByDigest(ctx, client, ref)
+	if err != nil {
+		return nil, err
+	}
+	var history []Entry
+	for _, event := range events {
+		entry := Entry{Digest: event.Image, Created: event.Created}
+		if commit, ok := commits[event.Image]; ok {
+			entry.Commit = commit
+		} else {
+			entry.Commit = labeledCommit(ctx, client, ref, event.Image)
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+func tagEvents(stream *imagev1.ImageStream, tag string) []imagev1.TagEvent {
+	for _, tags := range stream.Status.Tags {
+		if tags.Tag == tag {
+			return tags.Items
+		}
+	}
+	return nil
+}
+
+// commitsByDigest determines the commits the images promoted to the tag were built from using the
+// tags created for targets with tag_by_commit, which are in the image stream named after the image
+func commitsByDigest(ctx context.Context, client ctrlruntimeclient.Client, ref api.ImageStreamTagReference) (map[string]string, error) {
+	commits := map[string]string{}
+	// the image is the tag when promoting to a target by name and the name when promoting to a target by tag
+	for _, image := range []string{ref.Tag, ref.Name} {
+		stream := &imagev1.ImageStream{}
+		if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: ref.Namespace, Name: image}, stream); err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get image stream %s/%s: %w", ref.Namespace, image, err)
+		}
+		for _, tags := range stream.Status.Tags {
+			if !commitTag.MatchString(tags.Tag) {
+				continue
+			}
+			for _, event := range tags.Items {
+				commits[event.Image] = tags.Tag
+			}
+		}
+	}
+	return commits, nil
+}
+
+// labeledCommit determines the commit the image was built from using its labels
+func labeledCommit(ctx context.Context, client ctrlruntimeclient.Client, ref api.ImageStreamTagReference, digest string) string {
+	logger := logrus.WithField("tag", ref.ISTagName()).WithField("digest", digest)
+	image := &imagev1.Image{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Name: digest}, image); err != nil {
+		logger.WithError(err).Debug("Failed to get the image.")
+		return ""
+	}
+	ist := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ref.Namespace, Name: fmt.Sprintf("%s:%s", ref.Name, ref.Tag)},
+		Image:      *image,
+	}
+	labels, err := helper.LabelsOnISTagImage(ctx, client, ist, api.ReleaseArchitectureAMD64)
+	if err != nil {
+		logger.WithError(err).Debug("Failed to get the labels of the image.")
+		return ""
+	}
+	return labels[commitLabel]
+}
+
+// Apply points the tag to a digest which was promoted to it before and records
+// the rollback, which holds back promotions to the tag until it is cleared
+func Apply(ctx context.Context, client ctrlruntimeclient.Client, ref api.ImageStreamTagReference, digest, reason string, now time.Time) (*Record, error) {
+	stream := &imagev1.ImageStream{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, stream); err != nil {
+		return nil, fmt.Errorf("failed to get image stream %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	events := tagEvents(stream, ref.Tag)
+	if len(events) == 0 {
+		return nil, fmt.Errorf("nothing was promoted to %s", ref.ISTagName())
+	}
+	if events[0].Image == digest {
+		return nil, fmt.Errorf("%s already points to %s", ref.ISTagName(), digest)
+	}
+	var promoted bool
+	for _, event := range events {
+		promoted = promoted || event.Image == digest
+	}
+	if !promoted {
+		return nil, fmt.Errorf("%s was never promoted to %s", digest, ref.ISTagName())
+	}
+	records, err := Records(stream)
+	if err != nil {
+		return nil, err
+	}
+	record := Record{Digest: digest, From: events[0].Image, Reason: reason, Time: metav1.NewTime(now)}
+	if previous, rolledBack := records[ref.Tag]; rolledBack {
+		// the tag points to the digest it was rolled back to, keep track of what was promoted
+		record.From = previous.From
+	}
+	records[ref.Tag] = record
+	if err := setRecords(stream, records); err != nil {
+		return nil, err
+	}
+	pointTo(stream, ref.Tag, digest)
+	if err := client.Update(ctx, stream); err != nil {
+		return nil, fmt.Errorf("failed to update image stream %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	return &record, nil
+}
+
+// Clear removes the rollback of the tag, so it is promoted to again. The tag is
+// left pointing to the digest it was rolled back to until it is promoted to.
+func Clear(ctx context.Context, client ctrlruntimeclient.Client, ref api.ImageStreamTagReference) error {
+	stream := &imagev1.ImageStream{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, stream); err != nil {
+		return fmt.Errorf("failed to get image stream %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	records, err := Records(stream)
+	if err != nil {
+		return err
+	}
+	if _, rolledBack := records[ref.Tag]; !rolledBack {
+		return fmt.Errorf("%s was not rolled back", ref.ISTagName())
+	}
+	delete(records, ref.Tag)
+	if err := setRecords(stream, records); err != nil {
+		return err
+	}
+	if err := client.Update(ctx, stream); err != nil {
+		return fmt.Errorf("failed to update image stream %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	return nil
+}
+
+// Enforce points the tag back to the digest it was rolled back to if it was promoted to since
+func Enforce(ctx context.Context, client ctrlruntimeclient.Client, stream *imagev1.ImageStream, tag string, record Record) (bool, error) {
+	if events := tagEvents(stream, tag); len(events) > 0 && events[0].Image == record.Digest {
+		return false, nil
+	}
+	stream = stream.DeepCopy()
+	pointTo(stream, tag, record.Digest)
+	if err := client.Update(ctx, stream); err != nil {
+		return false, fmt.Errorf("failed to update image stream %s/%s: %w", stream.Namespace, stream.Name, err)
+	}
+	return true, nil
+}
+
+// pointTo points the tag to an image in the image stream like `oc tag` does
+func pointTo(stream *imagev1.ImageStream, tag, digest string) {
+	from := &coreapi.ObjectReference{Kind: "ImageStreamImage", Namespace: stream.Namespace, Name: imageStreamImage(stream.Name, digest)}
+	for i := range stream.Spec.Tags {
+		if stream.Spec.Tags[i].Name == tag {
+			stream.Spec.Tags[i].From = from
+			// a new generation makes the tag point to the image again if it was promoted to since
+			stream.Spec.Tags[i].Generation = nil
+			return
+		}
+	}
+	stream.Spec.Tags = append(stream.Spec.Tags, imagev1.TagReference{
+		Name:            tag,
+		From:            from,
+		ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.SourceTagReferencePolicy},
+	})
+}
+
+func imageStreamImage(name, digest string) string {
+	return fmt.Sprintf("%s@%s", name, digest)
+}
@@ -0,0 +1,267 @@
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func init() {
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		panic(fmt.Sprintf("failed to register imagev1 scheme: %v", err))
+	}
+}
+
+var (
+	ref     = api.ImageStreamTagReference{Namespace: "ocp", Name: "4.17", Tag: "cli"}
+	created = metav1.NewTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+)
+
+func integrationStream() *imagev1.ImageStream {
+	return &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ocp", Name: "4.17"},
+		Status: imagev1.ImageStreamStatus{
+			Tags: []imagev1.NamedTagEventList{
+				{Tag: "cli", Items: []imagev1.TagEvent{{Image: "sha256:bad", Created: created}, {Image: "sha256:good", Created: created}, {Image: "sha256:old", Created: created}}},
+				{Tag: "tests", Items: []imagev1.TagEvent{{Image: "sha256:tests", Created: created}}},
+			},
+		},
+	}
+}
+
+func TestHistory(t *testing.T) {
+	commitStream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ocp", Name: "cli"},
+		Status: imagev1.ImageStreamStatus{
+			Tags: []imagev1.NamedTagEventList{
+				{Tag: "0123456789abcdef0123456789abcdef01234567", Items: []imagev1.TagEvent{{Image: "sha256:bad"}}},
+				{Tag: "89abcdef0123456789abcdef0123456789abcdef", Items: []imagev1.TagEvent{{Image: "sha256:good"}}},
+				{Tag: "latest", Items: []imagev1.TagEvent{{Image: "sha256:old"}}},
+			},
+		},
+	}
+	oldImage := &imagev1.Image{
+		ObjectMeta:          metav1.ObjectMeta{Name: "sha256:old"},
+		DockerImageMetadata: runtime.RawExtension{Raw: []byte(`{"kind": "DockerImage", "apiVersion": "1.0", "Config": {"Labels": {"io.openshift.build.commit.id": "fedcba"}}}`)},
+	}
+	client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(integrationStream(), commitStream, oldImage).Build()
+
+	history, err := History(context.Background(), client, ref)
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	expected := []Entry{
+		{Digest: "sha256:bad", Commit: "0123456789abcdef0123456789abcdef01234567", Created: created},
+		{Digest: "sha256:good", Commit: "89abcdef0123456789abcdef0123456789abcdef", Created: created},
+		{Digest: "sha256:old", Commit: "fedcba", Created: created},
+	}
+	if diff := cmp.Diff(expected, history); diff != "" {
+		t.Errorf("unexpected history: %s", diff)
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	var testCases = []struct {
+		name            string
+		digest          string
+		stream          func() *imagev1.ImageStream
+		expectedError   string
+		expectedRecords map[string]Record
+		expectedSpec    []imagev1.TagReference
+	}{
+		{
+			name:            "rolling back to a digest promoted before",
+			digest:          "sha256:good",
+			stream:          integrationStream,
+			expectedRecords: map[string]Record{"cli": {Digest: "sha256:good", From: "sha256:bad", Reason: "broken", Time: metav1.NewTime(now)}},
+			expectedSpec: []imagev1.TagReference{{
+				Name:            "cli",
+				From:            &coreapi.ObjectReference{Kind: "ImageStreamImage", Namespace: "ocp", Name: "4.17@sha256:good"},
+				ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.SourceTagReferencePolicy},
+			}},
+		},
+		{
+			name:   "rolling back further keeps track of what was promoted",
+			digest: "sha256:old",
+			stream: func() *imagev1.ImageStream {
+				stream := integrationStream()
+				stream.Annotations = map[string]string{Annotation: `{"cli": {"digest": "sha256:good", "from": "sha256:bad", "time": "2024-06-01T00:00:00Z"}}`}
+				stream.Status.Tags[0].Items = append([]imagev1.TagEvent{{Image: "sha256:good"}}, stream.Status.Tags[0].Items...)
+				generation := int64(1)
+				stream.Spec.Tags = []imagev1.TagReference{{
+					Name:       "cli",
+					From:       &coreapi.ObjectReference{Kind: "ImageStreamImage", Namespace: "ocp", Name: "4.17@sha256:good"},
+					Generation: &generation,
+				}}
+				return stream
+			},
+			expectedRecords: map[string]Record{"cli": {Digest: "sha256:old", From: "sha256:bad", Reason: "broken", Time: metav1.NewTime(now)}},
+			expectedSpec: []imagev1.TagReference{{
+				Name: "cli",
+				From: &coreapi.ObjectReference{Kind: "ImageStreamImage", Namespace: "ocp", Name: "4.17@sha256:old"},
+			}},
+		},
+		{
+			name:          "rolling back to the current digest",
+			digest:        "sha256:bad",
+			stream:        integrationStream,
+			expectedError: "ocp/4.17:cli already points to sha256:bad",
+		},
+		{
+			name:          "rolling back to a digest never promoted",
+			digest:        "sha256:tests",
+			stream:        integrationStream,
+			expectedError: "sha256:tests was never promoted to ocp/4.17:cli",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(testCase.stream()).Build()
+			_, err := Apply(context.Background(), client, ref, testCase.digest, "broken", now)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expectedError, actualError); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			stream := &imagev1.ImageStream{}
+			if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ocp", Name: "4.17"}, stream); err != nil {
+				t.Fatal(err)
+			}
+			records, err := Records(stream)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(testCase.expectedRecords, records); diff != "" {
+				t.Errorf("unexpected records: %s", diff)
+			}
+			if stream.Labels[Label] != "true" {
+				t.Errorf("expected the image stream to be labeled, got labels %v", stream.Labels)
+			}
+			if diff := cmp.Diff(testCase.expectedSpec, stream.Spec.Tags); diff != "" {
+				t.Errorf("unexpected spec tags: %s", diff)
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		specTags  []imagev1.TagReference
+		otherTags string
+	}{
+		{
+			name: "tag pointed to the digest it was rolled back to",
+			specTags: []imagev1.TagReference{
+				{Name: "cli", From: &coreapi.ObjectReference{Kind: "ImageStreamImage", Namespace: "ocp", Name: "4.17@sha256:good"}},
+				{Name: "tests", From: &coreapi.ObjectReference{Kind: "DockerImage", Name: "quay.io/openshift/tests:latest"}},
+			},
+		},
+		{
+			name: "tag without a spec tag",
+		},
+		{
+			name:      "other tags are still rolled back",
+			otherTags: `, "tests": {"digest": "sha256:tests", "from": "sha256:broken", "time": "2024-06-01T00:00:00Z"}`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			stream := integrationStream()
+			stream.Annotations = map[string]string{Annotation: `{"cli": {"digest": "sha256:good", "from": "sha256:bad", "time": "2024-06-01T00:00:00Z"}` + testCase.otherTags + `}`}
+			stream.Labels = map[string]string{Label: "true"}
+			stream.Spec.Tags = testCase.specTags
+			client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(stream.DeepCopy()).Build()
+
+			if err := Clear(context.Background(), client, ref); err != nil {
+				t.Fatalf("failed to clear rollback: %v", err)
+			}
+			actual := &imagev1.ImageStream{}
+			if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ocp", Name: "4.17"}, actual); err != nil {
+				t.Fatal(err)
+			}
+			records, err := Records(actual)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, rolledBack := records["cli"]; rolledBack {
+				t.Errorf("expected the rollback to be removed, got %s", actual.Annotations[Annotation])
+			}
+			if _, labeled := actual.Labels[Label]; labeled != (len(records) != 0) {
+				t.Errorf("expected the label to be set only with rollbacks left, got labels %v with rollbacks %v", actual.Labels, records)
+			}
+			if diff := cmp.Diff(stream.Spec.Tags, actual.Spec.Tags); diff != "" {
+				t.Errorf("unexpected spec tags: %s", diff)
+			}
+			if diff := cmp.Diff(stream.Status.Tags, actual.Status.Tags); diff != "" {
+				t.Errorf("unexpected status tags: %s", diff)
+			}
+			if err := Clear(context.Background(), client, ref); err == nil || err.Error() != "ocp/4.17:cli was not rolled back" {
+				t.Errorf("expected clearing again to fail, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	record := Record{Digest: "sha256:good", From: "sha256:bad"}
+	var testCases = []struct {
+		name     string
+		current  string
+		expected bool
+	}{
+		{
+			name:    "tag points to the digest it was rolled back to",
+			current: "sha256:good",
+		},
+		{
+			name:     "tag was promoted to since",
+			current:  "sha256:newer",
+			expected: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			stream := integrationStream()
+			stream.Status.Tags[0].Items = append([]imagev1.TagEvent{{Image: testCase.current}}, stream.Status.Tags[0].Items...)
+			client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(stream).Build()
+			if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ocp", Name: "4.17"}, stream); err != nil {
+				t.Fatal(err)
+			}
+			enforced, err := Enforce(context.Background(), client, stream, "cli", record)
+			if err != nil {
+				t.Fatalf("failed to enforce rollback: %v", err)
+			}
+			if enforced != testCase.expected {
+				t.Errorf("expected enforced to be %t, got %t", testCase.expected, enforced)
+			}
+			actual := &imagev1.ImageStream{}
+			if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ocp", Name: "4.17"}, actual); err != nil {
+				t.Fatal(err)
+			}
+			if pointed := len(actual.Spec.Tags) == 1 && actual.Spec.Tags[0].From.Name == "4.17@sha256:good"; pointed != testCase.expected {
+				t.Errorf("expected the tag to be pointed to the digest: %t, got spec tags %v", testCase.expected, actual.Spec.Tags)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Envelope is a DSSE envelope holding a statement and its signatures,
+// see https://github.com/secure-systems-lab/dsse/blob/master/envelope.md
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is the signature of the payload of an envelope
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// pae is the pre-authentication encoding of the payload, which is what is signed
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// Signer signs statements with a private key
+type Signer struct {
+	key   crypto.Signer
+	keyID string
+}
+
+// LoadSigner loads a PEM-encoded ECDSA, Ed25519 or RSA private key
+func LoadSigner(path string) (*Signer, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	var key any
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key of type %T", key)
+	}
+	return NewSigner(signer)
+}
+
+// NewSigner signs with the key, identified by the digest of its public key
+func NewSigner(key crypto.Signer) (*Signer, error) {
+	keyID, err := KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{key: key, keyID: keyID}, nil
+}
+
+// KeyID identifies a public key by the digest of its encoding
+func KeyID(key crypto.PublicKey) (string, error) {
+	raw, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize public key: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return "SHA256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func (s *Signer) sign(message []byte) ([]byte, error) {
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		return s.key.Sign(rand.Reader, message, crypto.Hash(0))
+	}
+	sum := sha256.Sum256(message)
+	return s.key.Sign(rand.Reader, sum[:], crypto.SHA256)
+}
+
+// LoadPublicKey loads a PEM-encoded public key
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return key, nil
+}
+
+func verifySignature(key crypto.PublicKey, message, signature []byte) bool {
+	sum := sha256.Sum256(message)
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, sum[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+// Seal wraps the statement in an envelope, signed when a signer is given
+func Seal(statement Statement, signer *Signer) ([]byte, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize statement: %w", err)
+	}
+	envelope := Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{},
+	}
+	if signer != nil {
+		signature, err := signer.sign(pae(PayloadType, payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign statement: %w", err)
+		}
+		envelope.Signatures = append(envelope.Signatures, Signature{KeyID: signer.keyID, Sig: base64.StdEncoding.EncodeToString(signature)})
+	}
+	return json.Marshal(envelope)
+}
+
+// Verify opens the envelope and checks that the statement is about the image
+// with the digest.  When a key is given, the envelope must also be signed with
+// it.
+func Verify(raw []byte, key crypto.PublicKey, digest string) (*Statement, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse envelope: %w", err)
+	}
+	if envelope.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if key != nil {
+		message := pae(envelope.PayloadType, payload)
+		var verified bool
+		for _, signature := range envelope.Signatures {
+			sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+			if err != nil {
+				continue
+			}
+			if verifySignature(key, message, sig) {
+				verified = true
+				break
+			}
+		}
+		if !verified {
+			return nil, errors.New("the envelope is not signed with the key")
+		}
+	}
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("failed to parse statement: %w", err)
+	}
+	if statement.Type != StatementType || statement.PredicateType != PredicateType {
+		return nil, fmt.Errorf("unexpected statement of type %q with a predicate of type %q", statement.Type, statement.PredicateType)
+	}
+	if !statement.HasSubject(digest) {
+		return nil, fmt.Errorf("the statement is not about %s", digest)
+	}
+	return &statement, nil
+}
@@ -0,0 +1,195 @@
+// Package provenance describes how images promoted by ci-operator were built,
+// as in-toto statements carrying a SLSA provenance predicate, and signs and
+// verifies them as DSSE envelopes attached to the images in the registry.
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
+	"sigs.k8s.io/prow/pkg/pod-utils/gcs"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	// StatementType is the type of in-toto statements
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType is the type of the SLSA provenance predicate
+	PredicateType = "https://slsa.dev/provenance/v1"
+	// BuildType identifies images built and promoted by ci-operator
+	BuildType = "https://github.com/openshift/ci-tools/ci-operator/promotion@v1"
+	// BuilderID identifies ci-operator as the builder of the images
+	BuilderID = "https://github.com/openshift/ci-tools/ci-operator"
+
+	// ArtifactType is the type of the artifacts attached to the promoted images
+	ArtifactType = "application/vnd.in-toto+json"
+	// PayloadType is the type of the payload of the DSSE envelope
+	PayloadType = "application/vnd.in-toto+json"
+	// EnvelopeMediaType is the media type of the layer holding the DSSE envelope
+	EnvelopeMediaType = "application/vnd.dsse.envelope.v1+json"
+
+	// DefaultAttachImage is the image of the tool attaching the provenance to the promoted images
+	DefaultAttachImage = "ghcr.io/oras-project/oras:v1.2.0"
+)
+
+// Options configures the provenance attached to the promoted images
+type Options struct {
+	// Signer signs the provenance, it is attached unsigned when nil
+	Signer *Signer
+	// AttachImage is the image of the tool attaching the provenance
+	AttachImage string
+}
+
+// Statement is an in-toto statement about the promoted images
+type Statement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     Predicate            `json:"predicate"`
+}
+
+// ResourceDescriptor identifies an image or a revision of a repository
+type ResourceDescriptor struct {
+	Name        string            `json:"name,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Digest      map[string]string `json:"digest,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Predicate is the SLSA provenance of the promoted images
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition describes the inputs of the build
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   ExternalParameters   `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// ExternalParameters are the parameters of the job building the images
+type ExternalParameters struct {
+	// Job is the name of the job
+	Job string `json:"job,omitempty"`
+	// Refs are the revisions the job tested
+	Refs []prowapi.Refs `json:"refs,omitempty"`
+	// ConfigHash is the digest of the ci-operator configuration
+	ConfigHash string `json:"configHash"`
+	// Image is the name of the image in the pipeline image stream
+	Image string `json:"image"`
+}
+
+// RunDetails describes the run of the job building the images
+type RunDetails struct {
+	Builder  Builder       `json:"builder"`
+	Metadata BuildMetadata `json:"metadata"`
+}
+
+// Builder identifies what built the images
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// BuildMetadata identifies the job building the images
+type BuildMetadata struct {
+	// InvocationID is the URL of the job
+	InvocationID string `json:"invocationId,omitempty"`
+}
+
+// Digest returns the digest of a resource in the form used by the statement
+func Digest(digest string) map[string]string {
+	algorithm, encoded, found := strings.Cut(digest, ":")
+	if !found {
+		return nil
+	}
+	return map[string]string{algorithm: encoded}
+}
+
+// HasSubject determines whether the statement is about the image with the digest
+func (s *Statement) HasSubject(digest string) bool {
+	algorithm, encoded, _ := strings.Cut(digest, ":")
+	for _, subject := range s.Subject {
+		if subject.Digest[algorithm] == encoded {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigHash returns the digest of the ci-operator configuration
+func ConfigHash(config *api.ReleaseBuildConfiguration) (string, error) {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize configuration: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Refs returns the revisions tested by the job
+func Refs(spec *downwardapi.JobSpec) []prowapi.Refs {
+	var refs []prowapi.Refs
+	if spec.Refs != nil {
+		refs = append(refs, *spec.Refs)
+	}
+	return append(refs, spec.ExtraRefs...)
+}
+
+// SourceDependencies describes the revisions tested by the job as dependencies of the build
+func SourceDependencies(refs []prowapi.Refs) []ResourceDescriptor {
+	var dependencies []ResourceDescriptor
+	for _, ref := range refs {
+		link := ref.RepoLink
+		if link == "" {
+			link = fmt.Sprintf("https://github.com/%s/%s", ref.Org, ref.Repo)
+		}
+		if ref.BaseSHA != "" {
+			dependencies = append(dependencies, ResourceDescriptor{
+				Name:   ref.BaseRef,
+				URI:    fmt.Sprintf("git+%s@refs/heads/%s", link, ref.BaseRef),
+				Digest: map[string]string{"gitCommit": ref.BaseSHA},
+			})
+		}
+		for _, pull := range ref.Pulls {
+			dependencies = append(dependencies, ResourceDescriptor{
+				Name:   fmt.Sprintf("#%d", pull.Number),
+				URI:    fmt.Sprintf("git+%s@refs/pull/%d/head", link, pull.Number),
+				Digest: map[string]string{"gitCommit": pull.SHA},
+			})
+		}
+	}
+	return dependencies
+}
+
+// JobURL returns the URL of the job in Deck, if the job uploads its artifacts
+func JobURL(spec *downwardapi.JobSpec) string {
+	if spec.DecorationConfig == nil || spec.DecorationConfig.GCSConfiguration == nil {
+		return ""
+	}
+	config := spec.DecorationConfig.GCSConfiguration
+	if config.Bucket == "" {
+		return ""
+	}
+	var builder gcs.RepoPathBuilder
+	switch config.PathStrategy {
+	case prowapi.PathStrategyLegacy:
+		builder = gcs.NewLegacyRepoPathBuilder(config.DefaultOrg, config.DefaultRepo)
+	case prowapi.PathStrategySingle:
+		builder = gcs.NewSingleDefaultRepoPathBuilder(config.DefaultOrg, config.DefaultRepo)
+	default:
+		builder = gcs.NewExplicitRepoPathBuilder()
+	}
+	storage, bucket, found := strings.Cut(config.Bucket, "://")
+	if !found {
+		storage, bucket = "gs", config.Bucket
+	}
+	return fmt.Sprintf("%s/view/%s/%s/%s", api.URLForService(api.ServiceProw), storage, bucket, gcs.PathForSpec(spec, builder))
+}
@@ -0,0 +1,176 @@
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
+)
+
+const digest = "sha256:0123456789abcdef"
+
+func statement() Statement {
+	return Statement{
+		Type:          StatementType,
+		Subject:       []ResourceDescriptor{{Name: "registry.ci.openshift.org/ocp/4.17:cli", Digest: Digest(digest)}},
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: BuildDefinition{
+				BuildType:          BuildType,
+				ExternalParameters: ExternalParameters{Job: "job", ConfigHash: "sha256:config", Image: "cli"},
+			},
+			RunDetails: RunDetails{Builder: Builder{ID: BuilderID}},
+		},
+	}
+}
+
+func TestSealVerify(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testCases = []struct {
+		name          string
+		signingKey    crypto.Signer
+		verifyingKey  crypto.PublicKey
+		digest        string
+		expectedError string
+	}{
+		{
+			name:   "unsigned statement without a key",
+			digest: digest,
+		},
+		{
+			name:         "statement signed with ECDSA",
+			signingKey:   ecdsaKey,
+			verifyingKey: ecdsaKey.Public(),
+			digest:       digest,
+		},
+		{
+			name:         "statement signed with Ed25519",
+			signingKey:   ed25519Key,
+			verifyingKey: ed25519Key.Public(),
+			digest:       digest,
+		},
+		{
+			name:          "unsigned statement with a key",
+			verifyingKey:  ecdsaKey.Public(),
+			digest:        digest,
+			expectedError: "the envelope is not signed with the key",
+		},
+		{
+			name:          "statement signed with another key",
+			signingKey:    otherKey,
+			verifyingKey:  ecdsaKey.Public(),
+			digest:        digest,
+			expectedError: "the envelope is not signed with the key",
+		},
+		{
+			name:          "statement about another image",
+			signingKey:    ecdsaKey,
+			verifyingKey:  ecdsaKey.Public(),
+			digest:        "sha256:fedcba9876543210",
+			expectedError: "the statement is not about sha256:fedcba9876543210",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var signer *Signer
+			if testCase.signingKey != nil {
+				var err error
+				if signer, err = NewSigner(testCase.signingKey); err != nil {
+					t.Fatal(err)
+				}
+			}
+			envelope, err := Seal(statement(), signer)
+			if err != nil {
+				t.Fatalf("failed to seal statement: %v", err)
+			}
+			verified, err := Verify(envelope, testCase.verifyingKey, testCase.digest)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expectedError, actualError); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if err == nil {
+				if diff := cmp.Diff(statement(), *verified); diff != "" {
+					t.Errorf("unexpected statement: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestJobURL(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		spec     downwardapi.JobSpec
+		expected string
+	}{
+		{
+			name: "job not uploading artifacts",
+			spec: downwardapi.JobSpec{Job: "job", BuildID: "1", Type: prowapi.PeriodicJob},
+		},
+		{
+			name: "periodic",
+			spec: downwardapi.JobSpec{
+				Job: "job", BuildID: "1", Type: prowapi.PeriodicJob,
+				DecorationConfig: &prowapi.DecorationConfig{GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "test-platform-results", PathStrategy: prowapi.PathStrategySingle, DefaultOrg: "openshift", DefaultRepo: "origin"}},
+			},
+			expected: "https://prow.ci.openshift.org/view/gs/test-platform-results/logs/job/1",
+		},
+		{
+			name: "postsubmit with a bucket with a scheme",
+			spec: downwardapi.JobSpec{
+				Job: "job", BuildID: "1", Type: prowapi.PostsubmitJob,
+				Refs:             &prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc"},
+				DecorationConfig: &prowapi.DecorationConfig{GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "gs://origin-ci-test", PathStrategy: prowapi.PathStrategyExplicit}},
+			},
+			expected: "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/job/1",
+		},
+		{
+			name: "presubmit",
+			spec: downwardapi.JobSpec{
+				Job: "job", BuildID: "1", Type: prowapi.PresubmitJob,
+				Refs:             &prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc", Pulls: []prowapi.Pull{{Number: 42, SHA: "def"}}},
+				DecorationConfig: &prowapi.DecorationConfig{GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "test-platform-results", PathStrategy: prowapi.PathStrategyExplicit}},
+			},
+			expected: "https://prow.ci.openshift.org/view/gs/test-platform-results/pr-logs/pull/org_repo/42/job/1",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, JobURL(&testCase.spec)); diff != "" {
+				t.Errorf("unexpected job URL: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSourceDependencies(t *testing.T) {
+	refs := []prowapi.Refs{{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc", Pulls: []prowapi.Pull{{Number: 42, SHA: "def"}}}}
+	expected := []ResourceDescriptor{
+		{Name: "main", URI: "git+https://github.com/org/repo@refs/heads/main", Digest: map[string]string{"gitCommit": "abc"}},
+		{Name: "#42", URI: "git+https://github.com/org/repo@refs/pull/42/head", Digest: map[string]string{"gitCommit": "def"}},
+	}
+	if diff := cmp.Diff(expected, SourceDependencies(refs)); diff != "" {
+		t.Errorf("unexpected dependencies: %s", diff)
+	}
+}
@@ -0,0 +1,98 @@
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+
+	"github.com/openshift/ci-tools/pkg/kubernetes/pkg/credentialprovider"
+)
+
+// RegistryClient reads the provenance attached to images from their registry,
+// using the OCI referrers API and falling back to the referrers tag schema for
+// registries which do not implement it
+type RegistryClient struct {
+	client *http.Client
+	auths  credentialprovider.DockerConfig
+}
+
+// NewRegistryClient returns a client authenticating with the credentials
+func NewRegistryClient(client *http.Client, auths credentialprovider.DockerConfig) *RegistryClient {
+	return &RegistryClient{client: client, auths: auths}
+}
+
+// repository returns a client for the repository of the image
+func (c *RegistryClient) repository(image reference.DockerImageReference) (*remote.Repository, error) {
+	if image.Registry == "" {
+		return nil, fmt.Errorf("no registry in pull spec %s", image.Exact())
+	}
+	repository, err := remote.NewRepository(image.Registry + "/" + image.RepositoryName())
+	if err != nil {
+		return nil, err
+	}
+	credential := auth.EmptyCredential
+	if config, ok := c.auths[image.Registry]; ok {
+		credential = auth.Credential{Username: config.Username, Password: config.Password}
+	}
+	repository.Client = &auth.Client{
+		Client:     c.client,
+		Cache:      auth.NewCache(),
+		Credential: auth.StaticCredential(image.Registry, credential),
+	}
+	return repository, nil
+}
+
+// Provenance resolves the digest of the image and returns it with the
+// envelopes attached to it
+func (c *RegistryClient) Provenance(ctx context.Context, image reference.DockerImageReference) (string, [][]byte, error) {
+	repository, err := c.repository(image)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid repository for %s: %w", image.Exact(), err)
+	}
+	ref := image.ID
+	if ref == "" {
+		ref = image.Tag
+	}
+	target, err := repository.Resolve(ctx, ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve the digest of %s: %w", image.Exact(), err)
+	}
+	var referrers []ocispec.Descriptor
+	if err := repository.Referrers(ctx, target, ArtifactType, func(page []ocispec.Descriptor) error {
+		referrers = append(referrers, page...)
+		return nil
+	}); err != nil {
+		return "", nil, fmt.Errorf("failed to list the artifacts attached to %s: %w", image.Exact(), err)
+	}
+	var envelopes [][]byte
+	for _, referrer := range referrers {
+		raw, err := content.FetchAll(ctx, repository, referrer)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get artifact %s: %w", referrer.Digest, err)
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(raw, &manifest); err != nil {
+			return "", nil, fmt.Errorf("failed to parse artifact %s: %w", referrer.Digest, err)
+		}
+		for _, layer := range manifest.Layers {
+			if layer.MediaType != EnvelopeMediaType {
+				continue
+			}
+			// the content of blobs is verified against their digest
+			blob, err := content.FetchAll(ctx, repository.Blobs(), layer)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to get envelope %s: %w", layer.Digest, err)
+			}
+			envelopes = append(envelopes, blob)
+		}
+	}
+	return target.Digest.String(), envelopes, nil
+}
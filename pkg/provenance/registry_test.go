@@ -0,0 +1,106 @@
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+
+	"github.com/openshift/ci-tools/pkg/kubernetes/pkg/credentialprovider"
+)
+
+func TestRegistryClientProvenance(t *testing.T) {
+	envelope, err := Seal(statement(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelopeDigest := godigest.FromBytes(envelope)
+	artifact, err := json.Marshal(ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: ArtifactType,
+		Layers:       []ocispec.Descriptor{{MediaType: EnvelopeMediaType, Digest: envelopeDigest, Size: int64(len(envelope))}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifactDigest := godigest.FromBytes(artifact)
+	index, err := json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{
+			{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/vnd.other", Digest: godigest.FromString("other"), Size: 5},
+			{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: ArtifactType, Digest: artifactDigest, Size: int64(len(artifact))},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := []byte("{}")
+	imageDigest := godigest.FromBytes(image)
+	respond := func(w http.ResponseWriter, mediaType string, content []byte) {
+		w.Header().Set("Content-Type", mediaType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		w.Header().Set("Docker-Content-Digest", godigest.FromBytes(content).String())
+		_, _ = w.Write(content)
+	}
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if scope := r.URL.Query().Get("scope"); scope != "repository:ocp/4.17:pull" {
+				t.Errorf("unexpected scope %s", scope)
+			}
+			fmt.Fprint(w, `{"token": "token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/ocp/4.17/manifests/cli":
+			respond(w, ocispec.MediaTypeImageManifest, image)
+		case "/v2/ocp/4.17/manifests/" + strings.Replace(imageDigest.String(), ":", "-", 1):
+			respond(w, ocispec.MediaTypeImageIndex, index)
+		case "/v2/ocp/4.17/manifests/" + artifactDigest.String():
+			respond(w, ocispec.MediaTypeImageManifest, artifact)
+		case "/v2/ocp/4.17/blobs/" + envelopeDigest.String():
+			respond(w, "application/octet-stream", envelope)
+		default:
+			// the registry does not implement the referrers API
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := strings.TrimPrefix(server.URL, "https://")
+	ref, err := reference.Parse(registry + "/ocp/4.17:cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewRegistryClient(server.Client(), credentialprovider.DockerConfig{registry: {Username: "user", Password: "password"}})
+	actualDigest, envelopes, err := client.Provenance(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("failed to get provenance: %v", err)
+	}
+	if actualDigest != imageDigest.String() {
+		t.Errorf("expected digest %s, got %s", imageDigest, actualDigest)
+	}
+	if diff := cmp.Diff([][]byte{envelope}, envelopes); diff != "" {
+		t.Errorf("unexpected envelopes: %s", diff)
+	}
+}
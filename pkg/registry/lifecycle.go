@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// Deprecation is a reference to a deprecated component of the registry
+type Deprecation struct {
+	Type      string
+	Name      string
+	Lifecycle api.RegistryLifecycle
+}
+
+func (d Deprecation) String() string {
+	return d.Lifecycle.Describe(d.Type, d.Name)
+}
+
+// validateLifecycles checks that the lifecycles of the components are complete
+// and that the components they are replaced by exist
+func validateLifecycles(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName) []error {
+	var ret []error
+	validate := func(componentType, name string, lifecycle *api.RegistryLifecycle, exists func(string) bool) {
+		if lifecycle == nil {
+			return
+		}
+		if err := lifecycle.Validate(); err != nil {
+			ret = append(ret, fmt.Errorf("%s %s: invalid lifecycle: %w", componentType, name, err))
+		}
+		if replacement := lifecycle.ReplacedBy; replacement != "" {
+			if replacement == name {
+				ret = append(ret, fmt.Errorf("%s %s: invalid lifecycle: cannot be replaced by itself", componentType, name))
+			} else if !exists(replacement) {
+				ret = append(ret, fmt.Errorf("%s %s: invalid lifecycle: replacement %s does not exist", componentType, name, replacement))
+			}
+		}
+	}
+	for name, step := range stepsByName {
+		validate("step", name, step.Lifecycle, func(n string) bool { _, ok := stepsByName[n]; return ok })
+	}
+	for name, chain := range chainsByName {
+		validate("chain", name, chain.Lifecycle, func(n string) bool { _, ok := chainsByName[n]; return ok })
+	}
+	for name, workflow := range workflowsByName {
+		validate("workflow", name, workflow.Lifecycle, func(n string) bool { _, ok := workflowsByName[n]; return ok })
+	}
+	return ret
+}
+
+// DeprecationsFor lists the deprecated components of the registry a test executes,
+// whether it refers to them directly or they are contained in the chains and the
+// workflow it refers to. Components only used in the phases of the workflow the test
+// overrides are not executed and are not listed.
+func DeprecationsFor(test api.MultiStageTestConfiguration, graph NodeByName, stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName) []Deprecation {
+	type element struct {
+		nodeType Type
+		name     string
+	}
+	executed := sets.New[element]()
+	var add func(steps []api.TestStep)
+	add = func(steps []api.TestStep) {
+		for _, step := range steps {
+			switch {
+			case step.Reference != nil:
+				executed.Insert(element{nodeType: Reference, name: *step.Reference})
+			case step.Chain != nil:
+				executed.Insert(element{nodeType: Chain, name: *step.Chain})
+			case step.Parallel != nil:
+				add(step.Parallel.TestSteps())
+			}
+		}
+	}
+	var ret []Deprecation
+	var workflow api.MultiStageTestConfiguration
+	if test.Workflow != nil {
+		workflow = workflowsByName[*test.Workflow]
+		if workflow.Lifecycle != nil {
+			ret = append(ret, Deprecation{Type: "workflow", Name: *test.Workflow, Lifecycle: *workflow.Lifecycle})
+		}
+	}
+	for _, phase := range []struct{ own, inherited []api.TestStep }{
+		{own: test.Pre, inherited: workflow.Pre},
+		{own: test.Test, inherited: workflow.Test},
+		{own: test.Post, inherited: workflow.Post},
+	} {
+		if phase.own != nil {
+			add(phase.own)
+		} else {
+			add(phase.inherited)
+		}
+	}
+	// workflows are not followed up the graph: the steps of the workflow the
+	// test executes are already known, whereas the workflow contains all of them
+	uses := func(node Node, ok bool) bool {
+		if !ok {
+			return false
+		}
+		if executed.Has(element{nodeType: node.Type(), name: node.Name()}) {
+			return true
+		}
+		for _, ancestor := range node.Ancestors() {
+			if ancestor.Type() != Workflow && executed.Has(element{nodeType: ancestor.Type(), name: ancestor.Name()}) {
+				return true
+			}
+		}
+		return false
+	}
+	var chains, steps []Deprecation
+	for name, chain := range chainsByName {
+		if node, ok := graph.Chains[name]; chain.Lifecycle != nil && uses(node, ok) {
+			chains = append(chains, Deprecation{Type: "chain", Name: name, Lifecycle: *chain.Lifecycle})
+		}
+	}
+	for name, step := range stepsByName {
+		if node, ok := graph.References[name]; step.Lifecycle != nil && uses(node, ok) {
+			steps = append(steps, Deprecation{Type: "step", Name: name, Lifecycle: *step.Lifecycle})
+		}
+	}
+	for _, deprecations := range [][]Deprecation{chains, steps} {
+		sort.Slice(deprecations, func(i, j int) bool { return deprecations[i].Name < deprecations[j].Name })
+		ret = append(ret, deprecations...)
+	}
+	return ret
+}
@@ -0,0 +1,125 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestValidateLifecycles(t *testing.T) {
+	testCases := []struct {
+		name      string
+		steps     ReferenceByName
+		chains    ChainByName
+		workflows WorkflowByName
+		expected  []error
+	}{
+		{
+			name: "valid lifecycles",
+			steps: ReferenceByName{
+				"old": {As: "old", Lifecycle: &api.RegistryLifecycle{Deprecated: "renamed", ReplacedBy: "new", RemovalDate: "2026-01-31"}},
+				"new": {As: "new"},
+			},
+			chains:    ChainByName{"chain": {As: "chain", Lifecycle: &api.RegistryLifecycle{Deprecated: "unused"}}},
+			workflows: WorkflowByName{"workflow": {Lifecycle: &api.RegistryLifecycle{Deprecated: "unused"}}},
+		},
+		{
+			name: "invalid lifecycles",
+			steps: ReferenceByName{
+				"old": {As: "old", Lifecycle: &api.RegistryLifecycle{ReplacedBy: "new", RemovalDate: "31/01/2026"}},
+			},
+			chains: ChainByName{"chain": {As: "chain", Lifecycle: &api.RegistryLifecycle{Deprecated: "renamed", ReplacedBy: "chain"}}},
+			workflows: WorkflowByName{
+				"workflow": {Lifecycle: &api.RegistryLifecycle{Deprecated: "renamed", ReplacedBy: "old"}},
+			},
+			expected: []error{
+				errors.New("step old: invalid lifecycle: deprecated: a reason must be provided, removal_date: must be in the 2006-01-02 format"),
+				errors.New("step old: invalid lifecycle: replacement new does not exist"),
+				errors.New("chain chain: invalid lifecycle: cannot be replaced by itself"),
+				errors.New("workflow workflow: invalid lifecycle: replacement old does not exist"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := validateLifecycles(tc.steps, tc.chains, tc.workflows)
+			if diff := cmp.Diff(tc.expected, actual, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDeprecationsFor(t *testing.T) {
+	oldStep, newStep, oldChain, oldWorkflow, nested, inherited := "old-step", "new-step", "old-chain", "old-workflow", "nested", "inherited"
+	lifecycle := api.RegistryLifecycle{Deprecated: "superseded"}
+	steps := ReferenceByName{
+		oldStep:   {As: oldStep, Lifecycle: &lifecycle},
+		newStep:   {As: newStep},
+		nested:    {As: nested, Lifecycle: &lifecycle},
+		inherited: {As: inherited, Lifecycle: &lifecycle},
+	}
+	chains := ChainByName{oldChain: {As: oldChain, Steps: []api.TestStep{{Reference: &nested}}, Lifecycle: &lifecycle}}
+	workflows := WorkflowByName{oldWorkflow: {
+		Pre:       []api.TestStep{{Chain: &oldChain}},
+		Test:      []api.TestStep{{Reference: &inherited}},
+		Lifecycle: &lifecycle,
+	}}
+	graph, err := NewGraph(steps, chains, workflows, nil)
+	if err != nil {
+		t.Fatalf("failed to create the graph: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		test     api.MultiStageTestConfiguration
+		expected []Deprecation
+	}{
+		{
+			name: "direct references",
+			test: api.MultiStageTestConfiguration{
+				Pre:  []api.TestStep{{Chain: &oldChain}},
+				Test: []api.TestStep{{Reference: &newStep}, {LiteralTestStep: &api.LiteralTestStep{As: "literal"}}},
+				Post: []api.TestStep{{Reference: &oldStep}},
+			},
+			expected: []Deprecation{
+				{Type: "chain", Name: oldChain, Lifecycle: lifecycle},
+				{Type: "step", Name: nested, Lifecycle: lifecycle},
+				{Type: "step", Name: oldStep, Lifecycle: lifecycle},
+			},
+		},
+		{
+			name: "components of the workflow",
+			test: api.MultiStageTestConfiguration{Workflow: &oldWorkflow},
+			expected: []Deprecation{
+				{Type: "workflow", Name: oldWorkflow, Lifecycle: lifecycle},
+				{Type: "chain", Name: oldChain, Lifecycle: lifecycle},
+				{Type: "step", Name: inherited, Lifecycle: lifecycle},
+				{Type: "step", Name: nested, Lifecycle: lifecycle},
+			},
+		},
+		{
+			name: "components of overridden phases of the workflow are not executed",
+			test: api.MultiStageTestConfiguration{
+				Workflow: &oldWorkflow,
+				Pre:      []api.TestStep{},
+				Test:     []api.TestStep{{Parallel: &api.ParallelTestSteps{As: "group", Steps: []api.ParallelTestStep{{Reference: &newStep}, {Reference: &oldStep}}}}},
+			},
+			expected: []Deprecation{
+				{Type: "workflow", Name: oldWorkflow, Lifecycle: lifecycle},
+				{Type: "step", Name: oldStep, Lifecycle: lifecycle},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, DeprecationsFor(tc.test, graph, steps, chains, workflows)); diff != "" {
+				t.Errorf("unexpected deprecations: %s", diff)
+			}
+		})
+	}
+}
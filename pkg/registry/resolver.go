@@ -2,6 +2,7 @@ package registry
 
 import (
 	"fmt"
+	"reflect"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -44,6 +45,7 @@ func Validate(stepsByName ReferenceByName, chainsByName ChainByName, workflowsBy
 	for _, v := range observersByName {
 		ret = append(ret, validation.Observer(v)...)
 	}
+	ret = append(ret, validateLifecycles(stepsByName, chainsByName, workflowsByName)...)
 	return utilerrors.NewAggregate(ret)
 }
 
@@ -87,6 +89,7 @@ func (r *registry) mergeWorkflow(config *api.MultiStageTestConfiguration) ([][]a
 	var errs []error
 	if config.ClusterProfile == "" {
 		config.ClusterProfile = workflow.ClusterProfile
+		config.FallbackClusterProfiles = workflow.FallbackClusterProfiles
 	}
 	if config.Pre == nil {
 		config.Pre = workflow.Pre
@@ -109,6 +112,7 @@ func (r *registry) mergeWorkflow(config *api.MultiStageTestConfiguration) ([][]a
 	config.DNSConfig = overwriteIfUnset(workflow.DNSConfig, config.DNSConfig)
 	config.Observers = overwriteIfUnset(workflow.Observers, config.Observers)
 	config.NodeArchitecture = overwriteIfUnset(workflow.NodeArchitecture, config.NodeArchitecture)
+	config.SharedDir = overwriteIfUnset(workflow.SharedDir, config.SharedDir)
 
 	if l, err := mergeLeases(workflow.Leases, config.Leases); err != nil {
 		errs = append(errs, err)
@@ -132,10 +136,12 @@ func (r *registry) resolveTest(
 	var resolveErrors []error
 	expandedFlow := api.MultiStageTestConfigurationLiteral{
 		ClusterProfile:           config.ClusterProfile,
+		FallbackClusterProfiles:  config.FallbackClusterProfiles,
 		AllowSkipOnSuccess:       config.AllowSkipOnSuccess,
 		AllowBestEffortPostSteps: config.AllowBestEffortPostSteps,
 		Leases:                   config.Leases,
 		DependencyOverrides:      config.DependencyOverrides,
+		SharedDir:                config.SharedDir,
 	}
 	if config.Workflow != nil {
 		stack.push(stackRecordForTest("workflow/"+*config.Workflow, nil, nil, nil, nil))
@@ -163,6 +169,7 @@ func (r *registry) resolveTest(
 	observers, errs := r.processObservers(observerNames, stack)
 	resolveErrors = append(resolveErrors, errs...)
 	expandedFlow.Observers = observers
+	expandedFlow.Deprecations = r.deprecations(config)
 
 	resolveErrors = append(resolveErrors, stack.checkUnused(&stack.records[0], overridden, r)...)
 
@@ -195,6 +202,41 @@ func (r *registry) ResolveChain(name string) (api.RegistryChain, error) {
 	return ret, nil
 }
 
+// deprecations describes the deprecated workflow and chains a test is resolved from,
+// so they can be reported when it runs
+func (r *registry) deprecations(config api.MultiStageTestConfiguration) []string {
+	var ret []string
+	if config.Workflow != nil {
+		if lifecycle := r.workflowsByName[*config.Workflow].Lifecycle; lifecycle != nil {
+			ret = append(ret, lifecycle.Describe("workflow", *config.Workflow))
+		}
+	}
+	seen := sets.New[string]()
+	var walk func(steps []api.TestStep)
+	walk = func(steps []api.TestStep) {
+		for _, step := range steps {
+			switch {
+			case step.Chain != nil:
+				if seen.Has(*step.Chain) {
+					continue
+				}
+				seen.Insert(*step.Chain)
+				chain := r.chainsByName[*step.Chain]
+				if chain.Lifecycle != nil {
+					ret = append(ret, chain.Lifecycle.Describe("chain", *step.Chain))
+				}
+				walk(chain.Steps)
+			case step.Parallel != nil:
+				walk(step.Parallel.TestSteps())
+			}
+		}
+	}
+	for _, phase := range [][]api.TestStep{config.Pre, config.Test, config.Post} {
+		walk(phase)
+	}
+	return ret
+}
+
 // mergeEnvironments joins two environment maps.
 // A copy of `dst` is returned with elements overwritten by those in `src` if
 // they target the same variable.
@@ -248,7 +290,7 @@ func mergeLeases(dst, src []api.StepLease) ([]api.StepLease, error) {
 	}
 	for i := range src {
 		if p, ok := seen[src[i].Env]; ok {
-			if *p != src[i] {
+			if !reflect.DeepEqual(*p, src[i]) {
 				dup = append(dup, src[i].Env)
 			}
 			continue
@@ -268,6 +310,10 @@ func (r *registry) process(steps []api.TestStep, seen sets.Set[string], stack st
 			steps, err := r.processChain(*step.Chain, seen, stack)
 			errs = append(errs, err...)
 			ret = append(ret, steps...)
+		} else if step.Parallel != nil {
+			steps, err := r.processParallel(step.Parallel, seen, stack)
+			errs = append(errs, err...)
+			ret = append(ret, steps...)
 		} else {
 			step, err := r.processStep(&step, seen, stack)
 			errs = append(errs, err...)
@@ -292,6 +338,45 @@ func (r *registry) processChain(name string, seen sets.Set[string], stack stack)
 	return ret, err
 }
 
+// processParallel resolves the steps of a parallel group and marks each of
+// them as a member of the group. Every member of the group is a branch of its
+// own: the steps of a chain keep their order and share the chain's branch.
+func (r *registry) processParallel(group *api.ParallelTestSteps, seen sets.Set[string], stack stack) ([]api.LiteralTestStep, []error) {
+	stack.push(stackRecordForStep("parallel/"+group.As, nil, nil, nil, nil))
+	defer stack.pop()
+	// group names share the namespace of step names in a phase, so two groups
+	// with the same name cannot be merged into one when they are adjacent
+	key := "parallel/" + group.As
+	if seen.Has(key) {
+		return nil, []error{stack.errorf("duplicate parallel group name: %s", group.As)}
+	}
+	seen.Insert(key)
+	var ret []api.LiteralTestStep
+	var errs []error
+	for _, member := range group.TestSteps() {
+		steps, err := r.process([]api.TestStep{member}, seen, stack)
+		errs = append(errs, err...)
+		var branch string
+		if member.Chain != nil {
+			branch = *member.Chain
+		}
+		for i := range steps {
+			if g := steps[i].ParallelGroup; g != "" {
+				errs = append(errs, stack.errorf("step/%s: nested parallel groups are not allowed: %s", steps[i].As, g))
+				continue
+			}
+			steps[i].ParallelGroup = group.As
+			if branch != "" {
+				steps[i].ParallelBranch = branch
+			} else {
+				steps[i].ParallelBranch = steps[i].As
+			}
+		}
+		ret = append(ret, steps...)
+	}
+	return ret, errs
+}
+
 func (r *registry) processStep(step *api.TestStep, seen sets.Set[string], stack stack) (ret api.LiteralTestStep, err []error) {
 	if ref := step.Reference; ref != nil {
 		var ok bool
@@ -379,6 +464,12 @@ func (r *registry) iterateSteps(s api.TestStep, f func(*api.LiteralTestStep)) er
 				return err
 			}
 		}
+	case s.Parallel != nil:
+		for _, s := range s.Parallel.TestSteps() {
+			if err := r.iterateSteps(s, f); err != nil {
+				return err
+			}
+		}
 	case s.Reference != nil:
 		r, ok := r.stepsByName[*s.Reference]
 		if !ok {
@@ -602,6 +602,128 @@ func TestResolve(t *testing.T) {
 		expectedRes:           api.MultiStageTestConfigurationLiteral{},
 		expectedErr:           errors.New("test/test: chain/nested-chains: duplicate name: ipi-setup"),
 		expectedValidationErr: errors.New("chain/nested-chains: duplicate name: ipi-setup"),
+	}, {
+		name: "Test with parallel group containing a chain and a reference",
+		config: api.MultiStageTestConfiguration{
+			ClusterProfile: api.ClusterProfileAWS,
+			Test: []api.TestStep{{
+				Parallel: &api.ParallelTestSteps{
+					As:    "conformance",
+					Steps: []api.ParallelTestStep{{Chain: &chainInstall}, {Reference: &reference1}},
+				},
+			}},
+		},
+		stepMap: ReferenceByName{
+			reference1: {
+				As:       "generic-unit-test",
+				From:     "my-image",
+				Commands: "make test/unit",
+			},
+		},
+		chainMap: ChainByName{
+			chainInstall: {
+				Steps: []api.TestStep{{
+					LiteralTestStep: &api.LiteralTestStep{
+						As:       "ipi-lease",
+						From:     "installer",
+						Commands: "lease",
+					},
+				}, {
+					LiteralTestStep: &api.LiteralTestStep{
+						As:       "ipi-setup",
+						From:     "installer",
+						Commands: "openshift-cluster install",
+					},
+				}},
+			},
+		},
+		expectedRes: api.MultiStageTestConfigurationLiteral{
+			ClusterProfile: api.ClusterProfileAWS,
+			Test: []api.LiteralTestStep{{
+				As:             "ipi-lease",
+				From:           "installer",
+				Commands:       "lease",
+				ParallelGroup:  "conformance",
+				ParallelBranch: chainInstall,
+			}, {
+				As:             "ipi-setup",
+				From:           "installer",
+				Commands:       "openshift-cluster install",
+				ParallelGroup:  "conformance",
+				ParallelBranch: chainInstall,
+			}, {
+				As:             "generic-unit-test",
+				From:           "my-image",
+				Commands:       "make test/unit",
+				ParallelGroup:  "conformance",
+				ParallelBranch: "generic-unit-test",
+			}},
+		},
+	}, {
+		name: "Test with nested parallel groups",
+		config: api.MultiStageTestConfiguration{
+			ClusterProfile: api.ClusterProfileAWS,
+			Test: []api.TestStep{{
+				Parallel: &api.ParallelTestSteps{
+					As:    "outer",
+					Steps: []api.ParallelTestStep{{Chain: &chainInstall}},
+				},
+			}},
+		},
+		chainMap: ChainByName{
+			chainInstall: {
+				Steps: []api.TestStep{{
+					Parallel: &api.ParallelTestSteps{
+						As: "inner",
+						Steps: []api.ParallelTestStep{{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:       "ipi-lease",
+								From:     "installer",
+								Commands: "lease",
+							},
+						}},
+					},
+				}},
+			},
+		},
+		expectedRes: api.MultiStageTestConfigurationLiteral{},
+		expectedErr: errors.New("test/test: parallel/outer: step/ipi-lease: nested parallel groups are not allowed: inner"),
+	}, {
+		name: "Test with adjacent parallel groups with the same name",
+		config: api.MultiStageTestConfiguration{
+			ClusterProfile: api.ClusterProfileAWS,
+			Test: []api.TestStep{{
+				Parallel: &api.ParallelTestSteps{
+					As:    "conformance",
+					Steps: []api.ParallelTestStep{{Chain: &chainInstall}},
+				},
+			}, {
+				Parallel: &api.ParallelTestSteps{
+					As:    "conformance",
+					Steps: []api.ParallelTestStep{{Reference: &reference1}},
+				},
+			}},
+		},
+		stepMap: ReferenceByName{
+			reference1: {
+				As:       "generic-unit-test",
+				From:     "my-image",
+				Commands: "make test/unit",
+			},
+		},
+		chainMap: ChainByName{
+			chainInstall: {
+				Steps: []api.TestStep{{
+					LiteralTestStep: &api.LiteralTestStep{
+						As:       "ipi-lease",
+						From:     "installer",
+						Commands: "lease",
+					},
+				}},
+			},
+		},
+		expectedRes: api.MultiStageTestConfigurationLiteral{},
+		expectedErr: errors.New("test/test: parallel/conformance: duplicate parallel group name: conformance"),
 	}, {
 		name: "Full AWS Workflow",
 		config: api.MultiStageTestConfiguration{
@@ -1500,6 +1622,33 @@ func TestResolve(t *testing.T) {
 					NodeArchitecture: &nodeArchitectureARM64,
 				}},
 			},
+		}, {
+			name: "Test with a deprecated workflow and chain",
+			config: api.MultiStageTestConfiguration{
+				Workflow: &awsWorkflow,
+			},
+			stepMap: ReferenceByName{
+				"ipi-install": {As: "ipi-install", From: "installer", Commands: "openshift-cluster install"},
+			},
+			chainMap: ChainByName{
+				"ipi-conf":     {As: "ipi-conf", Steps: []api.TestStep{{Reference: strPtr("ipi-install")}}, Lifecycle: &api.RegistryLifecycle{Deprecated: "renamed", ReplacedBy: "ipi-conf-aws"}},
+				"ipi-conf-aws": {As: "ipi-conf-aws", Steps: []api.TestStep{{Reference: strPtr("ipi-install")}}},
+			},
+			workflowMap: WorkflowByName{
+				awsWorkflow: {
+					ClusterProfile: api.ClusterProfileAWS,
+					Pre:            []api.TestStep{{Chain: strPtr("ipi-conf")}},
+					Lifecycle:      &api.RegistryLifecycle{Deprecated: "unused", RemovalDate: "2026-01-31"},
+				},
+			},
+			expectedRes: api.MultiStageTestConfigurationLiteral{
+				ClusterProfile: api.ClusterProfileAWS,
+				Pre:            []api.LiteralTestStep{{As: "ipi-install", From: "installer", Commands: "openshift-cluster install"}},
+				Deprecations: []string{
+					"workflow ipi-aws is deprecated: unused; it is scheduled for removal on 2026-01-31",
+					"chain ipi-conf is deprecated: renamed; use chain ipi-conf-aws instead",
+				},
+			},
 		}} {
 		t.Run(testCase.name, func(t *testing.T) {
 			err := Validate(testCase.stepMap, testCase.chainMap, testCase.workflowMap, testCase.observerMap)
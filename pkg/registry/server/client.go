@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/hashicorp/go-retryablehttp"
@@ -24,14 +25,36 @@ type ResolverClient interface {
 	Resolve([]byte) (*api.ReleaseBuildConfiguration, error)
 	ClusterProfile(profileName string) (*api.ClusterProfileDetails, error)
 	IntegratedStream(namespace, name string) (*configresolver.IntegratedStream, error)
+	// RegistryRevision returns the revision of the registry the last configuration was resolved against
+	RegistryRevision() string
 }
 
 func NewResolverClient(address string) ResolverClient {
 	return &resolverClient{Address: address}
 }
 
+// NewResolverClientAtRevision returns a client resolving configurations against the
+// registry of the revision of the release repository instead of the current one
+func NewResolverClientAtRevision(address, revision string) ResolverClient {
+	return &resolverClient{Address: address, Revision: revision}
+}
+
 type resolverClient struct {
-	Address string
+	Address  string
+	Revision string
+
+	resolvedRevision string
+}
+
+func (r *resolverClient) RegistryRevision() string {
+	return r.resolvedRevision
+}
+
+// addRevision pins the revision of the registry in the query, if requested
+func (r *resolverClient) addRevision(query url.Values) {
+	if r.Revision != "" {
+		query.Add(RevisionQuery, r.Revision)
+	}
 }
 
 func (r *resolverClient) Config(info *api.Metadata) (*api.ReleaseBuildConfiguration, error) {
@@ -47,8 +70,9 @@ func (r *resolverClient) Config(info *api.Metadata) (*api.ReleaseBuildConfigurat
 	if len(info.Variant) > 0 {
 		query.Add(VariantQuery, info.Variant)
 	}
+	r.addRevision(query)
 	req.URL.RawQuery = query.Encode()
-	return configFromResolverRequest(req)
+	return r.configFromResolverRequest(req)
 }
 
 func (r *resolverClient) ConfigWithTest(base *api.Metadata, testSource *api.MetadataWithTest) (*api.ReleaseBuildConfiguration, error) {
@@ -76,9 +100,10 @@ func (r *resolverClient) ConfigWithTest(base *api.Metadata, testSource *api.Meta
 		}
 		query.Add(k, v)
 	}
+	r.addRevision(query)
 
 	req.URL.RawQuery = query.Encode()
-	return configFromResolverRequest(req)
+	return r.configFromResolverRequest(req)
 }
 
 func (r *resolverClient) Resolve(raw []byte) (*api.ReleaseBuildConfiguration, error) {
@@ -96,7 +121,10 @@ func (r *resolverClient) Resolve(raw []byte) (*api.ReleaseBuildConfiguration, er
 		return nil, fmt.Errorf("failed to create request for configresolver: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	return configFromResolverRequest(req)
+	query := req.URL.Query()
+	r.addRevision(query)
+	req.URL.RawQuery = query.Encode()
+	return r.configFromResolverRequest(req)
 }
 
 type adapter struct{}
@@ -129,11 +157,12 @@ func (a adapter) Warn(s string, i ...interface{}) {
 
 var _ retryablehttp.LeveledLogger = adapter{}
 
-func configFromResolverRequest(req *http.Request) (*api.ReleaseBuildConfiguration, error) {
-	data, err := doRequest(req)
+func (r *resolverClient) configFromResolverRequest(req *http.Request) (*api.ReleaseBuildConfiguration, error) {
+	data, header, err := doRequest(req)
 	if err != nil {
 		return nil, err
 	}
+	r.resolvedRevision = header.Get(RevisionHeader)
 	configSpecHTTP := &api.ReleaseBuildConfiguration{}
 	err = json.Unmarshal(data, configSpecHTTP)
 	if err != nil {
@@ -142,8 +171,8 @@ func configFromResolverRequest(req *http.Request) (*api.ReleaseBuildConfiguratio
 	return configSpecHTTP, nil
 }
 
-// doRequest makes a request to config resolver and returns the response body
-func doRequest(req *http.Request) ([]byte, error) {
+// doRequest makes a request to config resolver and returns the response body and headers
+func doRequest(req *http.Request) ([]byte, http.Header, error) {
 	retryClient := retryablehttp.NewClient()
 	retryClient.RetryMax = 5
 	retryClient.Logger = adapter{}
@@ -151,7 +180,7 @@ func doRequest(req *http.Request) ([]byte, error) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request to configresolver: %w", err)
+		return nil, nil, fmt.Errorf("failed to make request to configresolver: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
@@ -161,9 +190,10 @@ func doRequest(req *http.Request) ([]byte, error) {
 		} else {
 			responseBody = string(data)
 		}
-		return nil, fmt.Errorf("got unexpected http %d status code from configresolver: %s", resp.StatusCode, responseBody)
+		return nil, nil, fmt.Errorf("got unexpected http %d status code from configresolver: %s", resp.StatusCode, responseBody)
 	}
-	return io.ReadAll(resp.Body)
+	data, err := io.ReadAll(resp.Body)
+	return data, resp.Header, err
 }
 
 // ClusterProfile gets the info about a desired cluster profile by creating a request
@@ -178,7 +208,7 @@ func (r *resolverClient) ClusterProfile(profileName string) (*api.ClusterProfile
 	query.Add(NameQuery, profileName)
 	req.URL.RawQuery = query.Encode()
 
-	data, err := doRequest(req)
+	data, _, err := doRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -203,7 +233,7 @@ func (r *resolverClient) IntegratedStream(namespace, name string) (*configresolv
 	query.Add("name", name)
 	req.URL.RawQuery = query.Encode()
 
-	data, err := doRequest(req)
+	data, _, err := doRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +85,41 @@ func TestConfig(t *testing.T) {
 	}
 }
 
+func TestConfigAtRevision(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		revision string
+		served   string
+	}{
+		{name: "current registry", served: "current"},
+		{name: "pinned revision", revision: "pinned", served: "pinned"},
+		{name: "unversioned registry"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if revision := r.URL.Query().Get(RevisionQuery); revision != tc.revision {
+					t.Errorf("expected revision %q to be requested, got %q", tc.revision, revision)
+				}
+				if tc.served != "" {
+					w.Header().Set(RevisionHeader, tc.served)
+				}
+				w.WriteHeader(http.StatusOK)
+				if _, err := w.Write([]byte("{}")); err != nil {
+					t.Errorf("failed to write data: %v", err)
+				}
+			}))
+			defer server.Close()
+			client := NewResolverClientAtRevision(server.URL, tc.revision)
+			if _, err := client.Config(&api.Metadata{Org: "openshift", Repo: "hyperkube", Branch: "master"}); err != nil {
+				t.Fatalf("failed to get config: %v", err)
+			}
+			if revision := client.RegistryRevision(); revision != tc.served {
+				t.Errorf("expected the served revision %q, got %q", tc.served, revision)
+			}
+		})
+	}
+}
+
 func TestClusterProfile(t *testing.T) {
 	awsProfile := &api.ClusterProfileDetails{
 		Profile: "aws",
@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -36,8 +37,15 @@ const (
 	NameQuery = "name"
 )
 
+const (
+	// RevisionQuery pins the revision of the release repository whose registry is used to resolve configurations
+	RevisionQuery = "revision"
+	// RevisionHeader holds the revision of the registry a configuration was resolved against
+	RevisionHeader = "X-Registry-Revision"
+)
+
 type Resolver interface {
-	ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error)
+	ResolveConfigAtRevision(config api.ReleaseBuildConfiguration, revision string) (api.ReleaseBuildConfiguration, string, error)
 }
 
 type Getter interface {
@@ -79,8 +87,15 @@ func MissingQuery(w http.ResponseWriter, field string) {
 	fmt.Fprintf(w, "%s query missing or incorrect", field)
 }
 
-func resolveAndRespond(resolver Resolver, config api.ReleaseBuildConfiguration, w http.ResponseWriter, logger *logrus.Entry, resolverMetrics *metrics.Metrics) {
-	config, err := resolver.ResolveConfig(config)
+func resolveAndRespond(resolver Resolver, config api.ReleaseBuildConfiguration, revision string, w http.ResponseWriter, logger *logrus.Entry, resolverMetrics *metrics.Metrics) {
+	config, revision, err := resolver.ResolveConfigAtRevision(config, revision)
+	if errors.Is(err, agents.ErrUnknownRevision) {
+		metrics.RecordError("registry revision not found", resolverMetrics.ErrorRate)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "failed to resolve config: %v", err)
+		logger.WithError(err).Warning("failed to resolve config with registry")
+		return
+	}
 	if err != nil {
 		metrics.RecordError("failed to resolve config with registry", resolverMetrics.ErrorRate)
 		w.WriteHeader(http.StatusBadRequest)
@@ -99,6 +114,9 @@ func resolveAndRespond(resolver Resolver, config api.ReleaseBuildConfiguration,
 		logger.WithError(err).Errorf("failed to marshal config to JSON")
 		return
 	}
+	if revision != "" {
+		w.Header().Set(RevisionHeader, revision)
+	}
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write(jsonConfig); err != nil {
 		logrus.WithError(err).Error("Failed to write response")
@@ -189,7 +207,7 @@ func ResolveConfig(configs Getter, resolver Resolver, resolverMetrics *metrics.M
 			logger.WithError(err).Warning("failed to get config")
 			return
 		}
-		resolveAndRespond(resolver, config, w, logger, resolverMetrics)
+		resolveAndRespond(resolver, config, r.URL.Query().Get(RevisionQuery), w, logger, resolverMetrics)
 	}
 }
 
@@ -214,7 +232,7 @@ func ResolveLiteralConfig(resolver Resolver, resolverMetrics *metrics.Metrics) h
 			_, _ = w.Write([]byte("Could not parse request body as unresolved config."))
 			return
 		}
-		resolveAndRespond(resolver, unresolvedConfig, w, logger, resolverMetrics)
+		resolveAndRespond(resolver, unresolvedConfig, r.URL.Query().Get(RevisionQuery), w, logger, resolverMetrics)
 	}
 }
 
@@ -413,7 +431,7 @@ func ResolveAndMergeConfigsAndInjectTest(configs Getter, resolver Resolver, reso
 			mergedConfig = injectTest(*mergedConfig, configs, resolverMetrics, w, r, logger)
 		}
 		if mergedConfig != nil {
-			resolveAndRespond(resolver, *mergedConfig, w, logger, resolverMetrics)
+			resolveAndRespond(resolver, *mergedConfig, r.URL.Query().Get(RevisionQuery), w, logger, resolverMetrics)
 		}
 	}
 }
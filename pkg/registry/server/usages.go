@@ -0,0 +1,261 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/metrics"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// TypeQuery is used for fetching usages of a registry element by its type
+const TypeQuery = "type"
+
+var elementTypes = map[string]registry.Type{
+	"reference": registry.Reference,
+	"chain":     registry.Chain,
+	"workflow":  registry.Workflow,
+	"observer":  registry.Observer,
+}
+
+// Element identifies an element of the step registry
+type Element struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+func elementFor(node registry.Node) Element {
+	for name, nodeType := range elementTypes {
+		if nodeType == node.Type() {
+			return Element{Type: name, Name: node.Name()}
+		}
+	}
+	return Element{Name: node.Name()}
+}
+
+// TestUsage is a ci-operator test which executes an element of the step registry
+type TestUsage struct {
+	api.Metadata   `json:",inline"`
+	Test           string `json:"test"`
+	ClusterProfile string `json:"cluster_profile,omitempty"`
+	// Via holds the elements, referred to by the test or inherited from its
+	// workflow, which execute the element; it is empty when the test refers
+	// to the element directly
+	Via []Element `json:"via,omitempty"`
+}
+
+// Usages are all the tests which execute an element of the step registry, with
+// the number of those tests that use each cluster profile
+type Usages struct {
+	Element         `json:",inline"`
+	Tests           []TestUsage    `json:"tests"`
+	ClusterProfiles map[string]int `json:"cluster_profiles"`
+}
+
+// ErrUnknownType is returned when usages are requested for an element of a type the registry does not have
+var ErrUnknownType = errors.New("unknown type of registry element")
+
+// FindUsages determines which tests in the configurations execute the element of the
+// step registry, either by referring to it directly or to a chain or workflow which
+// contains it. Tests which override the phases of their workflow in which the element
+// is used, or disable the observer, do not execute it and are not reported.
+func FindUsages(graph registry.NodeByName, workflows registry.WorkflowByName, configs config.ByOrgRepo, elementType, name string) (*Usages, error) {
+	nodeType, known := elementTypes[elementType]
+	if !known {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, elementType)
+	}
+	nodes := map[registry.Type]map[string]registry.Node{
+		registry.Reference: graph.References,
+		registry.Chain:     graph.Chains,
+		registry.Workflow:  graph.Workflows,
+		registry.Observer:  graph.Observers,
+	}[nodeType]
+	node, exists := nodes[name]
+	if !exists {
+		return nil, fmt.Errorf("could not find %s %s", elementType, name)
+	}
+	ancestors := map[Element]bool{}
+	for _, ancestor := range node.Ancestors() {
+		ancestors[elementFor(ancestor)] = true
+	}
+
+	usages := &Usages{Element: elementFor(node), Tests: []TestUsage{}, ClusterProfiles: map[string]int{}}
+	for _, orgConfigs := range configs {
+		for _, repoConfigs := range orgConfigs {
+			for _, configuration := range repoConfigs {
+				for _, test := range configuration.Tests {
+					if test.MultiStageTestConfiguration == nil {
+						continue
+					}
+					multiStage := *test.MultiStageTestConfiguration
+					direct, via := false, map[Element]bool{}
+					for element, inherited := range executedElements(multiStage, workflows) {
+						if element != usages.Element && !ancestors[element] {
+							continue
+						}
+						if inherited {
+							via[Element{Type: "workflow", Name: *multiStage.Workflow}] = true
+						}
+						if element == usages.Element {
+							direct = direct || !inherited
+						} else {
+							via[element] = true
+						}
+					}
+					if multiStage.Workflow != nil && (Element{Type: "workflow", Name: *multiStage.Workflow}) == usages.Element {
+						direct = true
+					}
+					if !direct && len(via) == 0 {
+						continue
+					}
+					usage := TestUsage{Metadata: configuration.Metadata, Test: test.As, ClusterProfile: string(clusterProfileFor(multiStage, workflows))}
+					if !direct {
+						for element := range via {
+							usage.Via = append(usage.Via, element)
+						}
+						sort.Slice(usage.Via, func(i, j int) bool {
+							if usage.Via[i].Type != usage.Via[j].Type {
+								return usage.Via[i].Type < usage.Via[j].Type
+							}
+							return usage.Via[i].Name < usage.Via[j].Name
+						})
+					}
+					usages.Tests = append(usages.Tests, usage)
+					if usage.ClusterProfile != "" {
+						usages.ClusterProfiles[usage.ClusterProfile]++
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(usages.Tests, func(i, j int) bool {
+		if one, two := usages.Tests[i].Metadata.AsString(), usages.Tests[j].Metadata.AsString(); one != two {
+			return one < two
+		}
+		return usages.Tests[i].Test < usages.Tests[j].Test
+	})
+	return usages, nil
+}
+
+// executedElements lists the registry elements a test executes, which it refers to
+// directly or inherits from the phases and observers of its workflow it does not
+// override, and whether each one is inherited. Disabled observers are not executed.
+func executedElements(test api.MultiStageTestConfiguration, workflows registry.WorkflowByName) map[Element]bool {
+	var workflow api.MultiStageTestConfiguration
+	if test.Workflow != nil {
+		workflow = workflows[*test.Workflow]
+	}
+	elements := map[Element]bool{}
+	var add func(steps []api.TestStep, inherited bool)
+	add = func(steps []api.TestStep, inherited bool) {
+		for _, step := range steps {
+			switch {
+			case step.Reference != nil:
+				elements[Element{Type: "reference", Name: *step.Reference}] = inherited
+			case step.Chain != nil:
+				elements[Element{Type: "chain", Name: *step.Chain}] = inherited
+			case step.Parallel != nil:
+				add(step.Parallel.TestSteps(), inherited)
+			}
+		}
+	}
+	for _, phase := range []struct{ own, inherited []api.TestStep }{
+		{own: test.Pre, inherited: workflow.Pre},
+		{own: test.Test, inherited: workflow.Test},
+		{own: test.Post, inherited: workflow.Post},
+	} {
+		if phase.own != nil {
+			add(phase.own, false)
+		} else {
+			add(phase.inherited, true)
+		}
+	}
+	disabled := sets.New[string]()
+	if test.Observers != nil {
+		disabled.Insert(test.Observers.Disable...)
+	}
+	if workflow.Observers != nil {
+		for _, observer := range workflow.Observers.Enable {
+			if !disabled.Has(observer) {
+				elements[Element{Type: "observer", Name: observer}] = true
+			}
+		}
+	}
+	if test.Observers != nil {
+		for _, observer := range test.Observers.Enable {
+			if !disabled.Has(observer) {
+				elements[Element{Type: "observer", Name: observer}] = false
+			}
+		}
+	}
+	return elements
+}
+
+// clusterProfileFor determines the cluster profile of a test, which it may inherit from its workflow
+func clusterProfileFor(test api.MultiStageTestConfiguration, workflows registry.WorkflowByName) api.ClusterProfile {
+	if test.ClusterProfile != "" || test.Workflow == nil {
+		return test.ClusterProfile
+	}
+	return workflows[*test.Workflow].ClusterProfile
+}
+
+// ResolveUsages extracts the type and name of a registry element from the request
+// query and in the response provides all the tests which execute it
+func ResolveUsages(registryAgent agents.RegistryAgent, configAgent agents.ConfigAgent, resolverMetrics *metrics.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusNotImplemented)
+			_, _ = w.Write([]byte(http.StatusText(http.StatusNotImplemented)))
+			return
+		}
+		elementType := r.URL.Query().Get(TypeQuery)
+		if elementType == "" {
+			metrics.RecordError("invalid usages query", resolverMetrics.ErrorRate)
+			MissingQuery(w, TypeQuery)
+			return
+		}
+		name := r.URL.Query().Get(NameQuery)
+		if name == "" {
+			metrics.RecordError("invalid usages query", resolverMetrics.ErrorRate)
+			MissingQuery(w, NameQuery)
+			return
+		}
+		_, _, workflows, _, _ := registryAgent.GetRegistryComponents()
+		usages, err := FindUsages(registryAgent.GetRegistryGraph(), workflows, configAgent.GetAll(), elementType, name)
+		if errors.Is(err, ErrUnknownType) {
+			metrics.RecordError("invalid usages query", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "failed to find usages: %v", err)
+			return
+		}
+		if err != nil {
+			metrics.RecordError("registry element not found", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "failed to find usages: %v", err)
+			logrus.WithError(err).Warning("failed to find usages")
+			return
+		}
+		jsonContent, err := json.MarshalIndent(usages, "", "  ")
+		if err != nil {
+			metrics.RecordError("failed to marshal usages to JSON", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to marshal usages of %s %s to JSON: %v", elementType, name, err)
+			logrus.WithError(err).Errorf("failed to marshal usages of %s %s to JSON", elementType, name)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(jsonContent); err != nil {
+			logrus.WithError(err).Errorf("Failed to write response: %v", err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func TestFindUsages(t *testing.T) {
+	install, gather, chain, workflow, observer := "ipi-install", "gather", "ipi-conf", "ipi-aws", "monitor"
+	references := registry.ReferenceByName{install: {As: install}, gather: {As: gather}}
+	chains := registry.ChainByName{chain: {As: chain, Steps: []api.TestStep{{Reference: &install}}}}
+	workflows := registry.WorkflowByName{workflow: {
+		ClusterProfile: api.ClusterProfileAWS,
+		Pre:            []api.TestStep{{Chain: &chain}},
+		Observers:      &api.Observers{Enable: []string{observer}},
+	}}
+	observers := registry.ObserverByName{observer: {Name: observer}}
+	graph, err := registry.NewGraph(references, chains, workflows, observers)
+	if err != nil {
+		t.Fatalf("failed to create the graph: %v", err)
+	}
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	configs := config.ByOrgRepo{"org": {"repo": {{
+		Metadata: metadata,
+		Tests: []api.TestStepConfiguration{
+			{As: "unit", ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"}},
+			{As: "e2e", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Workflow: &workflow}},
+			{As: "e2e-own-pre", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+				Workflow: &workflow,
+				Pre:      []api.TestStep{{Reference: &gather}},
+			}},
+			{As: "e2e-no-observer", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+				Workflow:  &workflow,
+				Observers: &api.Observers{Disable: []string{observer}},
+			}},
+			{As: "e2e-gcp", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+				ClusterProfile: api.ClusterProfileGCP,
+				Pre:            []api.TestStep{{Chain: &chain}},
+				Post:           []api.TestStep{{Reference: &gather}},
+				Observers:      &api.Observers{Enable: []string{observer}},
+			}},
+			{As: "custom", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+				Test: []api.TestStep{{Reference: &install}},
+			}},
+			{As: "parallel", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+				Test: []api.TestStep{{Parallel: &api.ParallelTestSteps{As: "group", Steps: []api.ParallelTestStep{{Chain: &chain}, {Reference: &gather}}}}},
+			}},
+		},
+	}}}}
+
+	testCases := []struct {
+		name        string
+		elementType string
+		element     string
+		expected    *Usages
+		expectedErr bool
+		unknownType bool
+	}{
+		{
+			name:        "reference used directly and through a chain and a workflow, not by tests overriding the phase",
+			elementType: "reference",
+			element:     install,
+			expected: &Usages{
+				Element: Element{Type: "reference", Name: install},
+				Tests: []TestUsage{
+					{Metadata: metadata, Test: "custom"},
+					{Metadata: metadata, Test: "e2e", ClusterProfile: "aws", Via: []Element{{Type: "chain", Name: chain}, {Type: "workflow", Name: workflow}}},
+					{Metadata: metadata, Test: "e2e-gcp", ClusterProfile: "gcp", Via: []Element{{Type: "chain", Name: chain}}},
+					{Metadata: metadata, Test: "e2e-no-observer", ClusterProfile: "aws", Via: []Element{{Type: "chain", Name: chain}, {Type: "workflow", Name: workflow}}},
+					{Metadata: metadata, Test: "parallel", Via: []Element{{Type: "chain", Name: chain}}},
+				},
+				ClusterProfiles: map[string]int{"aws": 2, "gcp": 1},
+			},
+		},
+		{
+			name:        "reference used in a parallel group",
+			elementType: "reference",
+			element:     gather,
+			expected: &Usages{
+				Element: Element{Type: "reference", Name: gather},
+				Tests: []TestUsage{
+					{Metadata: metadata, Test: "e2e-gcp", ClusterProfile: "gcp"},
+					{Metadata: metadata, Test: "e2e-own-pre", ClusterProfile: "aws"},
+					{Metadata: metadata, Test: "parallel"},
+				},
+				ClusterProfiles: map[string]int{"aws": 1, "gcp": 1},
+			},
+		},
+		{
+			name:        "workflow",
+			elementType: "workflow",
+			element:     workflow,
+			expected: &Usages{
+				Element: Element{Type: "workflow", Name: workflow},
+				Tests: []TestUsage{
+					{Metadata: metadata, Test: "e2e", ClusterProfile: "aws"},
+					{Metadata: metadata, Test: "e2e-no-observer", ClusterProfile: "aws"},
+					{Metadata: metadata, Test: "e2e-own-pre", ClusterProfile: "aws"},
+				},
+				ClusterProfiles: map[string]int{"aws": 3},
+			},
+		},
+		{
+			name:        "observer enabled by the test or its workflow, not by tests disabling it",
+			elementType: "observer",
+			element:     observer,
+			expected: &Usages{
+				Element: Element{Type: "observer", Name: observer},
+				Tests: []TestUsage{
+					{Metadata: metadata, Test: "e2e", ClusterProfile: "aws", Via: []Element{{Type: "workflow", Name: workflow}}},
+					{Metadata: metadata, Test: "e2e-gcp", ClusterProfile: "gcp"},
+					{Metadata: metadata, Test: "e2e-own-pre", ClusterProfile: "aws", Via: []Element{{Type: "workflow", Name: workflow}}},
+				},
+				ClusterProfiles: map[string]int{"aws": 2, "gcp": 1},
+			},
+		},
+		{
+			name:        "unknown type",
+			elementType: "step",
+			element:     install,
+			expectedErr: true,
+			unknownType: true,
+		},
+		{
+			name:        "unknown element",
+			elementType: "chain",
+			element:     "missing",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := FindUsages(graph, workflows, configs, tc.elementType, tc.element)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if errors.Is(err, ErrUnknownType) != tc.unknownType {
+				t.Errorf("expected unknown type error: %t, got: %v", tc.unknownType, err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected usages: %s", diff)
+			}
+		})
+	}
+}
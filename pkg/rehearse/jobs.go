@@ -372,6 +372,12 @@ func (jc *JobConfigurer) inlineCiOpConfig(container *v1.Container, ciopConfigs c
 		logger.WithField(logCiopConfigFile, filename).Debug("Rehearsal job would use ci-operator config from registry, its content will be inlined")
 	}
 
+	for _, test := range ciopConfig.Configuration.Tests {
+		if testName != "" && test.As == testName {
+			jc.tests[jobName] = test
+		}
+	}
+
 	ciOpConfigContent, imageStreamTags, err := getResolvedConfigForTest(ciopConfig, resolver, testName)
 	if err != nil {
 		logger.WithError(err).Error("Failed to get resolved config for test")
@@ -411,6 +417,8 @@ type JobConfigurer struct {
 	refs                  *pjapi.Refs
 	uploader              configSpecUploader
 	logger                *logrus.Entry
+	// tests holds the configuration of the tests the rehearsed jobs run, by job name
+	tests map[string]api.TestStepConfiguration
 }
 
 // NewJobConfigurer filters the jobs and returns a new JobConfigurer.
@@ -430,6 +438,7 @@ func NewJobConfigurer(
 		refs:             refs,
 		uploader:         uploader,
 		logger:           logger,
+		tests:            map[string]api.TestStepConfiguration{},
 	}
 }
 
@@ -650,7 +659,7 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 				continue
 			}
 			testSteps := append(test.MultiStageTestConfiguration.Pre, append(test.MultiStageTestConfiguration.Test, test.MultiStageTestConfiguration.Post...)...)
-			for _, testStep := range testSteps {
+			for _, testStep := range api.FlattenTestSteps(testSteps) {
 				hasRef := testStep.Reference != nil && node.Type() == registry.Reference && node.Name() == *testStep.Reference
 				hasChain := testStep.Chain != nil && node.Type() == registry.Chain && node.Name() == *testStep.Chain
 				if hasRef || hasChain {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -55,6 +54,10 @@ type RehearsalConfig struct {
 	GCSCredentialsFile string
 	GCSBrowserPrefix   string
 
+	// JobDurationsFile holds the historical durations of jobs, used
+	// to prefer cheaper jobs when only a subset can be rehearsed
+	JobDurationsFile string
+
 	DryRun bool
 }
 
@@ -162,14 +165,17 @@ func (r RehearsalConfig) DetermineAffectedJobs(candidate RehearsalCandidate, can
 	return filterPresubmits(presubmits, restrictNetworkAccessFalseJobs, logger), filterPeriodics(periodics, restrictNetworkAccessFalseJobs, logger), restrictNetworkAccessFalseJobs, nil
 }
 
-func (r RehearsalConfig) SetupJobs(candidate RehearsalCandidate, candidatePath string, presubmits config.Presubmits, periodics config.Periodics, limit int, logger *logrus.Entry) (*config.ReleaseRepoConfig, *prowapi.Refs, []*prowconfig.Presubmit, error) {
+// SetupJobs configures the rehearsals of the affected jobs, selecting a subset of them if there are more than
+// the limit. The selection decisions explain why each job was chosen or dropped, and are only returned if a
+// subset was selected.
+func (r RehearsalConfig) SetupJobs(candidate RehearsalCandidate, candidatePath string, presubmits config.Presubmits, periodics config.Periodics, limit int, logger *logrus.Entry) (*config.ReleaseRepoConfig, *prowapi.Refs, []*prowconfig.Presubmit, []SelectionDecision, error) {
 	resolver, err := r.createResolver(candidatePath)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 	prConfig, err := config.GetAllConfigs(candidatePath)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 	org := candidate.org
 	repo := candidate.repo
@@ -179,31 +185,37 @@ func (r RehearsalConfig) SetupJobs(candidate RehearsalCandidate, candidatePath s
 	jobConfigurer := NewJobConfigurer(r.DryRun, prConfig.CiOperator, prConfig.Prow, resolver, logger, prRefs, uploader)
 	imageStreamTags, presubmitsToRehearse, err := jobConfigurer.ConfigurePresubmitRehearsals(presubmits)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	periodicImageStreamTags, periodicsToRehearse, err := jobConfigurer.ConfigurePeriodicRehearsals(periodics)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 	apihelper.MergeImageStreamTagMaps(imageStreamTags, periodicImageStreamTags)
 
 	periodicPresubmits, err := jobConfigurer.ConvertPeriodicsToPresubmits(periodicsToRehearse)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 	presubmitsToRehearse = append(presubmitsToRehearse, periodicPresubmits...)
 
+	var decisions []SelectionDecision
+
 	if rehearsals := len(presubmitsToRehearse); rehearsals == 0 {
 		logger.Info("no jobs to rehearse have been found")
-		return nil, nil, nil, nil
+		return nil, nil, nil, nil, nil
 	} else if rehearsals > limit {
 		jobCountFields := logrus.Fields{
 			"rehearsal-threshold": limit,
 			"rehearsal-jobs":      rehearsals,
 		}
 		logger.WithFields(jobCountFields).Info("Would rehearse too many jobs, selecting a subset")
-		presubmitsToRehearse = determineSubsetToRehearse(presubmitsToRehearse, limit)
+		traits, err := r.traitsFor(candidate, candidatePath, presubmitsToRehearse, jobConfigurer.tests, logger)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		presubmitsToRehearse, decisions = determineSubsetToRehearse(presubmitsToRehearse, limit, traits)
 	}
 
 	if prConfig.Prow.JobConfig.PresubmitsStatic == nil {
@@ -213,7 +225,26 @@ func (r RehearsalConfig) SetupJobs(candidate RehearsalCandidate, candidatePath s
 		prConfig.Prow.JobConfig.PresubmitsStatic[org+"/"+repo] = append(prConfig.Prow.JobConfig.PresubmitsStatic[org+"/"+repo], *presubmit)
 	}
 
-	return prConfig, prRefs, presubmitsToRehearse, nil
+	return prConfig, prRefs, presubmitsToRehearse, decisions, nil
+}
+
+// traitsFor determines the traits used to select a subset of the rehearsals
+func (r RehearsalConfig) traitsFor(candidate RehearsalCandidate, candidatePath string, rehearsals []*prowconfig.Presubmit, tests map[string]api.TestStepConfiguration, logger *logrus.Entry) (map[string]rehearsalTraits, error) {
+	var changed []registry.Node
+	if !r.NoRegistry {
+		var err error
+		if changed, err = determineChangedRegistrySteps(candidatePath, candidate.base.sha, logger); err != nil {
+			return nil, fmt.Errorf("could not determine changed registry steps: %w", err)
+		}
+	}
+	var durations map[string]time.Duration
+	if r.JobDurationsFile != "" {
+		var err error
+		if durations, err = LoadJobDurations(r.JobDurationsFile); err != nil {
+			logger.WithError(err).Warn("Could not load job durations, all jobs will be considered equally expensive")
+		}
+	}
+	return traitsFor(rehearsals, candidate.prNumber, tests, changed, durations), nil
 }
 
 func (r RehearsalConfig) createResolver(candidatePath string) (registry.Resolver, error) {
@@ -349,45 +380,6 @@ func determineChangedRegistrySteps(candidate, baseSHA string, logger *logrus.Ent
 	return changedRegistrySteps, nil
 }
 
-// determineSubsetToRehearse determines in a sophisticated way which subset of jobs should be chosen to be rehearsed.
-// First, it will create a list of the jobs mapped by the source type and calculates the maximum allowed jobs for each
-// source type. If there are jobs from a specific source type that are under the max allowed number, it will fill the gap
-// from the other not chosen jobs until it reaches the rehearsal limit.
-func determineSubsetToRehearse(presubmitsToRehearse []*prowconfig.Presubmit, rehearsalLimit int) []*prowconfig.Presubmit {
-	if len(presubmitsToRehearse) <= rehearsalLimit {
-		return presubmitsToRehearse
-	}
-
-	presubmitsBySourceType := make(map[config.SourceType][]*prowconfig.Presubmit)
-	for _, p := range presubmitsToRehearse {
-		sourceType := config.GetSourceType(p.Labels)
-		presubmitsBySourceType[sourceType] = append(presubmitsBySourceType[sourceType], p)
-	}
-
-	maxJobsPerSourceType := rehearsalLimit / len(presubmitsBySourceType)
-	var toRehearse []*prowconfig.Presubmit
-	var dropped []*prowconfig.Presubmit
-
-	for _, jobs := range presubmitsBySourceType {
-		if len(jobs) > maxJobsPerSourceType {
-			dropped = append(dropped, jobs[maxJobsPerSourceType:]...)
-			jobs = jobs[:maxJobsPerSourceType]
-		}
-		toRehearse = append(toRehearse, jobs...)
-	}
-
-	// There are two ways that we will hit this check. First, jobs from a specific resource are less than the
-	// maximum allowed, we will end up having less rehearsals than the limit. Second, summary of the maximum allowed jobs
-	// from each source can be lower than the rehearse limit  due to the rounding inherent in integer division.
-	// In both cases, we fill up the gap from the jobs that we didn't pick earlier.
-	if len(toRehearse) <= rehearsalLimit {
-		sort.Slice(dropped, func(a, b int) bool { return dropped[a].Name < dropped[b].Name })
-		toRehearse = append(toRehearse, dropped[:rehearsalLimit-len(toRehearse)]...)
-	}
-
-	return toRehearse
-}
-
 func pjKubeconfig(path string, defaultKubeconfig *rest.Config) (*rest.Config, error) {
 	if path == "" {
 		return defaultKubeconfig, nil
@@ -69,7 +69,7 @@ func TestDetermineSubsetToRehearse(t *testing.T) {
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-2", Labels: map[string]string{config.SourceTypeLabel: "changedPresubmit"}}},
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-3", Labels: map[string]string{config.SourceTypeLabel: "changedPresubmit"}}},
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-4", Labels: map[string]string{config.SourceTypeLabel: "changedPresubmit"}}},
-				{JobBase: prowconfig.JobBase{Name: "rehearsal-5", Labels: map[string]string{config.SourceTypeLabel: "changedPresubmit"}}},
+				{JobBase: prowconfig.JobBase{Name: "rehearsal-10", Labels: map[string]string{config.SourceTypeLabel: "changedPresubmit"}}},
 			},
 		},
 		{
@@ -92,7 +92,7 @@ func TestDetermineSubsetToRehearse(t *testing.T) {
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-10", Labels: map[string]string{config.SourceTypeLabel: "changedRegistryContent"}}},
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-3", Labels: map[string]string{config.SourceTypeLabel: "changedPeriodic"}}},
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-5", Labels: map[string]string{config.SourceTypeLabel: "changedTemplate"}}},
-				{JobBase: prowconfig.JobBase{Name: "rehearsal-9", Labels: map[string]string{config.SourceTypeLabel: "changedRegistryContent"}}},
+				{JobBase: prowconfig.JobBase{Name: "rehearsal-2", Labels: map[string]string{config.SourceTypeLabel: "changedPresubmit"}}},
 			},
 		},
 		{
@@ -122,7 +122,7 @@ func TestDetermineSubsetToRehearse(t *testing.T) {
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-5", Labels: map[string]string{config.SourceTypeLabel: "changedPeriodic"}}},
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-6", Labels: map[string]string{config.SourceTypeLabel: "changedPeriodic"}}},
 				{JobBase: prowconfig.JobBase{Name: "rehearsal-7", Labels: map[string]string{config.SourceTypeLabel: "changedPeriodic"}}},
-				{JobBase: prowconfig.JobBase{Name: "rehearsal-9", Labels: map[string]string{config.SourceTypeLabel: "changedTemplate"}}},
+				{JobBase: prowconfig.JobBase{Name: "rehearsal-4", Labels: map[string]string{config.SourceTypeLabel: "changedPresubmit"}}},
 			},
 		},
 		{
@@ -143,8 +143,9 @@ func TestDetermineSubsetToRehearse(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.id, func(t *testing.T) {
-			actual := determineSubsetToRehearse(tc.presubmitsToRehearse, tc.rehearsalLimit)
+			actual, _ := determineSubsetToRehearse(tc.presubmitsToRehearse, tc.rehearsalLimit, nil)
 			sort.Slice(actual, func(a, b int) bool { return actual[a].Name < actual[b].Name })
+			sort.Slice(tc.expected, func(a, b int) bool { return tc.expected[a].Name < tc.expected[b].Name })
 
 			if diff := cmp.Diff(actual, tc.expected, allowUnexported); diff != "" {
 				t.Errorf("Presubmit list differs from expected: %s", diff)
@@ -0,0 +1,207 @@
+package rehearse
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowconfig "sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// defaultJobDuration is the estimate used to weigh jobs without a known historical duration
+const defaultJobDuration = time.Hour
+
+// rehearsalTraits describes what rehearsing a job exercises beyond what its
+// labels tell, and how long the job historically takes to run
+type rehearsalTraits struct {
+	workflow      string
+	registryNodes []string
+	duration      time.Duration
+}
+
+// SelectionDecision explains why a job was or was not chosen to be rehearsed
+type SelectionDecision struct {
+	Job      string
+	Selected bool
+	Reason   string
+}
+
+// LoadJobDurations reads the historical durations of jobs, a YAML mapping of job names to durations
+func LoadJobDurations(path string) (map[string]time.Duration, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job durations: %w", err)
+	}
+	var values map[string]string
+	if err := yaml.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job durations: %w", err)
+	}
+	durations := make(map[string]time.Duration, len(values))
+	for job, value := range values {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for job %s: %w", job, err)
+		}
+		durations[job] = duration
+	}
+	return durations, nil
+}
+
+// traitsFor determines the traits of the rehearsals from the tests they run, the registry
+// nodes affected by the change and the historical durations of the jobs they rehearse
+func traitsFor(rehearsals []*prowconfig.Presubmit, prNumber int, tests map[string]api.TestStepConfiguration, changed []registry.Node, durations map[string]time.Duration) map[string]rehearsalTraits {
+	affected := sets.New[string]()
+	for _, node := range getAffectedNodes(changed) {
+		affected.Insert(node.Name())
+	}
+	traits := map[string]rehearsalTraits{}
+	for _, rehearsal := range rehearsals {
+		source := strings.TrimPrefix(rehearsal.Name, fmt.Sprintf("rehearse-%d-", prNumber))
+		t := rehearsalTraits{duration: durations[source]}
+		if test, ok := tests[source]; ok && test.MultiStageTestConfiguration != nil {
+			multiStage := test.MultiStageTestConfiguration
+			if multiStage.Workflow != nil {
+				t.workflow = *multiStage.Workflow
+			}
+			used := sets.New[string](t.workflow)
+			for _, step := range api.FlattenTestSteps(append(multiStage.Pre, append(multiStage.Test, multiStage.Post...)...)) {
+				if step.Reference != nil {
+					used.Insert(*step.Reference)
+				}
+				if step.Chain != nil {
+					used.Insert(*step.Chain)
+				}
+			}
+			if multiStage.Observers != nil {
+				used.Insert(multiStage.Observers.Enable...)
+			}
+			t.registryNodes = sets.List(used.Intersection(affected))
+		}
+		traits[rehearsal.Name] = t
+	}
+	return traits
+}
+
+// aspectsOf lists what rehearsing the job exercises: the reason it was affected,
+// its cluster profile, its workflow and the changed registry nodes it uses
+func aspectsOf(job *prowconfig.Presubmit, traits rehearsalTraits) []string {
+	aspects := []string{fmt.Sprintf("source %s", config.GetSourceType(job.Labels))}
+	if profile, ok := job.Labels[api.CloudClusterProfileLabel]; ok {
+		aspects = append(aspects, fmt.Sprintf("cluster profile %s", profile))
+	}
+	if traits.workflow != "" {
+		aspects = append(aspects, fmt.Sprintf("workflow %s", traits.workflow))
+	}
+	for _, node := range traits.registryNodes {
+		aspects = append(aspects, fmt.Sprintf("registry node %s", node))
+	}
+	return aspects
+}
+
+type selectionCandidate struct {
+	job      *prowconfig.Presubmit
+	aspects  []string
+	duration time.Duration
+	// estimated is set when the job has no historical duration
+	estimated bool
+}
+
+// durationNote describes the duration the candidate was weighed with
+func (c selectionCandidate) durationNote() string {
+	if c.estimated {
+		return fmt.Sprintf("no historical duration, estimated at %s", c.duration)
+	}
+	return c.duration.String()
+}
+
+// value is the sum of the values of the aspects of the candidate, where an aspect
+// is worth less the more often it is exercised by the jobs selected so far
+func (c selectionCandidate) value(covered map[string]int) float64 {
+	var value float64
+	for _, aspect := range c.aspects {
+		value += 1 / float64(1+covered[aspect])
+	}
+	return value
+}
+
+func (c selectionCandidate) uncovered(covered map[string]int) []string {
+	var uncovered []string
+	for _, aspect := range c.aspects {
+		if covered[aspect] == 0 {
+			uncovered = append(uncovered, aspect)
+		}
+	}
+	return uncovered
+}
+
+// score determines whether the candidate exercises anything the jobs selected so far do not
+// and how valuable it is per hour: jobs are worth the number of aspects they would newly cover
+// while there are any, and the value of the aspects they exercise otherwise
+func (c selectionCandidate) score(covered map[string]int) (bool, float64) {
+	if uncovered := c.uncovered(covered); len(uncovered) > 0 {
+		return true, float64(len(uncovered)) / c.duration.Hours()
+	}
+	return false, c.value(covered) / c.duration.Hours()
+}
+
+// determineSubsetToRehearse determines in a sophisticated way which subset of jobs should be chosen to be rehearsed.
+// Jobs are chosen greedily to cover as many distinct aspects (the reason the job is affected, its cluster profile,
+// workflow and the changed registry nodes it uses) as possible per hour of historical duration. Once everything is
+// covered, the remaining capacity is spread across the least exercised aspects. The decisions explain why each job was chosen or dropped, in the order they were made.
+func determineSubsetToRehearse(presubmitsToRehearse []*prowconfig.Presubmit, rehearsalLimit int, traits map[string]rehearsalTraits) ([]*prowconfig.Presubmit, []SelectionDecision) {
+	if len(presubmitsToRehearse) <= rehearsalLimit {
+		return presubmitsToRehearse, nil
+	}
+
+	var remaining []selectionCandidate
+	for _, job := range presubmitsToRehearse {
+		t := traits[job.Name]
+		candidate := selectionCandidate{job: job, aspects: aspectsOf(job, t), duration: t.duration}
+		if candidate.duration <= 0 {
+			candidate.duration, candidate.estimated = defaultJobDuration, true
+		}
+		remaining = append(remaining, candidate)
+	}
+	sort.Slice(remaining, func(i, j int) bool { return remaining[i].job.Name < remaining[j].job.Name })
+
+	var toRehearse []*prowconfig.Presubmit
+	var decisions []SelectionDecision
+	covered := map[string]int{}
+	for len(toRehearse) < rehearsalLimit && len(remaining) > 0 {
+		best := 0
+		bestNew, bestScore := remaining[0].score(covered)
+		for i, candidate := range remaining[1:] {
+			if hasNew, score := candidate.score(covered); (hasNew && !bestNew) || (hasNew == bestNew && score > bestScore) {
+				best, bestNew, bestScore = i+1, hasNew, score
+			}
+		}
+		chosen := remaining[best]
+		reason := fmt.Sprintf("exercises the least covered aspects (%s)", chosen.durationNote())
+		if uncovered := chosen.uncovered(covered); len(uncovered) > 0 {
+			reason = fmt.Sprintf("covers %s (%s)", strings.Join(uncovered, ", "), chosen.durationNote())
+		}
+		for _, aspect := range chosen.aspects {
+			covered[aspect]++
+		}
+		toRehearse = append(toRehearse, chosen.job)
+		decisions = append(decisions, SelectionDecision{Job: chosen.job.Name, Selected: true, Reason: reason})
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+
+	for _, candidate := range remaining {
+		reason := fmt.Sprintf("limit of %d rehearsals reached, everything it covers is exercised by the selected jobs", rehearsalLimit)
+		if uncovered := candidate.uncovered(covered); len(uncovered) > 0 {
+			reason = fmt.Sprintf("limit of %d rehearsals reached before covering %s", rehearsalLimit, strings.Join(uncovered, ", "))
+		}
+		decisions = append(decisions, SelectionDecision{Job: candidate.job.Name, Reason: reason})
+	}
+	return toRehearse, decisions
+}
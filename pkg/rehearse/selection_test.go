@@ -0,0 +1,101 @@
+package rehearse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowconfig "sigs.k8s.io/prow/pkg/config"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func TestDetermineSubsetToRehearseByCoverage(t *testing.T) {
+	job := func(name, profile string) *prowconfig.Presubmit {
+		return &prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: name, Labels: map[string]string{
+			config.SourceTypeLabel:       string(config.ChangedRegistryContent),
+			api.CloudClusterProfileLabel: profile,
+		}}}
+	}
+	presubmits := []*prowconfig.Presubmit{
+		job("aws-a", "aws"),
+		job("aws-b", "aws"),
+		job("aws-c", "aws"),
+		job("gcp-a", "gcp"),
+		job("gcp-b", "gcp"),
+	}
+	traits := map[string]rehearsalTraits{
+		"aws-a": {workflow: "ipi-aws", registryNodes: []string{"ipi-install"}, duration: 4 * time.Hour},
+		"aws-b": {workflow: "ipi-aws", registryNodes: []string{"ipi-install"}, duration: time.Hour},
+		"aws-c": {workflow: "upi-aws"},
+		"gcp-a": {workflow: "ipi-gcp", registryNodes: []string{"ipi-install"}, duration: 90 * time.Minute},
+		"gcp-b": {workflow: "ipi-gcp", duration: 30 * time.Minute},
+	}
+
+	selected, decisions := determineSubsetToRehearse(presubmits, 3, traits)
+	var names []string
+	for _, presubmit := range selected {
+		names = append(names, presubmit.Name)
+	}
+	if diff := cmp.Diff([]string{"gcp-b", "aws-b", "aws-c"}, names); diff != "" {
+		t.Errorf("unexpected selection: %s", diff)
+	}
+	expected := []SelectionDecision{
+		{Job: "gcp-b", Selected: true, Reason: "covers source changedRegistryContent, cluster profile gcp, workflow ipi-gcp (30m0s)"},
+		{Job: "aws-b", Selected: true, Reason: "covers cluster profile aws, workflow ipi-aws, registry node ipi-install (1h0m0s)"},
+		{Job: "aws-c", Selected: true, Reason: "covers workflow upi-aws (no historical duration, estimated at 1h0m0s)"},
+		{Job: "aws-a", Reason: "limit of 3 rehearsals reached, everything it covers is exercised by the selected jobs"},
+		{Job: "gcp-a", Reason: "limit of 3 rehearsals reached, everything it covers is exercised by the selected jobs"},
+	}
+	if diff := cmp.Diff(expected, decisions); diff != "" {
+		t.Errorf("unexpected decisions: %s", diff)
+	}
+
+	_, decisions = determineSubsetToRehearse(presubmits, 1, traits)
+	if diff := cmp.Diff(SelectionDecision{Job: "aws-c", Reason: "limit of 1 rehearsals reached before covering cluster profile aws, workflow upi-aws"}, decisions[3]); diff != "" {
+		t.Errorf("unexpected decision for a dropped job: %s", diff)
+	}
+}
+
+func TestTraitsFor(t *testing.T) {
+	workflow, chain, ref, unchanged := "ipi-aws", "ipi-install", "ipi-install-install", "gather"
+	graph, err := registry.NewGraph(
+		registry.ReferenceByName{ref: {As: ref}, unchanged: {As: unchanged}},
+		registry.ChainByName{chain: {As: chain, Steps: []api.TestStep{{Reference: &ref}}}},
+		registry.WorkflowByName{workflow: {Pre: []api.TestStep{{Chain: &chain}}}},
+		registry.ObserverByName{},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the graph: %v", err)
+	}
+	tests := map[string]api.TestStepConfiguration{
+		"pull-ci-org-repo-master-e2e": {As: "e2e", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+			Workflow: &workflow,
+			Pre:      []api.TestStep{{Chain: &chain}},
+			Post:     []api.TestStep{{Reference: &unchanged}},
+		}},
+		"pull-ci-org-repo-master-parallel": {As: "parallel", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{Parallel: &api.ParallelTestSteps{As: "group", Steps: []api.ParallelTestStep{{Chain: &chain}, {Reference: &unchanged}}}}},
+		}},
+		"pull-ci-org-repo-master-unit": {As: "unit", ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"}},
+	}
+	rehearsals := []*prowconfig.Presubmit{
+		{JobBase: prowconfig.JobBase{Name: "rehearse-1234-pull-ci-org-repo-master-e2e"}},
+		{JobBase: prowconfig.JobBase{Name: "rehearse-1234-pull-ci-org-repo-master-parallel"}},
+		{JobBase: prowconfig.JobBase{Name: "rehearse-1234-pull-ci-org-repo-master-unit"}},
+	}
+	durations := map[string]time.Duration{"pull-ci-org-repo-master-e2e": 2 * time.Hour}
+
+	expected := map[string]rehearsalTraits{
+		"rehearse-1234-pull-ci-org-repo-master-e2e":      {workflow: workflow, registryNodes: []string{workflow, chain}, duration: 2 * time.Hour},
+		"rehearse-1234-pull-ci-org-repo-master-parallel": {registryNodes: []string{chain}},
+		"rehearse-1234-pull-ci-org-repo-master-unit":     {},
+	}
+	actual := traitsFor(rehearsals, 1234, tests, []registry.Node{graph.References[ref]}, durations)
+	if diff := cmp.Diff(expected, actual, cmp.AllowUnexported(rehearsalTraits{})); diff != "" {
+		t.Errorf("unexpected traits: %s", diff)
+	}
+}
@@ -96,6 +96,21 @@ type Request struct {
 	Reason string `json:"reason"`
 }
 
+// LeaseRequest holds the data used to report which type of resource was leased
+// for a lease with fallback resource types to an aggregation server
+type LeaseRequest struct {
+	// JobName is the name of the job which acquired the lease
+	JobName string `json:"job_name"`
+	// Type is the type of job ("presubmit", "postsubmit", "periodic" or "batch")
+	Type string `json:"type"`
+	// Cluster is the cluster's console hostname
+	Cluster string `json:"cluster"`
+	// ResourceType is the preferred type of resource for the lease
+	ResourceType string `json:"resource_type"`
+	// LeasedType is the type of resource that was leased
+	LeasedType string `json:"leased_type"`
+}
+
 // PodScalerRequest holds the data from pod-scaler used to report a result to an aggregation server
 type PodScalerRequest struct {
 	WorkloadName     string
@@ -115,12 +130,22 @@ type Reporter interface {
 	// This action is best-effort and errors are logged but not exposed.
 	// Err may be nil in which case a success is reported.
 	Report(err error)
+	LeaseReporter
+}
+
+type LeaseReporter interface {
+	// ReportLeaseAcquisition sends a report that a resource of leasedType was
+	// leased for a lease preferring resourceType to an aggregation server.
+	// This action is best-effort and errors are logged but not exposed.
+	ReportLeaseAcquisition(resourceType, leasedType string)
 }
 
 type noopReporter struct{}
 
 func (r *noopReporter) Report(err error) {}
 
+func (r *noopReporter) ReportLeaseAcquisition(resourceType, leasedType string) {}
+
 type reporter struct {
 	client             *http.Client
 	username, password string
@@ -171,6 +196,27 @@ func (r *reporter) report(request Request) {
 	sendRequest(req, r.client, r.username, r.password)
 }
 
+func (r *reporter) ReportLeaseAcquisition(resourceType, leasedType string) {
+	data, err := json.Marshal(LeaseRequest{
+		JobName:      r.spec.Job,
+		Type:         string(r.spec.Type),
+		Cluster:      r.consoleHost,
+		ResourceType: resourceType,
+		LeasedType:   leasedType,
+	})
+	if err != nil {
+		logrus.Tracef("could not marshal lease request: %v", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/lease", r.address), bytes.NewReader(data))
+	if err != nil {
+		logrus.Tracef("could not create lease request: %v", err)
+		return
+	}
+	sendRequest(req, r.client, r.username, r.password)
+}
+
 type PodScalerReporter interface {
 	ReportResourceConfigurationWarning(workloadName, workloadType, configuredAmount, determinedAmount, resourceType string)
 }
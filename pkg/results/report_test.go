@@ -104,6 +104,43 @@ func TestReporter_Report(t *testing.T) {
 	}
 }
 
+func TestReporter_ReportLeaseAcquisition(t *testing.T) {
+	expected := `{"job_name":"runme","type":"presubmit","cluster":"foo.com","resource_type":"aws-quota-slice","leased_type":"aws-2-quota-slice"}`
+	var called bool
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if r.Method != http.MethodPost {
+			t.Errorf("incorrect method to report a lease: %s", r.Method)
+		}
+		if r.URL.Path != "/lease" {
+			t.Errorf("incorrect path to report a lease: %s", r.URL.Path)
+		}
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read lease body: %v", err)
+		}
+		if diff := cmp.Diff(expected, string(raw)); diff != "" {
+			t.Errorf("got incorrect lease report: %s", diff)
+		}
+	}))
+	defer testServer.Close()
+
+	reporter := reporter{
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+		address:     testServer.URL,
+		spec:        &api.JobSpec{JobSpec: downwardapi.JobSpec{Job: "runme", Type: v1.PresubmitJob}},
+		consoleHost: "foo.com",
+	}
+	reporter.ReportLeaseAcquisition("aws-quota-slice", "aws-2-quota-slice")
+	if !called {
+		t.Error("the lease was not reported")
+	}
+}
+
 func TestOptions_Reporter(t *testing.T) {
 	// this simulates the flow for ci-operator while we migrate to using the tool
 	options := Options{} // no flags set
@@ -115,6 +152,7 @@ func TestOptions_Reporter(t *testing.T) {
 	// neither of these should not fail
 	reporter.Report(nil)
 	reporter.Report(ForReason("foo").ForError(errors.New("oops")))
+	reporter.ReportLeaseAcquisition("aws-quota-slice", "aws-2-quota-slice")
 }
 
 func TestGetUsernameAndPassword(t *testing.T) {
@@ -12,10 +12,11 @@ const (
 	retestBackoffHold = iota
 	retestBackoffPause
 	retestBackoffRetest
+	retestBackoffStop
 )
 
 type backoffCache interface {
-	check(pr tide.PullRequest, baseSha string, policy RetesterPolicy) (retestBackoffAction, string)
+	check(pr tide.PullRequest, baseSha string, failures map[string]string, policy RetesterPolicy) (retestBackoffAction, string)
 	load(ctx context.Context) error
 	save(ctx context.Context) error
 }
@@ -0,0 +1,204 @@
+package retester
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/github"
+	prowio "sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/providers"
+	"sigs.k8s.io/prow/pkg/tide"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// operatorJUnitArtifact is the jUnit result ci-operator records for the steps it executed
+const operatorJUnitArtifact = "artifacts/junit_operator.xml"
+
+// failureInspector determines which tests failed in the job a status context links to
+type failureInspector interface {
+	failedTests(ctx context.Context, targetURL string) ([]string, error)
+}
+
+// artifactInspector reads the failed tests from the jUnit artifacts of jobs
+type artifactInspector struct {
+	opener prowio.Opener
+	logger *logrus.Entry
+}
+
+// failedTests lists the test cases which failed in the job with their failure messages. Unrelated
+// failures in the same step look the same in the results ci-operator records for the steps, so the
+// results recorded by the steps and the tests themselves are used, falling back to the results of
+// ci-operator when those do not record any failure.
+func (i *artifactInspector) failedTests(ctx context.Context, targetURL string) ([]string, error) {
+	jobPath, err := jobPathFromURL(targetURL)
+	if err != nil {
+		return nil, err
+	}
+	operatorPath := fmt.Sprintf("%s/%s", jobPath, operatorJUnitArtifact)
+	paths, err := i.jUnitPaths(ctx, jobPath)
+	if err != nil {
+		return nil, err
+	}
+	failed := sets.New[string]()
+	for _, path := range paths {
+		if path == operatorPath {
+			continue
+		}
+		failures, err := i.failuresIn(ctx, path)
+		if err != nil {
+			i.logger.WithError(err).Debug("Could not read the failures in a jUnit artifact.")
+			continue
+		}
+		failed.Insert(failures...)
+	}
+	if failed.Len() == 0 {
+		failures, err := i.failuresIn(ctx, operatorPath)
+		if err != nil {
+			return nil, err
+		}
+		failed.Insert(failures...)
+	}
+	return sets.List(failed), nil
+}
+
+// jUnitPaths lists the paths of all the jUnit results in the artifacts of the job
+func (i *artifactInspector) jUnitPaths(ctx context.Context, jobPath string) ([]string, error) {
+	provider, bucket, _, err := providers.ParseStoragePath(jobPath)
+	if err != nil {
+		return nil, err
+	}
+	iterator, err := i.opener.Iterator(ctx, jobPath+"/artifacts/", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the artifacts of %s: %w", jobPath, err)
+	}
+	var paths []string
+	for {
+		attributes, err := iterator.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the artifacts of %s: %w", jobPath, err)
+		}
+		if attributes.IsDir || !strings.HasPrefix(attributes.ObjName, "junit") || !strings.HasSuffix(attributes.ObjName, ".xml") {
+			continue
+		}
+		paths = append(paths, fmt.Sprintf("%s://%s/%s", provider, bucket, attributes.Name))
+	}
+	return paths, nil
+}
+
+// failuresIn lists the test cases which failed in a jUnit result, with the
+// first line of the failure message, which identifies the failure best
+func (i *artifactInspector) failuresIn(ctx context.Context, path string) ([]string, error) {
+	content, err := prowio.ReadContent(ctx, i.logger, i.opener, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var suites junit.TestSuites
+	if err := xml.Unmarshal(content, &suites); err != nil {
+		// steps may record a single suite instead of a list of them
+		var suite junit.TestSuite
+		if suiteErr := xml.Unmarshal(content, &suite); suiteErr != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+		}
+		suites.Suites = []*junit.TestSuite{&suite}
+	}
+	var failed []string
+	var collect func(suite *junit.TestSuite)
+	collect = func(suite *junit.TestSuite) {
+		for _, testCase := range suite.TestCases {
+			if testCase.FailureOutput == nil {
+				continue
+			}
+			failure := testCase.Name
+			if message, _, _ := strings.Cut(strings.TrimSpace(testCase.FailureOutput.Message), "\n"); message != "" {
+				failure = fmt.Sprintf("%s: %s", testCase.Name, message)
+			}
+			failed = append(failed, failure)
+		}
+		for _, child := range suite.Children {
+			collect(child)
+		}
+	}
+	for _, suite := range suites.Suites {
+		collect(suite)
+	}
+	return failed, nil
+}
+
+// jobPathFromURL determines the storage path of the artifacts of a job from the link
+// to its view in Deck, e.g. https://prow.example.com/view/gs/bucket/pr-logs/pull/org_repo/1/job/2
+func jobPathFromURL(targetURL string) (string, error) {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %q: %w", targetURL, err)
+	}
+	_, view, found := strings.Cut(parsed.Path, "/view/")
+	if !found {
+		return "", fmt.Errorf("%q does not link to a job view", targetURL)
+	}
+	provider, path, found := strings.Cut(view, "/")
+	if !found || path == "" {
+		return "", fmt.Errorf("%q does not link to a job in storage", targetURL)
+	}
+	return fmt.Sprintf("%s://%s", provider, path), nil
+}
+
+// failuresFor determines a signature for each failing required context on the HEAD of
+// the PR, which is the list of tests that failed in the job with their failure messages when those can be inspected
+// and the description of the context otherwise.
+func (c *RetestController) failuresFor(pr tide.PullRequest) (map[string]string, error) {
+	presubmits := c.presubmitsForPRByContext(pr)
+	combined, err := c.ghClient.GetCombinedStatus(string(pr.Repository.Owner.Login), string(pr.Repository.Name), string(pr.HeadRefOID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the combined status: %w", err)
+	}
+	failures := map[string]string{}
+	for _, status := range combined.Statuses {
+		if status.State != github.StatusFailure {
+			continue
+		}
+		if _, required := presubmits[status.Context]; !required {
+			continue
+		}
+		failures[status.Context] = c.failureSignature(status)
+	}
+	return failures, nil
+}
+
+func (c *RetestController) failureSignature(status github.Status) string {
+	if c.inspector != nil {
+		tests, err := c.inspector.failedTests(context.Background(), status.TargetURL)
+		if err != nil {
+			c.logger.WithError(err).WithField("context", status.Context).Debug("Could not determine the failed tests.")
+		} else if len(tests) > 0 {
+			return strings.Join(tests, ", ")
+		}
+	}
+	return status.Description
+}
+
+// describeFailures lists the failing contexts and their signatures for a comment
+func describeFailures(failures map[string]string) string {
+	var contexts []string
+	for name := range failures {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	var lines []string
+	for _, name := range contexts {
+		lines = append(lines, fmt.Sprintf("* `%s`: %s", name, failures[name]))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"time"
@@ -97,12 +98,13 @@ func (b *fileBackoffCache) save(_ context.Context) (ret error) {
 	return ret
 }
 
-func (b *fileBackoffCache) check(pr tide.PullRequest, baseSha string, policy RetesterPolicy) (retestBackoffAction, string) {
-	return check(&b.cache, pr, baseSha, policy)
+func (b *fileBackoffCache) check(pr tide.PullRequest, baseSha string, failures map[string]string, policy RetesterPolicy) (retestBackoffAction, string) {
+	return check(&b.cache, pr, baseSha, failures, policy)
 }
 
-// check updates the cache and returns a retestBackoffAction according to baseSha, policy, and number of retests performed for the PR.
-func check(cache *map[string]*pullRequest, pr tide.PullRequest, baseSha string, policy RetesterPolicy) (retestBackoffAction, string) {
+// check updates the cache and returns a retestBackoffAction according to baseSha, policy, number of retests performed for the PR,
+// and how many times in a row the required contexts failed identically.
+func check(cache *map[string]*pullRequest, pr tide.PullRequest, baseSha string, failures map[string]string, policy RetesterPolicy) (retestBackoffAction, string) {
 	key := prKey(&pr)
 	if _, has := (*cache)[key]; !has {
 		(*cache)[key] = &pullRequest{}
@@ -113,6 +115,8 @@ func check(cache *map[string]*pullRequest, pr tide.PullRequest, baseSha string,
 		record.PRSha = currentPRSha
 		record.RetestsForPrSha = 0
 		record.RetestsForBaseSha = 0
+		record.Failures = nil
+		record.IdenticalFailures = 0
 	}
 	if record.BaseSha != baseSha {
 		record.BaseSha = baseSha
@@ -129,6 +133,21 @@ func check(cache *map[string]*pullRequest, pr tide.PullRequest, baseSha string,
 		return retestBackoffPause, fmt.Sprintf("Revision %s was retested %d times against base HEAD %s: pausing", record.PRSha, policy.MaxRetestsForShaAndBase, record.BaseSha)
 	}
 
+	if policy.MaxIdenticalFailuresForSha > 0 && len(failures) > 0 {
+		if maps.Equal(record.Failures, failures) {
+			record.IdenticalFailures++
+		} else {
+			record.Failures = failures
+			record.IdenticalFailures = 1
+		}
+		switch {
+		case record.IdenticalFailures == policy.MaxIdenticalFailuresForSha:
+			return retestBackoffStop, fmt.Sprintf("Revision %s failed the same way %d times, which is unlikely to be a flake: not retesting\n\n%s", record.PRSha, record.IdenticalFailures, describeFailures(failures))
+		case record.IdenticalFailures > policy.MaxIdenticalFailuresForSha:
+			return retestBackoffPause, fmt.Sprintf("Revision %s failed the same way %d times: not retesting", record.PRSha, record.IdenticalFailures)
+		}
+	}
+
 	record.RetestsForBaseSha++
 	record.RetestsForPrSha++
 
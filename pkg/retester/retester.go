@@ -18,6 +18,7 @@ import (
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/github"
+	prowio "sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/tide"
 	"sigs.k8s.io/yaml"
 )
@@ -36,6 +37,10 @@ type pullRequest struct {
 	RetestsForPrSha    int         `json:"retests_for_pr_sha,omitempty"`
 	RetestsForBaseSha  int         `json:"retests_for_base_sha,omitempty"`
 	LastConsideredTime metav1.Time `json:"last_considered_time,omitempty"`
+	// Failures are the signatures of the required contexts that failed when the PR was last retested.
+	Failures map[string]string `json:"failures,omitempty"`
+	// IdenticalFailures is the number of consecutive times the required contexts failed with the same signatures.
+	IdenticalFailures int `json:"identical_failures,omitempty"`
 }
 
 var (
@@ -83,10 +88,14 @@ type Repo struct {
 // When merging policies, a 0 value results in inheriting the parent policy.
 // False in level repo means disabled repo. Nothing can change that.
 // True/False in level org means enabled/disabled org. But repo can be disabled/enabled.
+// MaxIdenticalFailuresForSha is the number of times the same required contexts may fail
+// with the same tests on a revision before retesting stops, as such failures are unlikely
+// to be flakes. A 0 value disables the detection of identical failures.
 type RetesterPolicy struct {
-	MaxRetestsForShaAndBase int   `json:"max_retests_for_sha_and_base,omitempty"`
-	MaxRetestsForSha        int   `json:"max_retests_for_sha,omitempty"`
-	Enabled                 *bool `json:"enabled,omitempty"`
+	MaxRetestsForShaAndBase    int   `json:"max_retests_for_sha_and_base,omitempty"`
+	MaxRetestsForSha           int   `json:"max_retests_for_sha,omitempty"`
+	Enabled                    *bool `json:"enabled,omitempty"`
+	MaxIdenticalFailuresForSha int   `json:"max_identical_failures_for_sha,omitempty"`
 }
 
 // LoadConfig loads retester configuration via file.
@@ -114,6 +123,7 @@ type RetestController struct {
 
 	usesGitHubApp bool
 	backoff       backoffCache
+	inspector     failureInspector
 
 	config *Config
 }
@@ -136,6 +146,9 @@ func (c *Config) GetRetesterPolicy(org, repo string) (RetesterPolicy, error) {
 				if repoStruct.MaxRetestsForShaAndBase != 0 {
 					policy.MaxRetestsForShaAndBase = repoStruct.MaxRetestsForShaAndBase
 				}
+				if repoStruct.MaxIdenticalFailuresForSha != 0 {
+					policy.MaxIdenticalFailuresForSha = repoStruct.MaxIdenticalFailuresForSha
+				}
 			} else {
 				return RetesterPolicy{}, nil
 			}
@@ -148,6 +161,9 @@ func (c *Config) GetRetesterPolicy(org, repo string) (RetesterPolicy, error) {
 			if orgStruct.MaxRetestsForShaAndBase != 0 && policy.MaxRetestsForShaAndBase == 0 {
 				policy.MaxRetestsForShaAndBase = orgStruct.MaxRetestsForShaAndBase
 			}
+			if orgStruct.MaxIdenticalFailuresForSha != 0 && policy.MaxIdenticalFailuresForSha == 0 {
+				policy.MaxIdenticalFailuresForSha = orgStruct.MaxIdenticalFailuresForSha
+			}
 		}
 		if !*policy.Enabled && (c.Retester.Enabled == nil || !*c.Retester.Enabled) {
 			return RetesterPolicy{}, nil
@@ -162,6 +178,9 @@ func (c *Config) GetRetesterPolicy(org, repo string) (RetesterPolicy, error) {
 	if policy.MaxRetestsForShaAndBase == 0 {
 		policy.MaxRetestsForShaAndBase = c.Retester.MaxRetestsForShaAndBase
 	}
+	if policy.MaxIdenticalFailuresForSha == 0 {
+		policy.MaxIdenticalFailuresForSha = c.Retester.MaxIdenticalFailuresForSha
+	}
 	return policy, nil
 }
 
@@ -175,6 +194,9 @@ func validatePolicies(policy RetesterPolicy) []error {
 			if policy.MaxRetestsForShaAndBase < 0 {
 				errs = append(errs, fmt.Errorf("max_retests_for_sha_and_base has invalid value: %d", policy.MaxRetestsForShaAndBase))
 			}
+			if policy.MaxIdenticalFailuresForSha < 0 {
+				errs = append(errs, fmt.Errorf("max_identical_failures_for_sha has invalid value: %d", policy.MaxIdenticalFailuresForSha))
+			}
 			if policy.MaxRetestsForSha < policy.MaxRetestsForShaAndBase {
 				errs = append(errs, fmt.Errorf("max_retest_for_sha value can't be lower than max_retests_for_sha_and_base value: %d < %d", policy.MaxRetestsForSha, policy.MaxRetestsForShaAndBase))
 			}
@@ -185,8 +207,9 @@ func validatePolicies(policy RetesterPolicy) []error {
 	return errs
 }
 
-// NewController generates a retest controller.
-func NewController(ctx context.Context, ghClient githubClient, cfg config.Getter, gitClient git.ClientFactory, usesApp bool, cacheFile string, cacheRecordAge time.Duration, config *Config, awsConfig *aws.Config) *RetestController {
+// NewController generates a retest controller. When an opener is given, the failed tests
+// are read from the artifacts of failed jobs to determine whether they failed identically.
+func NewController(ctx context.Context, ghClient githubClient, cfg config.Getter, gitClient git.ClientFactory, usesApp bool, cacheFile string, cacheRecordAge time.Duration, config *Config, awsConfig *aws.Config, opener prowio.Opener) *RetestController {
 	logger := logrus.NewEntry(logrus.StandardLogger())
 	var backoff backoffCache
 	if awsConfig != nil {
@@ -204,6 +227,9 @@ func NewController(ctx context.Context, ghClient githubClient, cfg config.Getter
 		backoff:       backoff,
 		config:        config,
 	}
+	if opener != nil {
+		ret.inspector = &artifactInspector{opener: opener, logger: logger}
+	}
 	if err := ret.backoff.load(ctx); err != nil {
 		logger.WithError(err).Warn("Failed to load backoff cache from disk")
 	}
@@ -258,6 +284,9 @@ func (c *RetestController) runWithCandidates(ctx context.Context, candidates map
 
 func (c *RetestController) createComment(pr tide.PullRequest, cmd, message string) {
 	comment := fmt.Sprintf("%s\n\n%s\n", cmd, message)
+	if cmd == "" {
+		comment = fmt.Sprintf("%s\n", message)
+	}
 	if err := c.ghClient.CreateComment(string(pr.Repository.Owner.Login), string(pr.Repository.Name), int(pr.Number), comment); err != nil {
 		c.logger.WithField("comment", comment).WithError(err).Error("failed to create a comment")
 	} else if cmd == "/retest-required" {
@@ -282,7 +311,14 @@ func (c *RetestController) retestOrBackoff(pr tide.PullRequest) error {
 		return fmt.Errorf("failed to validate retester policy: %v", validationErrors)
 	}
 
-	action, message := c.backoff.check(pr, baseSha, policy)
+	var failures map[string]string
+	if policy.MaxIdenticalFailuresForSha > 0 {
+		if failures, err = c.failuresFor(pr); err != nil {
+			return fmt.Errorf("failed to determine the failures: %w", err)
+		}
+	}
+
+	action, message := c.backoff.check(pr, baseSha, failures, policy)
 	switch action {
 	case retestBackoffHold:
 		c.createComment(pr, "/hold", message)
@@ -290,6 +326,8 @@ func (c *RetestController) retestOrBackoff(pr tide.PullRequest) error {
 		c.logger.Infof("%s: %s (%s)", prUrl(pr), "no comment", message)
 	case retestBackoffRetest:
 		c.createComment(pr, "/retest-required", message)
+	case retestBackoffStop:
+		c.createComment(pr, "", message)
 	}
 	return nil
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/shurcooL/githubv4"
 	"github.com/sirupsen/logrus"
 
@@ -21,6 +22,7 @@ import (
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	prowio "sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/tide"
 
 	"github.com/openshift/ci-tools/pkg/testhelper"
@@ -59,7 +61,7 @@ func TestLoadConfig(t *testing.T) {
 				Repos: map[string]Repo{
 					"ci-docs": {RetesterPolicy: RetesterPolicy{Enabled: &True}},
 					"ci-tools": {RetesterPolicy: RetesterPolicy{
-						MaxRetestsForSha: 3, MaxRetestsForShaAndBase: 3, Enabled: &True,
+						MaxRetestsForSha: 3, MaxRetestsForShaAndBase: 3, Enabled: &True, MaxIdenticalFailuresForSha: 2,
 					}},
 				}},
 			},
@@ -155,7 +157,7 @@ func TestGetRetesterPolicy(t *testing.T) {
 							MaxRetestsForSha: 3, MaxRetestsForShaAndBase: 3, Enabled: &True,
 						}},
 						"repo-max": {RetesterPolicy: RetesterPolicy{
-							MaxRetestsForSha: 6, Enabled: &True,
+							MaxRetestsForSha: 6, Enabled: &True, MaxIdenticalFailuresForSha: 2,
 						}},
 						"repo": {RetesterPolicy: RetesterPolicy{Enabled: &False}},
 					}},
@@ -183,21 +185,21 @@ func TestGetRetesterPolicy(t *testing.T) {
 			org:      "openshift",
 			repo:     "ci-tools",
 			config:   c,
-			expected: RetesterPolicy{3, 3, &True},
+			expected: RetesterPolicy{3, 3, &True, 0},
 		},
 		{
 			name:     "enabled repo with one max retest value and enabled org",
 			org:      "openshift",
 			repo:     "repo-max",
 			config:   c,
-			expected: RetesterPolicy{2, 6, &True},
+			expected: RetesterPolicy{2, 6, &True, 2},
 		},
 		{
 			name:     "enabled repo and disabled org",
 			org:      "no-openshift",
 			repo:     "ci-tools",
 			config:   c,
-			expected: RetesterPolicy{4, 4, &True},
+			expected: RetesterPolicy{4, 4, &True, 0},
 		},
 		{
 			name:   "disabled repo and enabled org",
@@ -210,7 +212,7 @@ func TestGetRetesterPolicy(t *testing.T) {
 			org:      "openshift",
 			repo:     "ci-docs",
 			config:   c,
-			expected: RetesterPolicy{2, 2, &True},
+			expected: RetesterPolicy{2, 2, &True, 0},
 		},
 		{
 			name:   "not configured repo and disabled org",
@@ -223,7 +225,7 @@ func TestGetRetesterPolicy(t *testing.T) {
 			org:      "no-openshift",
 			repo:     "true",
 			config:   c,
-			expected: RetesterPolicy{3, 9, &True},
+			expected: RetesterPolicy{3, 9, &True, 0},
 		},
 		{
 			name:   "not configured repo and not configured org",
@@ -262,25 +264,26 @@ func TestValidatePolicies(t *testing.T) {
 	}{
 		{
 			name:   "basic case",
-			policy: RetesterPolicy{3, 9, &True},
+			policy: RetesterPolicy{3, 9, &True, 0},
 		},
 		{
 			name: "empty policy is valid",
 		},
 		{
 			name:   "disable",
-			policy: RetesterPolicy{-1, -1, &False},
+			policy: RetesterPolicy{-1, -1, &False, 0},
 		},
 		{
 			name:   "negative",
-			policy: RetesterPolicy{-1, -1, &True},
+			policy: RetesterPolicy{-1, -1, &True, -1},
 			expected: []error{
 				errors.New("max_retest_for_sha has invalid value: -1"),
-				errors.New("max_retests_for_sha_and_base has invalid value: -1")},
+				errors.New("max_retests_for_sha_and_base has invalid value: -1"),
+				errors.New("max_identical_failures_for_sha has invalid value: -1")},
 		},
 		{
 			name:     "lower",
-			policy:   RetesterPolicy{9, 3, &True},
+			policy:   RetesterPolicy{9, 3, &True, 0},
 			expected: []error{errors.New("max_retest_for_sha value can't be lower than max_retests_for_sha_and_base value: 3 < 9")},
 		},
 	}
@@ -802,9 +805,11 @@ func TestCheck(t *testing.T) {
 		cache          fileBackoffCache
 		pr             tide.PullRequest
 		baseSha        string
+		failures       map[string]string
 		policy         RetesterPolicy
 		expected       retestBackoffAction
 		expectedString string
+		expectedRecord *pullRequest
 	}{
 		{
 			name:  "hold PR",
@@ -816,7 +821,7 @@ func TestCheck(t *testing.T) {
 					Owner         struct{ Login githubv4.String }
 				}{Name: "repo", NameWithOwner: "org/repo", Owner: struct{ Login githubv4.String }{Login: "org"}},
 				HeadRefOID: "holdPR"},
-			policy:         RetesterPolicy{3, 9, &True},
+			policy:         RetesterPolicy{3, 9, &True, 0},
 			expected:       0,
 			expectedString: "Revision holdPR was retested 9 times: holding",
 		},
@@ -830,7 +835,7 @@ func TestCheck(t *testing.T) {
 					Owner         struct{ Login githubv4.String }
 				}{Name: "repo", NameWithOwner: "org/repo", Owner: struct{ Login githubv4.String }{Login: "org"}},
 				HeadRefOID: "pausePR"},
-			policy:         RetesterPolicy{3, 9, &True},
+			policy:         RetesterPolicy{3, 9, &True, 0},
 			expected:       1,
 			expectedString: "Revision pausePR was retested 3 times against base HEAD : pausing",
 		},
@@ -838,20 +843,75 @@ func TestCheck(t *testing.T) {
 			name:           "retest PR",
 			cache:          fileBackoffCache{cache: map[string]*pullRequest{}, logger: logger},
 			pr:             tide.PullRequest{HeadRefOID: "retestPR"},
-			policy:         RetesterPolicy{3, 9, &True},
+			policy:         RetesterPolicy{3, 9, &True, 0},
 			expected:       2,
 			expectedString: "Remaining retests: 2 against base HEAD  and 8 for PR HEAD retestPR in total",
 		},
+		{
+			name:           "retest PR failing for the first time",
+			cache:          fileBackoffCache{cache: map[string]*pullRequest{}, logger: logger},
+			pr:             tide.PullRequest{HeadRefOID: "retestPR"},
+			failures:       map[string]string{"ci/prow/unit": "TestFoo"},
+			policy:         RetesterPolicy{3, 9, &True, 2},
+			expected:       2,
+			expectedString: "Remaining retests: 2 against base HEAD  and 8 for PR HEAD retestPR in total",
+			expectedRecord: &pullRequest{PRSha: "retestPR", RetestsForPrSha: 1, RetestsForBaseSha: 1, Failures: map[string]string{"ci/prow/unit": "TestFoo"}, IdenticalFailures: 1},
+		},
+		{
+			name:           "retest PR failing differently",
+			cache:          fileBackoffCache{cache: map[string]*pullRequest{"#0": {PRSha: "retestPR", RetestsForPrSha: 1, RetestsForBaseSha: 1, Failures: map[string]string{"ci/prow/unit": "TestFoo"}, IdenticalFailures: 1}}, logger: logger},
+			pr:             tide.PullRequest{HeadRefOID: "retestPR"},
+			failures:       map[string]string{"ci/prow/unit": "TestBar"},
+			policy:         RetesterPolicy{3, 9, &True, 2},
+			expected:       2,
+			expectedString: "Remaining retests: 1 against base HEAD  and 7 for PR HEAD retestPR in total",
+			expectedRecord: &pullRequest{PRSha: "retestPR", RetestsForPrSha: 2, RetestsForBaseSha: 2, Failures: map[string]string{"ci/prow/unit": "TestBar"}, IdenticalFailures: 1},
+		},
+		{
+			name:           "stop retesting PR failing identically",
+			cache:          fileBackoffCache{cache: map[string]*pullRequest{"#0": {PRSha: "stopPR", RetestsForPrSha: 1, RetestsForBaseSha: 1, Failures: map[string]string{"ci/prow/unit": "TestFoo", "ci/prow/e2e": "Job failed."}, IdenticalFailures: 1}}, logger: logger},
+			pr:             tide.PullRequest{HeadRefOID: "stopPR"},
+			failures:       map[string]string{"ci/prow/unit": "TestFoo", "ci/prow/e2e": "Job failed."},
+			policy:         RetesterPolicy{3, 9, &True, 2},
+			expected:       3,
+			expectedString: "Revision stopPR failed the same way 2 times, which is unlikely to be a flake: not retesting\n\n* `ci/prow/e2e`: Job failed.\n* `ci/prow/unit`: TestFoo",
+			expectedRecord: &pullRequest{PRSha: "stopPR", RetestsForPrSha: 1, RetestsForBaseSha: 1, Failures: map[string]string{"ci/prow/unit": "TestFoo", "ci/prow/e2e": "Job failed."}, IdenticalFailures: 2},
+		},
+		{
+			name:           "pause PR which was already reported to fail identically",
+			cache:          fileBackoffCache{cache: map[string]*pullRequest{"#0": {PRSha: "stopPR", RetestsForPrSha: 1, RetestsForBaseSha: 1, Failures: map[string]string{"ci/prow/unit": "TestFoo"}, IdenticalFailures: 2}}, logger: logger},
+			pr:             tide.PullRequest{HeadRefOID: "stopPR"},
+			failures:       map[string]string{"ci/prow/unit": "TestFoo"},
+			policy:         RetesterPolicy{3, 9, &True, 2},
+			expected:       1,
+			expectedString: "Revision stopPR failed the same way 3 times: not retesting",
+			expectedRecord: &pullRequest{PRSha: "stopPR", RetestsForPrSha: 1, RetestsForBaseSha: 1, Failures: map[string]string{"ci/prow/unit": "TestFoo"}, IdenticalFailures: 3},
+		},
+		{
+			name:           "new revision forgets failures",
+			cache:          fileBackoffCache{cache: map[string]*pullRequest{"#0": {PRSha: "stopPR", RetestsForPrSha: 1, RetestsForBaseSha: 1, Failures: map[string]string{"ci/prow/unit": "TestFoo"}, IdenticalFailures: 2}}, logger: logger},
+			pr:             tide.PullRequest{HeadRefOID: "newPR"},
+			failures:       map[string]string{"ci/prow/unit": "TestFoo"},
+			policy:         RetesterPolicy{3, 9, &True, 2},
+			expected:       2,
+			expectedString: "Remaining retests: 2 against base HEAD  and 8 for PR HEAD newPR in total",
+			expectedRecord: &pullRequest{PRSha: "newPR", RetestsForPrSha: 1, RetestsForBaseSha: 1, Failures: map[string]string{"ci/prow/unit": "TestFoo"}, IdenticalFailures: 1},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, actualString := tc.cache.check(tc.pr, tc.baseSha, tc.policy)
+			actual, actualString := tc.cache.check(tc.pr, tc.baseSha, tc.failures, tc.policy)
 			if diff := cmp.Diff(tc.expected, actual); diff != "" {
 				t.Errorf("%s differs from expected:\n%s", tc.name, diff)
 			}
 			if diff := cmp.Diff(tc.expectedString, actualString); diff != "" {
 				t.Errorf("%s differs from expected:\n%s", tc.name, diff)
 			}
+			if tc.expectedRecord != nil {
+				if diff := cmp.Diff(tc.expectedRecord, tc.cache.cache[prKey(&tc.pr)], cmpopts.IgnoreFields(pullRequest{}, "LastConsideredTime")); diff != "" {
+					t.Errorf("%s record differs from expected:\n%s", tc.name, diff)
+				}
+			}
 		})
 	}
 }
@@ -987,3 +1047,207 @@ func TestFindCandidates(t *testing.T) {
 		})
 	}
 }
+
+type fakeInspector map[string][]string
+
+func (f fakeInspector) failedTests(_ context.Context, targetURL string) ([]string, error) {
+	tests, ok := f[targetURL]
+	if !ok {
+		return nil, fmt.Errorf("no artifacts for %s", targetURL)
+	}
+	return tests, nil
+}
+
+func TestRetestOrBackoffIdenticalFailures(t *testing.T) {
+	config := &Config{Retester: Retester{
+		RetesterPolicy: RetesterPolicy{MaxRetestsForShaAndBase: 3, MaxRetestsForSha: 9, MaxIdenticalFailuresForSha: 2}, Oranizations: map[string]Oranization{
+			"openshift": {RetesterPolicy: RetesterPolicy{Enabled: &True}},
+		},
+	}}
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	configOpts := configflagutil.ConfigOptions{ConfigPath: filepath.Join("testdata", "prowconfig", "simple.yaml"), JobConfigPath: filepath.Join("testdata", "jobconfig", "simple.yaml")}
+	configAgent, err := configOpts.ConfigAgent()
+	if err != nil {
+		t.Fatalf("Error starting config agent: %v", err)
+	}
+	ghc := &MyFakeClient{fakegithub.NewFakeClient()}
+	ghc.CombinedStatuses = map[string]*github.CombinedStatus{
+		"a": {Statuses: []github.Status{
+			{State: "failure", Context: "test-presubmit", Description: "Job failed.", TargetURL: "https://prow/view/gs/bucket/1"},
+			{State: "failure", Context: "optional-presubmit", Description: "Job failed.", TargetURL: "https://prow/view/gs/bucket/2"},
+		}},
+	}
+	c := &RetestController{
+		ghClient:     ghc,
+		configGetter: configAgent.Config,
+		logger:       logger,
+		backoff:      &fileBackoffCache{cache: map[string]*pullRequest{}, logger: logger},
+		inspector:    fakeInspector{"https://prow/view/gs/bucket/1": {"e2e - test container test"}},
+		config:       config,
+	}
+	pr := tide.PullRequest{
+		Number:     1,
+		HeadRefOID: "a",
+		Repository: struct {
+			Name          githubv4.String
+			NameWithOwner githubv4.String
+			Owner         struct{ Login githubv4.String }
+		}{Name: "ci-tools", NameWithOwner: "openshift/ci-tools", Owner: struct{ Login githubv4.String }{Login: "openshift"}},
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := c.retestOrBackoff(pr); err != nil {
+			t.Fatalf("failed to retest or back off: %v", err)
+		}
+	}
+	var actual []string
+	for _, comment := range ghc.IssueComments[1] {
+		actual = append(actual, comment.Body)
+	}
+	expected := []string{
+		"/retest-required\n\nRemaining retests: 2 against base HEAD abcde and 8 for PR HEAD a in total\n",
+		"Revision a failed the same way 2 times, which is unlikely to be a flake: not retesting\n\n* `test-presubmit`: e2e - test container test\n",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("comments differ from expected:\n%s", diff)
+	}
+}
+
+func TestJobPathFromURL(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		expected      string
+		expectedError error
+	}{
+		{
+			name:     "job in GCS",
+			url:      "https://prow.ci.openshift.org/view/gs/test-platform-results/pr-logs/pull/openshift_ci-tools/1/pull-ci-openshift-ci-tools-master-unit/2",
+			expected: "gs://test-platform-results/pr-logs/pull/openshift_ci-tools/1/pull-ci-openshift-ci-tools-master-unit/2",
+		},
+		{
+			name:     "job in S3",
+			url:      "https://prow.example.com/view/s3/bucket/logs/job/1",
+			expected: "s3://bucket/logs/job/1",
+		},
+		{
+			name:          "not a job view",
+			url:           "https://example.com/build/1",
+			expectedError: errors.New(`"https://example.com/build/1" does not link to a job view`),
+		},
+		{
+			name:          "no path in storage",
+			url:           "https://prow.example.com/view/gs",
+			expectedError: errors.New(`"https://prow.example.com/view/gs" does not link to a job in storage`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := jobPathFromURL(tc.url)
+			if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("Error differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("%s differs from expected:\n%s", tc.name, diff)
+			}
+		})
+	}
+}
+
+// fakeOpener serves artifacts in the test-platform-results bucket from memory
+type fakeOpener struct {
+	prowio.Opener
+	artifacts map[string]string
+}
+
+func (f fakeOpener) Reader(_ context.Context, path string) (prowio.ReadCloser, error) {
+	content, ok := f.artifacts[strings.TrimPrefix(path, "gs://test-platform-results/")]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (f fakeOpener) Iterator(_ context.Context, prefix, _ string) (prowio.ObjectIterator, error) {
+	var objects []prowio.ObjectAttributes
+	for name := range f.artifacts {
+		if strings.HasPrefix(name, strings.TrimPrefix(prefix, "gs://test-platform-results/")) {
+			objects = append(objects, prowio.ObjectAttributes{Name: name, ObjName: filepath.Base(name)})
+		}
+	}
+	return &fakeObjectIterator{objects: objects}, nil
+}
+
+type fakeObjectIterator struct {
+	objects []prowio.ObjectAttributes
+}
+
+func (f *fakeObjectIterator) Next(_ context.Context) (prowio.ObjectAttributes, error) {
+	if len(f.objects) == 0 {
+		return prowio.ObjectAttributes{}, io.EOF
+	}
+	next := f.objects[0]
+	f.objects = f.objects[1:]
+	return next, nil
+}
+
+func TestArtifactInspectorFailedTests(t *testing.T) {
+	const (
+		url        = "https://prow.ci.openshift.org/view/gs/test-platform-results/pr-logs/pull/1/job/2"
+		operator   = "pr-logs/pull/1/job/2/artifacts/junit_operator.xml"
+		stepJUnit  = "pr-logs/pull/1/job/2/artifacts/e2e/openshift-e2e-test/artifacts/junit/junit_e2e.xml"
+		testJUnit  = "pr-logs/pull/1/job/2/artifacts/e2e/junit_install.xml"
+		otherFile  = "pr-logs/pull/1/job/2/artifacts/e2e/openshift-e2e-test/build-log.txt"
+		stepFailed = `<testsuites><testsuite name="operator"><testcase name="Run multi-stage test e2e - e2e-openshift-e2e-test container test"><failure message="">failed</failure></testcase></testsuite></testsuites>`
+	)
+	testCases := []struct {
+		name          string
+		artifacts     map[string]string
+		expected      []string
+		expectedError error
+	}{
+		{
+			name: "failures recorded by steps identify the failure",
+			artifacts: map[string]string{
+				operator:  stepFailed,
+				stepJUnit: `<testsuites><testsuite name="e2e"><testcase name="[sig-network] pods should be reachable"><failure message="timed out waiting for the condition&#xA;at pod.go:12">output</failure></testcase><testcase name="[sig-storage] volumes should mount"></testcase></testsuite></testsuites>`,
+				testJUnit: `<testsuite name="install"><testcase name="install should succeed: overall"></testcase></testsuite>`,
+				otherFile: "<not junit>",
+			},
+			expected: []string{"[sig-network] pods should be reachable: timed out waiting for the condition"},
+		},
+		{
+			name: "failures recorded by tests as a single suite identify the failure",
+			artifacts: map[string]string{
+				operator:  stepFailed,
+				testJUnit: `<testsuite name="install"><testcase name="install should succeed: overall"><failure message="">bootstrap failed</failure></testcase></testsuite>`,
+			},
+			expected: []string{"install should succeed: overall"},
+		},
+		{
+			name: "failures recorded for the steps are used when the steps record none",
+			artifacts: map[string]string{
+				operator:  stepFailed,
+				stepJUnit: `<testsuites><testsuite name="e2e"><testcase name="[sig-storage] volumes should mount"></testcase></testsuite></testsuites>`,
+			},
+			expected: []string{"Run multi-stage test e2e - e2e-openshift-e2e-test container test"},
+		},
+		{
+			name:          "no results at all",
+			artifacts:     map[string]string{},
+			expectedError: fmt.Errorf("failed to read gs://test-platform-results/%s: %w", operator, os.ErrNotExist),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inspector := &artifactInspector{opener: fakeOpener{artifacts: tc.artifacts}, logger: logrus.NewEntry(logrus.StandardLogger())}
+			actual, err := inspector.failedTests(context.Background(), url)
+			if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("Error differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Failed tests differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
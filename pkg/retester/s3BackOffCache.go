@@ -91,6 +91,6 @@ func (b *s3BackOffCache) save(ctx context.Context) error {
 	return nil
 }
 
-func (b *s3BackOffCache) check(pr tide.PullRequest, baseSha string, policy RetesterPolicy) (retestBackoffAction, string) {
-	return check(&b.cache, pr, baseSha, policy)
+func (b *s3BackOffCache) check(pr tide.PullRequest, baseSha string, failures map[string]string, policy RetesterPolicy) (retestBackoffAction, string) {
+	return check(&b.cache, pr, baseSha, failures, policy)
 }
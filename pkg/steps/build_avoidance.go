@@ -0,0 +1,344 @@
+package steps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildapi "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	dockercmd "github.com/openshift/imagebuilder/dockerfile/command"
+	"github.com/openshift/imagebuilder/dockerfile/parser"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/utils"
+)
+
+const (
+	// contentDigestMetadataKey is the key of the step graph metadata holding the content digest of the image
+	contentDigestMetadataKey = "content-digest"
+	// buildAvoidedMetadataKey is the key of the step graph metadata recording whether the build was avoided
+	buildAvoidedMetadataKey = "build-avoided"
+	// reusedImageMetadataKey is the key of the step graph metadata holding the image tagged instead of building
+	reusedImageMetadataKey = "reused-image"
+)
+
+// buildInputContent is what an input image contributes to the content of a build
+type buildInputContent struct {
+	Digest string                `json:"digest"`
+	As     []string              `json:"as,omitempty"`
+	Paths  []api.ImageSourcePath `json:"paths,omitempty"`
+}
+
+// buildContent is everything that determines the result of building an image from the repository
+type buildContent struct {
+	// Tree is the digest of the files in the context directory
+	Tree              string                       `json:"tree,omitempty"`
+	DockerfilePath    string                       `json:"dockerfile_path,omitempty"`
+	DockerfileLiteral *string                      `json:"dockerfile_literal,omitempty"`
+	From              string                       `json:"from,omitempty"`
+	Inputs            map[string]buildInputContent `json:"inputs,omitempty"`
+	BuildArgs         []api.BuildArg               `json:"build_args,omitempty"`
+	Architectures     []string                     `json:"architectures,omitempty"`
+	// BaseImages are the digests of the images the Dockerfile builds from
+	// which are not declared in the configuration, by pull spec
+	BaseImages map[string]string `json:"base_images,omitempty"`
+}
+
+// digest is stable for equal content, as maps are serialized with sorted keys
+func (c buildContent) digest() (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize build content: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// contentDigest determines the content digest of the image, resolving the digests of the images the build uses
+func (s *projectDirectoryImageBuildStep) contentDigest(ctx context.Context, sourceTag api.PipelineImageStreamTagReference) (string, error) {
+	content := buildContent{
+		DockerfilePath:    s.config.DockerfilePath,
+		DockerfileLiteral: s.config.DockerfileLiteral,
+		BuildArgs:         s.config.BuildArgs,
+		Architectures:     sets.List(s.architectures),
+	}
+	if len(s.config.From) > 0 {
+		digest, err := resolvePipelineImageStreamTagReference(ctx, s.client, s.config.From, s.jobSpec)
+		if err != nil {
+			return "", err
+		}
+		content.From = digest
+	}
+	for name, input := range s.config.Inputs {
+		digest, err := resolvePipelineImageStreamTagReference(ctx, s.client, api.PipelineImageStreamTagReference(name), s.jobSpec)
+		if err != nil {
+			return "", err
+		}
+		if content.Inputs == nil {
+			content.Inputs = map[string]buildInputContent{}
+		}
+		content.Inputs[name] = buildInputContent{Digest: digest, As: input.As, Paths: input.Paths}
+	}
+	tree, fromInstructions, err := s.inspectContext(ctx, sourceTag)
+	if err != nil {
+		return "", err
+	}
+	if _, overwritten := s.config.Inputs[string(sourceTag)]; !overwritten {
+		content.Tree = tree
+	}
+	dockerfile := fromInstructions
+	if s.config.DockerfileLiteral != nil {
+		dockerfile = *s.config.DockerfileLiteral
+	}
+	baseImages, err := undeclaredBaseImages(dockerfile, s.config)
+	if err != nil {
+		return "", err
+	}
+	for _, pullSpec := range baseImages {
+		digest, err := resolveImageDigest(ctx, s.client, s.jobSpec.Namespace(), pullSpec)
+		if err != nil {
+			return "", err
+		}
+		if content.BaseImages == nil {
+			content.BaseImages = map[string]string{}
+		}
+		content.BaseImages[pullSpec] = digest
+	}
+	return content.digest()
+}
+
+// undeclaredBaseImages lists the images the Dockerfile builds from which are neither replaced by
+// the images declared in the configuration nor stages of the Dockerfile itself
+func undeclaredBaseImages(dockerfile string, config api.ProjectDirectoryImageBuildStepConfiguration) ([]string, error) {
+	result, err := parser.Parse(strings.NewReader(dockerfile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Dockerfile: %w", err)
+	}
+	declared := sets.New[string]("scratch")
+	for _, input := range config.Inputs {
+		for _, as := range input.As {
+			declared.Insert(strings.ToLower(as))
+		}
+	}
+	var froms []*parser.Node
+	for _, child := range result.AST.Children {
+		if child.Value == dockercmd.From && child.Next != nil {
+			froms = append(froms, child)
+		}
+	}
+	var images []string
+	for i, from := range froms {
+		image := from.Next.Value
+		// the image of the last stage is replaced by the one the build is configured to be from
+		replaced := i == len(froms)-1 && config.From != ""
+		if !replaced && !declared.Has(strings.ToLower(image)) {
+			if strings.Contains(image, "$") {
+				return nil, fmt.Errorf("the base image %s depends on build arguments", image)
+			}
+			images = append(images, image)
+		}
+		if as := from.Next.Next; as != nil && strings.EqualFold(as.Value, "as") && as.Next != nil {
+			declared.Insert(strings.ToLower(as.Next.Value))
+		}
+	}
+	return images, nil
+}
+
+// resolveImageDigest determines the digest of the image the pull spec points to, without importing it
+func resolveImageDigest(ctx context.Context, client ctrlruntimeclient.Client, namespace, pullSpec string) (string, error) {
+	streamImport := &imagev1.ImageStreamImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: api.PipelineImageStream},
+		Spec: imagev1.ImageStreamImportSpec{
+			Import: false,
+			Images: []imagev1.ImageImportSpec{{From: coreapi.ObjectReference{Kind: "DockerImage", Name: pullSpec}}},
+		},
+	}
+	if err := client.Create(ctx, streamImport); err != nil {
+		return "", fmt.Errorf("failed to look up base image %s: %w", pullSpec, err)
+	}
+	if len(streamImport.Status.Images) == 0 || streamImport.Status.Images[0].Image == nil {
+		return "", fmt.Errorf("failed to look up base image %s: %s", pullSpec, importStatusMessage(streamImport))
+	}
+	return streamImport.Status.Images[0].Image.Name, nil
+}
+
+func importStatusMessage(streamImport *imagev1.ImageStreamImport) string {
+	if len(streamImport.Status.Images) == 0 || streamImport.Status.Images[0].Status.Message == "" {
+		return "no image was found"
+	}
+	return streamImport.Status.Images[0].Status.Message
+}
+
+// contextDigestScript digests the files in the context directory with their modes, symbolic
+// links by their targets, and prints the FROM instructions of the Dockerfile, if any
+func contextDigestScript(dockerfilePath string) string {
+	script := `{ { find . -path ./.git -prune -o -type f -print0 | LC_ALL=C sort -z | xargs -0 -r sha256sum; ` +
+		`find . -path ./.git -prune -o \( -type f -o -type l \) -printf '%m %y %p %l\n' | LC_ALL=C sort; } | sha256sum | cut -d ' ' -f 1`
+	if dockerfilePath != "" {
+		script += fmt.Sprintf(`; grep -i -E '^[[:space:]]*FROM[[:space:]]' '%s' || true`, strings.ReplaceAll(dockerfilePath, "'", `'\''`))
+	}
+	return script + "; } > /dev/termination-log"
+}
+
+// inspectContext digests the files in the context directory of the source image, except for
+// the git metadata, which differs between clones of the same revision. It also returns the FROM
+// instructions of the Dockerfile in the context directory, unless the Dockerfile is literal.
+func (s *projectDirectoryImageBuildStep) inspectContext(ctx context.Context, sourceTag api.PipelineImageStreamTagReference) (string, string, error) {
+	source := fmt.Sprintf("%s:%s", api.PipelineImageStream, sourceTag)
+	workingDir, err := getWorkingDir(s.client, source, s.jobSpec.Namespace())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get workingDir: %w", err)
+	}
+	var dockerfilePath string
+	if s.config.DockerfileLiteral == nil {
+		dockerfilePath = "Dockerfile"
+		if s.config.DockerfilePath != "" {
+			dockerfilePath = s.config.DockerfilePath
+		}
+	}
+	name := fmt.Sprintf("%s-content-digest", s.config.To)
+	pod := &coreapi.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.jobSpec.Namespace(),
+			Labels:    LabelsFor(s.jobSpec, map[string]string{}, s.config.Ref),
+		},
+		Spec: coreapi.PodSpec{
+			RestartPolicy: coreapi.RestartPolicyNever,
+			Containers: []coreapi.Container{{
+				Name:       "digest",
+				Image:      source, // the cluster will resolve this relative ref for us when we create Pods with it
+				WorkingDir: path.Join(workingDir, s.config.ContextDir),
+				Command:    []string{"/bin/sh", "-c", contextDigestScript(dockerfilePath)},
+				Resources: coreapi.ResourceRequirements{
+					Requests: coreapi.ResourceList{
+						coreapi.ResourceCPU:    resource.MustParse("100m"),
+						coreapi.ResourceMemory: resource.MustParse("200Mi"),
+					},
+				},
+			}},
+		},
+	}
+	if owner := s.jobSpec.Owner(); owner != nil {
+		pod.OwnerReferences = append(pod.OwnerReferences, *owner)
+	}
+	if _, err := RunPod(ctx, s.podClient, pod, true); err != nil {
+		return "", "", fmt.Errorf("failed to digest the context directory: %w", err)
+	}
+	if err := s.podClient.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: name}, pod); err != nil {
+		return "", "", fmt.Errorf("failed to get the digest of the context directory: %w", err)
+	}
+	if len(pod.Status.ContainerStatuses) == 0 || pod.Status.ContainerStatuses[0].State.Terminated == nil || pod.Status.ContainerStatuses[0].State.Terminated.Message == "" {
+		return "", "", errors.New("failed to digest the context directory: pod produced no output")
+	}
+	tree, fromInstructions, _ := strings.Cut(strings.TrimSpace(pod.Status.ContainerStatuses[0].State.Terminated.Message), "\n")
+	return "sha256:" + tree, fromInstructions, nil
+}
+
+// findImageWithContentDigest returns the first of the candidate tags whose image carries the content digest
+func findImageWithContentDigest(ctx context.Context, client ctrlruntimeclient.Client, candidates []api.ImageStreamTagReference, digest string) (*api.ImageStreamTagReference, *imagev1.ImageStreamTag, error) {
+	for _, candidate := range candidates {
+		ist := &imagev1.ImageStreamTag{}
+		if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: candidate.Namespace, Name: fmt.Sprintf("%s:%s", candidate.Name, candidate.Tag)}, ist); err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("could not fetch candidate image %s: %w", candidate.ISTagName(), err)
+		}
+		metadata, err := dockerImageMetadata(ctx, client, ist)
+		if err != nil {
+			logrus.WithError(err).Debugf("Could not read the labels of candidate image %s.", candidate.ISTagName())
+			continue
+		}
+		if metadata.Config != nil && metadata.Config.Labels[api.ContentDigestLabel] == digest {
+			return &candidate, ist, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// tagReusedImage tags the image into the pipeline image stream as the output of the build
+func (s *projectDirectoryImageBuildStep) tagReusedImage(ctx context.Context, reused api.ImageStreamTagReference, image string) error {
+	ist := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s:%s", api.PipelineImageStream, s.config.To),
+			Namespace: s.jobSpec.Namespace(),
+		},
+		Tag: &imagev1.TagReference{
+			ReferencePolicy: imagev1.TagReferencePolicy{
+				Type: imagev1.LocalTagReferencePolicy,
+			},
+			From: &coreapi.ObjectReference{
+				Kind:      "ImageStreamImage",
+				Name:      fmt.Sprintf("%s@%s", reused.Name, image),
+				Namespace: reused.Namespace,
+			},
+			ImportPolicy: imagev1.TagImportPolicy{
+				ImportMode: imagev1.ImportModePreserveOriginal,
+			},
+		},
+	}
+	if err := s.client.Create(ctx, ist); err != nil && !kerrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create imagestreamtag for reused image: %w", err)
+	}
+	if err := waitForTagInSpec(ctx, s.client, s.jobSpec.Namespace(), api.PipelineImageStream, string(s.config.To), 3*time.Minute); err != nil {
+		return fmt.Errorf("failed to wait for the tag %s to show in the spec of imagestream %s/%s", string(s.config.To), s.jobSpec.Namespace(), api.PipelineImageStream)
+	}
+	if err := utils.WaitForImportingISTag(ctx, s.client, s.jobSpec.Namespace(), api.PipelineImageStream, nil, sets.New(string(s.config.To)), utils.DefaultImageImportTimeout); err != nil {
+		return fmt.Errorf("failed to wait for importing imagestreamtags on %s/%s:%s: %w", s.jobSpec.Namespace(), api.PipelineImageStream, s.config.To, err)
+	}
+	return nil
+}
+
+// avoidBuild determines the content digest of the image and tags an image built from the same
+// content in the past into the pipeline instead of building it, if there is one. Any failure to
+// determine the digest is not fatal, the image is built then.
+func (s *projectDirectoryImageBuildStep) avoidBuild(ctx context.Context, sourceTag api.PipelineImageStreamTagReference) (string, bool, error) {
+	digest, err := s.contentDigest(ctx, sourceTag)
+	if err != nil {
+		logrus.WithError(err).Warnf("Could not determine the content digest of %s, it will be built.", s.config.To)
+		return "", false, nil
+	}
+	s.metadata = map[string]string{contentDigestMetadataKey: digest, buildAvoidedMetadataKey: "false"}
+	reused, ist, err := findImageWithContentDigest(ctx, s.client, s.reusableImages, digest)
+	if err != nil {
+		logrus.WithError(err).Warnf("Could not look for an image with the content of %s, it will be built.", s.config.To)
+		return digest, false, nil
+	}
+	if reused == nil {
+		logrus.Debugf("No image with the content digest %s of %s was found, it will be built.", digest, s.config.To)
+		return digest, false, nil
+	}
+	logrus.Infof("Tagging %s into %s:%s instead of building it, it was built from the same content.", reused.ISTagName(), api.PipelineImageStream, s.config.To)
+	if err := s.tagReusedImage(ctx, *reused, ist.Image.Name); err != nil {
+		return digest, false, err
+	}
+	s.metadata[buildAvoidedMetadataKey] = "true"
+	s.metadata[reusedImageMetadataKey] = fmt.Sprintf("%s@%s", reused.ISTagName(), ist.Image.Name)
+	return digest, true, nil
+}
+
+// addContentDigestLabel labels the image built with the digest of the content it was built from
+func addContentDigestLabel(build *buildapi.Build, digest string) {
+	build.Spec.Output.ImageLabels = append(build.Spec.Output.ImageLabels, buildapi.ImageLabel{Name: api.ContentDigestLabel, Value: digest})
+	sort.Slice(build.Spec.Output.ImageLabels, func(i, j int) bool {
+		return build.Spec.Output.ImageLabels[i].Name < build.Spec.Output.ImageLabels[j].Name
+	})
+}
@@ -0,0 +1,230 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestBuildContentDigest(t *testing.T) {
+	base := buildContent{
+		Tree:           "sha256:tree",
+		DockerfilePath: "Dockerfile",
+		From:           "sha256:from",
+		Inputs: map[string]buildInputContent{
+			"cli":  {Digest: "sha256:cli", Paths: []api.ImageSourcePath{{SourcePath: "/usr/bin/oc", DestinationDir: "."}}},
+			"root": {Digest: "sha256:root", As: []string{"builder"}},
+		},
+		BuildArgs:     []api.BuildArg{{Name: "VERSION", Value: "1"}},
+		Architectures: []string{"amd64"},
+		BaseImages:    map[string]string{"quay.io/org/base:latest": "sha256:base"},
+	}
+	digest, err := base.digest()
+	if err != nil {
+		t.Fatalf("failed to digest the content: %v", err)
+	}
+	again, err := base.digest()
+	if err != nil {
+		t.Fatalf("failed to digest the content: %v", err)
+	}
+	if digest != again {
+		t.Errorf("expected the digest of the same content to be stable, got %s and %s", digest, again)
+	}
+
+	for _, testCase := range []struct {
+		name   string
+		mutate func(*buildContent)
+	}{
+		{name: "tree", mutate: func(c *buildContent) { c.Tree = "sha256:other" }},
+		{name: "dockerfile path", mutate: func(c *buildContent) { c.DockerfilePath = "Dockerfile.rhel" }},
+		{name: "from", mutate: func(c *buildContent) { c.From = "sha256:other" }},
+		{name: "input digest", mutate: func(c *buildContent) {
+			c.Inputs = map[string]buildInputContent{"cli": {Digest: "sha256:other"}, "root": c.Inputs["root"]}
+		}},
+		{name: "build args", mutate: func(c *buildContent) { c.BuildArgs = []api.BuildArg{{Name: "VERSION", Value: "2"}} }},
+		{name: "architectures", mutate: func(c *buildContent) { c.Architectures = []string{"amd64", "arm64"} }},
+		{name: "base image digest", mutate: func(c *buildContent) {
+			c.BaseImages = map[string]string{"quay.io/org/base:latest": "sha256:other"}
+		}},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			changed := base
+			testCase.mutate(&changed)
+			changedDigest, err := changed.digest()
+			if err != nil {
+				t.Fatalf("failed to digest the content: %v", err)
+			}
+			if changedDigest == digest {
+				t.Errorf("expected a change of the %s to change the digest", testCase.name)
+			}
+		})
+	}
+}
+
+func TestFindImageWithContentDigest(t *testing.T) {
+	imageWithLabels := func(name, image string, labels map[string]string) *imagev1.ImageStreamTag {
+		raw := []byte(`{"Config":{}}`)
+		if labels != nil {
+			raw = []byte(`{"Config":{"Labels":{`)
+			for key, value := range labels {
+				raw = append(raw, []byte(`"`+key+`":"`+value+`"`)...)
+			}
+			raw = append(raw, []byte(`}}}`)...)
+		}
+		return &imagev1.ImageStreamTag{
+			ObjectMeta: meta.ObjectMeta{Namespace: "ocp", Name: name},
+			Image: imagev1.Image{
+				ObjectMeta:          meta.ObjectMeta{Name: image},
+				DockerImageMetadata: runtime.RawExtension{Raw: raw},
+			},
+		}
+	}
+	client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(
+		imageWithLabels("4.17:unlabeled", "sha256:unlabeled", nil),
+		imageWithLabels("4.17:other", "sha256:other", map[string]string{api.ContentDigestLabel: "sha256:other-content"}),
+		imageWithLabels("4.17:component", "sha256:component", map[string]string{api.ContentDigestLabel: "sha256:content"}),
+	).Build()
+
+	var testCases = []struct {
+		name          string
+		candidates    []api.ImageStreamTagReference
+		expected      *api.ImageStreamTagReference
+		expectedImage string
+	}{
+		{
+			name:       "no candidates",
+			candidates: nil,
+		},
+		{
+			name: "candidates without the digest or missing are skipped",
+			candidates: []api.ImageStreamTagReference{
+				{Namespace: "ocp", Name: "4.17", Tag: "missing"},
+				{Namespace: "ocp", Name: "4.17", Tag: "unlabeled"},
+				{Namespace: "ocp", Name: "4.17", Tag: "other"},
+				{Namespace: "ocp", Name: "4.17", Tag: "component"},
+			},
+			expected:      &api.ImageStreamTagReference{Namespace: "ocp", Name: "4.17", Tag: "component"},
+			expectedImage: "sha256:component",
+		},
+		{
+			name: "no candidate has the digest",
+			candidates: []api.ImageStreamTagReference{
+				{Namespace: "ocp", Name: "4.17", Tag: "unlabeled"},
+				{Namespace: "ocp", Name: "4.17", Tag: "other"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, ist, err := findImageWithContentDigest(context.Background(), client, testCase.candidates, "sha256:content")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Errorf("unexpected image: %s", diff)
+			}
+			if testCase.expected != nil && ist.Image.Name != testCase.expectedImage {
+				t.Errorf("expected image %s, got %s", testCase.expectedImage, ist.Image.Name)
+			}
+		})
+	}
+}
+
+func TestUndeclaredBaseImages(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		dockerfile    string
+		config        api.ProjectDirectoryImageBuildStepConfiguration
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:       "last stage is replaced by the configured image",
+			dockerfile: "FROM quay.io/org/builder:latest AS builder\nRUN make\nFROM quay.io/org/base:latest\nCOPY --from=builder /bin /bin\n",
+			config:     api.ProjectDirectoryImageBuildStepConfiguration{From: "base"},
+			expected:   []string{"quay.io/org/builder:latest"},
+		},
+		{
+			name:       "without a configured image every stage is built from its own",
+			dockerfile: "FROM quay.io/org/builder:latest AS builder\nFROM quay.io/org/base:latest\n",
+			expected:   []string{"quay.io/org/builder:latest", "quay.io/org/base:latest"},
+		},
+		{
+			name:       "inputs, stages and scratch are not base images",
+			dockerfile: "FROM quay.io/org/builder:latest AS builder\nFROM Builder AS tests\nFROM registry.ci.openshift.org/ocp/4.17:cli\nFROM scratch\n",
+			config: api.ProjectDirectoryImageBuildStepConfiguration{
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					Inputs: map[string]api.ImageBuildInputs{"cli": {As: []string{"registry.ci.openshift.org/ocp/4.17:cli"}}},
+				},
+			},
+			expected: []string{"quay.io/org/builder:latest"},
+		},
+		{
+			name:          "base image from a build argument",
+			dockerfile:    "ARG BASE\nFROM ${BASE}\n",
+			expectedError: "the base image ${BASE} depends on build arguments",
+		},
+		{
+			name:       "no Dockerfile",
+			dockerfile: "",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := undeclaredBaseImages(testCase.dockerfile, testCase.config)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expectedError, actualError); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Errorf("unexpected base images: %s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveImageDigest(t *testing.T) {
+	client := fakectrlruntimeclient.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, client ctrlruntimeclient.WithWatch, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+			streamImport, ok := obj.(*imagev1.ImageStreamImport)
+			if !ok {
+				return client.Create(ctx, obj, opts...)
+			}
+			if streamImport.Spec.Import {
+				t.Error("expected the image not to be imported")
+			}
+			switch name := streamImport.Spec.Images[0].From.Name; name {
+			case "quay.io/org/base:latest":
+				streamImport.Status.Images = []imagev1.ImageImportStatus{{Image: &imagev1.Image{ObjectMeta: meta.ObjectMeta{Name: "sha256:base"}}}}
+			default:
+				streamImport.Status.Images = []imagev1.ImageImportStatus{{Status: meta.Status{Message: "not found: " + name}}}
+			}
+			return nil
+		},
+	}).Build()
+
+	digest, err := resolveImageDigest(context.Background(), client, "ns", "quay.io/org/base:latest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digest != "sha256:base" {
+		t.Errorf("expected digest sha256:base, got %s", digest)
+	}
+	if _, err := resolveImageDigest(context.Background(), client, "ns", "quay.io/org/missing:latest"); err == nil || err.Error() != "failed to look up base image quay.io/org/missing:latest: not found: quay.io/org/missing:latest" {
+		t.Errorf("expected the lookup of a missing image to fail, got %v", err)
+	}
+}
@@ -62,6 +62,14 @@ func (s *clusterClaimStep) Creates() []api.StepLink             { return s.wrapp
 func (s *clusterClaimStep) Objects() []ctrlruntimeclient.Object { return s.wrapped.Objects() }
 func (s *clusterClaimStep) Provides() api.ParameterMap          { return s.wrapped.Provides() }
 
+func (s *clusterClaimStep) TrackState(state *ExecutionStateStore) error {
+	return trackWrappedState("cluster claim", s.wrapped, state)
+}
+
+func (s *clusterClaimStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	return renderWrapped(ctx, s.wrapped)
+}
+
 func (s *clusterClaimStep) Run(ctx context.Context) error {
 	return results.ForReason("utilizing_cluster_claim").ForError(s.run(ctx))
 }
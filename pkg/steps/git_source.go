@@ -38,6 +38,14 @@ func (s *gitSourceStep) Run(ctx context.Context) error {
 }
 
 func (s *gitSourceStep) run(ctx context.Context) error {
+	build, err := s.build()
+	if err != nil {
+		return err
+	}
+	return handleBuilds(ctx, s.buildClient, s.podClient, *build, newImageBuildOptions(s.architectures.UnsortedList()))
+}
+
+func (s *gitSourceStep) build() (*buildapi.Build, error) {
 	if refs := s.determineRefsWorkdir(s.jobSpec.Refs, s.jobSpec.ExtraRefs); refs != nil {
 		cloneURI := fmt.Sprintf("https://github.com/%s/%s.git", refs.Org, refs.Repo)
 		var secretName string
@@ -50,7 +58,7 @@ func (s *gitSourceStep) run(ctx context.Context) error {
 		if s.config.Ref != "" {
 			root = fmt.Sprintf("%s-%s", root, s.config.Ref)
 		}
-		return handleBuilds(ctx, s.buildClient, s.podClient, *buildFromSource(s.jobSpec, "", api.PipelineImageStreamTagReference(root), buildapi.BuildSource{
+		return buildFromSource(s.jobSpec, "", api.PipelineImageStreamTagReference(root), buildapi.BuildSource{
 			Type:         buildapi.BuildSourceGit,
 			Dockerfile:   s.config.DockerfileLiteral,
 			ContextDir:   s.config.ContextDir,
@@ -59,10 +67,18 @@ func (s *gitSourceStep) run(ctx context.Context) error {
 				URI: cloneURI,
 				Ref: refs.BaseRef,
 			},
-		}, "", s.config.DockerfilePath, s.resources, s.pullSecret, nil, s.config.Ref), newImageBuildOptions(s.architectures.UnsortedList()))
+		}, "", s.config.DockerfilePath, s.resources, s.pullSecret, nil, s.config.Ref), nil
 	}
 
-	return fmt.Errorf("nothing to build source image from, no refs")
+	return nil, fmt.Errorf("nothing to build source image from, no refs")
+}
+
+func (s *gitSourceStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	build, err := s.build()
+	if err != nil {
+		return nil, err
+	}
+	return renderBuilds(*build, s.architectures), nil
 }
 
 func (s *gitSourceStep) Name() string {
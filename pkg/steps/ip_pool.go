@@ -83,6 +83,14 @@ func (s *ipPoolStep) SubTests() []*junit.TestCase {
 	return nil
 }
 
+func (s *ipPoolStep) TrackState(state *ExecutionStateStore) error {
+	return trackWrappedState("IP pool lease", s.wrapped, state)
+}
+
+func (s *ipPoolStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	return renderWrapped(ctx, s.wrapped)
+}
+
 func (s *ipPoolStep) Run(ctx context.Context) error {
 	return results.ForReason("utilizing_ip_pool").ForError(s.run(ctx, time.Minute))
 }
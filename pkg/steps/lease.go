@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"sort"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift/ci-tools/pkg/api"
@@ -23,6 +25,9 @@ var NoLeaseClientErr = errors.New("step needs a lease but no lease client provid
 type stepLease struct {
 	api.StepLease
 	resources []string
+	// leasedType is the type of the resources, which differs from the
+	// resource type of the lease when a fallback was leased instead
+	leasedType string
 }
 
 // leaseStep wraps another step and acquires/releases one or more leases.
@@ -33,13 +38,18 @@ type leaseStep struct {
 
 	// for sending heartbeats during lease acquisition
 	namespace func() string
+	// for recording which types were leased for leases with fallbacks
+	reporter results.LeaseReporter
+	// for resuming the wrapped step with the same leased resources
+	state *ExecutionStateStore
 }
 
-func LeaseStep(client *lease.Client, leases []api.StepLease, wrapped api.Step, namespace func() string) api.Step {
+func LeaseStep(client *lease.Client, leases []api.StepLease, wrapped api.Step, namespace func() string, reporter results.LeaseReporter) api.Step {
 	ret := leaseStep{
 		client:    client,
 		wrapped:   wrapped,
 		namespace: namespace,
+		reporter:  reporter,
 	}
 	for _, l := range leases {
 		ret.leases = append(ret.leases, stepLease{StepLease: l})
@@ -72,28 +82,65 @@ func (s *leaseStep) Provides() api.ParameterMap {
 	for i := range s.leases {
 		l := &s.leases[i]
 		parameters[l.Env] = func() (string, error) {
-			if len(l.resources) == 0 {
-				return "", nil
-			}
-			strip := func(r string) string {
-				if i := strings.Index(r, "--"); i == -1 {
-					return r
-				} else {
-					return r[:i]
+			return l.value(), nil
+		}
+		if len(l.FallbackResourceTypes) != 0 {
+			for env, value := range l.fallbackValues() {
+				parameters[env] = func() (string, error) {
+					return value(), nil
 				}
 			}
-			builder := strings.Builder{}
-			builder.WriteString(strip(l.resources[0]))
-			for _, r := range l.resources[1:] {
-				builder.WriteString(" ")
-				builder.WriteString(strip(r))
-			}
-			return builder.String(), nil
 		}
 	}
 	return parameters
 }
 
+// fallbackValues are the values of the environment variables exposing a lease with fallbacks
+func (l *stepLease) fallbackValues() map[string]func() string {
+	return map[string]func() string{
+		api.LeaseTypeEnv(l.Env):   func() string { return l.leasedType },
+		api.LeaseRegionEnv(l.Env): l.region,
+	}
+}
+
+// region is the region of the leased resources, which are named
+// `<region>--<type>-<index>` when they are in one, separated by spaces
+// when they are in different regions
+func (l *stepLease) region() string {
+	var regions []string
+	seen := sets.New[string]()
+	for _, r := range l.resources {
+		region, _, found := strings.Cut(r, "--")
+		if !found || seen.Has(region) {
+			continue
+		}
+		seen.Insert(region)
+		regions = append(regions, region)
+	}
+	return strings.Join(regions, " ")
+}
+
+// value is the value of the environment variable exposing the leased resources
+func (l *stepLease) value() string {
+	if len(l.resources) == 0 {
+		return ""
+	}
+	strip := func(r string) string {
+		if i := strings.Index(r, "--"); i == -1 {
+			return r
+		} else {
+			return r[:i]
+		}
+	}
+	builder := strings.Builder{}
+	builder.WriteString(strip(l.resources[0]))
+	for _, r := range l.resources[1:] {
+		builder.WriteString(" ")
+		builder.WriteString(strip(r))
+	}
+	return builder.String()
+}
+
 func (s *leaseStep) SubTests() []*junit.TestCase {
 	if subTests, ok := s.wrapped.(SubtestReporter); ok {
 		return subTests.SubTests()
@@ -101,6 +148,59 @@ func (s *leaseStep) SubTests() []*junit.TestCase {
 	return nil
 }
 
+// TrackState forwards the state to the wrapped step.  The leases are acquired
+// again when the execution is resumed and the sub-steps which completed in the
+// execution being resumed can only be skipped when the same resources are
+// leased, as the state those sub-steps created lives on them.
+func (s *leaseStep) TrackState(state *ExecutionStateStore) error {
+	if state.Completed(s.wrapped.Name()) {
+		return nil
+	}
+	s.state = state
+	if tracker, ok := s.wrapped.(StateTracker); ok {
+		return tracker.TrackState(state)
+	}
+	return nil
+}
+
+// recordLeases records the resources leased for the sub-steps.  When they
+// differ from those the sub-steps which completed in the execution being
+// resumed used, those sub-steps are executed again with the new resources.
+func (s *leaseStep) recordLeases(ctx context.Context) error {
+	if s.state == nil {
+		return nil
+	}
+	leased := map[string]string{}
+	for i := range s.leases {
+		l := &s.leases[i]
+		leased[l.Env] = l.value()
+		if len(l.FallbackResourceTypes) != 0 {
+			for env, value := range l.fallbackValues() {
+				leased[env] = value()
+			}
+		}
+	}
+	if s.state.CompletedSubSteps(s.Name()).Len() != 0 {
+		if previous := s.state.PreviousLeases(s.Name()); !maps.Equal(previous, leased) {
+			logrus.Infof("Steps of %s which completed in the execution being resumed used leases %v, but %v were acquired: executing all of them again.", s.Name(), previous, leased)
+			s.state.ForgetSubSteps(s.Name())
+			if tracker, ok := s.wrapped.(StateTracker); ok {
+				if err := tracker.TrackState(s.state); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := s.state.RecordLeases(ctx, s.Name(), leased); err != nil {
+		logrus.WithError(err).Warnf("Failed to record the leases of step %s.", s.Name())
+	}
+	return nil
+}
+
+func (s *leaseStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	return renderWrapped(ctx, s.wrapped)
+}
+
 func (s *leaseStep) Run(ctx context.Context) error {
 	return results.ForReason("utilizing_lease").ForError(s.run(ctx))
 }
@@ -116,6 +216,11 @@ func (s *leaseStep) run(ctx context.Context) error {
 	if err := acquireLeases(client, ctx, cancel, s.leases); err != nil {
 		return err
 	}
+	s.reportFallbacks()
+	if err := s.recordLeases(ctx); err != nil {
+		releaseErr := results.ForReason("releasing_lease").ForError(releaseLeases(client, s.leases...))
+		return aggregateWrappedErrorAndReleaseError(results.ForReason("resuming_execution").ForError(err), releaseErr)
+	}
 	wrappedErr := results.ForReason("executing_test").ForError(s.wrapped.Run(ctx))
 	logrus.Infof("Releasing leases for test %s", s.Name())
 	releaseErr := results.ForReason("releasing_lease").ForError(releaseLeases(client, s.leases...))
@@ -123,6 +228,17 @@ func (s *leaseStep) run(ctx context.Context) error {
 	return aggregateWrappedErrorAndReleaseError(wrappedErr, releaseErr)
 }
 
+func (s *leaseStep) reportFallbacks() {
+	if s.reporter == nil {
+		return
+	}
+	for _, l := range s.leases {
+		if len(l.FallbackResourceTypes) != 0 {
+			s.reporter.ReportLeaseAcquisition(l.ResourceType, l.leasedType)
+		}
+	}
+}
+
 func aggregateWrappedErrorAndReleaseError(wrappedErr, releaseErr error) error {
 	// we want a sensible output error for reporting, so we bubble up these individually if we can
 	if wrappedErr != nil && releaseErr == nil {
@@ -152,15 +268,22 @@ func acquireLeases(
 	for _, i := range sorted {
 		l := &leases[i]
 		logrus.Debugf("Acquiring %d lease(s) for %s", l.Count, l.ResourceType)
-		names, err := client.Acquire(l.ResourceType, l.Count, ctx, cancel)
+		var names []string
+		var err error
+		if len(l.FallbackResourceTypes) != 0 {
+			names, l.leasedType, err = acquireWithFallback(client, ctx, cancel, l)
+		} else {
+			names, err = client.Acquire(l.ResourceType, l.Count, ctx, cancel)
+			l.leasedType = l.ResourceType
+		}
 		if err != nil {
-			if err == lease.ErrNotFound {
+			if errors.Is(err, lease.ErrNotFound) {
 				printResourceMetrics(client, l.ResourceType)
 			}
 			errs = append(errs, results.ForReason(results.Reason("acquiring_lease")).WithError(err).Errorf("failed to acquire lease for %q: %v", l.ResourceType, err))
 			break
 		}
-		logrus.Infof("Acquired %d lease(s) for %s: %v", l.Count, l.ResourceType, names)
+		logrus.Infof("Acquired %d lease(s) for %s: %v", l.Count, l.leasedType, names)
 		l.resources = names
 	}
 	if errs != nil {
@@ -171,6 +294,16 @@ func acquireLeases(
 	return utilerrors.NewAggregate(errs)
 }
 
+// acquireWithFallback leases resources of the first type, in order of preference,
+// which has enough of them free, waiting for resources of any of the types.
+func acquireWithFallback(client lease.Client, ctx context.Context, cancel context.CancelFunc, l *stepLease) ([]string, string, error) {
+	names, rtype, err := client.AcquireFirstAvailable(append([]string{l.ResourceType}, l.FallbackResourceTypes...), l.Count, ctx, cancel)
+	if err == nil && rtype != l.ResourceType {
+		logrus.Infof("No lease is free for %s, falling back to %s", l.ResourceType, rtype)
+	}
+	return names, rtype, err
+}
+
 func releaseLeases(client lease.Client, leases ...stepLease) error {
 	var errs []error
 	for _, l := range leases {
@@ -6,8 +6,13 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
 	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/junit"
@@ -62,7 +67,7 @@ func TestLeaseStepForward(t *testing.T) {
 		ResourceType: "lease_name",
 	}}
 	step := stepNeedsLease{}
-	withLease := LeaseStep(nil, leases, &step, emptyNamespace)
+	withLease := LeaseStep(nil, leases, &step, emptyNamespace, nil)
 	t.Run("Inputs", func(t *testing.T) {
 		s, err := step.Inputs()
 		if err != nil {
@@ -121,7 +126,7 @@ func TestLeaseStepForward(t *testing.T) {
 
 func TestProvidesStripsSuffix(t *testing.T) {
 	leases := []api.StepLease{{Env: api.DefaultLeaseEnv, ResourceType: "rtype"}}
-	withLease := LeaseStep(nil, leases, &stepNeedsLease{}, emptyNamespace)
+	withLease := LeaseStep(nil, leases, &stepNeedsLease{}, emptyNamespace, nil)
 	withLease.(*leaseStep).leases[0].resources = []string{"whatever--01"}
 	expected := "whatever"
 	actual, err := withLease.Provides()[api.DefaultLeaseEnv]()
@@ -218,7 +223,7 @@ func TestError(t *testing.T) {
 			var calls []string
 			client := lease.NewFakeClient("owner", "url", 0, tc.failures, &calls)
 			s := stepNeedsLease{fail: tc.runFails}
-			err := LeaseStep(&client, leases, &s, func() string { return "" }).Run(ctx)
+			err := LeaseStep(&client, leases, &s, func() string { return "" }, nil).Run(ctx)
 			if err == nil {
 				t.Fatalf("unexpected success, calls: %#v", calls)
 			}
@@ -238,7 +243,7 @@ func TestAcquireRelease(t *testing.T) {
 		{ResourceType: "rtype0", Count: 2},
 	}
 	step := stepNeedsLease{}
-	withLease := LeaseStep(&client, leases, &step, func() string { return "" })
+	withLease := LeaseStep(&client, leases, &step, func() string { return "" }, nil)
 	if err := withLease.Run(context.Background()); err != nil {
 		t.Fatal(err)
 	}
@@ -257,3 +262,196 @@ func TestAcquireRelease(t *testing.T) {
 		t.Fatalf("wrong calls to the lease client: %s", diff.ObjectDiff(calls, expected))
 	}
 }
+
+func TestLeaseStepResume(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		previous          map[string]string
+		expectedCompleted []string
+	}{{
+		name:              "same resources leased again",
+		previous:          map[string]string{api.DefaultLeaseEnv: "rtype_0"},
+		expectedCompleted: []string{"needs_lease-pre0"},
+	}, {
+		name:     "other resources leased",
+		previous: map[string]string{api.DefaultLeaseEnv: "rtype_3"},
+	}, {
+		name: "resources unknown",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			crclient := fakectrlruntimeclient.NewClientBuilder().Build()
+			namespace := func() string { return "ns" }
+			previous := NewExecutionStateStore(crclient, namespace, "job")
+			if err := previous.RecordSubStep(context.Background(), "needs_lease", "needs_lease-pre0"); err != nil {
+				t.Fatal(err)
+			}
+			if tc.previous != nil {
+				if err := previous.RecordLeases(context.Background(), "needs_lease", tc.previous); err != nil {
+					t.Fatal(err)
+				}
+			}
+			state := NewExecutionStateStore(crclient, namespace, "job")
+			if err := state.Load(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			var calls []string
+			client := lease.NewFakeClient("owner", "url", 0, nil, &calls)
+			step := stepNeedsLease{}
+			withLease := LeaseStep(&client, []api.StepLease{{ResourceType: "rtype", Env: api.DefaultLeaseEnv, Count: 1}}, &step, namespace, nil)
+			if err := withLease.(StateTracker).TrackState(state); err != nil {
+				t.Fatal(err)
+			}
+			if err := withLease.Run(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !step.ran {
+				t.Error("expected the step to run")
+			}
+			if diff := cmp.Diff(tc.expectedCompleted, sets.List(state.CompletedSubSteps("needs_lease")), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected completed sub-steps: %s", diff)
+			}
+			expectedCalls := []string{
+				"acquireWaitWithPriority owner rtype free leased random",
+				"releaseone owner rtype_0 free",
+			}
+			if diff := cmp.Diff(expectedCalls, calls); diff != "" {
+				t.Errorf("wrong calls to the lease client: %s", diff)
+			}
+		})
+	}
+}
+
+type fakeLeaseReporter struct {
+	reported [][2]string
+}
+
+func (r *fakeLeaseReporter) ReportLeaseAcquisition(resourceType, leasedType string) {
+	r.reported = append(r.reported, [2]string{resourceType, leasedType})
+}
+
+func TestAcquireWithFallback(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		failures         map[string]error
+		expectedErr      bool
+		expectedType     string
+		expectedResource string
+		expectedCalls    []string
+	}{{
+		name:             "preferred type is free",
+		expectedType:     "aws-quota-slice",
+		expectedResource: "aws-quota-slice_0",
+		expectedCalls: []string{
+			"acquire owner aws-quota-slice free leased",
+			"releaseone owner aws-quota-slice_0 free",
+		},
+	}, {
+		name: "fallback type is free",
+		failures: map[string]error{
+			"acquire owner aws-quota-slice free leased": lease.ErrNotFound,
+		},
+		expectedType:     "aws-2-quota-slice",
+		expectedResource: "aws-2-quota-slice_1",
+		expectedCalls: []string{
+			"acquire owner aws-quota-slice free leased",
+			"acquire owner aws-2-quota-slice free leased",
+			"releaseone owner aws-2-quota-slice_1 free",
+		},
+	}, {
+		name: "fallback type fails for other reasons",
+		failures: map[string]error{
+			"acquire owner aws-quota-slice free leased":   lease.ErrNotFound,
+			"acquire owner aws-2-quota-slice free leased": errors.New("injected failure"),
+		},
+		expectedType:     "aws-3-quota-slice",
+		expectedResource: "aws-3-quota-slice_2",
+		expectedCalls: []string{
+			"acquire owner aws-quota-slice free leased",
+			"acquire owner aws-2-quota-slice free leased",
+			"acquire owner aws-3-quota-slice free leased",
+			"releaseone owner aws-3-quota-slice_2 free",
+		},
+	}, {
+		name: "no type becomes free",
+		failures: map[string]error{
+			"acquire owner aws-quota-slice free leased":   lease.ErrNotFound,
+			"acquire owner aws-2-quota-slice free leased": lease.ErrNotFound,
+			"acquire owner aws-3-quota-slice free leased": lease.ErrNotFound,
+		},
+		expectedErr: true,
+		expectedCalls: []string{
+			"acquire owner aws-quota-slice free leased",
+			"acquire owner aws-2-quota-slice free leased",
+			"acquire owner aws-3-quota-slice free leased",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			client := lease.NewFakeClient("owner", "url", 0, tc.failures, &calls)
+			leases := []api.StepLease{{Env: api.DefaultLeaseEnv, ResourceType: "aws-quota-slice", Count: 1, FallbackResourceTypes: []string{"aws-2-quota-slice", "aws-3-quota-slice"}}}
+			reporter := &fakeLeaseReporter{}
+			step := stepNeedsLease{}
+			withLease := LeaseStep(&client, leases, &step, func() string { return "" }, reporter)
+			err := withLease.Run(context.Background())
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got %v", tc.expectedErr, err)
+			}
+			testhelper.Diff(t, "calls", calls, tc.expectedCalls)
+			if tc.expectedErr {
+				return
+			}
+			testhelper.Diff(t, "reported", reporter.reported, [][2]string{{"aws-quota-slice", tc.expectedType}})
+			parameters := withLease.Provides()
+			for env, expected := range map[string]string{
+				api.DefaultLeaseEnv:                   tc.expectedResource,
+				api.LeaseTypeEnv(api.DefaultLeaseEnv): tc.expectedType,
+			} {
+				actual, err := parameters[env]()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if actual != expected {
+					t.Errorf("got %q for %s, expected %q", actual, env, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestStepLeaseRegion(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		resources []string
+		expected  string
+	}{{
+		name:      "resource in a region",
+		resources: []string{"us-east-1--aws-2-quota-slice-07"},
+		expected:  "us-east-1",
+	}, {
+		name:      "resources in the same region",
+		resources: []string{"us-east-1--aws-2-quota-slice-07", "us-east-1--aws-2-quota-slice-08"},
+		expected:  "us-east-1",
+	}, {
+		name:      "resources in different regions",
+		resources: []string{"us-east-1--aws-2-quota-slice-07", "us-west-2--aws-2-quota-slice-00"},
+		expected:  "us-east-1 us-west-2",
+	}, {
+		name:      "resource not in a region",
+		resources: []string{"openstack-vexxhost-quota-slice-3"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := stepLease{resources: tc.resources}
+			if actual := l.region(); actual != tc.expected {
+				t.Errorf("expected region %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLeaseWithoutFallbackDoesNotProvideType(t *testing.T) {
+	leases := []api.StepLease{{Env: api.DefaultLeaseEnv, ResourceType: "rtype"}}
+	withLease := LeaseStep(nil, leases, &stepNeedsLease{}, emptyNamespace, nil)
+	if _, ok := withLease.Provides()[api.LeaseTypeEnv(api.DefaultLeaseEnv)]; ok {
+		t.Errorf("expected no %s parameter for a lease without fallbacks", api.LeaseTypeEnv(api.DefaultLeaseEnv))
+	}
+}
@@ -52,6 +52,9 @@ func (s *multiStageTestStep) generateObservers(
 type generatePodOptions struct {
 	IsObserver                  bool
 	enableSecretsStoreCSIDriver bool
+	// render leaves the images of dependencies unresolved, as they are only
+	// known once they exist in the cluster
+	render bool
 }
 
 func defaultGeneratePodOptions() *generatePodOptions {
@@ -86,6 +89,9 @@ func (s *multiStageTestStep) generatePods(
 			logrus.Infof("Skipping optional step %s", name)
 			continue
 		}
+		if step.Lifecycle != nil {
+			logrus.Warn(step.Lifecycle.Describe("step", step.As))
+		}
 		image := step.From
 		if link, ok := step.FromImageTag(); ok {
 			image = fmt.Sprintf("%s:%s", api.PipelineImageStream, link)
@@ -182,7 +188,13 @@ func (s *multiStageTestStep) generatePods(
 			}
 		}
 
-		addSecretWrapper(pod, s.vpnConf, !needsKubeConfig, genPodOpts)
+		if step.ParallelGroup != "" {
+			pod.Labels[ParallelGroupLabel] = step.ParallelGroup
+			if step.ParallelBranch != "" {
+				pod.Labels[ParallelBranchLabel] = step.ParallelBranch
+			}
+		}
+		addSecretWrapper(pod, s.vpnConf, !needsKubeConfig, s.sharedDirWrapperArgs(step), genPodOpts)
 		if s.vpnConf != nil {
 			s.addVPNClient(pod)
 		}
@@ -195,7 +207,7 @@ func (s *multiStageTestStep) generatePods(
 		}...)
 		container.Env = append(container.Env, env...)
 		container.Env = append(container.Env, s.generateParams(step.Environment)...)
-		depEnv, depErrs := s.envForDependencies(step)
+		depEnv, depErrs := s.envForDependencies(step, genPodOpts.render)
 		if len(depErrs) != 0 {
 			errs = append(errs, depErrs...)
 			continue
@@ -230,14 +242,18 @@ func (s *multiStageTestStep) generatePods(
 			addDshmVolume(shmSize, pod, container)
 		}
 		if s.profile != "" {
-			addProfile(s.profileSecretName(), s.profile, pod)
+			addProfile(s.profileSecretName(), s.clusterProfile(), pod)
 		}
 		if step.Cli != "" {
 			dependency := api.StepDependency{Name: fmt.Sprintf("%s:cli", api.ReleaseStreamFor(step.Cli))}
 			imagestream, _, _ := s.config.DependencyParts(dependency, claimRelease)
 			addCliInjector(imagestream, pod)
 		}
-		addSharedDirSecret(s.name, pod)
+		if s.sharedDirBackend() == api.SharedDirBackendPVC {
+			addSharedDirPVC(s.name, pod)
+		} else {
+			addSharedDirSecret(s.name, pod)
+		}
 		addCredentials(step.Credentials, pod, genPodOpts.enableSecretsStoreCSIDriver)
 		if step.RunAsScript != nil && *step.RunAsScript {
 			addCommandScript(commandConfigMapForTest(s.name), pod)
@@ -269,7 +285,23 @@ func isKubeconfigNeeded(step *api.LiteralTestStep, opts *generatePodOptions) boo
 	return needsKubeconfig || opts.IsObserver
 }
 
-func addSecretWrapper(pod *coreapi.Pod, vpnConf *vpnConf, skipKubeconfig bool, genPodOpts *generatePodOptions) {
+// sharedDirWrapperArgs returns the arguments which configure how the wrapper
+// propagates changes to the shared directory for a step.
+func (s *multiStageTestStep) sharedDirWrapperArgs(step api.LiteralTestStep) []string {
+	var ret []string
+	if backend := s.sharedDirBackend(); backend != api.SharedDirBackendSecret {
+		ret = append(ret, fmt.Sprintf("--shared-dir-backend=%s", backend))
+	}
+	if s.sharedDir != nil && s.sharedDir.SizeLimit != "" {
+		ret = append(ret, fmt.Sprintf("--shared-dir-size-limit=%s", s.sharedDir.SizeLimit))
+	}
+	if step.ParallelGroup != "" {
+		ret = append(ret, "--merge-shared-dir")
+	}
+	return ret
+}
+
+func addSecretWrapper(pod *coreapi.Pod, vpnConf *vpnConf, skipKubeconfig bool, sharedDirArgs []string, genPodOpts *generatePodOptions) {
 	volume := "entrypoint-wrapper"
 	dir := "/tmp/entrypoint-wrapper"
 	bin := filepath.Join(dir, "entrypoint-wrapper")
@@ -302,6 +334,7 @@ func addSecretWrapper(pod *coreapi.Pod, vpnConf *vpnConf, skipKubeconfig bool, g
 	if genPodOpts.IsObserver {
 		container.Args = append(container.Args, "--mode=observer")
 	}
+	container.Args = append(container.Args, sharedDirArgs...)
 	container.Args = append(container.Args, container.Command...)
 	container.Args = append(container.Args, args...)
 	container.Command = []string{bin}
@@ -392,7 +425,7 @@ func (s *multiStageTestStep) generateParams(env []api.StepParameter) []coreapi.E
 	return ret
 }
 
-func (s *multiStageTestStep) envForDependencies(step api.LiteralTestStep) ([]coreapi.EnvVar, []error) {
+func (s *multiStageTestStep) envForDependencies(step api.LiteralTestStep, render bool) ([]coreapi.EnvVar, []error) {
 	var env []coreapi.EnvVar
 	var errs []error
 	var claimRelease *api.ClaimRelease
@@ -405,8 +438,9 @@ func (s *multiStageTestStep) envForDependencies(step api.LiteralTestStep) ([]cor
 		// correctly as it could possibly point to an external registry that ci-operator will itself not have access to.
 		if dependency.PullSpec != "" {
 			ref = dependency.PullSpec
+		} else if imageStream, name, _ := s.config.DependencyParts(dependency, claimRelease); render {
+			ref = fmt.Sprintf("%s:%s", imageStream, name)
 		} else {
-			imageStream, name, _ := s.config.DependencyParts(dependency, claimRelease)
 			depRef, err := utils.ImageDigestFor(s.client, s.jobSpec.Namespace, imageStream, name)()
 			if err != nil {
 				errs = append(errs, fmt.Errorf("could not determine image pull spec for image %s on step %s", dependency.Name, step.As))
@@ -547,6 +581,23 @@ func addSharedDirSecret(secret string, pod *coreapi.Pod) {
 	})
 }
 
+func addSharedDirPVC(claim string, pod *coreapi.Pod) {
+	pod.Spec.Volumes = append(pod.Spec.Volumes, coreapi.Volume{
+		Name: claim,
+		VolumeSource: coreapi.VolumeSource{
+			PersistentVolumeClaim: &coreapi.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+		},
+	})
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, coreapi.VolumeMount{
+		Name:      claim,
+		MountPath: SecretMountPath,
+	})
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, coreapi.EnvVar{
+		Name:  SecretMountEnv,
+		Value: SecretMountPath,
+	})
+}
+
 func addCredentials(credentials []api.CredentialReference, pod *coreapi.Pod, useCSI bool) {
 	if useCSI {
 		for _, credential := range credentials {
@@ -13,7 +13,9 @@ import (
 	coreapi "k8s.io/api/core/v1"
 	rbacapi "k8s.io/api/rbac/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	csiapi "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	"sigs.k8s.io/yaml"
@@ -32,19 +34,89 @@ var (
 	GSMproject = "openshift-ci-secrets"
 )
 
-func (s *multiStageTestStep) createSharedDirSecret(ctx context.Context) error {
-	logrus.Debugf("Creating multi-stage test shared directory %q", s.name)
-	secret := &coreapi.Secret{ObjectMeta: meta.ObjectMeta{
+func (s *multiStageTestStep) sharedDirSecret() *coreapi.Secret {
+	return &coreapi.Secret{ObjectMeta: meta.ObjectMeta{
 		Namespace: s.jobSpec.Namespace(),
 		Name:      s.name,
 		Labels:    map[string]string{api.SkipCensoringLabel: "true"},
 	}}
+}
+
+func (s *multiStageTestStep) createSharedDirSecret(ctx context.Context) error {
+	logrus.Debugf("Creating multi-stage test shared directory %q", s.name)
+	secret := s.sharedDirSecret()
 	if err := s.client.Delete(ctx, secret); err != nil && !kerrors.IsNotFound(err) {
 		return fmt.Errorf("cannot delete shared directory %q: %w", s.name, err)
 	}
 	return s.client.Create(ctx, secret)
 }
 
+// sharedDirPVC is the volume claim backing the shared directory
+func (s *multiStageTestStep) sharedDirPVC() (*coreapi.PersistentVolumeClaim, error) {
+	size, err := resource.ParseQuantity(s.sharedDir.SizeLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shared directory size limit %q: %w", s.sharedDir.SizeLimit, err)
+	}
+	pvc := &coreapi.PersistentVolumeClaim{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: s.jobSpec.Namespace(),
+			Name:      s.name,
+			Labels:    map[string]string{MultiStageTestLabel: s.name},
+		},
+		Spec: coreapi.PersistentVolumeClaimSpec{
+			AccessModes: []coreapi.PersistentVolumeAccessMode{coreapi.ReadWriteMany},
+			Resources: coreapi.VolumeResourceRequirements{
+				Requests: coreapi.ResourceList{coreapi.ResourceStorage: size},
+			},
+		},
+	}
+	if s.sharedDir.StorageClass != "" {
+		pvc.Spec.StorageClassName = &s.sharedDir.StorageClass
+	}
+	if owner := s.jobSpec.Owner(); owner != nil {
+		pvc.OwnerReferences = append(pvc.OwnerReferences, *owner)
+	}
+	return pvc, nil
+}
+
+// sharedDirPVCDeletionTimeout is how long we wait for the volume claim left
+// behind by a previous execution to be deleted.
+const sharedDirPVCDeletionTimeout = 5 * time.Minute
+
+// createSharedDirPVC creates the volume claim which backs the shared directory
+// when the test is configured to use the `pvc` backend.  The claim outlives
+// the test and is removed along with the namespace.  A claim left behind by a
+// previous execution in the namespace is deleted first so its content is not
+// inherited.
+func (s *multiStageTestStep) createSharedDirPVC(ctx context.Context) error {
+	logrus.Debugf("Creating multi-stage test shared directory volume claim %q", s.name)
+	pvc, err := s.sharedDirPVC()
+	if err != nil {
+		return err
+	}
+	if err := s.client.Delete(ctx, pvc); err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete shared directory volume claim %q: %w", s.name, err)
+	}
+	// claims are protected by a finalizer while pods use them, so we need to
+	// wait for the deletion to finish before the claim can be created again
+	key := ctrlruntimeclient.ObjectKeyFromObject(pvc)
+	if err := wait.PollUntilContextTimeout(ctx, time.Second, sharedDirPVCDeletionTimeout, true, func(ctx context.Context) (bool, error) {
+		if err := s.client.Get(ctx, key, &coreapi.PersistentVolumeClaim{}); err != nil {
+			if kerrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		return false, nil
+	}); err != nil {
+		return fmt.Errorf("failed waiting for shared directory volume claim %q to be deleted: %w", s.name, err)
+	}
+	if err := s.client.Create(ctx, pvc); err != nil {
+		return fmt.Errorf("cannot create shared directory volume claim %q: %w", s.name, err)
+	}
+	return nil
+}
+
 func (s *multiStageTestStep) createCredentials(ctx context.Context) error {
 	logrus.Debugf("Creating multi-stage test credentials for %q", s.name)
 	toCreate := map[string]*coreapi.Secret{}
@@ -140,22 +212,26 @@ func getSecretString(name string) (string, error) {
 	return string(y), nil
 }
 
-func (s *multiStageTestStep) createCommandConfigMaps(ctx context.Context) error {
-	logrus.Debugf("Creating multi-stage test commands configmap for %q", s.name)
+func (s *multiStageTestStep) commandConfigMap() *coreapi.ConfigMap {
 	data := make(map[string]string)
 	for _, step := range append(s.pre, append(s.test, s.post...)...) {
 		data[step.As] = step.Commands
 	}
-	name := commandConfigMapForTest(s.name)
 	yes := true
-	commands := &coreapi.ConfigMap{
+	return &coreapi.ConfigMap{
 		ObjectMeta: meta.ObjectMeta{
-			Name:      name,
+			Name:      commandConfigMapForTest(s.name),
 			Namespace: s.jobSpec.Namespace(),
 		},
 		Data:      data,
 		Immutable: &yes,
 	}
+}
+
+func (s *multiStageTestStep) createCommandConfigMaps(ctx context.Context) error {
+	logrus.Debugf("Creating multi-stage test commands configmap for %q", s.name)
+	commands := s.commandConfigMap()
+	name := commands.Name
 	// delete old command configmap if it exists
 	if err := s.client.Delete(ctx, commands); err != nil && !kerrors.IsNotFound(err) {
 		return fmt.Errorf("could not delete command configmap %s: %w", name, err)
@@ -167,6 +243,17 @@ func (s *multiStageTestStep) createCommandConfigMaps(ctx context.Context) error
 }
 
 func (s *multiStageTestStep) setupRBAC(ctx context.Context) error {
+	sa, role, bindings := s.rbac()
+	if err := util.CreateRBACs(ctx, sa, role, bindings, s.client, 1*time.Second, 1*time.Minute); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// rbac are the objects granting the steps which need one access to the test
+// namespace through a kubeconfig
+func (s *multiStageTestStep) rbac() (*coreapi.ServiceAccount, *rbacapi.Role, []rbacapi.RoleBinding) {
 	labels := map[string]string{MultiStageTestLabel: s.name}
 	ns := s.jobSpec.Namespace()
 	m := meta.ObjectMeta{Namespace: ns, Name: s.name, Labels: labels}
@@ -222,11 +309,7 @@ func (s *multiStageTestStep) setupRBAC(ctx context.Context) error {
 			Subjects: subj,
 		})
 	}
-	if err := util.CreateRBACs(ctx, sa, role, bindings, s.client, 1*time.Second, 1*time.Minute); err != nil {
-		return err
-	}
-
-	return nil
+	return sa, role, bindings
 }
 
 // getNamespaceUID retrieves the base UID configured for the test namespace.
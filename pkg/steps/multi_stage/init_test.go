@@ -8,6 +8,7 @@ import (
 	"github.com/GoogleCloudPlatform/secrets-store-csi-driver-provider-gcp/config"
 	"github.com/google/go-cmp/cmp"
 
+	coreapi "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -172,6 +173,61 @@ func TestCreateSPCs(t *testing.T) {
 	}
 }
 
+func TestCreateSharedDirPVC(t *testing.T) {
+	stale := &coreapi.PersistentVolumeClaim{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace:   "test-ns",
+			Name:        "test",
+			Annotations: map[string]string{"stale": "true"},
+		},
+	}
+	for _, tc := range []struct {
+		name         string
+		existing     []ctrlruntimeclient.Object
+		storageClass string
+	}{{
+		name: "no claim",
+	}, {
+		name:         "claim with a storage class",
+		storageClass: "nfs",
+	}, {
+		name:     "claim left behind by a previous execution is recreated",
+		existing: []ctrlruntimeclient.Object{stale},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &testhelper_kube.FakePodClient{
+				FakePodExecutor: &testhelper_kube.FakePodExecutor{
+					LoggingClient: loggingclient.New(
+						fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.existing...).Build()),
+				},
+			}
+			step := &multiStageTestStep{
+				name:      "test",
+				jobSpec:   &api.JobSpec{},
+				client:    client,
+				sharedDir: &api.SharedDir{Backend: api.SharedDirBackendPVC, SizeLimit: "1Gi", StorageClass: tc.storageClass},
+			}
+			step.jobSpec.SetNamespace("test-ns")
+			if err := step.createSharedDirPVC(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			var pvc coreapi.PersistentVolumeClaim
+			if err := client.Get(context.TODO(), ctrlruntimeclient.ObjectKey{Namespace: "test-ns", Name: "test"}, &pvc); err != nil {
+				t.Fatal(err)
+			}
+			if _, inherited := pvc.Annotations["stale"]; inherited {
+				t.Error("the claim left behind by a previous execution was reused")
+			}
+			testhelper.Diff(t, "storage request", pvc.Spec.Resources.Requests.Storage().String(), "1Gi")
+			var storageClass string
+			if pvc.Spec.StorageClassName != nil {
+				storageClass = *pvc.Spec.StorageClassName
+			}
+			testhelper.Diff(t, "storage class", storageClass, tc.storageClass)
+		})
+	}
+}
+
 func TestGetSecretString(t *testing.T) {
 	name := "secret-name"
 
@@ -20,6 +20,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/junit"
 	"github.com/openshift/ci-tools/pkg/kubernetes"
 	"github.com/openshift/ci-tools/pkg/results"
+	base_steps "github.com/openshift/ci-tools/pkg/steps"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/steps/utils"
 )
@@ -59,6 +60,10 @@ const (
 const (
 	// MultiStageTestLabel is the label we use to mark a pod as part of a multi-stage test
 	MultiStageTestLabel = "ci.openshift.io/multi-stage-test"
+	// ParallelGroupLabel is the label we use to mark a pod as part of a parallel group of steps
+	ParallelGroupLabel = "ci.openshift.io/multi-stage-parallel-group"
+	// ParallelBranchLabel is the label we use to mark the branch of a parallel group a pod is part of
+	ParallelBranchLabel = "ci.openshift.io/multi-stage-parallel-branch"
 	// ClusterProfileMountPath is where we mount the cluster profile in a pod
 	ClusterProfileMountPath = "/var/run/secrets/ci.openshift.io/cluster-profile"
 	// SecretMountPath is where we mount the shared dir secret
@@ -88,7 +93,13 @@ type multiStageTestStep struct {
 	additionalSuffix string
 	nodeName         string
 	profile          api.ClusterProfile
-	config           *api.ReleaseBuildConfiguration
+	// fallbackProfiles are the profiles whose lease may be acquired in place of that of profile
+	fallbackProfiles []api.ClusterProfile
+	// deprecations describe the deprecated workflow and chains the test was resolved from
+	deprecations []string
+	// fallbackProfile is the fallback profile whose lease was acquired, if any
+	fallbackProfile api.ClusterProfile
+	config          *api.ReleaseBuildConfiguration
 	// params exposes getters for variables created by other steps
 	params                      api.Parameters
 	env                         api.TestEnvironment
@@ -105,7 +116,15 @@ type multiStageTestStep struct {
 	vpnConf                     *vpnConf
 	cancelObservers             func(context.CancelFunc)
 	nodeArchitecture            api.NodeArchitecture
+	sharedDir                   *api.SharedDir
 	enableSecretsStoreCSIDriver bool
+	// state records the progress of the test, completed holds the steps which
+	// completed in the execution being resumed.  Once a step has failed,
+	// stateFailed stops the progress from being recorded.
+	state       *base_steps.ExecutionStateStore
+	completed   sets.Set[string]
+	stateFailed bool
+	stateLock   sync.Mutex
 }
 
 func MultiStageTestStep(
@@ -148,6 +167,8 @@ func newMultiStageTestStep(
 		additionalSuffix:            targetAdditionalSuffix,
 		nodeName:                    nodeName,
 		profile:                     ms.ClusterProfile,
+		fallbackProfiles:            ms.FallbackClusterProfiles,
+		deprecations:                ms.Deprecations,
 		config:                      config,
 		params:                      params,
 		env:                         ms.Environment,
@@ -163,6 +184,7 @@ func newMultiStageTestStep(
 		subLock:                     &sync.Mutex{},
 		cancelObservers:             cancelObservers,
 		nodeArchitecture:            testConfig.NodeArchitecture,
+		sharedDir:                   ms.SharedDir,
 		enableSecretsStoreCSIDriver: enableSecretsStoreCSIDriver,
 	}
 }
@@ -172,9 +194,47 @@ func (s *multiStageTestStep) profileSecretName() string {
 	if s.additionalSuffix != "" {
 		name = strings.TrimSuffix(name, fmt.Sprintf("-%s", s.additionalSuffix))
 	}
+	if s.fallbackProfile != "" {
+		return name + "-cluster-profile-" + s.fallbackProfile.Name()
+	}
 	return name + "-cluster-profile"
 }
 
+// clusterProfile is the profile whose credentials are given to the steps
+func (s *multiStageTestStep) clusterProfile() api.ClusterProfile {
+	if s.fallbackProfile != "" {
+		return s.fallbackProfile
+	}
+	return s.profile
+}
+
+// resolveFallbackProfile determines which of the fallback profiles, if any,
+// had its lease acquired because none of the leases of the profile was free.
+func (s *multiStageTestStep) resolveFallbackProfile() error {
+	if len(s.fallbackProfiles) == 0 || s.params == nil {
+		return nil
+	}
+	leased, err := s.params.Get(api.LeaseTypeEnv(api.DefaultLeaseEnv))
+	if err != nil {
+		return fmt.Errorf("could not determine the type of the cluster profile lease: %w", err)
+	}
+	for _, fallback := range s.fallbackProfiles {
+		if fallback.LeaseType() == leased {
+			logrus.Infof("Using the credentials of cluster profile %s, whose lease was acquired in place of that of %s.", fallback, s.profile)
+			s.fallbackProfile = fallback
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *multiStageTestStep) sharedDirBackend() api.SharedDirBackend {
+	if s.sharedDir == nil || s.sharedDir.Backend == "" {
+		return api.SharedDirBackendSecret
+	}
+	return s.sharedDir.Backend
+}
+
 func (s *multiStageTestStep) Inputs() (api.InputDefinition, error) {
 	return nil, nil
 }
@@ -187,7 +247,13 @@ func (s *multiStageTestStep) Run(ctx context.Context) error {
 
 func (s *multiStageTestStep) run(ctx context.Context) error {
 	logrus.Infof("Running multi-stage test %s", s.name)
+	for _, deprecation := range s.deprecations {
+		logrus.Warn(deprecation)
+	}
 	if s.profile != "" {
+		if err := s.resolveFallbackProfile(); err != nil {
+			return err
+		}
 		if err := s.getProfileData(ctx); err != nil {
 			return err
 		}
@@ -196,7 +262,15 @@ func (s *multiStageTestStep) run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	if err := s.createSharedDirSecret(ctx); err != nil {
+	if s.sharedDirBackend() == api.SharedDirBackendPVC {
+		if err := s.createSharedDirPVC(ctx); err != nil {
+			return fmt.Errorf("failed to create persistent volume claim: %w", err)
+		}
+	} else if s.completed.Len() != 0 {
+		if err := s.restoreSharedDirSecret(ctx); err != nil {
+			return fmt.Errorf("failed to restore secret: %w", err)
+		}
+	} else if err := s.createSharedDirSecret(ctx); err != nil {
 		return fmt.Errorf("failed to create secret: %w", err)
 	}
 	if s.enableSecretsStoreCSIDriver {
@@ -386,6 +460,15 @@ func (s *multiStageTestStep) environment() ([]coreapi.EnvVar, error) {
 			return nil, err
 		}
 		ret = append(ret, coreapi.EnvVar{Name: l.Env, Value: val})
+		if len(l.FallbackResourceTypes) != 0 {
+			for _, env := range api.LeaseFallbackEnvs(l.Env) {
+				val, err := s.params.Get(env)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, coreapi.EnvVar{Name: env, Value: val})
+			}
+		}
 	}
 
 	for _, name := range []string{api.InitialReleaseName, api.LatestReleaseName} {
@@ -406,7 +489,7 @@ func (s *multiStageTestStep) environment() ([]coreapi.EnvVar, error) {
 			}
 			ret = append(ret, coreapi.EnvVar{Name: e, Value: val})
 		}
-		if s.profile == "aws" { //TODO(sgoeddel): only enabled for aws for now, later this will be configurable
+		if s.clusterProfile() == "aws" { //TODO(sgoeddel): only enabled for aws for now, later this will be configurable
 			val, err := s.params.Get(api.DefaultIPPoolLeaseEnv)
 			if err != nil {
 				return nil, err
@@ -334,6 +334,17 @@ func TestEnvironment(t *testing.T) {
 			leases:   []api.StepLease{{Env: "LEASE_ONE"}, {Env: "LEASE_TWO"}},
 			expected: []coreapi.EnvVar{{Name: "LEASE_ONE", Value: "ONE"}, {Name: "LEASE_TWO", Value: "TWO"}},
 		},
+		{
+			name:   "leased types and regions are exposed in environment for leases with fallbacks",
+			params: fakeStepParams{"LEASE_ONE": "us-east-1", "LEASE_ONE_TYPE": "aws-2-quota-slice", "LEASE_ONE_REGION": "us-east-1", "LEASE_TWO": "TWO"},
+			leases: []api.StepLease{{Env: "LEASE_ONE", FallbackResourceTypes: []string{"aws-2-quota-slice"}}, {Env: "LEASE_TWO"}},
+			expected: []coreapi.EnvVar{
+				{Name: "LEASE_ONE", Value: "us-east-1"},
+				{Name: "LEASE_ONE_TYPE", Value: "aws-2-quota-slice"},
+				{Name: "LEASE_ONE_REGION", Value: "us-east-1"},
+				{Name: "LEASE_TWO", Value: "TWO"},
+			},
+		},
 		{
 			name: "arbitrary variables are not exposed in environment",
 			params: fakeStepParams{
@@ -378,12 +389,49 @@ func TestEnvironment(t *testing.T) {
 	}
 }
 
+func TestResolveFallbackProfile(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		params   api.Parameters
+		expected api.ClusterProfile
+	}{
+		{
+			name:     "lease of the profile acquired",
+			params:   fakeStepParams{"LEASED_RESOURCE_TYPE": "aws-quota-slice"},
+			expected: api.ClusterProfileAWS,
+		},
+		{
+			name:     "lease of a fallback profile acquired",
+			params:   fakeStepParams{"LEASED_RESOURCE_TYPE": "aws-3-quota-slice"},
+			expected: api.ClusterProfileAWS3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			step := multiStageTestStep{
+				name:             "step",
+				profile:          api.ClusterProfileAWS,
+				fallbackProfiles: []api.ClusterProfile{api.ClusterProfileAWS2, api.ClusterProfileAWS3},
+				params:           tc.params,
+			}
+			if err := step.resolveFallbackProfile(); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, step.clusterProfile()); diff != "" {
+				t.Errorf("unexpected cluster profile, diff: %s", diff)
+			}
+		})
+	}
+}
+
 func TestProfileSecretName(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
 		name             string
 		stepName         string
 		additionalSuffix string
+		fallbackProfile  api.ClusterProfile
 		expected         string
 	}{
 		{
@@ -397,10 +445,16 @@ func TestProfileSecretName(t *testing.T) {
 			additionalSuffix: "0",
 			expected:         "step-cluster-profile",
 		},
+		{
+			name:            "fallback profile",
+			stepName:        "step",
+			fallbackProfile: api.ClusterProfileAWS2,
+			expected:        "step-cluster-profile-aws-2",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			step := multiStageTestStep{name: tc.stepName, additionalSuffix: tc.additionalSuffix}
+			step := multiStageTestStep{name: tc.stepName, additionalSuffix: tc.additionalSuffix, fallbackProfile: tc.fallbackProfile}
 			result := step.profileSecretName()
 			if diff := cmp.Diff(tc.expected, result); diff != "" {
 				t.Fatalf("result does not match expected, diff: %s", diff)
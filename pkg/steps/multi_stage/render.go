@@ -0,0 +1,62 @@
+package multi_stage
+
+import (
+	"context"
+
+	coreapi "k8s.io/api/core/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// Render renders the objects the test creates in the namespace and the pods of
+// its observers and steps, as they are created when the test succeeds.  Values
+// which are read from the cluster, like the cluster profile, the credentials
+// and the environment provided by leases, are not rendered.
+func (s *multiStageTestStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	var ret []ctrlruntimeclient.Object
+	if s.sharedDirBackend() == api.SharedDirBackendPVC {
+		pvc, err := s.sharedDirPVC()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, pvc)
+	} else {
+		ret = append(ret, s.sharedDirSecret())
+	}
+	ret = append(ret, s.commandConfigMap())
+	sa, role, bindings := s.rbac()
+	ret = append(ret, sa, role)
+	for i := range bindings {
+		ret = append(ret, &bindings[i])
+	}
+
+	var secretVolumes []coreapi.Volume
+	var secretVolumeMounts []coreapi.VolumeMount
+	if s.enableSecretsStoreCSIDriver {
+		secretVolumes, secretVolumeMounts = s.addCredentialsToCensoring(secretVolumes, secretVolumeMounts)
+	}
+	observers, err := s.generateObservers(s.observers, secretVolumes, secretVolumeMounts, &generatePodOptions{
+		IsObserver:                  true,
+		enableSecretsStoreCSIDriver: s.enableSecretsStoreCSIDriver,
+		render:                      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pods := observers
+	for _, phase := range [][]api.LiteralTestStep{s.pre, s.test, s.post} {
+		phasePods, _, err := s.generatePods(phase, nil, secretVolumes, secretVolumeMounts, &generatePodOptions{
+			enableSecretsStoreCSIDriver: s.enableSecretsStoreCSIDriver,
+			render:                      true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, phasePods...)
+	}
+	for i := range pods {
+		ret = append(ret, &pods[i])
+	}
+	return ret, nil
+}
@@ -0,0 +1,89 @@
+package multi_stage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	prowdapi "sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestRender(t *testing.T) {
+	config := api.ReleaseBuildConfiguration{
+		Tests: []api.TestStepConfiguration{{
+			As: "e2e",
+			MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+				Pre: []api.LiteralTestStep{{As: "setup", From: "src", Commands: "setup"}},
+				Test: []api.LiteralTestStep{{
+					As: "test", From: "src", Commands: "test",
+					Dependencies: []api.StepDependency{{Name: "operator", Env: "OPERATOR_IMAGE"}},
+				}},
+				Post:      []api.LiteralTestStep{{As: "teardown", From: "src", Commands: "teardown"}},
+				Observers: []api.Observer{{Name: "observer", From: "src", Commands: "observe"}},
+			},
+		}},
+	}
+	jobSpec := api.JobSpec{
+		JobSpec: prowdapi.JobSpec{
+			Job:       "job",
+			BuildID:   "build id",
+			ProwJobID: "prow job id",
+			Type:      "periodic",
+			DecorationConfig: &prowapi.DecorationConfig{
+				Timeout:     &prowapi.Duration{Duration: time.Minute},
+				GracePeriod: &prowapi.Duration{Duration: time.Second},
+				UtilityImages: &prowapi.UtilityImages{
+					Sidecar:    "sidecar",
+					Entrypoint: "entrypoint",
+				},
+			},
+		},
+	}
+	jobSpec.SetNamespace("ci-op-render")
+	// the step has no client, rendering must not access the cluster
+	step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil, "node-name", "", nil, false)
+
+	objects, err := step.Render(context.Background())
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	var rendered []string
+	var testPod *coreapi.Pod
+	for _, obj := range objects {
+		rendered = append(rendered, fmt.Sprintf("%T %s", obj, obj.GetName()))
+		if pod, ok := obj.(*coreapi.Pod); ok && pod.Name == "e2e-test" {
+			testPod = pod
+		}
+	}
+	expected := []string{
+		"*v1.Secret e2e",
+		"*v1.ConfigMap e2e-commands",
+		"*v1.ServiceAccount e2e",
+		"*v1.Role e2e",
+		"*v1.RoleBinding e2e",
+		"*v1.RoleBinding e2e-view",
+		"*v1.Pod e2e-observer",
+		"*v1.Pod e2e-setup",
+		"*v1.Pod e2e-test",
+		"*v1.Pod e2e-teardown",
+	}
+	if diff := cmp.Diff(expected, rendered); diff != "" {
+		t.Fatalf("unexpected rendered objects: %s", diff)
+	}
+	var dependency *coreapi.EnvVar
+	for i, env := range testPod.Spec.Containers[0].Env {
+		if env.Name == "OPERATOR_IMAGE" {
+			dependency = &testPod.Spec.Containers[0].Env[i]
+		}
+	}
+	if diff := cmp.Diff(&coreapi.EnvVar{Name: "OPERATOR_IMAGE", Value: "stable:operator"}, dependency); diff != "" {
+		t.Errorf("unexpected dependency: %s", diff)
+	}
+}
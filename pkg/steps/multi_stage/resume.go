@@ -0,0 +1,160 @@
+package multi_stage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	base_steps "github.com/openshift/ci-tools/pkg/steps"
+)
+
+// TrackState configures the test to record the steps which complete and, when
+// resuming, to skip the steps which precede the first one that did not
+// complete in the execution being resumed.  Post steps are executed when a pre
+// or test step fails and tear down what the pre steps set up, so steps can
+// only be skipped when the execution being resumed failed in a post step: in
+// every other case, the test is executed again from the start.  Volume claims
+// cannot be restored to the content they had when that step started, so tests
+// using the `pvc` backend cannot be resumed.
+func (s *multiStageTestStep) TrackState(state *base_steps.ExecutionStateStore) error {
+	s.state = state
+	completed := state.CompletedSubSteps(s.name)
+	s.completed = sets.New[string]()
+	for _, step := range append(append([]api.LiteralTestStep{}, s.pre...), s.test...) {
+		if !completed.Has(fmt.Sprintf("%s-%s", s.name, step.As)) {
+			if completed.Len() != 0 {
+				logrus.Infof("Test %s failed before its post steps in the execution being resumed, executing all of its steps again.", s.name)
+				state.ForgetSubSteps(s.name)
+			}
+			return nil
+		}
+	}
+	if s.sharedDirBackend() == api.SharedDirBackendPVC {
+		return fmt.Errorf("cannot resume test %s: its shared directory is backed by a volume claim, which cannot be restored", s.name)
+	}
+	for _, step := range append(append(append([]api.LiteralTestStep{}, s.pre...), s.test...), s.post...) {
+		name := fmt.Sprintf("%s-%s", s.name, step.As)
+		if !completed.Has(name) {
+			break
+		}
+		s.completed.Insert(name)
+	}
+	return nil
+}
+
+// sharedDirSnapshotName is the name of the secret which holds the content of
+// the shared directory before the first step which failed.
+func (s *multiStageTestStep) sharedDirSnapshotName() string {
+	return s.name + "-shared-dir-snapshot"
+}
+
+// skipCompleted removes the pods of steps which completed in the execution
+// being resumed, reporting them as skipped.
+func (s *multiStageTestStep) skipCompleted(pods []coreapi.Pod) []coreapi.Pod {
+	if s.completed.Len() == 0 {
+		return pods
+	}
+	var ret []coreapi.Pod
+	for _, pod := range pods {
+		if !s.completed.Has(pod.Name) {
+			ret = append(ret, pod)
+			continue
+		}
+		logrus.Infof("Skipping step %s, which completed in the execution being resumed.", pod.Name)
+		s.subLock.Lock()
+		s.subTests = append(s.subTests, &junit.TestCase{
+			Name:        fmt.Sprintf("%s - %s container test", s.Description(), pod.Name),
+			SkipMessage: &junit.SkipMessage{Message: "Step completed in the execution being resumed."},
+		})
+		s.subLock.Unlock()
+	}
+	return ret
+}
+
+// recordCompletion records the successful execution of a step.  When the
+// shared directory is backed by a secret, its content is saved first so it
+// can be restored if the execution is resumed.  Volume claims are not saved,
+// as tests using them cannot be resumed.  Nothing is recorded after a step
+// failed, so the saved content is the one the failed step started with.
+func (s *multiStageTestStep) recordCompletion(ctx context.Context, pod string) {
+	if s.state == nil {
+		return
+	}
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	if s.stateFailed {
+		return
+	}
+	if s.sharedDirBackend() == api.SharedDirBackendSecret {
+		if err := s.snapshotSharedDir(ctx); err != nil {
+			logrus.WithError(err).Warnf("Failed to save the shared directory after step %s.", pod)
+			return
+		}
+	}
+	if err := s.state.RecordSubStep(ctx, s.name, pod); err != nil {
+		logrus.WithError(err).Warnf("Failed to record the execution state of step %s.", pod)
+	}
+}
+
+// recordFailure stops the progress of the test from being recorded, as the
+// steps which follow a failed one cannot be skipped when resuming.
+func (s *multiStageTestStep) recordFailure() {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	s.stateFailed = true
+}
+
+func (s *multiStageTestStep) snapshotSharedDir(ctx context.Context) error {
+	var current coreapi.Secret
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: s.name}, &current); err != nil {
+		return fmt.Errorf("failed to get shared directory %q: %w", s.name, err)
+	}
+	snapshot := &coreapi.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: s.jobSpec.Namespace(),
+			Name:      s.sharedDirSnapshotName(),
+			Labels:    map[string]string{api.SkipCensoringLabel: "true"},
+		},
+		Data: current.Data,
+	}
+	if err := s.client.Create(ctx, snapshot); err == nil {
+		return nil
+	} else if !kerrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create shared directory snapshot: %w", err)
+	}
+	if err := s.client.Update(ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to update shared directory snapshot: %w", err)
+	}
+	return nil
+}
+
+// restoreSharedDirSecret recreates the shared directory with the content it
+// had before the step which failed in the execution being resumed.
+func (s *multiStageTestStep) restoreSharedDirSecret(ctx context.Context) error {
+	logrus.Debugf("Restoring multi-stage test shared directory %q", s.name)
+	var snapshot coreapi.Secret
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: s.sharedDirSnapshotName()}, &snapshot); err != nil {
+		return fmt.Errorf("failed to get shared directory snapshot: %w", err)
+	}
+	secret := &coreapi.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: s.jobSpec.Namespace(),
+			Name:      s.name,
+			Labels:    map[string]string{api.SkipCensoringLabel: "true"},
+		},
+		Data: snapshot.Data,
+	}
+	if err := s.client.Delete(ctx, secret); err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete shared directory %q: %w", s.name, err)
+	}
+	return s.client.Create(ctx, secret)
+}
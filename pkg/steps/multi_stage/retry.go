@@ -0,0 +1,195 @@
+package multi_stage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/results"
+	base_steps "github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+// retryPolicy returns the retry policy of the step executed by a pod, if any.
+func (s *multiStageTestStep) retryPolicy(pod string) *api.StepRetryPolicy {
+	for _, phase := range [][]api.LiteralTestStep{s.pre, s.test, s.post} {
+		for _, step := range phase {
+			if fmt.Sprintf("%s-%s", s.name, step.As) == pod {
+				return step.Retry
+			}
+		}
+	}
+	return nil
+}
+
+// runPodWithRetries executes a step pod, executing it again while it fails
+// for one of the reasons in its retry policy.  Each attempt is reported
+// separately, along with the reason it matched, and the error of the last
+// attempt is returned.  If the last attempt also failed for one of the
+// reasons in the policy, the error reports that reason.  The shared directory
+// is restored to the content it had before the first attempt when the step
+// is retried, so partial writes of a failed attempt are not carried over.
+func (s *multiStageTestStep) runPodWithRetries(ctx context.Context, pod *coreapi.Pod, policy *api.StepRetryPolicy) error {
+	if policy == nil {
+		return s.runPod(ctx, pod, base_steps.NewTestCaseNotifier(util.NopNotifier), util.WaitForPodFlag(0), 0)
+	}
+	var logPattern *regexp.Regexp
+	if policy.LogPattern != "" {
+		var err error
+		if logPattern, err = regexp.Compile(policy.LogPattern); err != nil {
+			return fmt.Errorf("invalid log pattern in the retry policy of step %s: %w", pod.Name, err)
+		}
+	}
+	sharedDir, err := s.sharedDirContent(ctx, pod)
+	if err != nil {
+		return err
+	}
+	var backoff time.Duration
+	if policy.Backoff != nil {
+		backoff = policy.Backoff.Duration
+	}
+	for attempt := 0; ; attempt++ {
+		err := s.runPod(ctx, pod.DeepCopy(), base_steps.NewTestCaseNotifier(util.NopNotifier), util.WaitForPodFlag(0), attempt)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		failed := &coreapi.Pod{}
+		if getErr := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}, failed); getErr != nil {
+			logrus.WithError(getErr).Warnf("Failed to get pod %s to determine whether it should be retried.", pod.Name)
+			return err
+		}
+		reason, retry := retryReason(failed, policy.Reasons, logPattern, func() string { return s.podLogs(ctx, failed) })
+		if !retry {
+			return err
+		}
+		if attempt == policy.Count {
+			s.reportRetryReason(pod.Name, attempt, fmt.Sprintf("Step failed (%s), no retries left.", reason))
+			return results.ForReason(retryResultsReason(reason)).ForError(err)
+		}
+		s.reportRetryReason(pod.Name, attempt, fmt.Sprintf("Step failed (%s), retrying (%d/%d).", reason, attempt+1, policy.Count))
+		logrus.Infof("Step %s failed (%s), retrying in %s (%d/%d).", pod.Name, reason, backoff, attempt+1, policy.Count)
+		if err := s.client.Delete(ctx, failed); err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pod %s before retrying: %w", pod.Name, err)
+		}
+		if err := util.WaitForPodDeletion(ctx, s.client, failed.Namespace, failed.Name, failed.UID); err != nil {
+			return fmt.Errorf("failed to wait for the deletion of pod %s before retrying: %w", pod.Name, err)
+		}
+		if err := s.restoreSharedDirContent(ctx, sharedDir); err != nil {
+			return fmt.Errorf("failed to restore the shared directory before retrying pod %s: %w", pod.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// sharedDirContent returns the content of the shared directory before a step
+// is executed, so it can be restored if the step is retried.  Volume claims
+// cannot be restored and steps in a parallel group share the directory with
+// the other steps in the group, whose changes cannot be told apart from those
+// of the failed attempt, so nil is returned in those cases.
+func (s *multiStageTestStep) sharedDirContent(ctx context.Context, pod *coreapi.Pod) (map[string][]byte, error) {
+	if s.sharedDirBackend() != api.SharedDirBackendSecret {
+		logrus.Debugf("The shared directory of step %s is backed by a volume claim, changes from failed attempts are kept when it is retried.", pod.Name)
+		return nil, nil
+	}
+	if pod.Labels[ParallelGroupLabel] != "" {
+		logrus.Debugf("Step %s is part of a parallel group, changes to the shared directory from failed attempts are kept when it is retried.", pod.Name)
+		return nil, nil
+	}
+	secret := &coreapi.Secret{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: s.name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get shared directory %q: %w", s.name, err)
+	}
+	ret := make(map[string][]byte, len(secret.Data))
+	for k, v := range secret.Data {
+		ret[k] = v
+	}
+	return ret, nil
+}
+
+// restoreSharedDirContent replaces the content of the shared directory with
+// the one returned by sharedDirContent.
+func (s *multiStageTestStep) restoreSharedDirContent(ctx context.Context, content map[string][]byte) error {
+	if content == nil {
+		return nil
+	}
+	secret := &coreapi.Secret{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: s.name}, secret); err != nil {
+		return fmt.Errorf("failed to get shared directory %q: %w", s.name, err)
+	}
+	secret.Data = content
+	return s.client.Update(ctx, secret)
+}
+
+// retryResultsReason is the reason of the error of a step which kept failing
+// for one of the reasons in its retry policy.
+func retryResultsReason(reason string) results.Reason {
+	return results.Reason("step_failed_" + reason)
+}
+
+// reportRetryReason adds the reason a step was retried to the failed test
+// cases of an attempt.
+func (s *multiStageTestStep) reportRetryReason(pod string, attempt int, message string) {
+	prefix := s.attemptPrefix(pod, attempt)
+	s.subLock.Lock()
+	defer s.subLock.Unlock()
+	for _, testCase := range s.subTests {
+		if testCase.FailureOutput != nil && strings.HasPrefix(testCase.Name, prefix) {
+			testCase.FailureOutput.Output += "\n" + message
+		}
+	}
+}
+
+// retryReason determines whether a failed pod qualifies for a retry, returning
+// the matching reason.  Logs are only retrieved if required.
+func retryReason(pod *coreapi.Pod, reasons []api.StepRetryReason, logPattern *regexp.Regexp, logs func() string) (string, bool) {
+	statuses := append(append([]coreapi.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, reason := range reasons {
+		switch reason {
+		case api.StepRetryReasonEvicted:
+			if pod.Status.Reason == "Evicted" {
+				return string(reason), true
+			}
+		case api.StepRetryReasonImagePull:
+			for _, status := range statuses {
+				if w := status.State.Waiting; w != nil && (w.Reason == "ErrImagePull" || w.Reason == "ImagePullBackOff") {
+					return string(reason), true
+				}
+			}
+		case api.StepRetryReasonOOMKilled:
+			for _, status := range statuses {
+				if t := status.State.Terminated; t != nil && t.Reason == "OOMKilled" {
+					return string(reason), true
+				}
+			}
+		}
+	}
+	if logPattern != nil && logPattern.MatchString(logs()) {
+		return "log_pattern", true
+	}
+	return "", false
+}
+
+// podLogs returns the logs of the test container of a pod, or an empty string
+// if they cannot be retrieved.
+func (s *multiStageTestStep) podLogs(ctx context.Context, pod *coreapi.Pod) string {
+	logs, err := s.client.GetLogs(pod.Namespace, pod.Name, &coreapi.PodLogOptions{Container: containerName}).DoRaw(ctx)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to get the logs of pod %s.", pod.Name)
+		return ""
+	}
+	return string(logs)
+}
@@ -0,0 +1,135 @@
+package multi_stage
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	testhelper_kube "github.com/openshift/ci-tools/pkg/testhelper/kubernetes"
+)
+
+func TestRetryReason(t *testing.T) {
+	all := &api.StepRetryPolicy{
+		Count:      1,
+		Reasons:    []api.StepRetryReason{api.StepRetryReasonEvicted, api.StepRetryReasonImagePull, api.StepRetryReasonOOMKilled},
+		LogPattern: "connection reset by peer",
+	}
+	for _, tc := range []struct {
+		name           string
+		pod            coreapi.PodStatus
+		policy         *api.StepRetryPolicy
+		logs           string
+		expectedReason string
+		expectedRetry  bool
+	}{{
+		name:   "failure without a known cause",
+		pod:    coreapi.PodStatus{Phase: coreapi.PodFailed},
+		policy: all,
+		logs:   "test failed",
+	}, {
+		name:           "evicted",
+		pod:            coreapi.PodStatus{Phase: coreapi.PodFailed, Reason: "Evicted"},
+		policy:         all,
+		expectedReason: "evicted",
+		expectedRetry:  true,
+	}, {
+		name: "image pull",
+		pod: coreapi.PodStatus{ContainerStatuses: []coreapi.ContainerStatus{{
+			State: coreapi.ContainerState{Waiting: &coreapi.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+		}}},
+		policy:         all,
+		expectedReason: "image_pull",
+		expectedRetry:  true,
+	}, {
+		name: "OOM in an init container",
+		pod: coreapi.PodStatus{InitContainerStatuses: []coreapi.ContainerStatus{{
+			State: coreapi.ContainerState{Terminated: &coreapi.ContainerStateTerminated{Reason: "OOMKilled"}},
+		}}},
+		policy:         all,
+		expectedReason: "oom_killed",
+		expectedRetry:  true,
+	}, {
+		name: "OOM not in the policy",
+		pod: coreapi.PodStatus{ContainerStatuses: []coreapi.ContainerStatus{{
+			State: coreapi.ContainerState{Terminated: &coreapi.ContainerStateTerminated{Reason: "OOMKilled"}},
+		}}},
+		policy: &api.StepRetryPolicy{Count: 1, Reasons: []api.StepRetryReason{api.StepRetryReasonEvicted}},
+	}, {
+		name:           "logs match",
+		pod:            coreapi.PodStatus{Phase: coreapi.PodFailed},
+		policy:         all,
+		logs:           "read tcp: connection reset by peer",
+		expectedReason: "log_pattern",
+		expectedRetry:  true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var logPattern *regexp.Regexp
+			if tc.policy.LogPattern != "" {
+				logPattern = regexp.MustCompile(tc.policy.LogPattern)
+			}
+			reason, retry := retryReason(&coreapi.Pod{Status: tc.pod}, tc.policy.Reasons, logPattern, func() string { return tc.logs })
+			if reason != tc.expectedReason || retry != tc.expectedRetry {
+				t.Errorf("expected (%q, %t), got (%q, %t)", tc.expectedReason, tc.expectedRetry, reason, retry)
+			}
+		})
+	}
+}
+
+func TestRestoreSharedDirContent(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		pod      *coreapi.Pod
+		expected map[string][]byte
+	}{{
+		name:     "partial writes are discarded",
+		pod:      &coreapi.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-step"}},
+		expected: map[string][]byte{"kubeconfig": []byte("before")},
+	}, {
+		name:     "partial writes are kept in a parallel group",
+		pod:      &coreapi.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-step", Labels: map[string]string{ParallelGroupLabel: "group"}}},
+		expected: map[string][]byte{"kubeconfig": []byte("after"), "partial": []byte("write")},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().WithObjects(&coreapi.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "test"},
+					Data:       map[string][]byte{"kubeconfig": []byte("before")},
+				}).Build()),
+			}
+			jobSpec := api.JobSpec{}
+			jobSpec.SetNamespace("ns")
+			step := &multiStageTestStep{name: "test", client: &testhelper_kube.FakePodClient{FakePodExecutor: crclient}, jobSpec: &jobSpec}
+			content, err := step.sharedDirContent(ctx, tc.pod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			secret := &coreapi.Secret{}
+			if err := crclient.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "test"}, secret); err != nil {
+				t.Fatal(err)
+			}
+			secret.Data = map[string][]byte{"kubeconfig": []byte("after"), "partial": []byte("write")}
+			if err := crclient.Update(ctx, secret); err != nil {
+				t.Fatal(err)
+			}
+			if err := step.restoreSharedDirContent(ctx, content); err != nil {
+				t.Fatal(err)
+			}
+			if err := crclient.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "test"}, secret); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, secret.Data); diff != "" {
+				t.Errorf("unexpected shared directory: %s", diff)
+			}
+		})
+	}
+}
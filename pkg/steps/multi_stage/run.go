@@ -40,6 +40,7 @@ func (s *multiStageTestStep) runSteps(
 		s.flags |= hasPrevErrs
 		return err
 	}
+	pods = s.skipCompleted(pods)
 	var errs []error
 	defer func() {
 		if len(errs) != 0 {
@@ -83,13 +84,14 @@ func (s *multiStageTestStep) runSteps(
 
 func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, bestEffortSteps sets.Set[string]) error {
 	var errs []error
-	for _, pod := range pods {
-		err := s.runPod(ctx, &pod, base_steps.NewTestCaseNotifier(util.NopNotifier), util.WaitForPodFlag(0))
-		if err == nil {
-			continue
+	for _, batch := range batchPods(pods) {
+		var err error
+		if group := batch[0].Labels[ParallelGroupLabel]; group != "" {
+			err = s.runParallelGroup(ctx, group, batch, bestEffortSteps)
+		} else {
+			err = s.runStepPod(ctx, &batch[0], bestEffortSteps)
 		}
-		if bestEffortSteps != nil && bestEffortSteps.Has(pod.Name) {
-			logrus.Infof("Pod %s is running in best-effort mode, ignoring the failure...", pod.Name)
+		if err == nil {
 			continue
 		}
 		errs = append(errs, err)
@@ -100,6 +102,96 @@ func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, be
 	return utilerrors.NewAggregate(errs)
 }
 
+// batchPods splits a list of pods into the units that are executed in
+// sequence: consecutive pods in the same parallel group form a single batch,
+// every other pod is a batch of its own.
+func batchPods(pods []coreapi.Pod) [][]coreapi.Pod {
+	var ret [][]coreapi.Pod
+	for i := 0; i < len(pods); {
+		j := i + 1
+		if group := pods[i].Labels[ParallelGroupLabel]; group != "" {
+			for j < len(pods) && pods[j].Labels[ParallelGroupLabel] == group {
+				j++
+			}
+		}
+		ret = append(ret, pods[i:j])
+		i = j
+	}
+	return ret
+}
+
+// parallelBranches splits the pods of a parallel group into its branches:
+// consecutive pods in the same branch form a single branch, every pod without
+// a branch is a branch of its own.
+func parallelBranches(pods []coreapi.Pod) [][]coreapi.Pod {
+	var ret [][]coreapi.Pod
+	for i := 0; i < len(pods); {
+		j := i + 1
+		if branch := pods[i].Labels[ParallelBranchLabel]; branch != "" {
+			for j < len(pods) && pods[j].Labels[ParallelBranchLabel] == branch {
+				j++
+			}
+		}
+		ret = append(ret, pods[i:j])
+		i = j
+	}
+	return ret
+}
+
+func (s *multiStageTestStep) runStepPod(ctx context.Context, pod *coreapi.Pod, bestEffortSteps sets.Set[string]) error {
+	err := s.runPodWithRetries(ctx, pod, s.retryPolicy(pod.Name))
+	if err != nil && bestEffortSteps != nil && bestEffortSteps.Has(pod.Name) {
+		logrus.Infof("Pod %s is running in best-effort mode, ignoring the failure...", pod.Name)
+		err = nil
+	}
+	if err == nil {
+		s.recordCompletion(ctx, pod.Name)
+	} else {
+		s.recordFailure()
+	}
+	return err
+}
+
+// runParallelGroup executes all branches of a parallel group concurrently
+// and waits for all of them to finish. The pods of a branch are executed in
+// order and a failure stops the branch, but does not interrupt the others.
+func (s *multiStageTestStep) runParallelGroup(ctx context.Context, group string, pods []coreapi.Pod, bestEffortSteps sets.Set[string]) error {
+	start := time.Now()
+	branches := parallelBranches(pods)
+	logrus.Infof("Running parallel group %s with %d steps in %d branches.", group, len(pods), len(branches))
+	errs := make([]error, len(branches))
+	var wg sync.WaitGroup
+	wg.Add(len(branches))
+	for i := range branches {
+		go func(i int) {
+			defer wg.Done()
+			for j := range branches[i] {
+				if errs[i] = s.runStepPod(ctx, &branches[i][j], bestEffortSteps); errs[i] != nil {
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	err := utilerrors.NewAggregate(errs)
+	duration := time.Since(start)
+	testCase := &junit.TestCase{
+		Name:      fmt.Sprintf("%s - parallel group %s", s.Description(), group),
+		Duration:  duration.Seconds(),
+		SystemOut: fmt.Sprintf("The collected steps of parallel group %s.", group),
+	}
+	verb := "succeeded"
+	if err != nil {
+		verb = "failed"
+		testCase.FailureOutput = &junit.FailureOutput{Output: err.Error()}
+	}
+	s.subLock.Lock()
+	s.subTests = append(s.subTests, testCase)
+	s.subLock.Unlock()
+	logrus.Infof("Parallel group %s %s after %s.", group, verb, duration.Truncate(time.Second))
+	return err
+}
+
 func (s *multiStageTestStep) runObservers(ctx, textCtx context.Context, pods []coreapi.Pod, done chan<- struct{}) {
 	wg := sync.WaitGroup{}
 	wg.Add(len(pods))
@@ -113,7 +205,7 @@ func (s *multiStageTestStep) runObservers(ctx, textCtx context.Context, pods []c
 			}
 		}(pod)
 		go func(p coreapi.Pod) {
-			err := s.runPod(textCtx, &p, base_steps.NewTestCaseNotifier(util.NopNotifier), util.Interruptible)
+			err := s.runPod(textCtx, &p, base_steps.NewTestCaseNotifier(util.NopNotifier), util.Interruptible, 0)
 			if ctx.Err() == nil {
 				// when the observer is cancelled, we get an error here that we need to ignore, as it's not an error
 				// for the Pod to be deleted when it's cancelled, it's just expected
@@ -134,7 +226,19 @@ func (s *multiStageTestStep) runObservers(ctx, textCtx context.Context, pods []c
 	done <- struct{}{}
 }
 
-func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notifier *base_steps.TestCaseNotifier, flags util.WaitForPodFlag) error {
+// attemptPrefix is the prefix of the names of the test cases of an attempt to
+// execute a step.
+func (s *multiStageTestStep) attemptPrefix(pod string, attempt int) string {
+	if attempt == 0 {
+		return fmt.Sprintf("%s - %s ", s.Description(), pod)
+	}
+	return fmt.Sprintf("%s - %s (retry %d) ", s.Description(), pod, attempt)
+}
+
+// runPod executes a pod and waits for its completion.  `attempt` is the
+// number of times the step has been retried, used to distinguish the test
+// cases of each execution.
+func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notifier *base_steps.TestCaseNotifier, flags util.WaitForPodFlag, attempt int) error {
 	start := time.Now()
 	logrus.Infof("Running step %s.", pod.Name)
 	client := s.client.WithNewLoggingClient()
@@ -162,7 +266,7 @@ func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notif
 		Failed:      utilpointer.Bool(err != nil),
 		Manifests:   client.Objects(),
 	})
-	s.subTests = append(s.subTests, notifier.SubTests(fmt.Sprintf("%s - %s ", s.Description(), pod.Name))...)
+	s.subTests = append(s.subTests, notifier.SubTests(s.attemptPrefix(pod.Name, attempt))...)
 	s.subLock.Unlock()
 	if err != nil {
 		linksText := strings.Builder{}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	prowdapi "sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/steps"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	testhelper_kube "github.com/openshift/ci-tools/pkg/testhelper/kubernetes"
@@ -277,6 +279,294 @@ func TestJUnit(t *testing.T) {
 	}
 }
 
+func TestRunRetries(t *testing.T) {
+	oomKilled := &api.StepRetryPolicy{Count: 2, Reasons: []api.StepRetryReason{api.StepRetryReasonOOMKilled}}
+	for _, tc := range []struct {
+		name           string
+		failureReason  string
+		expectedPods   int
+		expectedReason string
+		expected       []string
+	}{{
+		name:           "step failing for a retry reason is retried until retries are exhausted",
+		failureReason:  "OOMKilled",
+		expectedPods:   3,
+		expectedReason: "executing_multi_stage_test:step_failed_oom_killed",
+		expected: []string{
+			"Run multi-stage test test - test-test0 container test",
+			"Run multi-stage test test - test-test0 (retry 1) container test",
+			"Run multi-stage test test - test-test0 (retry 2) container test",
+		},
+	}, {
+		name:           "step failing for another reason is not retried",
+		failureReason:  "Error",
+		expectedPods:   1,
+		expectedReason: "executing_multi_stage_test",
+		expected: []string{
+			"Run multi-stage test test - test-test0 container test",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(
+					fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&v1.Pod{}, "metadata.name", fakePodNameIndexer).
+						WithObjects(sa).
+						Build()),
+				Failures:       sets.New[string]("test-test0"),
+				FailureReasons: map[string]string{"test-test0": tc.failureReason},
+			}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			client := &testhelper_kube.FakePodClient{FakePodExecutor: crclient}
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Test: []api.LiteralTestStep{{As: "test0", Retry: oomKilled}},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, "node-name", "", nil, false)
+			err := step.Run(context.Background())
+			if err == nil {
+				t.Fatal("expected the step to fail")
+			}
+			if diff := cmp.Diff([]string{tc.expectedReason}, results.Reasons(err)); diff != "" {
+				t.Errorf("unexpected reasons: %s", diff)
+			}
+			if n := len(crclient.CreatedPods); n != tc.expectedPods {
+				t.Errorf("expected %d attempts, got %d", tc.expectedPods, n)
+			}
+			var names []string
+			for _, testCase := range step.(steps.SubtestReporter).SubTests() {
+				if !strings.HasSuffix(testCase.Name, "container test") {
+					continue
+				}
+				names = append(names, testCase.Name)
+				if testCase.FailureOutput == nil {
+					t.Errorf("expected test case %s to fail", testCase.Name)
+				} else if tc.failureReason == "OOMKilled" && !strings.Contains(testCase.FailureOutput.Output, "Step failed (oom_killed)") {
+					t.Errorf("expected test case %s to report the retry reason, got: %s", testCase.Name, testCase.FailureOutput.Output)
+				}
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected test cases: %s", diff)
+			}
+		})
+	}
+}
+
+func TestBatchPods(t *testing.T) {
+	pod := func(name, group string) v1.Pod {
+		ret := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+		if group != "" {
+			ret.Labels[ParallelGroupLabel] = group
+		}
+		return ret
+	}
+	for _, tc := range []struct {
+		name     string
+		pods     []v1.Pod
+		expected [][]string
+	}{{
+		name: "no pods",
+	}, {
+		name:     "sequential pods",
+		pods:     []v1.Pod{pod("a", ""), pod("b", "")},
+		expected: [][]string{{"a"}, {"b"}},
+	}, {
+		name:     "parallel group",
+		pods:     []v1.Pod{pod("a", ""), pod("b", "g"), pod("c", "g"), pod("d", "")},
+		expected: [][]string{{"a"}, {"b", "c"}, {"d"}},
+	}, {
+		name:     "consecutive parallel groups",
+		pods:     []v1.Pod{pod("a", "g0"), pod("b", "g0"), pod("c", "g1"), pod("d", "g1")},
+		expected: [][]string{{"a", "b"}, {"c", "d"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var names [][]string
+			for _, batch := range batchPods(tc.pods) {
+				var l []string
+				for _, p := range batch {
+					l = append(l, p.Name)
+				}
+				names = append(names, l)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected batches: %s", diff)
+			}
+		})
+	}
+}
+
+func TestParallelBranches(t *testing.T) {
+	pod := func(name, branch string) v1.Pod {
+		ret := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{ParallelGroupLabel: "group"}}}
+		if branch != "" {
+			ret.Labels[ParallelBranchLabel] = branch
+		}
+		return ret
+	}
+	for _, tc := range []struct {
+		name     string
+		pods     []v1.Pod
+		expected [][]string
+	}{{
+		name:     "steps without a branch",
+		pods:     []v1.Pod{pod("a", ""), pod("b", "")},
+		expected: [][]string{{"a"}, {"b"}},
+	}, {
+		name:     "chain and single steps",
+		pods:     []v1.Pod{pod("a", "chain"), pod("b", "chain"), pod("c", "c"), pod("d", "d")},
+		expected: [][]string{{"a", "b"}, {"c"}, {"d"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var names [][]string
+			for _, branch := range parallelBranches(tc.pods) {
+				var l []string
+				for _, p := range branch {
+					l = append(l, p.Name)
+				}
+				names = append(names, l)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected branches: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRunParallelGroup(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		failures      sets.Set[string]
+		expectedPods  sets.Set[string]
+		expectedJUnit sets.Set[string]
+	}{{
+		name:         "all steps succeed",
+		expectedPods: sets.New[string]("test-pre0", "test-test0", "test-test1", "test-test2", "test-test3", "test-post0"),
+		expectedJUnit: sets.New[string](
+			"Run multi-stage test test - test-pre0 container test",
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test - test-test0 container test",
+			"Run multi-stage test test - test-test1 container test",
+			"Run multi-stage test test - test-test2 container test",
+			"Run multi-stage test test - parallel group group",
+			"Run multi-stage test test - test-test3 container test",
+			"Run multi-stage test test phase",
+			"Run multi-stage test test - test-post0 container test",
+			"Run multi-stage test post phase",
+		),
+	}, {
+		name:         "failure in a branch stops the branch but does not interrupt the group",
+		failures:     sets.New[string]("test-test0"),
+		expectedPods: sets.New[string]("test-pre0", "test-test0", "test-test2", "test-post0"),
+		expectedJUnit: sets.New[string](
+			"Run multi-stage test test - test-pre0 container test",
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test - test-test0 container test",
+			"Run multi-stage test test - test-test2 container test",
+			"Run multi-stage test test - parallel group group",
+			"Run multi-stage test test phase",
+			"Run multi-stage test test - test-post0 container test",
+			"Run multi-stage test post phase",
+		),
+	}, {
+		name:         "failure in a single-step branch does not interrupt the group",
+		failures:     sets.New[string]("test-test2"),
+		expectedPods: sets.New[string]("test-pre0", "test-test0", "test-test1", "test-test2", "test-post0"),
+		expectedJUnit: sets.New[string](
+			"Run multi-stage test test - test-pre0 container test",
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test - test-test0 container test",
+			"Run multi-stage test test - test-test1 container test",
+			"Run multi-stage test test - test-test2 container test",
+			"Run multi-stage test test - parallel group group",
+			"Run multi-stage test test phase",
+			"Run multi-stage test test - test-post0 container test",
+			"Run multi-stage test post phase",
+		),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(
+					fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&v1.Pod{}, "metadata.name", fakePodNameIndexer).
+						WithObjects(sa).
+						Build()),
+				Failures: tc.failures,
+			}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			client := &testhelper_kube.FakePodClient{FakePodExecutor: crclient}
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Pre: []api.LiteralTestStep{{As: "pre0"}},
+					Test: []api.LiteralTestStep{
+						{As: "test0", ParallelGroup: "group", ParallelBranch: "chain"},
+						{As: "test1", ParallelGroup: "group", ParallelBranch: "chain"},
+						{As: "test2", ParallelGroup: "group", ParallelBranch: "test2"},
+						{As: "test3"},
+					},
+					Post: []api.LiteralTestStep{{As: "post0"}},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, "node-name", "", nil, false)
+			if err := step.Run(context.Background()); (err != nil) != (tc.failures != nil) {
+				t.Errorf("expected error: %t, got error: %v", tc.failures != nil, err)
+			}
+			pods := sets.New[string]()
+			for _, pod := range crclient.CreatedPods {
+				pods.Insert(pod.Name)
+				_, inGroup := pod.Labels[ParallelGroupLabel]
+				if expected := pod.Name == "test-test0" || pod.Name == "test-test1" || pod.Name == "test-test2"; inGroup != expected {
+					t.Errorf("pod %s: expected parallel group label: %t", pod.Name, expected)
+				}
+			}
+			if diff := cmp.Diff(sets.List(tc.expectedPods), sets.List(pods)); diff != "" {
+				t.Errorf("did not execute correct pods: %s", diff)
+			}
+			junit := sets.New[string]()
+			for _, t := range step.(steps.SubtestReporter).SubTests() {
+				junit.Insert(t.Name)
+			}
+			if diff := cmp.Diff(sets.List(tc.expectedJUnit), sets.List(junit)); diff != "" {
+				t.Errorf("unexpected JUnit: %s", diff)
+			}
+		})
+	}
+}
+
 func fakePodNameIndexer(object ctrlruntimeclient.Object) []string {
 	p, ok := object.(*v1.Pod)
 	if !ok {
@@ -284,3 +574,142 @@ func fakePodNameIndexer(object ctrlruntimeclient.Object) []string {
 	}
 	return []string{p.Name}
 }
+
+func TestRunResume(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		failures  sets.Set[string]
+		expected  []string
+		resumable bool
+	}{{
+		name:     "failure in a test step executes the pre steps again, as the post steps tore them down",
+		failures: sets.New[string]("test-test1"),
+		expected: []string{"test-pre0", "test-test0", "test-test1", "test-post0", "test-post1"},
+	}, {
+		name:      "failure in a post step resumes from it",
+		failures:  sets.New[string]("test-post0"),
+		expected:  []string{"test-post0", "test-post1"},
+		resumable: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &v1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Name: "test", Namespace: "ns", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}},
+				ImagePullSecrets: []v1.LocalObjectReference{{Name: "ci-operator-dockercfg-12345"}},
+			}
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(
+					fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&v1.Pod{}, "metadata.name", fakePodNameIndexer).
+						WithObjects(sa).
+						Build()),
+				Failures: tc.failures,
+			}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("ns")
+			client := &testhelper_kube.FakePodClient{
+				PendingTimeout:  30 * time.Minute,
+				FakePodExecutor: crclient,
+			}
+			newStep := func() *multiStageTestStep {
+				return newMultiStageTestStep(api.TestStepConfiguration{
+					As: "test",
+					MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+						Pre:  []api.LiteralTestStep{{As: "pre0"}},
+						Test: []api.LiteralTestStep{{As: "test0"}, {As: "test1"}},
+						Post: []api.LiteralTestStep{{As: "post0"}, {As: "post1"}},
+					},
+				}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, "node-name", "", func(cf context.CancelFunc) {}, false)
+			}
+			step := newStep()
+			if err := step.TrackState(steps.NewExecutionStateStore(crclient, jobSpec.Namespace, "job")); err != nil {
+				t.Fatal(err)
+			}
+			if err := step.Run(context.Background()); err == nil {
+				t.Fatal("expected the first execution to fail")
+			}
+			executed := len(crclient.CreatedPods)
+			crclient.Failures = nil
+			state := steps.NewExecutionStateStore(crclient, jobSpec.Namespace, "job")
+			if err := state.Load(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			step = newStep()
+			if err := step.TrackState(state); err != nil {
+				t.Fatal(err)
+			}
+			if err := step.Run(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, pod := range crclient.CreatedPods[executed:] {
+				names = append(names, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected pods: %s", diff)
+			}
+			if !tc.resumable {
+				return
+			}
+			if err := state.Load(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			step = newStep()
+			step.sharedDir = &api.SharedDir{Backend: api.SharedDirBackendPVC, SizeLimit: "1Gi"}
+			if err := step.TrackState(state); err == nil {
+				t.Error("expected resuming a test with a volume claim shared directory to fail")
+			}
+		})
+	}
+}
+
+func TestRecordCompletionStopsAfterFailure(t *testing.T) {
+	crclient := &testhelper_kube.FakePodExecutor{
+		LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().Build()),
+	}
+	jobSpec := api.JobSpec{}
+	jobSpec.SetNamespace("ns")
+	if err := crclient.Create(context.Background(), &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "test"}, Data: map[string][]byte{"file": []byte("before")}}); err != nil {
+		t.Fatal(err)
+	}
+	state := steps.NewExecutionStateStore(crclient, jobSpec.Namespace, "job")
+	step := &multiStageTestStep{name: "test", client: &testhelper_kube.FakePodClient{FakePodExecutor: crclient}, jobSpec: &jobSpec, state: state}
+	step.recordCompletion(context.Background(), "test-pre0")
+	step.recordFailure()
+	secret := &v1.Secret{}
+	if err := crclient.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "test"}, secret); err != nil {
+		t.Fatal(err)
+	}
+	secret.Data["file"] = []byte("after")
+	if err := crclient.Update(context.Background(), secret); err != nil {
+		t.Fatal(err)
+	}
+	step.recordCompletion(context.Background(), "test-post0")
+	snapshot := &v1.Secret{}
+	if err := crclient.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "test-shared-dir-snapshot"}, snapshot); err != nil {
+		t.Fatalf("failed to get shared directory snapshot: %v", err)
+	}
+	if diff := cmp.Diff("before", string(snapshot.Data["file"])); diff != "" {
+		t.Errorf("expected the snapshot from before the failure: %s", diff)
+	}
+	if err := state.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"test-pre0"}, sets.List(state.CompletedSubSteps("test"))); diff != "" {
+		t.Errorf("unexpected recorded steps: %s", diff)
+	}
+}
@@ -43,12 +43,16 @@ func (s *pipelineImageCacheStep) Run(ctx context.Context) error {
 }
 
 func (s *pipelineImageCacheStep) run(ctx context.Context) error {
-	dockerfile := rawCommandDockerfile(s.config.From, s.config.Commands)
 	fromDigest, err := resolvePipelineImageStreamTagReference(ctx, s.client, s.config.From, s.jobSpec)
 	if err != nil {
 		return err
 	}
-	return handleBuilds(ctx, s.client, s.podClient, *buildFromSource(
+	return handleBuilds(ctx, s.client, s.podClient, *s.build(fromDigest), newImageBuildOptions(s.architectures.UnsortedList()))
+}
+
+func (s *pipelineImageCacheStep) build(fromDigest string) *buildapi.Build {
+	dockerfile := rawCommandDockerfile(s.config.From, s.config.Commands)
+	return buildFromSource(
 		s.jobSpec, s.config.From, s.config.To,
 		buildapi.BuildSource{
 			Type:       buildapi.BuildSourceDockerfile,
@@ -60,7 +64,11 @@ func (s *pipelineImageCacheStep) run(ctx context.Context) error {
 		s.pullSecret,
 		nil,
 		s.config.Ref,
-	), newImageBuildOptions(s.architectures.UnsortedList()))
+	)
+}
+
+func (s *pipelineImageCacheStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	return renderBuilds(*s.build(""), s.architectures), nil
 }
 
 func (s *pipelineImageCacheStep) Requires() []api.StepLink {
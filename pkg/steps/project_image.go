@@ -30,6 +30,10 @@ type projectDirectoryImageBuildStep struct {
 	pullSecret         *coreapi.Secret
 	multiArch          bool
 	architectures      sets.Set[string]
+	// reusableImages are tagged into the pipeline instead of building
+	// the image when they were built from the same content
+	reusableImages []api.ImageStreamTagReference
+	metadata       map[string]string
 }
 
 func (s *projectDirectoryImageBuildStep) Inputs() (api.InputDefinition, error) {
@@ -53,7 +57,29 @@ func (s *projectDirectoryImageBuildStep) run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	build := buildFromSource(
+	var contentDigest string
+	if len(s.reusableImages) > 0 && !s.config.IsBundleImage() && !api.IsIndexImage(string(s.config.To)) {
+		digest, avoided, err := s.avoidBuild(ctx, sourceTag)
+		if err != nil || avoided {
+			return err
+		}
+		contentDigest = digest
+	}
+	build := s.build(images, fromDigest)
+	if contentDigest != "" {
+		addContentDigestLabel(build, contentDigest)
+	}
+
+	// Bundle images are non multi-arch by design. No manifest list is needed. Here we spawn a single build.
+	if s.config.IsBundleImage() {
+		return handleBuild(ctx, s.client, s.podClient, *build)
+	}
+
+	return handleBuilds(ctx, s.client, s.podClient, *build, newImageBuildOptions(s.architectures.UnsortedList()))
+}
+
+func (s *projectDirectoryImageBuildStep) build(images []buildapi.ImageSource, fromDigest string) *buildapi.Build {
+	return buildFromSource(
 		s.jobSpec, s.config.From, s.config.To,
 		buildapi.BuildSource{
 			Type:       buildapi.BuildSourceImage,
@@ -67,13 +93,26 @@ func (s *projectDirectoryImageBuildStep) run(ctx context.Context) error {
 		s.config.BuildArgs,
 		s.config.Ref,
 	)
+}
 
-	// Bundle images are non multi-arch by design. No manifest list is needed. Here we spawn a single build.
+// renderedWorkingDir stands in for the working directory of the source image,
+// which is only known once the image has been built
+const renderedWorkingDir = "$(WORKING_DIR)"
+
+// Render renders the build of the image, which copies the context directory
+// from the working directory of the source image
+func (s *projectDirectoryImageBuildStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	_, images, err := imagesFor(s.config, func(string) (string, error) {
+		return renderedWorkingDir, nil
+	}, s.releaseBuildConfig.IsBundleImage)
+	if err != nil {
+		return nil, err
+	}
+	build := s.build(images, "")
 	if s.config.IsBundleImage() {
-		return handleBuild(ctx, s.client, s.podClient, *build)
+		return []ctrlruntimeclient.Object{build}, nil
 	}
-
-	return handleBuilds(ctx, s.client, s.podClient, *build, newImageBuildOptions(s.architectures.UnsortedList()))
+	return renderBuilds(*build, s.architectures), nil
 }
 
 type workingDir func(tag string) (string, error)
@@ -124,26 +163,35 @@ func getWorkingDir(client ctrlruntimeclient.Client, source, namespace string) (s
 	if err := client.Get(context.TODO(), ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: source}, ist); err != nil {
 		return "", fmt.Errorf("could not fetch source ImageStreamTag: %w", err)
 	}
+	metadata, err := dockerImageMetadata(context.TODO(), client, ist)
+	if err != nil {
+		return "", err
+	}
+	return metadata.Config.WorkingDir, nil
+}
+
+// dockerImageMetadata reads the metadata of the image an ImageStreamTag points to
+func dockerImageMetadata(ctx context.Context, client ctrlruntimeclient.Client, ist *imagev1.ImageStreamTag) (*docker10.DockerImage, error) {
 	image := ist.Image
 
 	// If the image contains a manifest list, the docker metadata are empty. Instead
 	// we need to grab the metadata from one of the images in manifest list.
 	if len(ist.Image.DockerImageManifests) > 0 {
 		img := &imagev1.Image{}
-		if err := client.Get(context.TODO(), ctrlruntimeclient.ObjectKey{Name: ist.Image.DockerImageManifests[0].Digest}, img); err != nil {
-			return "", fmt.Errorf("could not fetch source ImageStreamTag: %w", err)
+		if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Name: ist.Image.DockerImageManifests[0].Digest}, img); err != nil {
+			return nil, fmt.Errorf("could not fetch source ImageStreamTag: %w", err)
 		}
 		image = *img
 	}
 
 	metadata := &docker10.DockerImage{}
 	if len(image.DockerImageMetadata.Raw) == 0 {
-		return "", fmt.Errorf("could not fetch Docker image metadata for ImageStreamTag %s", source)
+		return nil, fmt.Errorf("could not fetch Docker image metadata for ImageStreamTag %s", ist.Name)
 	}
 	if err := json.Unmarshal(image.DockerImageMetadata.Raw, metadata); err != nil {
-		return "", fmt.Errorf("malformed Docker image metadata on ImageStreamTag: %w", err)
+		return nil, fmt.Errorf("malformed Docker image metadata on ImageStreamTag: %w", err)
 	}
-	return metadata.Config.WorkingDir, nil
+	return metadata, nil
 }
 
 func (s *projectDirectoryImageBuildStep) Requires() []api.StepLink {
@@ -196,6 +244,10 @@ func (s *projectDirectoryImageBuildStep) Objects() []ctrlruntimeclient.Object {
 	return s.client.Objects()
 }
 
+func (s *projectDirectoryImageBuildStep) Metadata() map[string]string {
+	return s.metadata
+}
+
 func (s *projectDirectoryImageBuildStep) ResolveMultiArch() sets.Set[string] {
 	s.architectures.Insert(string(api.NodeArchitectureAMD64))
 	s.architectures.Insert(s.config.AdditionalArchitectures...)
@@ -219,6 +271,7 @@ func ProjectDirectoryImageBuildStep(
 	podClient kubernetes.PodClient,
 	jobSpec *api.JobSpec,
 	pullSecret *coreapi.Secret,
+	reusableImages []api.ImageStreamTagReference,
 ) api.Step {
 	return &projectDirectoryImageBuildStep{
 		config:             config,
@@ -230,5 +283,6 @@ func ProjectDirectoryImageBuildStep(
 		pullSecret:         pullSecret,
 		multiArch:          config.MultiArch,
 		architectures:      sets.New[string](),
+		reusableImages:     reusableImages,
 	}
 }
@@ -3,6 +3,7 @@ package release
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
@@ -17,7 +18,6 @@ import (
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
-	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
@@ -27,6 +27,9 @@ import (
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/kubernetes"
 	"github.com/openshift/ci-tools/pkg/kubernetes/pkg/credentialprovider"
+	"github.com/openshift/ci-tools/pkg/promotion/gates"
+	"github.com/openshift/ci-tools/pkg/promotion/rollback"
+	"github.com/openshift/ci-tools/pkg/provenance"
 	"github.com/openshift/ci-tools/pkg/release/prerelease"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/steps"
@@ -45,6 +48,7 @@ type promotionStep struct {
 	mirrorFunc        func(source, target string, tag api.ImageStreamTagReference, date string, imageMirror map[string]string)
 	targetNameFunc    func(string, api.PromotionTarget) string
 	nodeArchitectures []string
+	provenance        *provenance.Options
 }
 
 func (s *promotionStep) Inputs() (api.InputDefinition, error) {
@@ -67,21 +71,81 @@ func mainRefs(refs *prowapi.Refs, extra []prowapi.Refs) *prowapi.Refs {
 	return nil
 }
 
-func (s *promotionStep) run(ctx context.Context) error {
+// defaultCLIVersion is the version of the image mirroring the images when the
+// latest stable version cannot be determined
+const defaultCLIVersion = "4.14"
+
+func (s *promotionStep) promotedTags(configuration *api.ReleaseBuildConfiguration) (map[string][]api.ImageStreamTagReference, sets.Set[string]) {
 	opts := []PromotedTagsOption{
 		WithRequiredImages(s.requiredImages),
 	}
-	logger := logrus.WithField("name", s.name)
-
 	if refs := mainRefs(s.jobSpec.Refs, s.jobSpec.ExtraRefs); refs != nil {
 		opts = append(opts, WithCommitSha(refs.BaseSHA))
 	}
+	return PromotedTagsWithRequiredImages(configuration, opts...)
+}
+
+// gatedConfiguration returns the configuration with the promotion targets whose gates
+// are not satisfied by the commit being promoted disabled
+func (s *promotionStep) gatedConfiguration(ctx context.Context, prowJobs gates.ProwJobLister, logger *logrus.Entry) (*api.ReleaseBuildConfiguration, error) {
+	var gated bool
+	for _, target := range api.PromotionTargets(s.configuration.PromotionConfiguration) {
+		gated = gated || (target.Gates != nil && !target.Disabled)
+	}
+	if !gated {
+		return s.configuration, nil
+	}
+	if prowJobs == nil {
+		return nil, errors.New("promotion is gated on tests, but their results cannot be determined")
+	}
+	postsubmits, err := prowJobs(ctx, s.configuration.Metadata.Org, s.configuration.Metadata.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the results of the tests promotion is gated on: %w", err)
+	}
+	var commit string
+	if refs := mainRefs(s.jobSpec.Refs, s.jobSpec.ExtraRefs); refs != nil {
+		commit = refs.BaseSHA
+	}
+	configuration := s.configuration.DeepCopy()
+	for i, target := range configuration.PromotionConfiguration.Targets {
+		if target.Disabled {
+			continue
+		}
+		if err := gates.Check(configuration.Metadata, target.Gates, commit, postsubmits); err != nil {
+			logger.WithError(err).Warnf("Not promoting to %s, as its gates are not satisfied.", s.targetNameFunc(s.registry, target))
+			configuration.PromotionConfiguration.Targets[i].Disabled = true
+		}
+	}
+	return configuration, nil
+}
+
+func (s *promotionStep) run(ctx context.Context) error {
+	logger := logrus.WithField("name", s.name)
 
-	tags, names := PromotedTagsWithRequiredImages(s.configuration, opts...)
+	// the registry override is used by the ci-chat-bot, which does not promote to app.ci
+	var appCIClient ctrlruntimeclient.Client
+	var prowJobs gates.ProwJobLister
+	if s.configuration.PromotionConfiguration.RegistryOverride == "" {
+		var err error
+		if appCIClient, err = s.appCIClient(); err != nil {
+			return err
+		}
+		prowJobs = gates.ClientProwJobLister(appCIClient, api.ProwJobNamespace)
+	}
+	configuration, err := s.gatedConfiguration(ctx, prowJobs, logger)
+	if err != nil {
+		return err
+	}
+	tags, names := s.promotedTags(configuration)
 	if len(names) == 0 {
 		logger.Info("Nothing to promote, skipping...")
 		return nil
 	}
+	if appCIClient != nil {
+		if tags, err = withoutRolledBackTags(ctx, appCIClient, tags, logger); err != nil {
+			return fmt.Errorf("could not determine the rolled back tags: %w", err)
+		}
+	}
 
 	logger.Infof("Promoting tags to %s: %s", s.targets(), strings.Join(sets.List(names), ", "))
 	pipeline := &imagev1.ImageStream{}
@@ -101,23 +165,53 @@ func (s *promotionStep) run(ctx context.Context) error {
 
 	// in some cases like when we are called by the ci-chat-bot we may need to create namespaces
 	// in general, we do not expect to be able to do this, so we only do it best-effort
-	if err := s.ensureNamespaces(ctx, namespaces); err != nil {
+	if err := s.ensureNamespaces(ctx, appCIClient, namespaces); err != nil {
 		logger.WithError(err).Warn("Failed to ensure namespaces to promote to in central registry.")
 	}
 
 	version, err := prerelease.Stable4LatestMajorMinor(&http.Client{})
 	if err != nil {
-		logrus.WithError(err).Warn("Failed to determine the sable release version, using 4.14 instead")
-		version = "4.14"
+		logrus.WithError(err).Warnf("Failed to determine the sable release version, using %s instead", defaultCLIVersion)
+		version = defaultCLIVersion
 	}
 
 	if _, err := steps.RunPod(ctx, s.client, getPromotionPod(imageMirrorTarget, timeStr, s.jobSpec.Namespace(), s.name, version, s.nodeArchitectures), false); err != nil {
 		return fmt.Errorf("unable to run promotion pod: %w", err)
 	}
+	if s.provenance != nil {
+		if err := s.attachProvenance(ctx, tags, pipeline, imageMirrorTarget); err != nil {
+			return fmt.Errorf("unable to attach provenance to the promoted images: %w", err)
+		}
+	}
 	return nil
 }
 
-func (s *promotionStep) ensureNamespaces(ctx context.Context, namespaces sets.Set[string]) error {
+// renderedTime stands in for the time of the promotion in the tags it creates
+const renderedTime = "YYYYMMDDhhmmss"
+
+// Render renders the pod mirroring the images, with the images referenced by
+// their tag in the pipeline image stream, as their digests are only known once
+// they have been built
+func (s *promotionStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	tags, names := s.promotedTags(s.configuration)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	pipeline := &imagev1.ImageStream{}
+	for _, tag := range sets.List(sets.KeySet(tags)) {
+		pipeline.Status.Tags = append(pipeline.Status.Tags, imagev1.NamedTagEventList{
+			Tag:   tag,
+			Items: []imagev1.TagEvent{{DockerImageReference: fmt.Sprintf("%s:%s", api.PipelineImageStream, tag)}},
+		})
+	}
+	imageMirrorTarget, _ := getImageMirrorTarget(tags, pipeline, s.registry, renderedTime, s.mirrorFunc)
+	if len(imageMirrorTarget) == 0 {
+		return nil, nil
+	}
+	return []ctrlruntimeclient.Object{getPromotionPod(imageMirrorTarget, renderedTime, s.jobSpec.Namespace(), s.name, defaultCLIVersion, s.nodeArchitectures)}, nil
+}
+
+func (s *promotionStep) ensureNamespaces(ctx context.Context, client ctrlruntimeclient.Client, namespaces sets.Set[string]) error {
 	if len(namespaces) == 0 {
 		return nil
 	}
@@ -126,27 +220,11 @@ func (s *promotionStep) ensureNamespaces(ctx context.Context, namespaces sets.Se
 	if s.configuration.PromotionConfiguration.RegistryOverride != "" {
 		return nil
 	}
-	var dockercfg credentialprovider.DockerConfigJSON
-	if err := json.Unmarshal(s.pushSecret.Data[coreapi.DockerConfigJsonKey], &dockercfg); err != nil {
-		return fmt.Errorf("failed to deserialize push secret: %w", err)
-	}
-
-	appCIDockercfg, hasAppCIDockercfg := dockercfg.Auths[api.ServiceDomainAPPCIRegistry]
-	if !hasAppCIDockercfg {
-		return fmt.Errorf("push secret has no entry for %s", api.ServiceDomainAPPCIRegistry)
-	}
-
-	appCIKubeconfig := &rest.Config{Host: api.APPCIKubeAPIURL, BearerToken: appCIDockercfg.Password}
-	client, err := corev1client.NewForConfig(appCIKubeconfig)
-	if err != nil {
-		return fmt.Errorf("failed to construct kubeconfig: %w", err)
-	}
-
 	for namespace := range namespaces {
 		var success bool
 		var errs []error
 		for i := 0; i < 3; i++ {
-			if _, err := client.Namespaces().Create(ctx, &coreapi.Namespace{ObjectMeta: meta.ObjectMeta{Name: namespace}}, meta.CreateOptions{}); err == nil || apierrors.IsAlreadyExists(err) {
+			if err := client.Create(ctx, &coreapi.Namespace{ObjectMeta: meta.ObjectMeta{Name: namespace}}); err == nil || apierrors.IsAlreadyExists(err) {
 				success = true
 				break
 			} else {
@@ -161,6 +239,63 @@ func (s *promotionStep) ensureNamespaces(ctx context.Context, namespaces sets.Se
 	return nil
 }
 
+// appCIClient returns a client for app.ci using the token in the push secret
+func (s *promotionStep) appCIClient() (ctrlruntimeclient.Client, error) {
+	var dockercfg credentialprovider.DockerConfigJSON
+	if err := json.Unmarshal(s.pushSecret.Data[coreapi.DockerConfigJsonKey], &dockercfg); err != nil {
+		return nil, fmt.Errorf("failed to deserialize push secret: %w", err)
+	}
+
+	appCIDockercfg, hasAppCIDockercfg := dockercfg.Auths[api.ServiceDomainAPPCIRegistry]
+	if !hasAppCIDockercfg {
+		return nil, fmt.Errorf("push secret has no entry for %s", api.ServiceDomainAPPCIRegistry)
+	}
+
+	appCIKubeconfig := &rest.Config{Host: api.APPCIKubeAPIURL, BearerToken: appCIDockercfg.Password}
+	client, err := ctrlruntimeclient.New(appCIKubeconfig, ctrlruntimeclient.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct app.ci client: %w", err)
+	}
+	return client, nil
+}
+
+// withoutRolledBackTags removes the tags which were rolled back from the tags to promote,
+// as nothing is promoted to them until their rollback is cleared. Only the image streams
+// labeled as having rollbacks are read.
+func withoutRolledBackTags(ctx context.Context, client ctrlruntimeclient.Reader, tags map[string][]api.ImageStreamTagReference, logger *logrus.Entry) (map[string][]api.ImageStreamTagReference, error) {
+	namespaces := sets.New[string]()
+	for _, dsts := range tags {
+		for _, dst := range dsts {
+			namespaces.Insert(dst.Namespace)
+		}
+	}
+	recordsByStream := map[string]map[string]rollback.Record{}
+	for _, namespace := range sets.List(namespaces) {
+		streams := &imagev1.ImageStreamList{}
+		if err := client.List(ctx, streams, ctrlruntimeclient.InNamespace(namespace), ctrlruntimeclient.HasLabels{rollback.Label}); err != nil {
+			return nil, fmt.Errorf("failed to list rolled back image streams in %s: %w", namespace, err)
+		}
+		for i := range streams.Items {
+			records, err := rollback.Records(&streams.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			recordsByStream[fmt.Sprintf("%s/%s", namespace, streams.Items[i].Name)] = records
+		}
+	}
+	filtered := map[string][]api.ImageStreamTagReference{}
+	for _, src := range sets.List(sets.KeySet(tags)) {
+		for _, dst := range tags[src] {
+			if _, rolledBack := recordsByStream[fmt.Sprintf("%s/%s", dst.Namespace, dst.Name)][dst.Tag]; rolledBack {
+				logger.Warnf("Not promoting to %s, as it was rolled back.", dst.ISTagName())
+				continue
+			}
+			filtered[src] = append(filtered[src], dst)
+		}
+	}
+	return filtered, nil
+}
+
 func getImageMirrorTarget(tags map[string][]api.ImageStreamTagReference, pipeline *imagev1.ImageStream, registry string, time string, mirrorFunc func(source, target string, tag api.ImageStreamTagReference, time string, imageMirror map[string]string)) (map[string]string, sets.Set[string]) {
 	if pipeline == nil {
 		return nil, nil
@@ -458,7 +593,9 @@ func (s *promotionStep) Objects() []ctrlruntimeclient.Object {
 }
 
 // PromotionStep copies tags from the pipeline image stream to the destination defined in the promotion config.
-// If the source tag does not exist it is silently skipped.
+// If the source tag does not exist it is silently skipped. When provenance options are given, the provenance
+// of the promoted images is attached to them in the registry. Targets with gates are only promoted to when the
+// postsubmit ProwJobs of the repository show the required tests passing. Tags which were rolled back are not promoted to.
 func PromotionStep(
 	name string,
 	configuration *api.ReleaseBuildConfiguration,
@@ -470,6 +607,7 @@ func PromotionStep(
 	mirrorFunc func(source, target string, tag api.ImageStreamTagReference, date string, imageMirror map[string]string),
 	targetNameFunc func(string, api.PromotionTarget) string,
 	nodeArchitectures []string,
+	provenance *provenance.Options,
 ) api.Step {
 	return &promotionStep{
 		name:              name,
@@ -482,5 +620,6 @@ func PromotionStep(
 		mirrorFunc:        mirrorFunc,
 		targetNameFunc:    targetNameFunc,
 		nodeArchitectures: nodeArchitectures,
+		provenance:        provenance,
 	}
 }
@@ -1,19 +1,29 @@
 package release
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
 
 	coreapi "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/diff"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
 
 	imageapi "github.com/openshift/api/image/v1"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/promotion/rollback"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
@@ -707,6 +717,102 @@ func TestGetPromotionPod(t *testing.T) {
 	}
 }
 
+func TestPromotionStepRender(t *testing.T) {
+	config := &api.ReleaseBuildConfiguration{
+		PromotionConfiguration: &api.PromotionConfiguration{
+			Targets: []api.PromotionTarget{{Namespace: "ocp", Name: "4.16"}},
+		},
+		Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "foo"}},
+	}
+	jobSpec := &api.JobSpec{}
+	jobSpec.SetNamespace("ci-op-render")
+	step := PromotionStep(api.PromotionStepName, config, sets.New[string](), jobSpec, nil, nil, "registry.ci.openshift.org", api.DefaultMirrorFunc, api.DefaultTargetNameFunc, nil, nil)
+	objects, err := step.(*promotionStep).Render(context.Background())
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("expected the promotion pod to be rendered, got %d objects", len(objects))
+	}
+	pod, ok := objects[0].(*coreapi.Pod)
+	if !ok {
+		t.Fatalf("expected a pod, got %T", objects[0])
+	}
+	if mirror := "pipeline:foo=registry.ci.openshift.org/ocp/4.16:foo"; !strings.Contains(pod.Spec.Containers[0].Args[0], mirror) {
+		t.Errorf("expected the pod to mirror %s, got %s", mirror, pod.Spec.Containers[0].Args[0])
+	}
+}
+
+func TestGatedConfiguration(t *testing.T) {
+	config := &api.ReleaseBuildConfiguration{
+		Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "main"},
+		PromotionConfiguration: &api.PromotionConfiguration{
+			Targets: []api.PromotionTarget{
+				{Namespace: "ocp", Name: "4.16"},
+				{Namespace: "ocp", Name: "gated", Gates: &api.PromotionGates{RequiredTests: []string{"e2e"}}},
+				{Namespace: "ocp", Name: "held-back", Gates: &api.PromotionGates{RequiredTests: []string{"e2e", "upgrade"}}},
+			},
+		},
+	}
+	prowJobs := func(_ context.Context, org, repo string) ([]prowapi.ProwJob, error) {
+		if org != "org" || repo != "repo" {
+			return nil, fmt.Errorf("unexpected repository %s/%s", org, repo)
+		}
+		return []prowapi.ProwJob{{
+			Spec:   prowapi.ProwJobSpec{Type: prowapi.PostsubmitJob, Job: "branch-ci-org-repo-main-e2e", Refs: &prowapi.Refs{BaseRef: "main", BaseSHA: "abc"}},
+			Status: prowapi.ProwJobStatus{State: prowapi.SuccessState, CompletionTime: &meta.Time{}},
+		}}, nil
+	}
+	jobSpec := &api.JobSpec{JobSpec: downwardapi.JobSpec{Refs: &prowapi.Refs{BaseRef: "main", BaseSHA: "abc"}}}
+	step := PromotionStep(api.PromotionStepName, config, sets.New[string](), jobSpec, nil, nil, "registry.ci.openshift.org", api.DefaultMirrorFunc, api.DefaultTargetNameFunc, nil, nil)
+	gated, err := step.(*promotionStep).gatedConfiguration(context.Background(), prowJobs, logrus.NewEntry(logrus.StandardLogger()))
+	if err != nil {
+		t.Fatalf("failed to check gates: %v", err)
+	}
+	var disabled []bool
+	for _, target := range gated.PromotionConfiguration.Targets {
+		disabled = append(disabled, target.Disabled)
+	}
+	if diff := cmp.Diff([]bool{false, false, true}, disabled); diff != "" {
+		t.Errorf("unexpected disabled targets: %s", diff)
+	}
+	if config.PromotionConfiguration.Targets[2].Disabled {
+		t.Error("the configuration of the step was modified")
+	}
+}
+
+func TestWithoutRolledBackTags(t *testing.T) {
+	tags := map[string][]api.ImageStreamTagReference{
+		"cli": {
+			{Namespace: "ocp", Name: "4.17", Tag: "cli"},
+			{Namespace: "ocp", Name: "cli", Tag: "abc"},
+		},
+		"tests": {{Namespace: "ocp", Name: "4.17", Tag: "tests"}},
+	}
+	stream := &imageapi.ImageStream{ObjectMeta: meta.ObjectMeta{
+		Namespace:   "ocp",
+		Name:        "4.17",
+		Labels:      map[string]string{rollback.Label: "true"},
+		Annotations: map[string]string{rollback.Annotation: `{"cli": {"digest": "sha256:good", "from": "sha256:bad", "time": "2024-06-01T00:00:00Z"}}`},
+	}}
+	scheme := runtime.NewScheme()
+	if err := imageapi.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fakectrlruntimeclient.NewClientBuilder().WithScheme(scheme).WithObjects(stream).Build()
+	filtered, err := withoutRolledBackTags(context.Background(), client, tags, logrus.NewEntry(logrus.StandardLogger()))
+	if err != nil {
+		t.Fatalf("failed to determine the rolled back tags: %v", err)
+	}
+	expected := map[string][]api.ImageStreamTagReference{
+		"cli":   {{Namespace: "ocp", Name: "cli", Tag: "abc"}},
+		"tests": {{Namespace: "ocp", Name: "4.17", Tag: "tests"}},
+	}
+	if diff := cmp.Diff(expected, filtered); diff != "" {
+		t.Errorf("unexpected tags: %s", diff)
+	}
+}
+
 func TestGetImageMirror(t *testing.T) {
 	var testCases = []struct {
 		name       string
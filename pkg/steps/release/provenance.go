@@ -0,0 +1,227 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/image/reference"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/provenance"
+	"github.com/openshift/ci-tools/pkg/steps"
+)
+
+// provenanceDir is where the envelopes are mounted in the pod attaching them
+const provenanceDir = "/etc/provenance"
+
+// provenanceAttachment is an envelope to attach to the promoted images
+type provenanceAttachment struct {
+	envelope []byte
+	// subjects are the promoted images, by the digest in each repository
+	subjects []string
+}
+
+// findImageDigest returns the digest of the image of a tag in the image stream
+func findImageDigest(is *imagev1.ImageStream, tag string) string {
+	for _, t := range is.Status.Tags {
+		if t.Tag == tag && len(t.Items) > 0 {
+			return t.Items[0].Image
+		}
+	}
+	return ""
+}
+
+// imageDependencies describes the images a promoted image is built from
+func (s *promotionStep) imageDependencies(pipeline *imagev1.ImageStream, tag string) []provenance.ResourceDescriptor {
+	var names []string
+	for _, image := range s.configuration.Images {
+		if string(image.To) != tag {
+			continue
+		}
+		if image.From != "" {
+			names = append(names, string(image.From))
+		}
+		for name := range image.Inputs {
+			names = append(names, name)
+		}
+	}
+	var dependencies []provenance.ResourceDescriptor
+	for _, name := range sets.List(sets.New[string](names...)) {
+		digest := findImageDigest(pipeline, name)
+		if digest == "" {
+			continue
+		}
+		dependency := provenance.ResourceDescriptor{
+			Name:   fmt.Sprintf("%s:%s", api.PipelineImageStream, name),
+			URI:    getPublicImageReference(findDockerImageReference(pipeline, name), pipeline.Status.PublicDockerImageRepository),
+			Digest: provenance.Digest(digest),
+		}
+		if base, ok := s.configuration.BaseImages[name]; ok {
+			dependency.Annotations = map[string]string{"base_image": base.ISTagName()}
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies
+}
+
+// provenanceAttachments describes how each promoted image was built, for the
+// description to be attached to the image in every repository it was promoted to
+func (s *promotionStep) provenanceAttachments(tags map[string][]api.ImageStreamTagReference, pipeline *imagev1.ImageStream, imageMirrorTarget map[string]string) ([]provenanceAttachment, error) {
+	configHash, err := provenance.ConfigHash(s.configuration)
+	if err != nil {
+		return nil, err
+	}
+	refs := provenance.Refs(&s.jobSpec.JobSpec)
+	jobURL := provenance.JobURL(&s.jobSpec.JobSpec)
+	var attachments []provenanceAttachment
+	for _, src := range sets.List(sets.KeySet(tags)) {
+		digest := findImageDigest(pipeline, src)
+		if digest == "" {
+			continue
+		}
+		source := getPublicImageReference(findDockerImageReference(pipeline, src), pipeline.Status.PublicDockerImageRepository)
+		statement := provenance.Statement{
+			Type:          provenance.StatementType,
+			PredicateType: provenance.PredicateType,
+			Predicate: provenance.Predicate{
+				BuildDefinition: provenance.BuildDefinition{
+					BuildType: provenance.BuildType,
+					ExternalParameters: provenance.ExternalParameters{
+						Job:        s.jobSpec.Job,
+						Refs:       refs,
+						ConfigHash: configHash,
+						Image:      src,
+					},
+					ResolvedDependencies: append(provenance.SourceDependencies(refs), s.imageDependencies(pipeline, src)...),
+				},
+				RunDetails: provenance.RunDetails{
+					Builder:  provenance.Builder{ID: provenance.BuilderID},
+					Metadata: provenance.BuildMetadata{InvocationID: jobURL},
+				},
+			},
+		}
+		subjects := sets.New[string]()
+		for _, target := range sets.List(sets.KeySet(imageMirrorTarget)) {
+			// targets mirrored from other targets, like the pruning tags, are not promoted images
+			if imageMirrorTarget[target] != source {
+				continue
+			}
+			statement.Subject = append(statement.Subject, provenance.ResourceDescriptor{Name: target, Digest: provenance.Digest(digest)})
+			ref, err := reference.Parse(target)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse promotion target %s: %w", target, err)
+			}
+			subjects.Insert(fmt.Sprintf("%s@%s", ref.AsRepository().Exact(), digest))
+		}
+		if len(statement.Subject) == 0 {
+			continue
+		}
+		envelope, err := provenance.Seal(statement, s.provenance.Signer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal the provenance of %s: %w", src, err)
+		}
+		attachments = append(attachments, provenanceAttachment{envelope: envelope, subjects: sets.List(subjects)})
+	}
+	return attachments, nil
+}
+
+// getProvenancePod returns the config map holding the envelopes and the pod attaching them to the promoted images
+func getProvenancePod(attachments []provenanceAttachment, namespace string, name string, image string) (*coreapi.ConfigMap, *coreapi.Pod) {
+	name = fmt.Sprintf("%s-provenance", name)
+	configMap := &coreapi.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string]string{},
+	}
+	registryConfig := fmt.Sprintf("%s/%s", api.RegistryPushCredentialsCICentralSecretMountPath, coreapi.DockerConfigJsonKey)
+	// the image of the tool may not provide bash, the retries are written for any shell
+	script := []string{
+		"set -e",
+		fmt.Sprintf(`attach() { for r in 1 2 3 4 5; do echo Attach attempt $r; oras attach --registry-config=%s --artifact-type=%s "$1" "$2:%s" && return 0; backoff=$(($(od -An -N2 -tu2 /dev/urandom) %% 60)); echo Sleeping randomized ${backoff}s before retry; sleep $backoff; done; return 1; }`,
+			registryConfig, provenance.ArtifactType, provenance.EnvelopeMediaType),
+	}
+	for i, attachment := range attachments {
+		key := fmt.Sprintf("%d.json", i)
+		configMap.Data[key] = string(attachment.envelope)
+		for _, subject := range attachment.subjects {
+			script = append(script, fmt.Sprintf("attach %s %s", subject, key))
+		}
+	}
+	pod := &coreapi.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{steps.AnnotationSaveContainerLogs: "true"},
+		},
+		Spec: coreapi.PodSpec{
+			RestartPolicy: coreapi.RestartPolicyNever,
+			Containers: []coreapi.Container{
+				{
+					Name:       "provenance",
+					Image:      image,
+					Command:    []string{"/bin/sh", "-c"},
+					Args:       []string{strings.Join(script, "\n")},
+					WorkingDir: provenanceDir,
+					VolumeMounts: []coreapi.VolumeMount{
+						{
+							Name:      "push-secret",
+							MountPath: api.RegistryPushCredentialsCICentralSecretMountPath,
+							ReadOnly:  true,
+						},
+						{
+							Name:      "provenance",
+							MountPath: provenanceDir,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+			Volumes: []coreapi.Volume{
+				{
+					Name: "push-secret",
+					VolumeSource: coreapi.VolumeSource{
+						Secret: &coreapi.SecretVolumeSource{SecretName: api.RegistryPushCredentialsCICentralSecret},
+					},
+				},
+				{
+					Name: "provenance",
+					VolumeSource: coreapi.VolumeSource{
+						ConfigMap: &coreapi.ConfigMapVolumeSource{LocalObjectReference: coreapi.LocalObjectReference{Name: name}},
+					},
+				},
+			},
+		},
+	}
+	return configMap, pod
+}
+
+// attachProvenance attaches the provenance of the promoted images to them in the registry
+func (s *promotionStep) attachProvenance(ctx context.Context, tags map[string][]api.ImageStreamTagReference, pipeline *imagev1.ImageStream, imageMirrorTarget map[string]string) error {
+	attachments, err := s.provenanceAttachments(tags, pipeline, imageMirrorTarget)
+	if err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return nil
+	}
+	configMap, pod := getProvenancePod(attachments, s.jobSpec.Namespace(), s.name, s.provenance.AttachImage)
+	if err := s.client.Create(ctx, configMap); err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create config map %s: %w", configMap.Name, err)
+		}
+		// left over from an earlier attempt, which sealed other envelopes
+		if err := s.client.Update(ctx, configMap); err != nil {
+			return fmt.Errorf("failed to update config map %s: %w", configMap.Name, err)
+		}
+	}
+	if _, err := steps.RunPod(ctx, s.client, pod, false); err != nil {
+		return fmt.Errorf("unable to run provenance pod: %w", err)
+	}
+	return nil
+}
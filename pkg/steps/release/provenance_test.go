@@ -0,0 +1,166 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
+
+	imageapi "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/provenance"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+	testhelper_kube "github.com/openshift/ci-tools/pkg/testhelper/kubernetes"
+)
+
+func TestProvenanceAttachments(t *testing.T) {
+	config := &api.ReleaseBuildConfiguration{
+		InputConfiguration: api.InputConfiguration{
+			BaseImages: map[string]api.ImageStreamTagReference{"base": {Namespace: "ocp", Name: "4.17", Tag: "base"}},
+		},
+		Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+			From: "base",
+			To:   "operator",
+			ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+				Inputs: map[string]api.ImageBuildInputs{"bin": {}},
+			},
+		}},
+		PromotionConfiguration: &api.PromotionConfiguration{
+			Targets: []api.PromotionTarget{{Namespace: "ocp", Name: "4.17"}},
+		},
+	}
+	configHash, err := provenance.ConfigHash(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc"}
+	jobSpec := &api.JobSpec{JobSpec: downwardapi.JobSpec{Job: "branch-ci-org-repo-main-images", BuildID: "1", Type: prowapi.PostsubmitJob, Refs: &refs}}
+	pipeline := &imageapi.ImageStream{
+		Status: imageapi.ImageStreamStatus{
+			Tags: []imageapi.NamedTagEventList{
+				{Tag: "base", Items: []imageapi.TagEvent{{Image: "sha256:base", DockerImageReference: "registry.build01.ci.openshift.org/ci-op-1/pipeline@sha256:base"}}},
+				{Tag: "bin", Items: []imageapi.TagEvent{{Image: "sha256:bin", DockerImageReference: "registry.build01.ci.openshift.org/ci-op-1/pipeline@sha256:bin"}}},
+				{Tag: "operator", Items: []imageapi.TagEvent{{Image: "sha256:operator", DockerImageReference: "registry.build01.ci.openshift.org/ci-op-1/pipeline@sha256:operator"}}},
+			},
+		},
+	}
+	step := &promotionStep{configuration: config, jobSpec: jobSpec, provenance: &provenance.Options{}}
+	tags := map[string][]api.ImageStreamTagReference{"operator": {{Namespace: "ocp", Name: "4.17", Tag: "operator"}}}
+	imageMirrorTarget := map[string]string{
+		"registry.ci.openshift.org/ocp/4.17:operator":                      "registry.build01.ci.openshift.org/ci-op-1/pipeline@sha256:operator",
+		"registry.ci.openshift.org/ocp/4.17:operator_20240603235401_prune": "registry.ci.openshift.org/ocp/4.17:operator",
+	}
+
+	attachments, err := step.provenanceAttachments(tags, pipeline, imageMirrorTarget)
+	if err != nil {
+		t.Fatalf("failed to describe provenance: %v", err)
+	}
+	if len(attachments) != 1 {
+		t.Fatalf("expected the provenance of one image, got %d", len(attachments))
+	}
+	if diff := cmp.Diff([]string{"registry.ci.openshift.org/ocp/4.17@sha256:operator"}, attachments[0].subjects); diff != "" {
+		t.Errorf("unexpected subjects: %s", diff)
+	}
+	statement, err := provenance.Verify(attachments[0].envelope, nil, "sha256:operator")
+	if err != nil {
+		t.Fatalf("failed to verify provenance: %v", err)
+	}
+	expected := &provenance.Statement{
+		Type:          provenance.StatementType,
+		Subject:       []provenance.ResourceDescriptor{{Name: "registry.ci.openshift.org/ocp/4.17:operator", Digest: map[string]string{"sha256": "operator"}}},
+		PredicateType: provenance.PredicateType,
+		Predicate: provenance.Predicate{
+			BuildDefinition: provenance.BuildDefinition{
+				BuildType: provenance.BuildType,
+				ExternalParameters: provenance.ExternalParameters{
+					Job:        "branch-ci-org-repo-main-images",
+					Refs:       []prowapi.Refs{refs},
+					ConfigHash: configHash,
+					Image:      "operator",
+				},
+				ResolvedDependencies: []provenance.ResourceDescriptor{
+					{Name: "main", URI: "git+https://github.com/org/repo@refs/heads/main", Digest: map[string]string{"gitCommit": "abc"}},
+					{
+						Name:        "pipeline:base",
+						URI:         "registry.build01.ci.openshift.org/ci-op-1/pipeline@sha256:base",
+						Digest:      map[string]string{"sha256": "base"},
+						Annotations: map[string]string{"base_image": "ocp/4.17:base"},
+					},
+					{Name: "pipeline:bin", URI: "registry.build01.ci.openshift.org/ci-op-1/pipeline@sha256:bin", Digest: map[string]string{"sha256": "bin"}},
+				},
+			},
+			RunDetails: provenance.RunDetails{Builder: provenance.Builder{ID: provenance.BuilderID}},
+		},
+	}
+	if diff := cmp.Diff(expected, statement); diff != "" {
+		t.Errorf("unexpected statement: %s", diff)
+	}
+}
+
+func TestGetProvenancePod(t *testing.T) {
+	attachments := []provenanceAttachment{
+		{envelope: []byte(`{"payloadType":"application/vnd.in-toto+json"}`), subjects: sets.List(sets.New[string]("registry.ci.openshift.org/ocp/4.17@sha256:operator", "registry.ci.openshift.org/ocp/4.18@sha256:operator"))},
+		{envelope: []byte(`{"payloadType":"application/vnd.in-toto+json"}`), subjects: []string{"registry.ci.openshift.org/ocp/4.17@sha256:cli"}},
+	}
+	configMap, pod := getProvenancePod(attachments, "ci-op-zyvwvffx", "promotion", provenance.DefaultAttachImage)
+	testhelper.CompareWithFixture(t, configMap, testhelper.WithPrefix("configmap-"))
+	testhelper.CompareWithFixture(t, pod, testhelper.WithPrefix("pod-"))
+}
+
+func TestAttachProvenanceReplacesLeftoverConfigMap(t *testing.T) {
+	leftover := &coreapi.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Namespace: "ci-op-1", Name: "promotion-provenance"},
+		Data:       map[string]string{"0.json": "stale"},
+	}
+	// the pod is not run, only the config map it mounts is of interest
+	client := loggingclient.New(fakectrlruntimeclient.NewClientBuilder().WithObjects(leftover).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, client ctrlruntimeclient.WithWatch, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+			if _, ok := obj.(*coreapi.Pod); ok {
+				return errors.New("injected")
+			}
+			return client.Create(ctx, obj, opts...)
+		},
+	}).Build())
+	jobSpec := &api.JobSpec{JobSpec: downwardapi.JobSpec{Job: "branch-ci-org-repo-main-images", BuildID: "1", Type: prowapi.PostsubmitJob, Refs: &prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc"}}}
+	jobSpec.SetNamespace("ci-op-1")
+	step := &promotionStep{
+		name:          "promotion",
+		configuration: &api.ReleaseBuildConfiguration{Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "operator"}}},
+		jobSpec:       jobSpec,
+		client:        &testhelper_kube.FakePodClient{FakePodExecutor: &testhelper_kube.FakePodExecutor{LoggingClient: client}},
+		provenance:    &provenance.Options{AttachImage: provenance.DefaultAttachImage},
+	}
+	pipeline := &imageapi.ImageStream{
+		Status: imageapi.ImageStreamStatus{
+			Tags: []imageapi.NamedTagEventList{
+				{Tag: "operator", Items: []imageapi.TagEvent{{Image: "sha256:operator", DockerImageReference: "registry.build01.ci.openshift.org/ci-op-1/pipeline@sha256:operator"}}},
+			},
+		},
+	}
+	tags := map[string][]api.ImageStreamTagReference{"operator": {{Namespace: "ocp", Name: "4.17", Tag: "operator"}}}
+	imageMirrorTarget := map[string]string{"registry.ci.openshift.org/ocp/4.17:operator": "registry.build01.ci.openshift.org/ci-op-1/pipeline@sha256:operator"}
+
+	if err := step.attachProvenance(context.Background(), tags, pipeline, imageMirrorTarget); err == nil || !strings.Contains(err.Error(), "unable to run provenance pod") {
+		t.Fatalf("expected to fail running the pod, got %v", err)
+	}
+	configMap := &coreapi.ConfigMap{}
+	if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ci-op-1", Name: "promotion-provenance"}, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provenance.Verify([]byte(configMap.Data["0.json"]), nil, "sha256:operator"); err != nil {
+		t.Errorf("expected the config map to hold the new envelope: %v", err)
+	}
+}
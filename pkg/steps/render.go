@@ -0,0 +1,98 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	buildapi "github.com/openshift/api/build/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// Renderer may be implemented by steps which submit objects to the cluster,
+// to render the objects without running the step, so they can be reviewed
+// offline.  Steps wrapping others forward the call to the wrapped step.  Values
+// which are only known once the inputs of the step exist in the cluster, like
+// image digests, are left empty.
+type Renderer interface {
+	Render(ctx context.Context) ([]ctrlruntimeclient.Object, error)
+}
+
+// renderWrapped forwards the rendering to a step which is wrapped by one that
+// acquires a resource for it, like a lease.  The resource itself is not
+// rendered, as it is not acquired through an object in the test namespace.
+func renderWrapped(ctx context.Context, wrapped api.Step) ([]ctrlruntimeclient.Object, error) {
+	if renderer, ok := wrapped.(Renderer); ok {
+		return renderer.Render(ctx)
+	}
+	return nil, nil
+}
+
+// renderBuilds renders the builds a step submits for each of the
+// architectures, as handleBuilds would
+func renderBuilds(build buildapi.Build, architectures sets.Set[string]) []ctrlruntimeclient.Object {
+	var ret []ctrlruntimeclient.Object
+	for _, b := range constructMultiArchBuilds(build, sets.List(architectures)) {
+		b := b
+		ret = append(ret, &b)
+	}
+	return ret
+}
+
+// WriteRenderedObjects renders the objects the steps submit into the
+// directory, in a sub-directory for each step holding a file for each object.
+// Steps which do not submit objects are skipped.
+func WriteRenderedObjects(ctx context.Context, dir string, steps []api.Step) error {
+	for _, step := range steps {
+		renderer, ok := step.(Renderer)
+		if !ok {
+			logrus.Debugf("Step %s does not render the objects it submits, skipping.", step.Name())
+			continue
+		}
+		objects, err := renderer.Render(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to render the objects of step %s: %w", step.Name(), err)
+		}
+		if len(objects) == 0 {
+			continue
+		}
+		stepDir := filepath.Join(dir, step.Name())
+		if err := os.MkdirAll(stepDir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory for step %s: %w", step.Name(), err)
+		}
+		for _, obj := range objects {
+			if err := writeRenderedObject(stepDir, obj); err != nil {
+				return fmt.Errorf("failed to write the objects of step %s: %w", step.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func writeRenderedObject(dir string, obj ctrlruntimeclient.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+	if err != nil {
+		return fmt.Errorf("failed to determine the kind of %s: %w", obj.GetName(), err)
+	}
+	// objects built in code carry no type information, which is needed to
+	// submit the rendered files
+	obj = obj.DeepCopyObject().(ctrlruntimeclient.Object)
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	raw, err := yaml.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+	name := fmt.Sprintf("%s-%s.yaml", strings.ToLower(gvk.Kind), obj.GetName())
+	return os.WriteFile(filepath.Join(dir, name), raw, 0644)
+}
@@ -0,0 +1,80 @@
+package steps
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
+	"sigs.k8s.io/yaml"
+
+	buildapi "github.com/openshift/api/build/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestWriteRenderedObjects(t *testing.T) {
+	jobSpec := &api.JobSpec{
+		JobSpec: downwardapi.JobSpec{
+			Job:       "job",
+			BuildID:   "buildId",
+			ProwJobID: "prowJobId",
+			Refs: &prowapi.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "master",
+				BaseSHA: "abc",
+			},
+		},
+	}
+	jobSpec.SetNamespace("ci-op-render")
+	cache := &pipelineImageCacheStep{
+		config: api.PipelineImageCacheStepConfiguration{
+			From:     api.PipelineImageStreamTagReferenceSource,
+			To:       "bin",
+			Commands: "make build",
+		},
+		jobSpec:       jobSpec,
+		architectures: sets.New[string]("amd64", "arm64"),
+	}
+	leases := []api.StepLease{{ResourceType: "aws-quota-slice", Env: "LEASED_RESOURCE", Count: 1}}
+	dir := t.TempDir()
+	if err := WriteRenderedObjects(context.Background(), dir, []api.Step{LeaseStep(nil, leases, cache, emptyNamespace, nil), &stepNeedsLease{}}); err != nil {
+		t.Fatalf("failed to render objects: %v", err)
+	}
+
+	var files []string
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, rel)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"bin/build-bin-amd64.yaml", "bin/build-bin-arm64.yaml"}, files); diff != "" {
+		t.Errorf("unexpected rendered files: %s", diff)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "bin", "build-bin-arm64.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := &buildapi.Build{}
+	if err := yaml.Unmarshal(raw, build); err != nil {
+		t.Fatalf("failed to parse rendered build: %v", err)
+	}
+	if build.APIVersion != "build.openshift.io/v1" || build.Kind != "Build" {
+		t.Errorf("expected the type of the build to be rendered, got %s %s", build.APIVersion, build.Kind)
+	}
+	if diff := cmp.Diff(buildapi.OptionalNodeSelector{"kubernetes.io/arch": "arm64"}, build.Spec.NodeSelector); diff != "" {
+		t.Errorf("unexpected node selector: %s", diff)
+	}
+}
@@ -0,0 +1,229 @@
+package steps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	// executionStatePrefix prefixes the name of the ConfigMap in the test
+	// namespace which records the progress of the execution.
+	executionStatePrefix = "ci-operator-execution-state"
+	// executionStateKey is the ConfigMap key which holds the serialized state.
+	executionStateKey = "state.json"
+)
+
+// ExecutionState is the progress of a ci-operator execution, persisted in the
+// test namespace so a failed execution can be resumed.
+type ExecutionState struct {
+	// Steps are the names of the graph steps which completed successfully.
+	Steps []string `json:"steps,omitempty"`
+	// SubSteps holds, for each step which reports them, the names of the
+	// sub-steps which completed successfully.
+	SubSteps map[string][]string `json:"sub_steps,omitempty"`
+	// Failed are the names of the graph steps which failed.
+	Failed []string `json:"failed,omitempty"`
+	// Leases holds, for each step which acquires leases, the values of the
+	// environment variables exposing the leased resources to its sub-steps.
+	Leases map[string]map[string]string `json:"leases,omitempty"`
+}
+
+// StateTracker may be implemented by steps composed of sub-steps which can be
+// skipped when a failed execution is resumed.  Steps wrapping others forward
+// the call to the wrapped step.  An error is returned when the step cannot be
+// resumed from the state of the previous execution.
+type StateTracker interface {
+	TrackState(state *ExecutionStateStore) error
+}
+
+// trackWrappedState forwards the state to a step which is wrapped by one that
+// acquires a resource for it, like a cluster claim.  The resource is acquired
+// again when the execution is resumed and the one the previous execution used
+// has been released along with the state on it, so sub-steps which completed
+// with it cannot be skipped.
+func trackWrappedState(resource string, wrapped api.Step, state *ExecutionStateStore) error {
+	if state.Completed(wrapped.Name()) {
+		return nil
+	}
+	if state.CompletedSubSteps(wrapped.Name()).Len() != 0 {
+		return fmt.Errorf("cannot resume step %s: steps which completed in the execution being resumed used a %s which was released", wrapped.Name(), resource)
+	}
+	if tracker, ok := wrapped.(StateTracker); ok {
+		return tracker.TrackState(state)
+	}
+	return nil
+}
+
+// ExecutionStateStore records the progress of the execution in the test
+// namespace.  When the state of a previous execution has been loaded, the
+// steps and sub-steps it completed are reported as such so they can be
+// skipped.  Namespaces are shared by executions with the same inputs, so the
+// state is stored separately for each job.
+type ExecutionStateStore struct {
+	client    ctrlruntimeclient.Client
+	namespace func() string
+	job       string
+
+	lock     sync.Mutex
+	state    ExecutionState
+	previous ExecutionState
+}
+
+// NewExecutionStateStore creates a store for the state of `job`, which
+// identifies the execution within the namespace.
+func NewExecutionStateStore(client ctrlruntimeclient.Client, namespace func() string, job string) *ExecutionStateStore {
+	return &ExecutionStateStore{client: client, namespace: namespace, job: job}
+}
+
+// name is the name of the ConfigMap holding the state of the job.
+func (s *ExecutionStateStore) name() string {
+	hash := sha256.Sum256([]byte(s.job))
+	return fmt.Sprintf("%s-%s", executionStatePrefix, hex.EncodeToString(hash[:])[:16])
+}
+
+// Load reads the state persisted by a previous execution.  Progress recorded
+// from this point on is added to it.
+func (s *ExecutionStateStore) Load(ctx context.Context) error {
+	cm := coreapi.ConfigMap{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.namespace(), Name: s.name()}, &cm); err != nil {
+		if kerrors.IsNotFound(err) {
+			return fmt.Errorf("no execution state found for %s in namespace %s", s.job, s.namespace())
+		}
+		return fmt.Errorf("failed to get execution state: %w", err)
+	}
+	var state ExecutionState
+	if err := json.Unmarshal([]byte(cm.Data[executionStateKey]), &state); err != nil {
+		return fmt.Errorf("failed to parse execution state: %w", err)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.previous = state
+	s.state = ExecutionState{Steps: state.Steps}
+	if len(state.SubSteps) != 0 {
+		s.state.SubSteps = make(map[string][]string, len(state.SubSteps))
+		for k, v := range state.SubSteps {
+			s.state.SubSteps[k] = v
+		}
+	}
+	if len(state.Leases) != 0 {
+		s.state.Leases = maps.Clone(state.Leases)
+	}
+	logrus.Infof("Resuming execution: %d steps completed previously, failed steps: %v", len(state.Steps), state.Failed)
+	return nil
+}
+
+// Completed determines whether a step completed in the previous execution.
+func (s *ExecutionStateStore) Completed(step string) bool {
+	if s == nil {
+		return false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return sets.New[string](s.previous.Steps...).Has(step)
+}
+
+// CompletedSubSteps returns the sub-steps of a step which completed in the
+// previous execution.
+func (s *ExecutionStateStore) CompletedSubSteps(step string) sets.Set[string] {
+	if s == nil {
+		return sets.New[string]()
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return sets.New[string](s.previous.SubSteps[step]...)
+}
+
+// ForgetSubSteps discards the sub-steps of a step which completed in the
+// previous execution, for when they have to be executed again.  The change is
+// persisted along with the next progress recorded.
+func (s *ExecutionStateStore) ForgetSubSteps(step string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.previous.SubSteps, step)
+	delete(s.state.SubSteps, step)
+}
+
+// PreviousLeases returns the leased resources the sub-steps of a step used in
+// the previous execution.
+func (s *ExecutionStateStore) PreviousLeases(step string) map[string]string {
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.previous.Leases[step]
+}
+
+// RecordLeases records the leased resources the sub-steps of a step use.
+func (s *ExecutionStateStore) RecordLeases(ctx context.Context, step string, leases map[string]string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.state.Leases == nil {
+		s.state.Leases = map[string]map[string]string{}
+	}
+	s.state.Leases[step] = leases
+	return s.save(ctx)
+}
+
+// RecordStep records the result of the execution of a graph step.
+func (s *ExecutionStateStore) RecordStep(ctx context.Context, step string, succeeded bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if succeeded {
+		s.state.Steps = sets.List(sets.New[string](s.state.Steps...).Insert(step))
+		s.state.Failed = sets.List(sets.New[string](s.state.Failed...).Delete(step))
+	} else {
+		s.state.Failed = sets.List(sets.New[string](s.state.Failed...).Insert(step))
+	}
+	return s.save(ctx)
+}
+
+// RecordSubStep records the successful completion of a sub-step.
+func (s *ExecutionStateStore) RecordSubStep(ctx context.Context, step, subStep string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.state.SubSteps == nil {
+		s.state.SubSteps = map[string][]string{}
+	}
+	s.state.SubSteps[step] = sets.List(sets.New[string](s.state.SubSteps[step]...).Insert(subStep))
+	return s.save(ctx)
+}
+
+// save persists the current state, the lock must be held by the caller.
+func (s *ExecutionStateStore) save(ctx context.Context) error {
+	raw, err := json.Marshal(s.state)
+	if err != nil {
+		return fmt.Errorf("failed to serialize execution state: %w", err)
+	}
+	cm := &coreapi.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Namespace: s.namespace(), Name: s.name()},
+		Data:       map[string]string{executionStateKey: string(raw)},
+	}
+	if err := s.client.Create(ctx, cm); err == nil {
+		return nil
+	} else if !kerrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create execution state: %w", err)
+	}
+	if err := s.client.Update(ctx, cm); err != nil {
+		return fmt.Errorf("failed to update execution state: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
+	utilpointer "k8s.io/utils/pointer"
+
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/junit"
 	"github.com/openshift/ci-tools/pkg/results"
@@ -17,9 +21,26 @@ type message struct {
 	err             error
 	additionalTests []*junit.TestCase
 	stepDetails     api.CIOperatorStepDetails
+	skipped         bool
 }
 
-func Run(ctx context.Context, graph api.StepGraph) (*junit.TestSuites, []api.CIOperatorStepDetails, []error) {
+// Run executes the graph.  If `state` is not nil, the progress of the
+// execution is recorded in it and steps which completed in the execution it
+// was loaded from are not executed again.
+func Run(ctx context.Context, graph api.StepGraph, state *ExecutionStateStore) (*junit.TestSuites, []api.CIOperatorStepDetails, []error) {
+	if state != nil {
+		var errs []error
+		graph.IterateAllEdges(func(node *api.StepNode) {
+			if tracker, ok := node.Step.(StateTracker); ok {
+				if err := tracker.TrackState(state); err != nil {
+					errs = append(errs, results.ForReason("resuming_execution").ForError(err))
+				}
+			}
+		})
+		if len(errs) != 0 {
+			return nil, nil, errs
+		}
+	}
 	var seen []api.StepLink
 	executionResults := make(chan message)
 	done := make(chan bool)
@@ -34,7 +55,7 @@ func Run(ctx context.Context, graph api.StepGraph) (*junit.TestSuites, []api.CIO
 
 	start := time.Now()
 	for _, root := range graph {
-		go runStep(ctx, root, executionResults)
+		go runOrSkipStep(ctx, root, state, executionResults)
 	}
 
 	suites := &junit.TestSuites{
@@ -56,6 +77,11 @@ func Run(ctx context.Context, graph api.StepGraph) (*junit.TestSuites, []api.CIO
 		case out := <-executionResults:
 			testCase := &junit.TestCase{Name: out.node.Step.Description(), Duration: out.duration.Seconds()}
 			stepDetails = append(stepDetails, out.stepDetails)
+			if state != nil && !out.skipped {
+				if err := state.RecordStep(ctx, out.node.Step.Name(), out.err == nil); err != nil {
+					logrus.WithError(err).Warnf("Failed to record the execution state of step %s.", out.node.Step.Name())
+				}
+			}
 			if out.err != nil {
 				testCase.FailureOutput = &junit.FailureOutput{Output: out.err.Error()}
 				executionErrors = append(executionErrors, results.ForReason("step_failed").WithError(out.err).Errorf("step %s failed: %v", out.node.Step.Name(), out.err))
@@ -70,7 +96,7 @@ func Run(ctx context.Context, graph api.StepGraph) (*junit.TestSuites, []api.CIO
 						// when the last of its parents finishes.
 						if api.HasAllLinks(child.Step.Requires(), seen) {
 							wg.Add(1)
-							go runStep(ctx, child, executionResults)
+							go runOrSkipStep(ctx, child, state, executionResults)
 						}
 					}
 				}
@@ -78,7 +104,10 @@ func Run(ctx context.Context, graph api.StepGraph) (*junit.TestSuites, []api.CIO
 
 			// append all reported tests cases
 			var testCases []*junit.TestCase
-			if len(out.additionalTests) > 0 {
+			if out.skipped {
+				testCase.SkipMessage = &junit.SkipMessage{Message: "Step completed in the execution being resumed."}
+				testCases = []*junit.TestCase{testCase}
+			} else if len(out.additionalTests) > 0 {
 				testCases = out.additionalTests
 			} else {
 				testCases = []*junit.TestCase{testCase}
@@ -116,6 +145,33 @@ type SubStepReporter interface {
 	SubSteps() []api.CIOperatorStepDetailInfo
 }
 
+// MetadataReporter may be implemented by steps that record details specific to
+// their type in the step graph.
+type MetadataReporter interface {
+	Metadata() map[string]string
+}
+
+// runOrSkipStep executes a step unless it completed in the execution being
+// resumed, in which case it is reported as skipped.
+func runOrSkipStep(ctx context.Context, node *api.StepNode, state *ExecutionStateStore, out chan<- message) {
+	if !state.Completed(node.Step.Name()) {
+		runStep(ctx, node, out)
+		return
+	}
+	logrus.Infof("Skipping step %s, which completed in the execution being resumed.", node.Step.Name())
+	out <- message{
+		node:    node,
+		skipped: true,
+		stepDetails: api.CIOperatorStepDetails{
+			CIOperatorStepDetailInfo: api.CIOperatorStepDetailInfo{
+				StepName:    node.Step.Name(),
+				Description: node.Step.Description(),
+				Failed:      utilpointer.Bool(false),
+			},
+		},
+	}
+}
+
 func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
 	start := time.Now()
 	err := node.Step.Run(ctx)
@@ -131,6 +187,10 @@ func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
 	if x, ok := node.Step.(SubStepReporter); ok {
 		subSteps = x.SubSteps()
 	}
+	var metadata map[string]string
+	if x, ok := node.Step.(MetadataReporter); ok {
+		metadata = x.Metadata()
+	}
 
 	out <- message{
 		node:            node,
@@ -146,6 +206,7 @@ func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
 				Duration:    &duration,
 				Manifests:   node.Step.Objects(),
 				Failed:      &failed,
+				Metadata:    metadata,
 			},
 			Substeps: subSteps,
 		},
@@ -11,7 +11,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	coreapi "k8s.io/api/core/v1"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/results"
@@ -290,7 +292,7 @@ func TestStepsRun(t *testing.T) {
 			if tc.cancelled {
 				cancel()
 			}
-			suites, _, errs := Run(ctx, api.BuildGraph(steps))
+			suites, _, errs := Run(ctx, api.BuildGraph(steps), nil)
 			if errs == nil && len(tc.errExpected) > 0 {
 				t.Error("got no error but expected one")
 			}
@@ -336,3 +338,96 @@ func TestStepsRun(t *testing.T) {
 		})
 	}
 }
+
+func TestStepsRunResume(t *testing.T) {
+	client := fakectrlruntimeclient.NewClientBuilder().Build()
+	namespace := func() string { return "ns" }
+	root := &fakeStep{name: "root", creates: []api.StepLink{api.InternalImageLink("root")}}
+	child := &fakeStep{name: "child", requires: []api.StepLink{api.InternalImageLink("root")}, runErr: errors.New("oopsie")}
+	steps := []api.Step{root, child}
+	if _, _, errs := Run(context.Background(), api.BuildGraph(steps), NewExecutionStateStore(client, namespace, "job")); len(errs) != 1 {
+		t.Fatalf("expected one error, got: %v", errs)
+	}
+	child.runErr = nil
+	state := NewExecutionStateStore(client, namespace, "job")
+	if err := state.Load(context.Background()); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	suites, _, errs := Run(context.Background(), api.BuildGraph(steps), state)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if root.numRuns != 1 || child.numRuns != 2 {
+		t.Errorf("unexpected executions: root %d, child %d", root.numRuns, child.numRuns)
+	}
+	if suite := suites.Suites[0]; suite.NumTests != 2 || suite.NumSkipped != 1 || suite.NumFailed != 0 {
+		t.Errorf("unexpected junit output: %#v", suite)
+	}
+	if err := NewExecutionStateStore(client, namespace, "other-job").Load(context.Background()); err == nil {
+		t.Error("expected no state to be found for another job")
+	}
+	var cm coreapi.ConfigMap
+	if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: state.name()}, &cm); err != nil {
+		t.Fatalf("failed to get state: %v", err)
+	}
+	if diff := cmp.Diff(`{"steps":["child","root"]}`, cm.Data[executionStateKey]); diff != "" {
+		t.Errorf("unexpected state: %s", diff)
+	}
+}
+
+type fakeTrackingStep struct {
+	fakeStep
+	tracked bool
+}
+
+func (f *fakeTrackingStep) TrackState(*ExecutionStateStore) error {
+	f.tracked = true
+	return nil
+}
+
+func TestTrackWrappedState(t *testing.T) {
+	client := fakectrlruntimeclient.NewClientBuilder().Build()
+	namespace := func() string { return "ns" }
+	previous := NewExecutionStateStore(client, namespace, "job")
+	if err := previous.RecordSubStep(context.Background(), "leased", "leased-pre0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := previous.RecordStep(context.Background(), "done", true); err != nil {
+		t.Fatal(err)
+	}
+	state := NewExecutionStateStore(client, namespace, "job")
+	if err := state.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name          string
+		step          string
+		expectedErr   string
+		expectTracked bool
+	}{{
+		name:          "no sub-steps completed",
+		step:          "fresh",
+		expectTracked: true,
+	}, {
+		name: "step completed",
+		step: "done",
+	}, {
+		name:        "sub-steps completed with a released resource",
+		step:        "leased",
+		expectedErr: "cannot resume step leased: steps which completed in the execution being resumed used a lease which was released",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			wrapped := &fakeTrackingStep{fakeStep: fakeStep{name: tc.step}}
+			var errStr string
+			if err := trackWrappedState("lease", wrapped, state); err != nil {
+				errStr = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedErr, errStr); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+			if wrapped.tracked != tc.expectTracked {
+				t.Errorf("expected tracked %t, got %t", tc.expectTracked, wrapped.tracked)
+			}
+		})
+	}
+}
@@ -199,6 +199,18 @@ func (s *sourceStep) run(ctx context.Context) error {
 	)
 }
 
+// Render renders the build cloning the source, with the clonerefs image
+// referenced by its tag rather than its digest
+func (s *sourceStep) Render(ctx context.Context) ([]ctrlruntimeclient.Object, error) {
+	clonerefsRef := corev1.ObjectReference{
+		Kind:      "ImageStreamTag",
+		Namespace: s.config.ClonerefsImage.Namespace,
+		Name:      fmt.Sprintf("%s:%s", s.config.ClonerefsImage.Name, s.config.ClonerefsImage.Tag),
+	}
+	build := createBuild(s.config, s.jobSpec, clonerefsRef, s.resources, s.cloneAuthConfig, s.pullSecret, "")
+	return renderBuilds(*build, s.architectures), nil
+}
+
 func createBuild(config api.SourceStepConfiguration, jobSpec *api.JobSpec, clonerefsRef corev1.ObjectReference, resources api.ResourceConfiguration, cloneAuthConfig *CloneAuthConfig, pullSecret *corev1.Secret, fromDigest string) *buildapi.Build {
 	var refs []prowv1.Refs
 	if jobSpec.Refs != nil {
@@ -24,9 +24,12 @@ import (
 
 type FakePodExecutor struct {
 	loggingclient.LoggingClient
-	Failures    sets.Set[string]
-	CreatedPods []*coreapi.Pod
-	lock        sync.Mutex
+	Failures sets.Set[string]
+	// FailureReasons optionally sets the termination reason of the
+	// containers of failed pods, by pod name.
+	FailureReasons map[string]string
+	CreatedPods    []*coreapi.Pod
+	lock           sync.Mutex
 }
 
 func (f *FakePodExecutor) Create(ctx context.Context, o ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
@@ -79,6 +82,7 @@ func (f *FakePodExecutor) process(pod *coreapi.Pod) {
 		terminated := &coreapi.ContainerStateTerminated{}
 		if fail {
 			terminated.ExitCode = 1
+			terminated.Reason = f.FailureReasons[pod.Name]
 		}
 		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, coreapi.ContainerStatus{
 			Name:  container.Name,
@@ -185,6 +185,7 @@ func (v *Validator) validateConfiguration(ctx *configContext, config *api.Releas
 				len(api.ImageTargets(config)) > 0,
 				config.ReleaseTagConfiguration,
 				config.Releases)...)
+		validationErrors = append(validationErrors, validatePromotionGates("promotion", *config.PromotionConfiguration, config.Tests)...)
 	}
 
 	validationErrors = append(validationErrors, validateReleases("releases", config.Releases, config.ReleaseTagConfiguration != nil)...)
@@ -521,6 +522,51 @@ func validatePromotionConfiguration(fieldRoot string, input api.PromotionConfigu
 	return validationErrors
 }
 
+// validatePromotionGates ensures the gates of the promotion targets require postsubmit tests of the configuration
+func validatePromotionGates(fieldRoot string, input api.PromotionConfiguration, tests []api.TestStepConfiguration) []error {
+	var validationErrors []error
+	postsubmits := sets.New[string]()
+	for _, test := range tests {
+		if test.Postsubmit {
+			postsubmits.Insert(test.As)
+		}
+	}
+	for i, target := range api.PromotionTargets(&input) {
+		if target.Gates == nil {
+			continue
+		}
+		gatesRoot := fmt.Sprintf("%s.to[%d].gates", fieldRoot, i)
+		if len(target.Gates.RequiredTests) == 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.required_tests: at least one test is required", gatesRoot))
+		}
+		seen := sets.New[string]()
+		for j, test := range target.Gates.RequiredTests {
+			if seen.Has(test) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.required_tests[%d]: duplicate test %q", gatesRoot, j, test))
+			}
+			seen.Insert(test)
+			if !postsubmits.Has(test) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.required_tests[%d]: %q is not a postsubmit test in this configuration", gatesRoot, j, test))
+			}
+		}
+		if target.Gates.MinimumPasses < 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.minimum_passes: must not be negative", gatesRoot))
+		}
+		if target.Gates.RecentCommits < 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.recent_commits: must not be negative", gatesRoot))
+		}
+		if target.Gates.MinimumPasses > 0 && target.Gates.RecentCommits == 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.recent_commits: required with minimum_passes", gatesRoot))
+		} else if target.Gates.MinimumPasses > 0 && target.Gates.RecentCommits < target.Gates.MinimumPasses {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.recent_commits: must be at least minimum_passes (%d)", gatesRoot, target.Gates.MinimumPasses))
+		}
+		if target.Gates.MinimumPasses == 0 && target.Gates.RecentCommits != 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.recent_commits: only valid with minimum_passes", gatesRoot))
+		}
+	}
+	return validationErrors
+}
+
 func validateReleaseTagConfiguration(fieldRoot string, input api.ReleaseTagConfiguration) []error {
 	var validationErrors []error
 
@@ -492,6 +492,72 @@ func TestValidatePromotion(t *testing.T) {
 	}
 }
 
+func TestValidatePromotionGates(t *testing.T) {
+	tests := []api.TestStepConfiguration{{As: "e2e", Postsubmit: true}, {As: "unit"}}
+	var testCases = []struct {
+		name     string
+		gates    *api.PromotionGates
+		expected []error
+	}{
+		{
+			name: "no gates",
+		},
+		{
+			name:  "gates on a postsubmit test are valid",
+			gates: &api.PromotionGates{RequiredTests: []string{"e2e"}},
+		},
+		{
+			name:  "gates with a minimum of passes on recent commits are valid",
+			gates: &api.PromotionGates{RequiredTests: []string{"e2e"}, MinimumPasses: 2, RecentCommits: 3},
+		},
+		{
+			name:     "gates without tests are invalid",
+			gates:    &api.PromotionGates{},
+			expected: []error{errors.New("promotion.to[0].gates.required_tests: at least one test is required")},
+		},
+		{
+			name:  "gates on tests which are not postsubmits are invalid",
+			gates: &api.PromotionGates{RequiredTests: []string{"e2e", "unit", "missing", "e2e"}},
+			expected: []error{
+				errors.New(`promotion.to[0].gates.required_tests[1]: "unit" is not a postsubmit test in this configuration`),
+				errors.New(`promotion.to[0].gates.required_tests[2]: "missing" is not a postsubmit test in this configuration`),
+				errors.New(`promotion.to[0].gates.required_tests[3]: duplicate test "e2e"`),
+			},
+		},
+		{
+			name:  "negative numbers are invalid",
+			gates: &api.PromotionGates{RequiredTests: []string{"e2e"}, MinimumPasses: -1, RecentCommits: -1},
+			expected: []error{
+				errors.New("promotion.to[0].gates.minimum_passes: must not be negative"),
+				errors.New("promotion.to[0].gates.recent_commits: must not be negative"),
+			},
+		},
+		{
+			name:     "passes without recent commits are invalid",
+			gates:    &api.PromotionGates{RequiredTests: []string{"e2e"}, MinimumPasses: 2},
+			expected: []error{errors.New("promotion.to[0].gates.recent_commits: required with minimum_passes")},
+		},
+		{
+			name:     "fewer recent commits than passes are invalid",
+			gates:    &api.PromotionGates{RequiredTests: []string{"e2e"}, MinimumPasses: 3, RecentCommits: 2},
+			expected: []error{errors.New("promotion.to[0].gates.recent_commits: must be at least minimum_passes (3)")},
+		},
+		{
+			name:     "recent commits without passes are invalid",
+			gates:    &api.PromotionGates{RequiredTests: []string{"e2e"}, RecentCommits: 2},
+			expected: []error{errors.New("promotion.to[0].gates.recent_commits: only valid with minimum_passes")},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			input := api.PromotionConfiguration{Targets: []api.PromotionTarget{{Namespace: "foo", Name: "bar", Gates: testCase.gates}}}
+			if diff := cmp.Diff(validatePromotionGates("promotion", input, tests), testCase.expected, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("got incorrect errors: %v", diff)
+			}
+		})
+	}
+}
+
 func TestValidateReleaseTagConfiguration(t *testing.T) {
 	var testCases = []struct {
 		name     string
@@ -112,7 +112,9 @@ var trapPattern = regexp.MustCompile(`(^|\W)\s*trap\s*['"]?\w*['"]?\s*\w*`)
 // component, the image references exist in the test configuration, etc.) are
 // not performed.
 func (v *Validator) IsValidReference(step api.LiteralTestStep) []error {
-	return v.validateLiteralTestStep(&context{field: fieldPath(step.As)}, testStageUnknown, step, nil)
+	context := &context{field: fieldPath(step.As)}
+	ret := v.validateLiteralTestStep(context, testStageUnknown, step, nil)
+	return append(ret, validateUnresolvedParallelGroup(context, step)...)
 }
 
 func (v *Validator) validateTestStepConfiguration(
@@ -438,6 +440,34 @@ func (v *Validator) validateClusterProfile(fieldRoot string, p api.ClusterProfil
 	return []error{fmt.Errorf("%s: invalid cluster profile %q", fieldRoot, p)}
 }
 
+// validateFallbackClusterProfiles checks that the fallbacks of a cluster profile
+// are distinct profiles with their own lease which provision the same type of cluster.
+func (v *Validator) validateFallbackClusterProfiles(fieldRoot string, profile api.ClusterProfile, fallbacks []api.ClusterProfile, metadata *api.Metadata) []error {
+	var errs []error
+	seen := sets.New[api.ClusterProfile](profile)
+	for i, fallback := range fallbacks {
+		field := fmt.Sprintf("%s.fallback_cluster_profiles[%d]", fieldRoot, i)
+		if seen.Has(fallback) {
+			errs = append(errs, fmt.Errorf("%s: duplicate cluster profile %q", field, fallback))
+			continue
+		}
+		seen.Insert(fallback)
+		if profileErrs := v.validateClusterProfile(field, fallback, metadata); len(profileErrs) != 0 {
+			errs = append(errs, profileErrs...)
+			continue
+		}
+		if fallback.LeaseType() == "" {
+			errs = append(errs, fmt.Errorf("%s: cluster profile %q has no lease to fall back to", field, fallback))
+		} else if profile != "" && fallback.LeaseType() == profile.LeaseType() {
+			errs = append(errs, fmt.Errorf("%s: cluster profile %q has the same lease as %q", field, fallback, profile))
+		}
+		if profile != "" && fallback.ClusterType() != profile.ClusterType() {
+			errs = append(errs, fmt.Errorf("%s: cluster profile %q provisions clusters of type %q, not %q", field, fallback, fallback.ClusterType(), profile.ClusterType()))
+		}
+	}
+	return errs
+}
+
 // verifyClusterProfileOwnership checks if metadata's org and repo match those in the profile,
 // verifying if it's one of the owners of the profile.
 func verifyClusterProfileOwnership(profile api.ClusterProfileDetails, m *api.Metadata) error {
@@ -589,8 +619,15 @@ func (v *Validator) validateTestConfigurationType(
 			clusterCount++
 			validationErrors = append(validationErrors, v.validateClusterProfile(fieldRoot, testConfig.ClusterProfile, metadata)...)
 		}
+		if len(testConfig.FallbackClusterProfiles) != 0 && testConfig.ClusterProfile == "" && testConfig.Workflow == nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: 'fallback_cluster_profiles' requires 'cluster_profile'", fieldRoot))
+		}
+		validationErrors = append(validationErrors, v.validateFallbackClusterProfiles(fieldRoot, testConfig.ClusterProfile, testConfig.FallbackClusterProfiles, metadata)...)
 		context := newContext(fieldPath(fieldRoot), testConfig.Environment, releases, inputImagesSeen)
 		validationErrors = append(validationErrors, validateLeases(context.addField("leases"), testConfig.Leases)...)
+		if testConfig.SharedDir != nil {
+			validationErrors = append(validationErrors, validateSharedDir(fieldRoot+".shared_dir", *testConfig.SharedDir)...)
+		}
 		if testConfig.NodeArchitecture != nil {
 			validationErrors = append(validationErrors, validateNodeArchitecture(fieldRoot, *testConfig.NodeArchitecture))
 		}
@@ -605,7 +642,14 @@ func (v *Validator) validateTestConfigurationType(
 			clusterCount++
 			validationErrors = append(validationErrors, v.validateClusterProfile(fieldRoot, testConfig.ClusterProfile, metadata)...)
 		}
+		if len(testConfig.FallbackClusterProfiles) != 0 && testConfig.ClusterProfile == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: 'fallback_cluster_profiles' requires 'cluster_profile'", fieldRoot))
+		}
+		validationErrors = append(validationErrors, v.validateFallbackClusterProfiles(fieldRoot, testConfig.ClusterProfile, testConfig.FallbackClusterProfiles, metadata)...)
 		validationErrors = append(validationErrors, validateLeases(context.addField("leases"), testConfig.Leases)...)
+		if testConfig.SharedDir != nil {
+			validationErrors = append(validationErrors, validateSharedDir(fieldRoot+".shared_dir", *testConfig.SharedDir)...)
+		}
 		for i, s := range testConfig.Pre {
 			validationErrors = append(validationErrors, v.validateLiteralTestStep(context.addField("pre").addIndex(i), testStagePre, s, claimRelease)...)
 		}
@@ -615,6 +659,9 @@ func (v *Validator) validateTestConfigurationType(
 		for i, s := range testConfig.Post {
 			validationErrors = append(validationErrors, v.validateLiteralTestStep(context.addField("post").addIndex(i), testStagePost, s, claimRelease)...)
 		}
+		validationErrors = append(validationErrors, validateParallelGroups(context.addField("pre"), testConfig.Pre)...)
+		validationErrors = append(validationErrors, validateParallelGroups(context.addField("test"), testConfig.Test)...)
+		validationErrors = append(validationErrors, validateParallelGroups(context.addField("post"), testConfig.Post)...)
 	}
 	if typeCount == 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("%s has no type, you may want to specify 'container' for a container based test", fieldRoot))
@@ -638,22 +685,34 @@ func (v *Validator) validateTestSteps(context *context, stage testStage, steps [
 		ret = append(ret, validateTestStep(contextI, s)...)
 		if s.LiteralTestStep != nil {
 			ret = append(ret, v.validateLiteralTestStep(contextI, stage, *s.LiteralTestStep, claimRelease)...)
+			ret = append(ret, validateUnresolvedParallelGroup(contextI, *s.LiteralTestStep)...)
+			ret = append(ret, validateUnresolvedLifecycle(contextI, *s.LiteralTestStep)...)
+		}
+		if p := s.Parallel; p != nil {
+			ret = append(ret, v.validateTestSteps(contextI.addField("parallel").addField("steps"), stage, p.TestSteps(), claimRelease)...)
 		}
 	}
 	return
 }
 
 func validateTestStep(context *context, step api.TestStep) (ret []error) {
-	if (step.LiteralTestStep != nil && step.Reference != nil) ||
-		(step.LiteralTestStep != nil && step.Chain != nil) ||
-		(step.Reference != nil && step.Chain != nil) {
-		ret = append(ret, context.errorf("only one of `ref`, `chain`, or a literal test step can be set"))
+	var n int
+	for _, set := range []bool{step.LiteralTestStep != nil, step.Reference != nil, step.Chain != nil, step.Parallel != nil} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
+		ret = append(ret, context.errorf("only one of `ref`, `chain`, `parallel`, or a literal test step can be set"))
 		return
 	}
-	if step.LiteralTestStep == nil && step.Reference == nil && step.Chain == nil {
-		ret = append(ret, context.errorf("a reference, chain, or literal test step is required"))
+	if n == 0 {
+		ret = append(ret, context.errorf("a reference, chain, parallel group, or literal test step is required"))
 		return
 	}
+	if p := step.Parallel; p != nil {
+		ret = append(ret, validateParallelTestSteps(context.addField("parallel"), p)...)
+	}
 	if step.Reference != nil {
 		if len(*step.Reference) == 0 {
 			ret = append(ret, context.addField("ref").errorf("length cannot be 0"))
@@ -675,6 +734,84 @@ func validateTestStep(context *context, step api.TestStep) (ret []error) {
 	return
 }
 
+func validateSharedDir(fieldRoot string, sharedDir api.SharedDir) (ret []error) {
+	switch sharedDir.Backend {
+	case "", api.SharedDirBackendSecret, api.SharedDirBackendPVC:
+	default:
+		ret = append(ret, fmt.Errorf("%s.backend: invalid value %q, must be one of %q, %q", fieldRoot, sharedDir.Backend, api.SharedDirBackendSecret, api.SharedDirBackendPVC))
+	}
+	if sharedDir.StorageClass != "" && sharedDir.Backend != api.SharedDirBackendPVC {
+		ret = append(ret, fmt.Errorf("%s.storage_class: only valid for the %q backend", fieldRoot, api.SharedDirBackendPVC))
+	}
+	if sharedDir.SizeLimit == "" {
+		if sharedDir.Backend == api.SharedDirBackendPVC {
+			ret = append(ret, fmt.Errorf("%s.size_limit: required for the %q backend", fieldRoot, api.SharedDirBackendPVC))
+		}
+		return ret
+	}
+	limit, err := resource.ParseQuantity(sharedDir.SizeLimit)
+	if err != nil {
+		return append(ret, fmt.Errorf("%s.size_limit: invalid quantity: %w", fieldRoot, err))
+	}
+	if limit.Sign() <= 0 {
+		ret = append(ret, fmt.Errorf("%s.size_limit: must be positive", fieldRoot))
+	}
+	if sharedDir.Backend != api.SharedDirBackendPVC && limit.Cmp(resource.MustParse(api.SharedDirSecretSizeLimit)) > 0 {
+		ret = append(ret, fmt.Errorf("%s.size_limit: cannot exceed %s for the %q backend", fieldRoot, api.SharedDirSecretSizeLimit, api.SharedDirBackendSecret))
+	}
+	return ret
+}
+
+func validateParallelTestSteps(context *context, group *api.ParallelTestSteps) (ret []error) {
+	if group.As == "" {
+		ret = append(ret, context.errorf("`as` is required"))
+	} else if errs := validation.IsDNS1123Label(group.As); len(errs) != 0 {
+		ret = append(ret, context.addField("as").errorf("invalid name %q: %s", group.As, strings.Join(errs, ", ")))
+	}
+	if len(group.Steps) == 0 {
+		ret = append(ret, context.addField("steps").errorf("at least one step is required"))
+	}
+	return ret
+}
+
+// validateUnresolvedParallelGroup verifies that a step which has not been
+// resolved yet is not marked as part of a parallel group, as only resolving
+// a `parallel` group may do that.
+func validateUnresolvedParallelGroup(context *context, step api.LiteralTestStep) []error {
+	var ret []error
+	if step.ParallelGroup != "" {
+		ret = append(ret, context.addField("parallel_group").errorf("cannot be set directly, use a `parallel` group instead"))
+	}
+	if step.ParallelBranch != "" {
+		ret = append(ret, context.addField("parallel_branch").errorf("cannot be set directly, use a `parallel` group instead"))
+	}
+	return ret
+}
+
+func validateUnresolvedLifecycle(context *context, step api.LiteralTestStep) []error {
+	if step.Lifecycle != nil {
+		return []error{context.addField("lifecycle").errorf("can only be set for steps in the registry")}
+	}
+	return nil
+}
+
+// validateParallelGroups verifies that all steps of a parallel group in a
+// fully-resolved phase are consecutive.
+func validateParallelGroups(context *context, steps []api.LiteralTestStep) (ret []error) {
+	seen := sets.New[string]()
+	for i, s := range steps {
+		g := s.ParallelGroup
+		if g == "" || (i != 0 && steps[i-1].ParallelGroup == g) {
+			continue
+		}
+		if seen.Has(g) {
+			ret = append(ret, context.addIndex(i).errorf("steps in parallel group %q are not consecutive", g))
+		}
+		seen.Insert(g)
+	}
+	return ret
+}
+
 func (v *Validator) validateLiteralTestStep(context *context, stage testStage, step api.LiteralTestStep, claimRelease *api.ClaimRelease) (ret []error) {
 	if len(step.As) == 0 {
 		ret = append(ret, context.errorf("`as` is required"))
@@ -714,6 +851,9 @@ func (v *Validator) validateLiteralTestStep(context *context, stage testStage, s
 			ret = append(ret, err)
 		}
 	}
+	if step.Retry != nil {
+		ret = append(ret, validateRetryPolicy(context.addField("retry"), *step.Retry)...)
+	}
 	switch stage {
 	case testStagePre, testStageTest:
 		if step.OptionalOnSuccess != nil {
@@ -723,6 +863,33 @@ func (v *Validator) validateLiteralTestStep(context *context, stage testStage, s
 	return ret
 }
 
+func validateRetryPolicy(context *context, retry api.StepRetryPolicy) (ret []error) {
+	if retry.Count <= 0 {
+		ret = append(ret, context.errorf("`count` must be positive"))
+	} else if retry.Count > api.StepRetryMaxCount {
+		ret = append(ret, context.errorf("`count` cannot be larger than %d", api.StepRetryMaxCount))
+	}
+	if retry.Backoff != nil && retry.Backoff.Duration < 0 {
+		ret = append(ret, context.errorf("`backoff` cannot be negative"))
+	}
+	for i, reason := range retry.Reasons {
+		switch reason {
+		case api.StepRetryReasonEvicted, api.StepRetryReasonImagePull, api.StepRetryReasonOOMKilled:
+		default:
+			ret = append(ret, context.addField("reasons").addIndex(i).errorf("invalid reason %q, must be one of %q, %q, %q", reason, api.StepRetryReasonEvicted, api.StepRetryReasonImagePull, api.StepRetryReasonOOMKilled))
+		}
+	}
+	if retry.LogPattern != "" {
+		if _, err := regexp.Compile(retry.LogPattern); err != nil {
+			ret = append(ret, context.errorf("invalid `log_pattern`: %v", err))
+		}
+	}
+	if len(retry.Reasons) == 0 && retry.LogPattern == "" {
+		ret = append(ret, context.errorf("at least one of `reasons` or `log_pattern` is required"))
+	}
+	return ret
+}
+
 func validateFromAndFromImage(
 	context *context,
 	from string,
@@ -902,11 +1069,26 @@ func validateLeases(context *context, leases []api.StepLease) (ret []error) {
 		if l.Env == "" {
 			ret = append(ret, context.addIndex(i).errorf("'env' cannot be empty"))
 		} else if context.leasesSeen != nil {
-			if context.leasesSeen.Has(l.Env) {
-				ret = append(ret, context.addIndex(i).errorf("duplicate environment variable: %s", l.Env))
-			} else {
-				context.leasesSeen.Insert(l.Env)
+			envs := []string{l.Env}
+			if len(l.FallbackResourceTypes) != 0 {
+				envs = append(envs, api.LeaseFallbackEnvs(l.Env)...)
+			}
+			for _, env := range envs {
+				if context.leasesSeen.Has(env) {
+					ret = append(ret, context.addIndex(i).errorf("duplicate environment variable: %s", env))
+				} else {
+					context.leasesSeen.Insert(env)
+				}
+			}
+		}
+		seen := sets.New[string](l.ResourceType)
+		for j, fallback := range l.FallbackResourceTypes {
+			if fallback == "" {
+				ret = append(ret, context.addIndex(i).errorf("'fallback_resource_types[%d]' cannot be empty", j))
+			} else if seen.Has(fallback) {
+				ret = append(ret, context.addIndex(i).errorf("duplicate resource type in 'fallback_resource_types': %s", fallback))
 			}
+			seen.Insert(fallback)
 		}
 	}
 	return
@@ -1016,7 +1016,58 @@ func TestValidateTestSteps(t *testing.T) {
 			Reference: &myReference,
 		}},
 		errs: []error{
-			errors.New("test[0]: only one of `ref`, `chain`, or a literal test step can be set"),
+			errors.New("test[0]: only one of `ref`, `chain`, `parallel`, or a literal test step can be set"),
+		},
+	}, {
+		name: "valid parallel group",
+		steps: []api.TestStep{{
+			Parallel: &api.ParallelTestSteps{
+				As: "group",
+				Steps: []api.ParallelTestStep{{
+					LiteralTestStep: &api.LiteralTestStep{
+						As:        "as",
+						From:      "from",
+						Commands:  "commands",
+						Resources: resources},
+				}, {
+					Reference: &myReference,
+				}},
+			},
+		}},
+	}, {
+		name: "invalid parallel group",
+		steps: []api.TestStep{{
+			Parallel: &api.ParallelTestSteps{As: "Not_Valid"},
+		}},
+		errs: []error{
+			errors.New("test[0].parallel.as: invalid name \"Not_Valid\": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')"),
+			errors.New("test[0].parallel.steps: at least one step is required"),
+		},
+	}, {
+		name: "parallel group set directly on a step",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:            "as",
+				From:          "from",
+				Commands:      "commands",
+				Resources:     resources,
+				ParallelGroup: "group"},
+		}},
+		errs: []error{
+			errors.New("test[0].parallel_group: cannot be set directly, use a `parallel` group instead"),
+		},
+	}, {
+		name: "lifecycle set on a literal step",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				Lifecycle: &api.RegistryLifecycle{Deprecated: "replaced"}},
+		}},
+		errs: []error{
+			errors.New("test[0].lifecycle: can only be set for steps in the registry"),
 		},
 	}, {
 		name: "Step with same name as reference",
@@ -1046,6 +1097,72 @@ func TestValidateTestSteps(t *testing.T) {
 		errs: []error{
 			errors.New("test[0]: `optional_on_success` is only allowed for Post steps"),
 		},
+	}, {
+		name: "valid retry policy",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				Retry: &api.StepRetryPolicy{
+					Count:      2,
+					Backoff:    &prowv1.Duration{Duration: time.Minute},
+					Reasons:    []api.StepRetryReason{api.StepRetryReasonEvicted, api.StepRetryReasonOOMKilled},
+					LogPattern: "connection (reset|refused)",
+				},
+			},
+		}},
+	}, {
+		name: "invalid retry policy",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				Retry: &api.StepRetryPolicy{
+					Backoff:    &prowv1.Duration{Duration: -time.Minute},
+					Reasons:    []api.StepRetryReason{"flaky"},
+					LogPattern: "(",
+				},
+			},
+		}},
+		errs: []error{
+			errors.New("test[0].retry: `count` must be positive"),
+			errors.New("test[0].retry: `backoff` cannot be negative"),
+			errors.New(`test[0].retry.reasons[0]: invalid reason "flaky", must be one of "evicted", "image_pull", "oom_killed"`),
+			errors.New("test[0].retry: invalid `log_pattern`: error parsing regexp: missing closing ): `(`"),
+		},
+	}, {
+		name: "retry policy with too many retries",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				Retry: &api.StepRetryPolicy{
+					Count:   1000,
+					Reasons: []api.StepRetryReason{api.StepRetryReasonEvicted},
+				},
+			},
+		}},
+		errs: []error{errors.New("test[0].retry: `count` cannot be larger than 5")},
+	}, {
+		name: "retry policy without reasons",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				Retry:     &api.StepRetryPolicy{Count: 1},
+			},
+		}},
+		errs: []error{
+			errors.New("test[0].retry: at least one of `reasons` or `log_pattern` is required"),
+		},
 	}, {
 		name: "Multiple errors",
 		steps: []api.TestStep{{
@@ -1503,6 +1620,64 @@ func TestValidateLeases(t *testing.T) {
 		err: []error{
 			errors.New("tests[0].steps.test[0].leases[0]: duplicate environment variable: AWS_LEASED_RESOURCE"),
 		},
+	}, {
+		name: "valid fallback resource types",
+		test: api.MultiStageTestConfigurationLiteral{
+			Leases: []api.StepLease{
+				{ResourceType: "aws-quota-slice", Env: "AWS_LEASED_RESOURCE", FallbackResourceTypes: []string{"aws-2-quota-slice", "aws-3-quota-slice"}},
+			},
+		},
+	}, {
+		name: "invalid fallback resource types",
+		test: api.MultiStageTestConfigurationLiteral{
+			Leases: []api.StepLease{
+				{ResourceType: "aws-quota-slice", Env: "AWS_LEASED_RESOURCE", FallbackResourceTypes: []string{"", "aws-quota-slice", "aws-2-quota-slice", "aws-2-quota-slice"}},
+			},
+		},
+		err: []error{
+			errors.New("tests[0].steps.leases[0]: 'fallback_resource_types[0]' cannot be empty"),
+			errors.New("tests[0].steps.leases[0]: duplicate resource type in 'fallback_resource_types': aws-quota-slice"),
+			errors.New("tests[0].steps.leases[0]: duplicate resource type in 'fallback_resource_types': aws-2-quota-slice"),
+		},
+	}, {
+		name: "invalid duplicate name with the variables for the leased type and region",
+		test: api.MultiStageTestConfigurationLiteral{
+			Leases: []api.StepLease{
+				{ResourceType: "aws-quota-slice", Env: "LEASE", FallbackResourceTypes: []string{"aws-2-quota-slice"}},
+				{ResourceType: "gcp-quota-slice", Env: "LEASE_TYPE"},
+				{ResourceType: "gcp-quota-slice", Env: "LEASE_REGION"},
+			},
+		},
+		err: []error{
+			errors.New("tests[0].steps.leases[1]: duplicate environment variable: LEASE_TYPE"),
+			errors.New("tests[0].steps.leases[2]: duplicate environment variable: LEASE_REGION"),
+		},
+	}, {
+		name: "valid fallback cluster profiles",
+		test: api.MultiStageTestConfigurationLiteral{
+			ClusterProfile:          api.ClusterProfileAWS,
+			FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileAWS2, api.ClusterProfileAWS3},
+		},
+	}, {
+		name: "invalid fallback cluster profiles",
+		test: api.MultiStageTestConfigurationLiteral{
+			ClusterProfile:          api.ClusterProfileAWS,
+			FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileAWS, "nope", api.ClusterProfileGCP, api.ClusterProfileAWS2, api.ClusterProfileAWS2},
+		},
+		err: []error{
+			errors.New(`tests[0].fallback_cluster_profiles[0]: duplicate cluster profile "aws"`),
+			errors.New(`tests[0].fallback_cluster_profiles[1]: invalid cluster profile "nope"`),
+			errors.New(`tests[0].fallback_cluster_profiles[2]: cluster profile "gcp" provisions clusters of type "gcp", not "aws"`),
+			errors.New(`tests[0].fallback_cluster_profiles[4]: duplicate cluster profile "aws-2"`),
+		},
+	}, {
+		name: "fallback cluster profiles without a cluster profile",
+		test: api.MultiStageTestConfigurationLiteral{
+			FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileAWS2},
+		},
+		err: []error{
+			errors.New("tests[0]: 'fallback_cluster_profiles' requires 'cluster_profile'"),
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			test := api.TestStepConfiguration{
@@ -1517,6 +1692,66 @@ func TestValidateLeases(t *testing.T) {
 	}
 }
 
+func TestValidateSharedDir(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		sharedDir api.SharedDir
+		expected  []error
+	}{{
+		name:      "default backend with a small limit",
+		sharedDir: api.SharedDir{SizeLimit: "512Ki"},
+	}, {
+		name:      "volume backend with a large limit",
+		sharedDir: api.SharedDir{Backend: api.SharedDirBackendPVC, SizeLimit: "10Gi"},
+	}, {
+		name:      "volume backend with a storage class",
+		sharedDir: api.SharedDir{Backend: api.SharedDirBackendPVC, SizeLimit: "10Gi", StorageClass: "nfs"},
+	}, {
+		name:      "storage class with the secret backend",
+		sharedDir: api.SharedDir{SizeLimit: "512Ki", StorageClass: "nfs"},
+		expected: []error{
+			errors.New(`tests[0].steps.shared_dir.storage_class: only valid for the "pvc" backend`),
+		},
+	}, {
+		name:      "invalid backend",
+		sharedDir: api.SharedDir{Backend: "configmap"},
+		expected: []error{
+			errors.New(`tests[0].steps.shared_dir.backend: invalid value "configmap", must be one of "secret", "pvc"`),
+		},
+	}, {
+		name:      "volume backend without a limit",
+		sharedDir: api.SharedDir{Backend: api.SharedDirBackendPVC},
+		expected: []error{
+			errors.New(`tests[0].steps.shared_dir.size_limit: required for the "pvc" backend`),
+		},
+	}, {
+		name:      "invalid limit",
+		sharedDir: api.SharedDir{Backend: api.SharedDirBackendPVC, SizeLimit: "lots"},
+		expected: []error{
+			errors.New("tests[0].steps.shared_dir.size_limit: invalid quantity: quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'"),
+		},
+	}, {
+		name:      "negative limit",
+		sharedDir: api.SharedDir{Backend: api.SharedDirBackendPVC, SizeLimit: "-1Gi"},
+		expected: []error{
+			errors.New("tests[0].steps.shared_dir.size_limit: must be positive"),
+		},
+	}, {
+		name:      "secret backend limit too large",
+		sharedDir: api.SharedDir{Backend: api.SharedDirBackendSecret, SizeLimit: "2Mi"},
+		expected: []error{
+			errors.New(`tests[0].steps.shared_dir.size_limit: cannot exceed 1Mi for the "secret" backend`),
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := validateSharedDir("tests[0].steps.shared_dir", tc.sharedDir)
+			if diff := cmp.Diff(tc.expected, actual, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateTestConfigurationType(t *testing.T) {
 	for _, tc := range []struct {
 		name     string
@@ -0,0 +1,246 @@
+package webreg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/repoowners"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// APIPrefix is the path under which the registry API is served. Responses
+// only change in backwards-compatible ways within a version of the API.
+const APIPrefix = "/api/v1/registry/"
+
+// RegistryIndex lists the names of all the components of the registry
+type RegistryIndex struct {
+	Generation int      `json:"generation"`
+	References []string `json:"references"`
+	Chains     []string `json:"chains"`
+	Workflows  []string `json:"workflows"`
+	Observers  []string `json:"observers"`
+}
+
+// RegistryComponent is a reference, chain, workflow or observer of the registry
+// with the environment and dependencies of all the steps it runs
+type RegistryComponent struct {
+	Type          string            `json:"type"`
+	Name          string            `json:"name"`
+	Documentation string            `json:"documentation,omitempty"`
+	Path          string            `json:"path,omitempty"`
+	Owners        repoowners.Config `json:"owners"`
+	// Lifecycle is set for deprecated components
+	Lifecycle *api.RegistryLifecycle `json:"lifecycle,omitempty"`
+	// Reference is set for references
+	Reference *api.LiteralTestStep `json:"reference,omitempty"`
+	// Steps are set for chains
+	Steps []api.TestStep `json:"steps,omitempty"`
+	// Workflow is set for workflows
+	Workflow *api.MultiStageTestConfiguration `json:"workflow,omitempty"`
+	// Observer is set for observers
+	Observer *api.Observer `json:"observer,omitempty"`
+	// Environment maps the parameters consumed by the steps to their documentation, defaults and consumers
+	Environment map[string]Parameter `json:"environment,omitempty"`
+	// Dependencies maps the images the steps depend on to the variables they are exposed in
+	Dependencies map[string]map[string]DependencyVariable `json:"dependencies,omitempty"`
+}
+
+// Parameter is a parameter consumed by the steps of a component
+type Parameter struct {
+	Documentation string  `json:"documentation,omitempty"`
+	Default       *string `json:"default,omitempty"`
+	// Steps are the steps consuming the parameter
+	Steps []string `json:"steps"`
+}
+
+// DependencyVariable is a variable an image the steps of a component depend on is exposed in
+type DependencyVariable struct {
+	// Steps are the steps consuming the variable
+	Steps []string `json:"steps"`
+	// Override is set when a workflow overrides the image the steps depend on
+	Override bool `json:"override,omitempty"`
+}
+
+// etagFor identifies the content served for a generation of the registry
+func etagFor(generation int) string {
+	return fmt.Sprintf(`"v1-%d"`, generation)
+}
+
+// etagMatches determines whether the value of an If-None-Match header, a list
+// of entity tags or "*", matches the entity tag. Weak tags match the strong
+// tag with the same value, as the header uses the weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// RegistryAPIHandler serves the components of the registry as JSON:
+// the index of all components under the prefix and each component
+// under <prefix>/<type>/<name>. Successful responses carry an ETag that
+// changes whenever the registry is reloaded, so clients can revalidate cheaply.
+func RegistryAPIHandler(agent agents.RegistryAgent) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNotImplemented)
+			_, _ = w.Write([]byte(http.StatusText(http.StatusNotImplemented)))
+			return
+		}
+		registryContent := agent.GetRegistryContent()
+		var content interface{}
+		path := strings.Trim(strings.TrimPrefix(req.URL.Path, APIPrefix), "/")
+		switch parts := strings.Split(path, "/"); {
+		case path == "":
+			content = registryIndex(registryContent.Generation, registryContent.References, registryContent.Chains, registryContent.Workflows, registryContent.Observers)
+		case len(parts) == 2:
+			component, err := registryComponent(parts[0], parts[1], registryContent.References, registryContent.Chains, registryContent.Workflows, registryContent.Observers, registryContent.Documentation, registryContent.Metadata)
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, "%s: %v", http.StatusText(http.StatusNotFound), err)
+				return
+			}
+			content = component
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "%s: invalid path %s", http.StatusText(http.StatusNotFound), req.URL.Path)
+			return
+		}
+
+		etag := etagFor(registryContent.Generation)
+		w.Header().Set("ETag", etag)
+		if match := req.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		raw, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to marshal %s to JSON: %v", path, err)
+			logrus.WithError(err).Errorf("failed to marshal %s to JSON", path)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(raw); err != nil {
+			logrus.WithError(err).Errorf("Failed to write response: %v", err)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func registryIndex(generation int, refs registry.ReferenceByName, chains registry.ChainByName, workflows registry.WorkflowByName, observers registry.ObserverByName) RegistryIndex {
+	return RegistryIndex{
+		Generation: generation,
+		References: sortedKeys(refs),
+		Chains:     sortedKeys(chains),
+		Workflows:  sortedKeys(workflows),
+		Observers:  sortedKeys(observers),
+	}
+}
+
+func parametersFrom(items map[string]environmentLine) map[string]Parameter {
+	if len(items) == 0 {
+		return nil
+	}
+	parameters := make(map[string]Parameter, len(items))
+	for name, item := range items {
+		parameters[name] = Parameter{Documentation: item.Documentation, Default: item.Default, Steps: item.Steps}
+	}
+	return parameters
+}
+
+func dependenciesFrom(items map[string]dependencyVars) map[string]map[string]DependencyVariable {
+	if len(items) == 0 {
+		return nil
+	}
+	dependencies := make(map[string]map[string]DependencyVariable, len(items))
+	for image, vars := range items {
+		dependencies[image] = make(map[string]DependencyVariable, len(vars))
+		for name, line := range vars {
+			dependencies[image][name] = DependencyVariable{Steps: line.Steps, Override: line.Override}
+		}
+	}
+	return dependencies
+}
+
+func registryComponent(componentType, name string, refs registry.ReferenceByName, chains registry.ChainByName, workflows registry.WorkflowByName, observers registry.ObserverByName, docs map[string]string, metadata api.RegistryMetadata) (*RegistryComponent, error) {
+	component := &RegistryComponent{Type: componentType, Name: name, Documentation: docs[name]}
+	var suffix string
+	switch componentType {
+	case "reference":
+		reference, ok := refs[name]
+		if !ok {
+			return nil, fmt.Errorf("could not find reference %s", name)
+		}
+		suffix = load.RefSuffix
+		component.Reference = &reference
+		component.Lifecycle = reference.Lifecycle
+		steps := []api.TestStep{{Reference: &name}}
+		component.Environment = parametersFrom(getEnvironmentDataItems(steps, refs, chains))
+		component.Dependencies = dependenciesFrom(getDependencyDataItems(steps, refs, chains, nil))
+	case "chain":
+		chain, ok := chains[name]
+		if !ok {
+			return nil, fmt.Errorf("could not find chain %s", name)
+		}
+		suffix = load.ChainSuffix
+		component.Steps = chain.Steps
+		component.Lifecycle = chain.Lifecycle
+		component.Environment = parametersFrom(getEnvironmentDataItems(chain.Steps, refs, chains))
+		component.Dependencies = dependenciesFrom(getDependencyDataItems(chain.Steps, refs, chains, nil))
+	case "workflow":
+		workflow, ok := workflows[name]
+		if !ok {
+			return nil, fmt.Errorf("could not find workflow %s", name)
+		}
+		suffix = load.WorkflowSuffix
+		component.Workflow = &workflow
+		component.Lifecycle = workflow.Lifecycle
+		var steps []api.TestStep
+		for _, phase := range [][]api.TestStep{workflow.Pre, workflow.Test, workflow.Post} {
+			steps = append(steps, phase...)
+		}
+		component.Environment = parametersFrom(getEnvironmentDataItems(steps, refs, chains))
+		component.Dependencies = dependenciesFrom(getDependencyDataItems(steps, refs, chains, workflow.Dependencies))
+	case "observer":
+		observer, ok := observers[name]
+		if !ok {
+			return nil, fmt.Errorf("could not find observer %s", name)
+		}
+		suffix = load.ObserverSuffix
+		component.Observer = &observer
+		for _, parameter := range observer.Environment {
+			if component.Environment == nil {
+				component.Environment = map[string]Parameter{}
+			}
+			component.Environment[parameter.Name] = Parameter{Documentation: parameter.Documentation, Default: parameter.Default, Steps: []string{name}}
+		}
+	default:
+		return nil, fmt.Errorf("unknown component type %s", componentType)
+	}
+	if info, ok := metadata[name+suffix]; ok {
+		component.Path = info.Path
+		component.Owners = info.Owners
+	}
+	return component, nil
+}
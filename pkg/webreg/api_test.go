@@ -0,0 +1,201 @@
+package webreg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/prow/pkg/repoowners"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+type fakeRegistryAgent struct {
+	agents.RegistryAgent
+	generation int
+	refs       registry.ReferenceByName
+	chains     registry.ChainByName
+	workflows  registry.WorkflowByName
+	observers  registry.ObserverByName
+	docs       map[string]string
+	metadata   api.RegistryMetadata
+}
+
+func (a *fakeRegistryAgent) GetRegistryContent() agents.RegistryContent {
+	return agents.RegistryContent{
+		Generation:    a.generation,
+		References:    a.refs,
+		Chains:        a.chains,
+		Workflows:     a.workflows,
+		Observers:     a.observers,
+		Documentation: a.docs,
+		Metadata:      a.metadata,
+	}
+}
+
+func TestRegistryAPIHandler(t *testing.T) {
+	install, chain, workflow, observer := "ipi-install", "ipi-conf", "ipi-aws", "monitor"
+	agent := &fakeRegistryAgent{
+		generation: 3,
+		refs: registry.ReferenceByName{install: {
+			As:           install,
+			Environment:  []api.StepParameter{{Name: "SIZE", Default: pointer.String("large"), Documentation: "The size."}},
+			Dependencies: []api.StepDependency{{Name: "installer", Env: "INSTALLER"}},
+		}},
+		chains:    registry.ChainByName{chain: {As: chain, Steps: []api.TestStep{{Reference: &install}}}},
+		workflows: registry.WorkflowByName{workflow: {Pre: []api.TestStep{{Chain: &chain}}, Dependencies: api.TestDependencies{"INSTALLER": "stable:installer"}}},
+		observers: registry.ObserverByName{observer: {Name: observer, Environment: []api.StepParameter{{Name: "INTERVAL", Documentation: "The interval."}}}},
+		docs:      map[string]string{install: "Installs a cluster.", observer: "Monitors the cluster."},
+		metadata: api.RegistryMetadata{
+			"ipi-install-ref.yaml": {Path: "ipi/install/ipi-install-ref.yaml", Owners: repoowners.Config{Approvers: []string{"alice"}}},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		path           string
+		ifNoneMatch    string
+		expectedStatus int
+		expectedETag   string
+		expected       interface{}
+	}{
+		{
+			name:           "index",
+			path:           APIPrefix,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"v1-3"`,
+			expected:       &RegistryIndex{Generation: 3, References: []string{install}, Chains: []string{chain}, Workflows: []string{workflow}, Observers: []string{observer}},
+		},
+		{
+			name:           "reference with environment, dependencies and owners",
+			path:           APIPrefix + "reference/" + install,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"v1-3"`,
+			expected: &RegistryComponent{
+				Type:          "reference",
+				Name:          install,
+				Documentation: "Installs a cluster.",
+				Path:          "ipi/install/ipi-install-ref.yaml",
+				Owners:        repoowners.Config{Approvers: []string{"alice"}},
+				Reference:     &api.LiteralTestStep{As: install, Environment: []api.StepParameter{{Name: "SIZE", Default: pointer.String("large"), Documentation: "The size."}}, Dependencies: []api.StepDependency{{Name: "installer", Env: "INSTALLER"}}},
+				Environment:   map[string]Parameter{"SIZE": {Documentation: "The size.", Default: pointer.String("large"), Steps: []string{install}}},
+				Dependencies:  map[string]map[string]DependencyVariable{"installer": {"INSTALLER": {Steps: []string{install}}}},
+			},
+		},
+		{
+			name:           "workflow with overridden dependencies",
+			path:           APIPrefix + "workflow/" + workflow,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"v1-3"`,
+			expected: &RegistryComponent{
+				Type:         "workflow",
+				Name:         workflow,
+				Workflow:     &api.MultiStageTestConfiguration{Pre: []api.TestStep{{Chain: &chain}}, Dependencies: api.TestDependencies{"INSTALLER": "stable:installer"}},
+				Environment:  map[string]Parameter{"SIZE": {Documentation: "The size.", Default: pointer.String("large"), Steps: []string{install}}},
+				Dependencies: map[string]map[string]DependencyVariable{"stable:installer": {"INSTALLER": {Steps: []string{install}, Override: true}}},
+			},
+		},
+		{
+			name:           "observer",
+			path:           APIPrefix + "observer/" + observer,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"v1-3"`,
+			expected: &RegistryComponent{
+				Type:          "observer",
+				Name:          observer,
+				Documentation: "Monitors the cluster.",
+				Observer:      &api.Observer{Name: observer, Environment: []api.StepParameter{{Name: "INTERVAL", Documentation: "The interval."}}},
+				Environment:   map[string]Parameter{"INTERVAL": {Documentation: "The interval.", Steps: []string{observer}}},
+			},
+		},
+		{
+			name:           "unchanged generation",
+			path:           APIPrefix + "chain/" + chain,
+			ifNoneMatch:    `"v1-3"`,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   `"v1-3"`,
+		},
+		{
+			name:           "unchanged generation in a list of weak tags",
+			path:           APIPrefix + "chain/" + chain,
+			ifNoneMatch:    `W/"v1-1", W/"v1-3"`,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   `"v1-3"`,
+		},
+		{
+			name:           "any generation",
+			path:           APIPrefix,
+			ifNoneMatch:    "*",
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   `"v1-3"`,
+		},
+		{
+			name:           "any generation of a missing component",
+			path:           APIPrefix + "chain/missing",
+			ifNoneMatch:    "*",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "changed generation",
+			path:           APIPrefix + "chain/" + chain,
+			ifNoneMatch:    `"v1-2"`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"v1-3"`,
+			expected: &RegistryComponent{
+				Type:         "chain",
+				Name:         chain,
+				Steps:        []api.TestStep{{Reference: &install}},
+				Environment:  map[string]Parameter{"SIZE": {Documentation: "The size.", Default: pointer.String("large"), Steps: []string{install}}},
+				Dependencies: map[string]map[string]DependencyVariable{"installer": {"INSTALLER": {Steps: []string{install}}}},
+			},
+		},
+		{
+			name:           "unknown component",
+			path:           APIPrefix + "chain/missing",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown type",
+			path:           APIPrefix + "step/" + install,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			RegistryAPIHandler(agent)(rec, req)
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if etag := rec.Header().Get("ETag"); etag != tc.expectedETag {
+				t.Errorf("expected ETag %q, got %q", tc.expectedETag, etag)
+			}
+			if tc.expected == nil {
+				return
+			}
+			var actual interface{}
+			switch tc.expected.(type) {
+			case *RegistryIndex:
+				actual = &RegistryIndex{}
+			default:
+				actual = &RegistryComponent{}
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), actual); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected response: %s", diff)
+			}
+		})
+	}
+}
@@ -102,6 +102,7 @@ const mainPage = `
 
 const referencePage = `
 <h2 id="title"><a href="#title">Step:</a> <nobr style="font-family:monospace">{{ .Reference.As }}</nobr></h2>
+{{ lifecycleBlock "reference" .Reference.Lifecycle }}
 <p id="documentation">{{ .Reference.Documentation }}</p>
 <h3 id="image"><a href="#image">Container image used for this step:</a> <span style="font-family:monospace">{{ fromImage .Reference.From .Reference.FromImage }}</span></h3>
 <p id="image">{{ fromImageDescription .Reference.From .Reference.FromImage }}<d/p>
@@ -111,12 +112,14 @@ const referencePage = `
 {{ syntaxedSource .Reference.Commands }}
 <h3 id="properties"><a href="#properties">Properties</a></h3>
 {{ template "referenceProperties" .Reference }}
+<h3 id="usages"><a href="/usages?type=reference&name={{ .Reference.As }}">Tests using this step</a></h3>
 <h3 id="github"><p><a href="#github">GitHub Link:</a></h3></p>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 `
 
 const chainPage = `
 <h2 id="title"><a href="#title">Chain:</a> <nobr style="font-family:monospace">{{ .Chain.As }}</nobr></h2>
+{{ lifecycleBlock "chain" .Chain.Lifecycle }}
 <p id="documentation">{{ .Chain.Documentation }}</p>
 <h3 id="steps" title="Step run by the chain, in runtime order"><a href="#steps">Steps</a></h3>
 {{ template "stepTable" .Chain.Steps}}
@@ -127,6 +130,7 @@ const chainPage = `
 {{ template "refEnvironment" .Chain.As }}
 <h3 id="graph" title="Visual representation of steps run by this chain"><a href="#graph">Step Graph</a></h3>
 {{ chainGraph .Chain.As }}
+<h3 id="usages"><a href="/usages?type=chain&name={{ .Chain.As }}">Tests using this chain</a></h3>
 <h3 id="github"><a href="#github">GitHub Link:</a></h3>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 `
@@ -135,6 +139,9 @@ const chainPage = `
 const workflowJobPage = `
 {{ $type := .Workflow.Type }}
 <h2 id="title"><a href="#title">{{ $type }}:</a> <nobr style="font-family:monospace">{{ .Workflow.As }}</nobr></h2>
+{{ if eq $type "Workflow" }}
+{{ lifecycleBlock "workflow" .Workflow.Lifecycle }}
+{{ end }}
 {{ if .Workflow.Documentation }}
 	<p id="documentation">{{ .Workflow.Documentation }}</p>
 {{ end }}
@@ -155,11 +162,60 @@ const workflowJobPage = `
 <h3 id="graph" title="Visual representation of steps run by this {{ toLower $type }}"><a href="#graph">Step Graph</a></h3>
 {{ workflowGraph .Workflow.As .Workflow.Type }}
 {{ if eq $type "Workflow" }}
+<h3 id="usages"><a href="/usages?type=workflow&name={{ .Workflow.As }}">Tests using this workflow</a></h3>
 <h3 id="github"><a href="#github">GitHub Link:</a></h3>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 {{ end }}
 `
 
+const usagesPage = `
+<h2 id="title"><a href="#title">Tests using {{ .Type }}:</a> <nobr style="font-family:monospace">{{ .Name }}</nobr></h2>
+<h3 id="cluster_profiles" title="Number of tests using the {{ .Type }} per cluster profile"><a href="#cluster_profiles">Cluster Profiles</a></h3>
+<table class="table">
+	<thead>
+		<tr>
+			<th title="The cluster profile the tests run on" class="info">Cluster Profile</th>
+			<th title="The number of tests running on the cluster profile" class="info">Tests</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{ range $profile, $count := .ClusterProfiles }}
+			<tr>
+				<td style="font-family:monospace">{{ $profile }}</td>
+				<td>{{ $count }}</td>
+			</tr>
+		{{ end }}
+	</tbody>
+</table>
+<h3 id="tests" title="Tests which execute the {{ .Type }}, directly or through other registry components"><a href="#tests">Tests</a></h3>
+<table class="table">
+	<thead>
+		<tr>
+			<th title="The multistage test" class="info">Test</th>
+			<th title="GitHub organization, repo, branch and variant of the ci-operator config" class="info">Configuration</th>
+			<th title="The cluster profile the test runs on" class="info">Cluster Profile</th>
+			<th title="Registry components through which the test executes the {{ .Type }}" class="info">Via</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{ range $index, $test := .Tests }}
+			<tr>
+				<td><nobr><a href="/job?org={{ $test.Org }}&repo={{ $test.Repo }}&branch={{ $test.Branch }}&test={{ $test.Test }}{{ if $test.Variant }}&variant={{ $test.Variant }}{{ end }}" style="font-family:monospace">{{ $test.Test }}</a></nobr></td>
+				<td style="font-family:monospace">{{ $test.Metadata.AsString }}</td>
+				<td style="font-family:monospace">{{ $test.ClusterProfile }}</td>
+				<td>
+					<ul>
+					{{ range $index, $element := $test.Via }}
+						<li>{{ template "nameWithLink" $element }}</li>
+					{{ end }}
+					</ul>
+				</td>
+			</tr>
+		{{ end }}
+	</tbody>
+</table>
+`
+
 const jobSearchPage = `
 {{ template "jobTable" . }}
 `
@@ -372,8 +428,23 @@ const templateDefinitions = `
        {{ else }}
          Name of the acquired lease of type <span style="font-family:monospace">{{ $lease.ResourceType }}</span>
        {{ end }}
+       {{ if $lease.FallbackResourceTypes }}
+         or of the first free fallback type of {{ range $i, $type := $lease.FallbackResourceTypes }}{{ if $i }}, {{ end }}<span style="font-family:monospace">{{ $type }}</span>{{ end }}
+       {{ end }}
      </td>
    </tr>
+   {{ if $lease.FallbackResourceTypes }}
+   <tr>
+     <td style="font-family:monospace">{{ $lease.Env }}_TYPE</td>
+     <td>Lease<sup>[<a href="https://docs.ci.openshift.org/docs/architecture/step-registry/#explicit-lease-configuration">?</a>]</sup></td>
+     <td>Type of the acquired lease</td>
+   </tr>
+   <tr>
+     <td style="font-family:monospace">{{ $lease.Env }}_REGION</td>
+     <td>Lease<sup>[<a href="https://docs.ci.openshift.org/docs/architecture/step-registry/#explicit-lease-configuration">?</a>]</sup></td>
+     <td>Region of the acquired lease</td>
+   </tr>
+   {{ end }}
    {{ end }}
    </tbody>
    </table>
@@ -395,13 +466,18 @@ const templateDefinitions = `
 		{{ range $index, $step := . }}
 			<tr>
 				{{ $nameAndType := testStepNameAndType $step }}
-				{{ $doc := docsForName $nameAndType.Name }}
-				{{ if not $step.LiteralTestStep }}
-					<td>{{ template "nameWithLink" $nameAndType }}</td>
+				{{ if $step.Parallel }}
+					<td>parallel: {{ $nameAndType.Name }}</td>
+					<td>Executed concurrently:{{ template "stepList" $step.Parallel.TestSteps }}</td>
 				{{ else }}
-					<td>{{ $nameAndType.Name }}</td>
+					{{ $doc := docsForName $nameAndType.Name }}
+					{{ if not $step.LiteralTestStep }}
+						<td>{{ template "nameWithLink" $nameAndType }}</td>
+					{{ else }}
+						<td>{{ $nameAndType.Name }}</td>
+					{{ end }}
+					<td>{{ noescape $doc }}</td>
 				{{ end }}
-				<td>{{ noescape $doc }}</td>
 			</tr>
 		{{ end }}
 	</tbody>
@@ -413,7 +489,11 @@ const templateDefinitions = `
 	<ul>
 	{{ range $index, $step := .}}
 		{{ $nameAndType := testStepNameAndType $step }}
-		<li>{{ template "nameWithLink" $nameAndType }}</li>
+		{{ if $step.Parallel }}
+			<li>parallel: {{ $nameAndType.Name }}{{ template "stepList" $step.Parallel.TestSteps }}</li>
+		{{ else }}
+			<li>{{ template "nameWithLink" $nameAndType }}</li>
+		{{ end }}
 	{{ end }}
 	</ul>
 {{ end }}
@@ -604,6 +684,24 @@ func repoSpan(r Repo, containsVariant bool) int {
 	return rowspan + 1
 }
 
+func lifecycleBlock(componentType string, lifecycle *api.RegistryLifecycle) template.HTML {
+	if lifecycle == nil {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("<div id=\"lifecycle\" class=\"alert alert-warning\">\n<strong>Deprecated:</strong> ")
+	builder.WriteString(template.HTMLEscapeString(lifecycle.Deprecated))
+	if lifecycle.ReplacedBy != "" {
+		replacement := template.HTMLEscapeString(lifecycle.ReplacedBy)
+		builder.WriteString(fmt.Sprintf("<br>\nUse <a href=\"/%s/%s\" style=\"font-family:monospace\">%s</a> instead.", componentType, replacement, replacement))
+	}
+	if lifecycle.RemovalDate != "" {
+		builder.WriteString(fmt.Sprintf("<br>\nScheduled for removal on %s.", template.HTMLEscapeString(lifecycle.RemovalDate)))
+	}
+	builder.WriteString("\n</div>")
+	return template.HTML(builder.String())
+}
+
 func orgSpan(o Org, containsVariant bool) int {
 	rowspan := 0
 	for _, repo := range o.Repos {
@@ -679,8 +777,9 @@ func getBaseTemplate() (*template.Template, error) {
 			"doubleInc": func(i int) int {
 				return i + 2
 			},
-			"githubLink":  githubLink,
-			"ownersBlock": ownersBlock,
+			"githubLink":     githubLink,
+			"ownersBlock":    ownersBlock,
+			"lifecycleBlock": lifecycleBlock,
 		},
 	)
 	return base.Funcs(template.FuncMap{"markdown": markDowner}).Parse(templateDefinitions)
@@ -701,6 +800,9 @@ func getTestStepNameAndType(step api.TestStep) stepNameAndType {
 	} else if step.Chain != nil {
 		name = *step.Chain
 		typeName = "chain"
+	} else if step.Parallel != nil {
+		name = step.Parallel.As
+		typeName = "parallel"
 	}
 	return stepNameAndType{
 		Name: name,
@@ -799,9 +901,9 @@ func setWorkflowGraph(t *template.Template, chains registry.ChainByName, workflo
 }
 
 type environmentLine struct {
-	Documentation string
-	Default       *string
-	Steps         []string
+	Documentation string   `json:"documentation,omitempty"`
+	Default       *string  `json:"default,omitempty"`
+	Steps         []string `json:"steps"`
 }
 
 type environmentData struct {
@@ -850,6 +952,8 @@ func getEnvironmentDataItems(worklist []api.TestStep, registryRefs registry.Refe
 				}
 				worklist = append(worklist, chain.Steps...)
 			}
+		case step.Parallel != nil:
+			worklist = append(worklist, step.Parallel.TestSteps()...)
 		case step.LiteralTestStep != nil:
 			for _, env := range step.Environment {
 				add(env.Name, env.Documentation, step.As, env.Default)
@@ -861,8 +965,8 @@ func getEnvironmentDataItems(worklist []api.TestStep, registryRefs registry.Refe
 }
 
 type dependencyLine struct {
-	Steps    []string
-	Override bool
+	Steps    []string `json:"steps"`
+	Override bool     `json:"override,omitempty"`
 }
 type dependencyVars map[string]dependencyLine
 
@@ -921,6 +1025,8 @@ func getDependencyDataItems(worklist []api.TestStep, registryRefs registry.Refer
 				}
 				worklist = append(worklist, chain.Steps...)
 			}
+		case step.Parallel != nil:
+			worklist = append(worklist, step.Parallel.TestSteps()...)
 		case step.LiteralTestStep != nil:
 			for _, dep := range step.Dependencies {
 				add(dep.Name, dep.Env, step.As)
@@ -1080,6 +1186,8 @@ func WebRegHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent)
 				searchHandler(confAgent, w, req)
 			case "job":
 				jobHandler(regAgent, confAgent, w, req)
+			case "usages":
+				usagesHandler(regAgent, confAgent, w, req)
 			case "ci-operator-reference":
 				ciOpConfigRefHandler(w)
 			default:
@@ -1317,6 +1425,7 @@ func referenceHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *ht
 				OptionalOnSuccess: refs[name].OptionalOnSuccess,
 				BestEffort:        refs[name].BestEffort,
 				Cli:               refs[name].Cli,
+				Lifecycle:         refs[name].Lifecycle,
 			},
 			Documentation: docs[name],
 		},
@@ -1362,6 +1471,7 @@ func chainHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.R
 			As:            name,
 			Documentation: docs[name],
 			Steps:         chains[name].Steps,
+			Lifecycle:     chains[name].Lifecycle,
 		},
 		Metadata: metadata[chainMetadataName],
 	}
@@ -1409,6 +1519,7 @@ func workflowHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *htt
 				As:            name,
 				Documentation: docs[name],
 				Steps:         workflows[name],
+				Lifecycle:     workflows[name].Lifecycle,
 			},
 			Type: workflowType},
 		Metadata: metadata[workflowMetadataName],
@@ -1621,6 +1732,38 @@ func searchHandler(confAgent agents.ConfigAgent, w http.ResponseWriter, req *htt
 	writePage(w, "Job Search Page", page, matches)
 }
 
+func usagesHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	elementType := req.URL.Query().Get(registryserver.TypeQuery)
+	if elementType == "" {
+		registryserver.MissingQuery(w, registryserver.TypeQuery)
+		return
+	}
+	name := req.URL.Query().Get(registryserver.NameQuery)
+	if name == "" {
+		registryserver.MissingQuery(w, registryserver.NameQuery)
+		return
+	}
+	_, _, workflows, _, _ := regAgent.GetRegistryComponents()
+	usages, err := registryserver.FindUsages(regAgent.GetRegistryGraph(), workflows, confAgent.GetAll(), elementType, name)
+	if err != nil {
+		writeErrorPage(w, err, http.StatusNotFound)
+		return
+	}
+	page, err := baseTemplate.Clone()
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if page, err = page.Parse(usagesPage); err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	writePage(w, "Registry Usages Page", page, usages)
+}
+
 func searchJobs(jobs *Jobs, search string) *Jobs {
 	search = strings.TrimPrefix(search, "pull-ci-")
 	search = strings.TrimPrefix(search, "branch-ci-")
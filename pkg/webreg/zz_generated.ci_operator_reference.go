@@ -298,6 +298,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"          # but not promote them afterwards.\n" +
 	"          excluded_images:\n" +
 	"            - \"\"\n" +
+	"          # Gates hold back the promotion to this target until the\n" +
+	"          # postsubmit tests they require have passed, so a broken\n" +
+	"          # merge does not flow into the target.\n" +
+	"          gates:\n" +
+	"            # RequiredTests are the names of tests in this configuration,\n" +
+	"            # run as postsubmit jobs, which must have passed on the commit\n" +
+	"            # the images are built from.\n" +
+	"            required_tests:\n" +
+	"                - \"\"\n" +
 	"          # Name is an optional image stream name to use that\n" +
 	"          # contains all component tags. If specified, tag is\n" +
 	"          # ignored.\n" +
@@ -617,6 +626,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # be used with rehearsals. Otherwise, the overrides should be passed in as parameters to ci-operator.\n" +
 	"            dependency_overrides:\n" +
 	"                \"\": \"\"\n" +
+	"            # Deprecations describe the deprecated workflow and chains of the registry\n" +
+	"            # the test was resolved from. Deprecated steps carry their own lifecycle.\n" +
+	"            deprecations:\n" +
+	"                - \"\"\n" +
 	"            # DnsConfig for step's Pod.\n" +
 	"            dnsConfig:\n" +
 	"                # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -628,10 +641,22 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # Environment has the values of parameters for the steps.\n" +
 	"            env:\n" +
 	"                \"\": \"\"\n" +
+	"            # FallbackClusterProfiles are profiles, in order of preference, whose lease\n" +
+	"            # will be acquired when none of the ClusterProfile is free. Steps are given\n" +
+	"            # the credentials of the profile whose lease was acquired.\n" +
+	"            fallback_cluster_profiles:\n" +
+	"                - \"\"\n" +
 	"            # Leases lists resources that should be acquired for the test.\n" +
 	"            leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
 	"                  env: ' '\n" +
+	"                  # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"                  # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"                  # The type that was leased and the region of the resources will be exposed\n" +
+	"                  # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"                  # LeaseRegionEnv.\n" +
+	"                  fallback_resource_types:\n" +
+	"                    - \"\"\n" +
 	"                  # ResourceType is the type of resource that will be leased.\n" +
 	"                  resource_type: ' '\n" +
 	"            # NodeArchitecture is the architecture for the node where the test will run.\n" +
@@ -737,8 +762,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
 	"                      env: ' '\n" +
+	"                      # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"                      # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"                      # The type that was leased and the region of the resources will be exposed\n" +
+	"                      # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"                      # LeaseRegionEnv.\n" +
+	"                      fallback_resource_types:\n" +
+	"                        - \"\"\n" +
 	"                      # ResourceType is the type of resource that will be leased.\n" +
 	"                      resource_type: ' '\n" +
+	"                  # Lifecycle marks the step as deprecated. It is only valid for steps in\n" +
+	"                  # the registry and is carried over to the steps resolved from them.\n" +
+	"                  lifecycle:\n" +
+	"                    deprecated: ' '\n" +
+	"                    removal_date: ' '\n" +
+	"                    replaced_by: ' '\n" +
 	"                  # NoKubeconfig determines that no $KUBECONFIG will exist in $SHARED_DIR,\n" +
 	"                  # so no local copy of it will be created for the step and if the step\n" +
 	"                  # creates one, it will not be propagated.\n" +
@@ -754,6 +792,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelBranch is the name of the branch of a parallel group this step\n" +
+	"                  # is part of. Consecutive steps in the same branch are executed in order,\n" +
+	"                  # while branches are executed concurrently. This field is populated when\n" +
+	"                  # a `parallel` group is resolved and cannot be set directly.\n" +
+	"                  parallel_branch: ' '\n" +
+	"                  # ParallelGroup is the name of the parallel group this step is part of.\n" +
+	"                  # Consecutive steps in a phase with the same group name are executed\n" +
+	"                  # concurrently. This field is populated when a `parallel` group is\n" +
+	"                  # resolved and cannot be set directly.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -764,6 +812,20 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry configures the step to be executed again when it fails because\n" +
+	"                  # of an infrastructure problem.\n" +
+	"                  retry:\n" +
+	"                    # Backoff is how long to wait before each retry, doubled after each one.\n" +
+	"                    backoff: 0s\n" +
+	"                    # Count is the maximum number of times the step is retried after the\n" +
+	"                    # initial attempt, at most 5.\n" +
+	"                    count: 0\n" +
+	"                    # LogPattern is a regular expression: the step is also retried if it\n" +
+	"                    # matches the logs of the failed test container.\n" +
+	"                    log_pattern: ' '\n" +
+	"                    # Reasons are the failures for which the step is retried.\n" +
+	"                    reasons:\n" +
+	"                        - \"\"\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
@@ -830,8 +892,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
 	"                      env: ' '\n" +
+	"                      # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"                      # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"                      # The type that was leased and the region of the resources will be exposed\n" +
+	"                      # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"                      # LeaseRegionEnv.\n" +
+	"                      fallback_resource_types:\n" +
+	"                        - \"\"\n" +
 	"                      # ResourceType is the type of resource that will be leased.\n" +
 	"                      resource_type: ' '\n" +
+	"                  # Lifecycle marks the step as deprecated. It is only valid for steps in\n" +
+	"                  # the registry and is carried over to the steps resolved from them.\n" +
+	"                  lifecycle:\n" +
+	"                    deprecated: ' '\n" +
+	"                    removal_date: ' '\n" +
+	"                    replaced_by: ' '\n" +
 	"                  # NoKubeconfig determines that no $KUBECONFIG will exist in $SHARED_DIR,\n" +
 	"                  # so no local copy of it will be created for the step and if the step\n" +
 	"                  # creates one, it will not be propagated.\n" +
@@ -847,6 +922,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelBranch is the name of the branch of a parallel group this step\n" +
+	"                  # is part of. Consecutive steps in the same branch are executed in order,\n" +
+	"                  # while branches are executed concurrently. This field is populated when\n" +
+	"                  # a `parallel` group is resolved and cannot be set directly.\n" +
+	"                  parallel_branch: ' '\n" +
+	"                  # ParallelGroup is the name of the parallel group this step is part of.\n" +
+	"                  # Consecutive steps in a phase with the same group name are executed\n" +
+	"                  # concurrently. This field is populated when a `parallel` group is\n" +
+	"                  # resolved and cannot be set directly.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -857,11 +942,39 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry configures the step to be executed again when it fails because\n" +
+	"                  # of an infrastructure problem.\n" +
+	"                  retry:\n" +
+	"                    # Backoff is how long to wait before each retry, doubled after each one.\n" +
+	"                    backoff: 0s\n" +
+	"                    # Count is the maximum number of times the step is retried after the\n" +
+	"                    # initial attempt, at most 5.\n" +
+	"                    count: 0\n" +
+	"                    # LogPattern is a regular expression: the step is also retried if it\n" +
+	"                    # matches the logs of the failed test container.\n" +
+	"                    log_pattern: ' '\n" +
+	"                    # Reasons are the failures for which the step is retried.\n" +
+	"                    reasons:\n" +
+	"                        - \"\"\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"            # SharedDir configures the storage backing the shared directory of the test.\n" +
+	"            shared_dir:\n" +
+	"                # Backend is the storage used for the shared directory, `secret` (the\n" +
+	"                # default) or `pvc`.\n" +
+	"                backend: ' '\n" +
+	"                # SizeLimit is the maximum total size of the content of the shared\n" +
+	"                # directory, as a Kubernetes quantity. A step fails if it leaves more\n" +
+	"                # content than this in the shared directory. Required for the `pvc`\n" +
+	"                # backend, where it also determines the size of the volume.\n" +
+	"                size_limit: ' '\n" +
+	"                # StorageClass is the storage class of the volume claim of the `pvc`\n" +
+	"                # backend, which must support the `ReadWriteMany` access mode. The\n" +
+	"                # default storage class of the cluster is used if unset.\n" +
+	"                storage_class: ' '\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
 	"                - # As is the name of the LiteralTestStep.\n" +
@@ -923,8 +1036,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
 	"                      env: ' '\n" +
+	"                      # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"                      # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"                      # The type that was leased and the region of the resources will be exposed\n" +
+	"                      # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"                      # LeaseRegionEnv.\n" +
+	"                      fallback_resource_types:\n" +
+	"                        - \"\"\n" +
 	"                      # ResourceType is the type of resource that will be leased.\n" +
 	"                      resource_type: ' '\n" +
+	"                  # Lifecycle marks the step as deprecated. It is only valid for steps in\n" +
+	"                  # the registry and is carried over to the steps resolved from them.\n" +
+	"                  lifecycle:\n" +
+	"                    deprecated: ' '\n" +
+	"                    removal_date: ' '\n" +
+	"                    replaced_by: ' '\n" +
 	"                  # NoKubeconfig determines that no $KUBECONFIG will exist in $SHARED_DIR,\n" +
 	"                  # so no local copy of it will be created for the step and if the step\n" +
 	"                  # creates one, it will not be propagated.\n" +
@@ -940,6 +1066,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelBranch is the name of the branch of a parallel group this step\n" +
+	"                  # is part of. Consecutive steps in the same branch are executed in order,\n" +
+	"                  # while branches are executed concurrently. This field is populated when\n" +
+	"                  # a `parallel` group is resolved and cannot be set directly.\n" +
+	"                  parallel_branch: ' '\n" +
+	"                  # ParallelGroup is the name of the parallel group this step is part of.\n" +
+	"                  # Consecutive steps in a phase with the same group name are executed\n" +
+	"                  # concurrently. This field is populated when a `parallel` group is\n" +
+	"                  # resolved and cannot be set directly.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -950,6 +1086,20 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry configures the step to be executed again when it fails because\n" +
+	"                  # of an infrastructure problem.\n" +
+	"                  retry:\n" +
+	"                    # Backoff is how long to wait before each retry, doubled after each one.\n" +
+	"                    backoff: 0s\n" +
+	"                    # Count is the maximum number of times the step is retried after the\n" +
+	"                    # initial attempt, at most 5.\n" +
+	"                    count: 0\n" +
+	"                    # LogPattern is a regular expression: the step is also retried if it\n" +
+	"                    # matches the logs of the failed test container.\n" +
+	"                    log_pattern: ' '\n" +
+	"                    # Reasons are the failures for which the step is retried.\n" +
+	"                    reasons:\n" +
+	"                        - \"\"\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
@@ -1063,10 +1213,22 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # Environment has the values of parameters for the steps.\n" +
 	"            env:\n" +
 	"                \"\": \"\"\n" +
+	"            # FallbackClusterProfiles are profiles, in order of preference, whose lease\n" +
+	"            # will be acquired when none of the ClusterProfile is free. Steps are given\n" +
+	"            # the credentials of the profile whose lease was acquired.\n" +
+	"            fallback_cluster_profiles:\n" +
+	"                - \"\"\n" +
 	"            # Leases lists resources that should be acquired for the test.\n" +
 	"            leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
 	"                  env: ' '\n" +
+	"                  # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"                  # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"                  # The type that was leased and the region of the resources will be exposed\n" +
+	"                  # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"                  # LeaseRegionEnv.\n" +
+	"                  fallback_resource_types:\n" +
+	"                    - \"\"\n" +
 	"                  # ResourceType is the type of resource that will be leased.\n" +
 	"                  resource_type: ' '\n" +
 	"            # NodeArchitecture is the architecture for the node where the test will run.\n" +
@@ -1126,7 +1288,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
+	"                      fallback_resource_types:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                      resource_type: ' '\n" +
+	"                  lifecycle:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    deprecated: ' '\n" +
+	"                    removal_date: ' '\n" +
+	"                    replaced_by: ' '\n" +
 	"                  no_kubeconfig: false\n" +
 	"                  node_architecture: \"\"\n" +
 	"                  # Observers are the observers that should be running\n" +
@@ -1134,6 +1304,95 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  # Parallel is a group of steps which are executed concurrently.\n" +
+	"                  parallel:\n" +
+	"                    # As is the name of the group.\n" +
+	"                    as: ' '\n" +
+	"                    # Steps are the branches that are executed concurrently.\n" +
+	"                    steps:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - as: ' '\n" +
+	"                          best_effort: false\n" +
+	"                          # Chain is the name of a step chain reference.\n" +
+	"                          chain: \"\"\n" +
+	"                          # Cli is the (optional) name of the release from which the `oc` binary\n" +
+	"                          # will be injected into this step.\n" +
+	"                          cli: ' '\n" +
+	"                          commands: ' '\n" +
+	"                          credentials:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - mount_path: ' '\n" +
+	"                              name: ' '\n" +
+	"                              namespace: ' '\n" +
+	"                          dependencies:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - env: ' '\n" +
+	"                              name: ' '\n" +
+	"                          dnsConfig:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            nameservers:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                            searches:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                          env:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - default: \"\"\n" +
+	"                              documentation: ' '\n" +
+	"                              name: ' '\n" +
+	"                          from: ' '\n" +
+	"                          from_image:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            as: ' '\n" +
+	"                            name: ' '\n" +
+	"                            namespace: ' '\n" +
+	"                            tag: ' '\n" +
+	"                          grace_period: 0s\n" +
+	"                          leases:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - env: ' '\n" +
+	"                              fallback_resource_types:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                              resource_type: ' '\n" +
+	"                          lifecycle:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            deprecated: ' '\n" +
+	"                            removal_date: ' '\n" +
+	"                            replaced_by: ' '\n" +
+	"                          no_kubeconfig: false\n" +
+	"                          node_architecture: \"\"\n" +
+	"                          # Observers are the observers that should be running\n" +
+	"                          observers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          optional_on_success: false\n" +
+	"                          parallel_branch: ' '\n" +
+	"                          parallel_group: ' '\n" +
+	"                          # Reference is the name of a step reference.\n" +
+	"                          ref: \"\"\n" +
+	"                          # Resources defines the resource requirements for the step.\n" +
+	"                          resources:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            limits:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                \"\": \"\"\n" +
+	"                          retry:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            backoff: 0s\n" +
+	"                            count: 0\n" +
+	"                            log_pattern: ' '\n" +
+	"                            reasons:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                          run_as_script: false\n" +
+	"                          timeout: 0s\n" +
+	"                  parallel_branch: ' '\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1145,6 +1404,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    backoff: 0s\n" +
+	"                    count: 0\n" +
+	"                    log_pattern: ' '\n" +
+	"                    reasons:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
@@ -1191,7 +1458,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
+	"                      fallback_resource_types:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                      resource_type: ' '\n" +
+	"                  lifecycle:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    deprecated: ' '\n" +
+	"                    removal_date: ' '\n" +
+	"                    replaced_by: ' '\n" +
 	"                  no_kubeconfig: false\n" +
 	"                  node_architecture: \"\"\n" +
 	"                  # Observers are the observers that should be running\n" +
@@ -1199,6 +1474,95 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  # Parallel is a group of steps which are executed concurrently.\n" +
+	"                  parallel:\n" +
+	"                    # As is the name of the group.\n" +
+	"                    as: ' '\n" +
+	"                    # Steps are the branches that are executed concurrently.\n" +
+	"                    steps:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - as: ' '\n" +
+	"                          best_effort: false\n" +
+	"                          # Chain is the name of a step chain reference.\n" +
+	"                          chain: \"\"\n" +
+	"                          # Cli is the (optional) name of the release from which the `oc` binary\n" +
+	"                          # will be injected into this step.\n" +
+	"                          cli: ' '\n" +
+	"                          commands: ' '\n" +
+	"                          credentials:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - mount_path: ' '\n" +
+	"                              name: ' '\n" +
+	"                              namespace: ' '\n" +
+	"                          dependencies:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - env: ' '\n" +
+	"                              name: ' '\n" +
+	"                          dnsConfig:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            nameservers:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                            searches:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                          env:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - default: \"\"\n" +
+	"                              documentation: ' '\n" +
+	"                              name: ' '\n" +
+	"                          from: ' '\n" +
+	"                          from_image:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            as: ' '\n" +
+	"                            name: ' '\n" +
+	"                            namespace: ' '\n" +
+	"                            tag: ' '\n" +
+	"                          grace_period: 0s\n" +
+	"                          leases:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - env: ' '\n" +
+	"                              fallback_resource_types:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                              resource_type: ' '\n" +
+	"                          lifecycle:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            deprecated: ' '\n" +
+	"                            removal_date: ' '\n" +
+	"                            replaced_by: ' '\n" +
+	"                          no_kubeconfig: false\n" +
+	"                          node_architecture: \"\"\n" +
+	"                          # Observers are the observers that should be running\n" +
+	"                          observers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          optional_on_success: false\n" +
+	"                          parallel_branch: ' '\n" +
+	"                          parallel_group: ' '\n" +
+	"                          # Reference is the name of a step reference.\n" +
+	"                          ref: \"\"\n" +
+	"                          # Resources defines the resource requirements for the step.\n" +
+	"                          resources:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            limits:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                \"\": \"\"\n" +
+	"                          retry:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            backoff: 0s\n" +
+	"                            count: 0\n" +
+	"                            log_pattern: ' '\n" +
+	"                            reasons:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                          run_as_script: false\n" +
+	"                          timeout: 0s\n" +
+	"                  parallel_branch: ' '\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1210,8 +1574,30 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    backoff: 0s\n" +
+	"                    count: 0\n" +
+	"                    log_pattern: ' '\n" +
+	"                    reasons:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
+	"            # SharedDir configures the storage backing the shared directory of the test.\n" +
+	"            shared_dir:\n" +
+	"                # Backend is the storage used for the shared directory, `secret` (the\n" +
+	"                # default) or `pvc`.\n" +
+	"                backend: ' '\n" +
+	"                # SizeLimit is the maximum total size of the content of the shared\n" +
+	"                # directory, as a Kubernetes quantity. A step fails if it leaves more\n" +
+	"                # content than this in the shared directory. Required for the `pvc`\n" +
+	"                # backend, where it also determines the size of the volume.\n" +
+	"                size_limit: ' '\n" +
+	"                # StorageClass is the storage class of the volume claim of the `pvc`\n" +
+	"                # backend, which must support the `ReadWriteMany` access mode. The\n" +
+	"                # default storage class of the cluster is used if unset.\n" +
+	"                storage_class: ' '\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1256,7 +1642,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
+	"                      fallback_resource_types:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                      resource_type: ' '\n" +
+	"                  lifecycle:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    deprecated: ' '\n" +
+	"                    removal_date: ' '\n" +
+	"                    replaced_by: ' '\n" +
 	"                  no_kubeconfig: false\n" +
 	"                  node_architecture: \"\"\n" +
 	"                  # Observers are the observers that should be running\n" +
@@ -1264,6 +1658,95 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  # Parallel is a group of steps which are executed concurrently.\n" +
+	"                  parallel:\n" +
+	"                    # As is the name of the group.\n" +
+	"                    as: ' '\n" +
+	"                    # Steps are the branches that are executed concurrently.\n" +
+	"                    steps:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - as: ' '\n" +
+	"                          best_effort: false\n" +
+	"                          # Chain is the name of a step chain reference.\n" +
+	"                          chain: \"\"\n" +
+	"                          # Cli is the (optional) name of the release from which the `oc` binary\n" +
+	"                          # will be injected into this step.\n" +
+	"                          cli: ' '\n" +
+	"                          commands: ' '\n" +
+	"                          credentials:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - mount_path: ' '\n" +
+	"                              name: ' '\n" +
+	"                              namespace: ' '\n" +
+	"                          dependencies:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - env: ' '\n" +
+	"                              name: ' '\n" +
+	"                          dnsConfig:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            nameservers:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                            searches:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                          env:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - default: \"\"\n" +
+	"                              documentation: ' '\n" +
+	"                              name: ' '\n" +
+	"                          from: ' '\n" +
+	"                          from_image:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            as: ' '\n" +
+	"                            name: ' '\n" +
+	"                            namespace: ' '\n" +
+	"                            tag: ' '\n" +
+	"                          grace_period: 0s\n" +
+	"                          leases:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - env: ' '\n" +
+	"                              fallback_resource_types:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                              resource_type: ' '\n" +
+	"                          lifecycle:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            deprecated: ' '\n" +
+	"                            removal_date: ' '\n" +
+	"                            replaced_by: ' '\n" +
+	"                          no_kubeconfig: false\n" +
+	"                          node_architecture: \"\"\n" +
+	"                          # Observers are the observers that should be running\n" +
+	"                          observers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          optional_on_success: false\n" +
+	"                          parallel_branch: ' '\n" +
+	"                          parallel_group: ' '\n" +
+	"                          # Reference is the name of a step reference.\n" +
+	"                          ref: \"\"\n" +
+	"                          # Resources defines the resource requirements for the step.\n" +
+	"                          resources:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            limits:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                \"\": \"\"\n" +
+	"                          retry:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            backoff: 0s\n" +
+	"                            count: 0\n" +
+	"                            log_pattern: ' '\n" +
+	"                            reasons:\n" +
+	"                                # LiteralTestStep is a full test step definition.\n" +
+	"                                - \"\"\n" +
+	"                          run_as_script: false\n" +
+	"                          timeout: 0s\n" +
+	"                  parallel_branch: ' '\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1275,6 +1758,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    backoff: 0s\n" +
+	"                    count: 0\n" +
+	"                    log_pattern: ' '\n" +
+	"                    reasons:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
 	"            # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
@@ -1467,6 +1958,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # be used with rehearsals. Otherwise, the overrides should be passed in as parameters to ci-operator.\n" +
 	"        dependency_overrides:\n" +
 	"            \"\": \"\"\n" +
+	"        # Deprecations describe the deprecated workflow and chains of the registry\n" +
+	"        # the test was resolved from. Deprecated steps carry their own lifecycle.\n" +
+	"        deprecations:\n" +
+	"            - \"\"\n" +
 	"        # DnsConfig for step's Pod.\n" +
 	"        dnsConfig:\n" +
 	"            # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -1478,10 +1973,22 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # Environment has the values of parameters for the steps.\n" +
 	"        env:\n" +
 	"            \"\": \"\"\n" +
+	"        # FallbackClusterProfiles are profiles, in order of preference, whose lease\n" +
+	"        # will be acquired when none of the ClusterProfile is free. Steps are given\n" +
+	"        # the credentials of the profile whose lease was acquired.\n" +
+	"        fallback_cluster_profiles:\n" +
+	"            - \"\"\n" +
 	"        # Leases lists resources that should be acquired for the test.\n" +
 	"        leases:\n" +
 	"            - # Env is the environment variable that will contain the resource name.\n" +
 	"              env: ' '\n" +
+	"              # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"              # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"              # The type that was leased and the region of the resources will be exposed\n" +
+	"              # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"              # LeaseRegionEnv.\n" +
+	"              fallback_resource_types:\n" +
+	"                - \"\"\n" +
 	"              # ResourceType is the type of resource that will be leased.\n" +
 	"              resource_type: ' '\n" +
 	"        # NodeArchitecture is the architecture for the node where the test will run.\n" +
@@ -1587,8 +2094,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
 	"                  env: ' '\n" +
+	"                  # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"                  # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"                  # The type that was leased and the region of the resources will be exposed\n" +
+	"                  # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"                  # LeaseRegionEnv.\n" +
+	"                  fallback_resource_types:\n" +
+	"                    - \"\"\n" +
 	"                  # ResourceType is the type of resource that will be leased.\n" +
 	"                  resource_type: ' '\n" +
+	"              # Lifecycle marks the step as deprecated. It is only valid for steps in\n" +
+	"              # the registry and is carried over to the steps resolved from them.\n" +
+	"              lifecycle:\n" +
+	"                deprecated: ' '\n" +
+	"                removal_date: ' '\n" +
+	"                replaced_by: ' '\n" +
 	"              # NoKubeconfig determines that no $KUBECONFIG will exist in $SHARED_DIR,\n" +
 	"              # so no local copy of it will be created for the step and if the step\n" +
 	"              # creates one, it will not be propagated.\n" +
@@ -1604,6 +2124,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelBranch is the name of the branch of a parallel group this step\n" +
+	"              # is part of. Consecutive steps in the same branch are executed in order,\n" +
+	"              # while branches are executed concurrently. This field is populated when\n" +
+	"              # a `parallel` group is resolved and cannot be set directly.\n" +
+	"              parallel_branch: ' '\n" +
+	"              # ParallelGroup is the name of the parallel group this step is part of.\n" +
+	"              # Consecutive steps in a phase with the same group name are executed\n" +
+	"              # concurrently. This field is populated when a `parallel` group is\n" +
+	"              # resolved and cannot be set directly.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1614,6 +2144,20 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry configures the step to be executed again when it fails because\n" +
+	"              # of an infrastructure problem.\n" +
+	"              retry:\n" +
+	"                # Backoff is how long to wait before each retry, doubled after each one.\n" +
+	"                backoff: 0s\n" +
+	"                # Count is the maximum number of times the step is retried after the\n" +
+	"                # initial attempt, at most 5.\n" +
+	"                count: 0\n" +
+	"                # LogPattern is a regular expression: the step is also retried if it\n" +
+	"                # matches the logs of the failed test container.\n" +
+	"                log_pattern: ' '\n" +
+	"                # Reasons are the failures for which the step is retried.\n" +
+	"                reasons:\n" +
+	"                    - \"\"\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
@@ -1680,8 +2224,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
 	"                  env: ' '\n" +
+	"                  # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"                  # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"                  # The type that was leased and the region of the resources will be exposed\n" +
+	"                  # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"                  # LeaseRegionEnv.\n" +
+	"                  fallback_resource_types:\n" +
+	"                    - \"\"\n" +
 	"                  # ResourceType is the type of resource that will be leased.\n" +
 	"                  resource_type: ' '\n" +
+	"              # Lifecycle marks the step as deprecated. It is only valid for steps in\n" +
+	"              # the registry and is carried over to the steps resolved from them.\n" +
+	"              lifecycle:\n" +
+	"                deprecated: ' '\n" +
+	"                removal_date: ' '\n" +
+	"                replaced_by: ' '\n" +
 	"              # NoKubeconfig determines that no $KUBECONFIG will exist in $SHARED_DIR,\n" +
 	"              # so no local copy of it will be created for the step and if the step\n" +
 	"              # creates one, it will not be propagated.\n" +
@@ -1697,6 +2254,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelBranch is the name of the branch of a parallel group this step\n" +
+	"              # is part of. Consecutive steps in the same branch are executed in order,\n" +
+	"              # while branches are executed concurrently. This field is populated when\n" +
+	"              # a `parallel` group is resolved and cannot be set directly.\n" +
+	"              parallel_branch: ' '\n" +
+	"              # ParallelGroup is the name of the parallel group this step is part of.\n" +
+	"              # Consecutive steps in a phase with the same group name are executed\n" +
+	"              # concurrently. This field is populated when a `parallel` group is\n" +
+	"              # resolved and cannot be set directly.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1707,11 +2274,39 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry configures the step to be executed again when it fails because\n" +
+	"              # of an infrastructure problem.\n" +
+	"              retry:\n" +
+	"                # Backoff is how long to wait before each retry, doubled after each one.\n" +
+	"                backoff: 0s\n" +
+	"                # Count is the maximum number of times the step is retried after the\n" +
+	"                # initial attempt, at most 5.\n" +
+	"                count: 0\n" +
+	"                # LogPattern is a regular expression: the step is also retried if it\n" +
+	"                # matches the logs of the failed test container.\n" +
+	"                log_pattern: ' '\n" +
+	"                # Reasons are the failures for which the step is retried.\n" +
+	"                reasons:\n" +
+	"                    - \"\"\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"        # SharedDir configures the storage backing the shared directory of the test.\n" +
+	"        shared_dir:\n" +
+	"            # Backend is the storage used for the shared directory, `secret` (the\n" +
+	"            # default) or `pvc`.\n" +
+	"            backend: ' '\n" +
+	"            # SizeLimit is the maximum total size of the content of the shared\n" +
+	"            # directory, as a Kubernetes quantity. A step fails if it leaves more\n" +
+	"            # content than this in the shared directory. Required for the `pvc`\n" +
+	"            # backend, where it also determines the size of the volume.\n" +
+	"            size_limit: ' '\n" +
+	"            # StorageClass is the storage class of the volume claim of the `pvc`\n" +
+	"            # backend, which must support the `ReadWriteMany` access mode. The\n" +
+	"            # default storage class of the cluster is used if unset.\n" +
+	"            storage_class: ' '\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
 	"            - # As is the name of the LiteralTestStep.\n" +
@@ -1773,8 +2368,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
 	"                  env: ' '\n" +
+	"                  # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"                  # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"                  # The type that was leased and the region of the resources will be exposed\n" +
+	"                  # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"                  # LeaseRegionEnv.\n" +
+	"                  fallback_resource_types:\n" +
+	"                    - \"\"\n" +
 	"                  # ResourceType is the type of resource that will be leased.\n" +
 	"                  resource_type: ' '\n" +
+	"              # Lifecycle marks the step as deprecated. It is only valid for steps in\n" +
+	"              # the registry and is carried over to the steps resolved from them.\n" +
+	"              lifecycle:\n" +
+	"                deprecated: ' '\n" +
+	"                removal_date: ' '\n" +
+	"                replaced_by: ' '\n" +
 	"              # NoKubeconfig determines that no $KUBECONFIG will exist in $SHARED_DIR,\n" +
 	"              # so no local copy of it will be created for the step and if the step\n" +
 	"              # creates one, it will not be propagated.\n" +
@@ -1790,6 +2398,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelBranch is the name of the branch of a parallel group this step\n" +
+	"              # is part of. Consecutive steps in the same branch are executed in order,\n" +
+	"              # while branches are executed concurrently. This field is populated when\n" +
+	"              # a `parallel` group is resolved and cannot be set directly.\n" +
+	"              parallel_branch: ' '\n" +
+	"              # ParallelGroup is the name of the parallel group this step is part of.\n" +
+	"              # Consecutive steps in a phase with the same group name are executed\n" +
+	"              # concurrently. This field is populated when a `parallel` group is\n" +
+	"              # resolved and cannot be set directly.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1800,6 +2418,20 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry configures the step to be executed again when it fails because\n" +
+	"              # of an infrastructure problem.\n" +
+	"              retry:\n" +
+	"                # Backoff is how long to wait before each retry, doubled after each one.\n" +
+	"                backoff: 0s\n" +
+	"                # Count is the maximum number of times the step is retried after the\n" +
+	"                # initial attempt, at most 5.\n" +
+	"                count: 0\n" +
+	"                # LogPattern is a regular expression: the step is also retried if it\n" +
+	"                # matches the logs of the failed test container.\n" +
+	"                log_pattern: ' '\n" +
+	"                # Reasons are the failures for which the step is retried.\n" +
+	"                reasons:\n" +
+	"                    - \"\"\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
@@ -1913,10 +2545,22 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # Environment has the values of parameters for the steps.\n" +
 	"        env:\n" +
 	"            \"\": \"\"\n" +
+	"        # FallbackClusterProfiles are profiles, in order of preference, whose lease\n" +
+	"        # will be acquired when none of the ClusterProfile is free. Steps are given\n" +
+	"        # the credentials of the profile whose lease was acquired.\n" +
+	"        fallback_cluster_profiles:\n" +
+	"            - \"\"\n" +
 	"        # Leases lists resources that should be acquired for the test.\n" +
 	"        leases:\n" +
 	"            - # Env is the environment variable that will contain the resource name.\n" +
 	"              env: ' '\n" +
+	"              # FallbackResourceTypes are alternative types of resource, in order of\n" +
+	"              # preference, that will be leased when no resource of ResourceType is free.\n" +
+	"              # The type that was leased and the region of the resources will be exposed\n" +
+	"              # to the step via the environment variables named by LeaseTypeEnv and\n" +
+	"              # LeaseRegionEnv.\n" +
+	"              fallback_resource_types:\n" +
+	"                - \"\"\n" +
 	"              # ResourceType is the type of resource that will be leased.\n" +
 	"              resource_type: ' '\n" +
 	"        # NodeArchitecture is the architecture for the node where the test will run.\n" +
@@ -1976,7 +2620,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
+	"                  fallback_resource_types:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"                  resource_type: ' '\n" +
+	"              lifecycle:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                deprecated: ' '\n" +
+	"                removal_date: ' '\n" +
+	"                replaced_by: ' '\n" +
 	"              no_kubeconfig: false\n" +
 	"              node_architecture: \"\"\n" +
 	"              # Observers are the observers that should be running\n" +
@@ -1984,6 +2636,95 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              # Parallel is a group of steps which are executed concurrently.\n" +
+	"              parallel:\n" +
+	"                # As is the name of the group.\n" +
+	"                as: ' '\n" +
+	"                # Steps are the branches that are executed concurrently.\n" +
+	"                steps:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      best_effort: false\n" +
+	"                      # Chain is the name of a step chain reference.\n" +
+	"                      chain: \"\"\n" +
+	"                      # Cli is the (optional) name of the release from which the `oc` binary\n" +
+	"                      # will be injected into this step.\n" +
+	"                      cli: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      credentials:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - mount_path: ' '\n" +
+	"                          name: ' '\n" +
+	"                          namespace: ' '\n" +
+	"                      dependencies:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                      dnsConfig:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        nameservers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                        searches:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - default: \"\"\n" +
+	"                          documentation: ' '\n" +
+	"                          name: ' '\n" +
+	"                      from: ' '\n" +
+	"                      from_image:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        as: ' '\n" +
+	"                        name: ' '\n" +
+	"                        namespace: ' '\n" +
+	"                        tag: ' '\n" +
+	"                      grace_period: 0s\n" +
+	"                      leases:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          fallback_resource_types:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          resource_type: ' '\n" +
+	"                      lifecycle:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        deprecated: ' '\n" +
+	"                        removal_date: ' '\n" +
+	"                        replaced_by: ' '\n" +
+	"                      no_kubeconfig: false\n" +
+	"                      node_architecture: \"\"\n" +
+	"                      # Observers are the observers that should be running\n" +
+	"                      observers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      optional_on_success: false\n" +
+	"                      parallel_branch: ' '\n" +
+	"                      parallel_group: ' '\n" +
+	"                      # Reference is the name of a step reference.\n" +
+	"                      ref: \"\"\n" +
+	"                      # Resources defines the resource requirements for the step.\n" +
+	"                      resources:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        limits:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                      retry:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        backoff: 0s\n" +
+	"                        count: 0\n" +
+	"                        log_pattern: ' '\n" +
+	"                        reasons:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      run_as_script: false\n" +
+	"                      timeout: 0s\n" +
+	"              parallel_branch: ' '\n" +
+	"              parallel_group: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -1995,6 +2736,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                backoff: 0s\n" +
+	"                count: 0\n" +
+	"                log_pattern: ' '\n" +
+	"                reasons:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
@@ -2041,7 +2790,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
+	"                  fallback_resource_types:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"                  resource_type: ' '\n" +
+	"              lifecycle:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                deprecated: ' '\n" +
+	"                removal_date: ' '\n" +
+	"                replaced_by: ' '\n" +
 	"              no_kubeconfig: false\n" +
 	"              node_architecture: \"\"\n" +
 	"              # Observers are the observers that should be running\n" +
@@ -2049,6 +2806,95 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              # Parallel is a group of steps which are executed concurrently.\n" +
+	"              parallel:\n" +
+	"                # As is the name of the group.\n" +
+	"                as: ' '\n" +
+	"                # Steps are the branches that are executed concurrently.\n" +
+	"                steps:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      best_effort: false\n" +
+	"                      # Chain is the name of a step chain reference.\n" +
+	"                      chain: \"\"\n" +
+	"                      # Cli is the (optional) name of the release from which the `oc` binary\n" +
+	"                      # will be injected into this step.\n" +
+	"                      cli: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      credentials:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - mount_path: ' '\n" +
+	"                          name: ' '\n" +
+	"                          namespace: ' '\n" +
+	"                      dependencies:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                      dnsConfig:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        nameservers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                        searches:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - default: \"\"\n" +
+	"                          documentation: ' '\n" +
+	"                          name: ' '\n" +
+	"                      from: ' '\n" +
+	"                      from_image:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        as: ' '\n" +
+	"                        name: ' '\n" +
+	"                        namespace: ' '\n" +
+	"                        tag: ' '\n" +
+	"                      grace_period: 0s\n" +
+	"                      leases:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          fallback_resource_types:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          resource_type: ' '\n" +
+	"                      lifecycle:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        deprecated: ' '\n" +
+	"                        removal_date: ' '\n" +
+	"                        replaced_by: ' '\n" +
+	"                      no_kubeconfig: false\n" +
+	"                      node_architecture: \"\"\n" +
+	"                      # Observers are the observers that should be running\n" +
+	"                      observers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      optional_on_success: false\n" +
+	"                      parallel_branch: ' '\n" +
+	"                      parallel_group: ' '\n" +
+	"                      # Reference is the name of a step reference.\n" +
+	"                      ref: \"\"\n" +
+	"                      # Resources defines the resource requirements for the step.\n" +
+	"                      resources:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        limits:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                      retry:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        backoff: 0s\n" +
+	"                        count: 0\n" +
+	"                        log_pattern: ' '\n" +
+	"                        reasons:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      run_as_script: false\n" +
+	"                      timeout: 0s\n" +
+	"              parallel_branch: ' '\n" +
+	"              parallel_group: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -2060,8 +2906,30 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                backoff: 0s\n" +
+	"                count: 0\n" +
+	"                log_pattern: ' '\n" +
+	"                reasons:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
+	"        # SharedDir configures the storage backing the shared directory of the test.\n" +
+	"        shared_dir:\n" +
+	"            # Backend is the storage used for the shared directory, `secret` (the\n" +
+	"            # default) or `pvc`.\n" +
+	"            backend: ' '\n" +
+	"            # SizeLimit is the maximum total size of the content of the shared\n" +
+	"            # directory, as a Kubernetes quantity. A step fails if it leaves more\n" +
+	"            # content than this in the shared directory. Required for the `pvc`\n" +
+	"            # backend, where it also determines the size of the volume.\n" +
+	"            size_limit: ' '\n" +
+	"            # StorageClass is the storage class of the volume claim of the `pvc`\n" +
+	"            # backend, which must support the `ReadWriteMany` access mode. The\n" +
+	"            # default storage class of the cluster is used if unset.\n" +
+	"            storage_class: ' '\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
@@ -2106,7 +2974,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
+	"                  fallback_resource_types:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"                  resource_type: ' '\n" +
+	"              lifecycle:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                deprecated: ' '\n" +
+	"                removal_date: ' '\n" +
+	"                replaced_by: ' '\n" +
 	"              no_kubeconfig: false\n" +
 	"              node_architecture: \"\"\n" +
 	"              # Observers are the observers that should be running\n" +
@@ -2114,6 +2990,95 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              # Parallel is a group of steps which are executed concurrently.\n" +
+	"              parallel:\n" +
+	"                # As is the name of the group.\n" +
+	"                as: ' '\n" +
+	"                # Steps are the branches that are executed concurrently.\n" +
+	"                steps:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      best_effort: false\n" +
+	"                      # Chain is the name of a step chain reference.\n" +
+	"                      chain: \"\"\n" +
+	"                      # Cli is the (optional) name of the release from which the `oc` binary\n" +
+	"                      # will be injected into this step.\n" +
+	"                      cli: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      credentials:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - mount_path: ' '\n" +
+	"                          name: ' '\n" +
+	"                          namespace: ' '\n" +
+	"                      dependencies:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                      dnsConfig:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        nameservers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                        searches:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - default: \"\"\n" +
+	"                          documentation: ' '\n" +
+	"                          name: ' '\n" +
+	"                      from: ' '\n" +
+	"                      from_image:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        as: ' '\n" +
+	"                        name: ' '\n" +
+	"                        namespace: ' '\n" +
+	"                        tag: ' '\n" +
+	"                      grace_period: 0s\n" +
+	"                      leases:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          fallback_resource_types:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          resource_type: ' '\n" +
+	"                      lifecycle:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        deprecated: ' '\n" +
+	"                        removal_date: ' '\n" +
+	"                        replaced_by: ' '\n" +
+	"                      no_kubeconfig: false\n" +
+	"                      node_architecture: \"\"\n" +
+	"                      # Observers are the observers that should be running\n" +
+	"                      observers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      optional_on_success: false\n" +
+	"                      parallel_branch: ' '\n" +
+	"                      parallel_group: ' '\n" +
+	"                      # Reference is the name of a step reference.\n" +
+	"                      ref: \"\"\n" +
+	"                      # Resources defines the resource requirements for the step.\n" +
+	"                      resources:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        limits:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                      retry:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        backoff: 0s\n" +
+	"                        count: 0\n" +
+	"                        log_pattern: ' '\n" +
+	"                        reasons:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      run_as_script: false\n" +
+	"                      timeout: 0s\n" +
+	"              parallel_branch: ' '\n" +
+	"              parallel_group: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -2125,6 +3090,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                backoff: 0s\n" +
+	"                count: 0\n" +
+	"                log_pattern: ' '\n" +
+	"                reasons:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
 	"        # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +